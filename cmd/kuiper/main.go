@@ -174,7 +174,7 @@ func main() {
 		{
 			Name:    "create",
 			Aliases: []string{"create"},
-			Usage:   "create stream $stream_name | create stream $stream_name -f $stream_def_file | create table $table_name | create table $table_name -f $table_def_file| create rule $rule_name $rule_json | create rule $rule_name -f $rule_def_file | create plugin $plugin_type $plugin_name $plugin_json | create plugin $plugin_type $plugin_name -f $plugin_def_file | create service $service_name $service_json | create schema $schema_type $schema_name $schema_json | create script $script_json",
+			Usage:   "create stream $stream_name | create stream $stream_name -f $stream_def_file | create table $table_name | create table $table_name -f $table_def_file| create rule $rule_name $rule_json | create rule $rule_name -f $rule_def_file | create plugin $plugin_type $plugin_name $plugin_json | create plugin $plugin_type $plugin_name -f $plugin_def_file | create service $service_name $service_json | create schema $schema_type $schema_name $schema_json | create script $script_json | create savepoint $rule_name $savepoint_name",
 
 			Subcommands: []cli.Command{
 				{
@@ -282,6 +282,25 @@ func main() {
 						}
 					},
 				},
+				{
+					Name:  "savepoint",
+					Usage: "create savepoint $rule_name $savepoint_name",
+					Action: func(c *cli.Context) error {
+						if len(c.Args()) != 2 {
+							fmt.Printf("Expect rule name and savepoint name.\n")
+							return nil
+						}
+						args := &model.SavepointDesc{Rule: c.Args()[0], Name: c.Args()[1]}
+						var reply string
+						err = client.Call("Server.CreateSavepoint", args, &reply)
+						if err != nil {
+							fmt.Println(err)
+						} else {
+							fmt.Println(reply)
+						}
+						return nil
+					},
+				},
 				{
 					Name:  "plugin",
 					Usage: "create plugin $plugin_type $plugin_name [$plugin_json | -f plugin_def_file | -zf path_to_file]",
@@ -643,7 +662,7 @@ func main() {
 		{
 			Name:    "drop",
 			Aliases: []string{"drop"},
-			Usage:   "drop stream $stream_name | drop table $table_name |drop rule $rule_name | drop plugin $plugin_type $plugin_name -s $stop | drop service $service_name | drop schema $schema_type $schema_name | drop script $script_name",
+			Usage:   "drop stream $stream_name | drop table $table_name |drop rule $rule_name | drop plugin $plugin_type $plugin_name -s $stop | drop service $service_name | drop schema $schema_type $schema_name | drop script $script_name | drop savepoint $rule_name $savepoint_name",
 			Subcommands: []cli.Command{
 				{
 					Name:  "stream",
@@ -683,6 +702,25 @@ func main() {
 						return nil
 					},
 				},
+				{
+					Name:  "savepoint",
+					Usage: "drop savepoint $rule_name $savepoint_name",
+					Action: func(c *cli.Context) error {
+						if len(c.Args()) != 2 {
+							fmt.Printf("Expect rule name and savepoint name.\n")
+							return nil
+						}
+						args := &model.SavepointDesc{Rule: c.Args()[0], Name: c.Args()[1]}
+						var reply string
+						err = client.Call("Server.DropSavepoint", args, &reply)
+						if err != nil {
+							fmt.Println(err)
+						} else {
+							fmt.Println(reply)
+						}
+						return nil
+					},
+				},
 				{
 					Name:  "plugin",
 					Usage: "drop plugin $plugin_type $plugin_name -s stop",
@@ -792,7 +830,7 @@ func main() {
 		{
 			Name:    "show",
 			Aliases: []string{"show"},
-			Usage:   "show streams | show tables | show rules | show plugins $plugin_type | show services | show service_funcs | show schemas $schema_type | show scripts",
+			Usage:   "show streams | show tables | show rules | show plugins $plugin_type | show services | show service_funcs | show schemas $schema_type | show scripts | show savepoints $rule_name",
 
 			Subcommands: []cli.Command{
 				{
@@ -825,6 +863,24 @@ func main() {
 						return nil
 					},
 				},
+				{
+					Name:  "savepoints",
+					Usage: "show savepoints $rule_name",
+					Action: func(c *cli.Context) error {
+						if len(c.Args()) != 1 {
+							fmt.Printf("Expect rule name.\n")
+							return nil
+						}
+						var reply string
+						err = client.Call("Server.ShowSavepoints", c.Args()[0], &reply)
+						if err != nil {
+							fmt.Println(err)
+						} else {
+							fmt.Println(reply)
+						}
+						return nil
+					},
+				},
 				{
 					Name:  "plugins",
 					Usage: "show plugins $plugin_type",
@@ -997,12 +1053,17 @@ func main() {
 		{
 			Name:    "start",
 			Aliases: []string{"start"},
-			Usage:   "start rule $rule_name",
+			Usage:   "start rule $rule_name [-s $savepoint_name]",
 			Subcommands: []cli.Command{
 				{
 					Name:  "rule",
-					Usage: "start rule $rule_name",
-					// Flags: nflag,
+					Usage: "start rule $rule_name [-s $savepoint_name]",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "savepoint, s",
+							Usage: "the savepoint to restore the rule states from",
+						},
+					},
 					Action: func(c *cli.Context) error {
 						if len(c.Args()) != 1 {
 							fmt.Printf("Expect rule name.\n")
@@ -1010,7 +1071,11 @@ func main() {
 						}
 						rname := c.Args()[0]
 						var reply string
-						err = client.Call("Server.StartRule", rname, &reply)
+						if sp := c.String("savepoint"); sp != "" {
+							err = client.Call("Server.StartRuleFromSavepoint", &model.SavepointDesc{Rule: rname, Name: sp}, &reply)
+						} else {
+							err = client.Call("Server.StartRule", rname, &reply)
+						}
 						if err != nil {
 							fmt.Println(err)
 						} else {
@@ -1198,7 +1263,7 @@ func main() {
 		{
 			Name:    "import",
 			Aliases: []string{"import"},
-			Usage:   "import ruleset | data -f file -p partial -s stop",
+			Usage:   "import ruleset | data -f file -p partial -s stop | import savepoint $rule_name $savepoint_name -f file",
 			Subcommands: []cli.Command{
 				{
 					Name:  "ruleset",
@@ -1226,6 +1291,37 @@ func main() {
 						return nil
 					},
 				},
+				{
+					Name:  "savepoint",
+					Usage: "import savepoint $rule_name $savepoint_name -f savepoint_file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:     "file, f",
+							Usage:    "the location of the exported savepoint file",
+							FilePath: "/home/rule1_sp1.savepoint",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						sfile := c.String("file")
+						if sfile == "" {
+							fmt.Print("Required savepoint file to import")
+							return nil
+						}
+						if len(c.Args()) != 2 {
+							fmt.Printf("Expect rule name and savepoint name.\n")
+							return nil
+						}
+						args := &model.SavepointDesc{Rule: c.Args()[0], Name: c.Args()[1], FileName: sfile}
+						var reply string
+						err = client.Call("Server.ImportSavepoint", args, &reply)
+						if err != nil {
+							fmt.Println(err)
+						} else {
+							fmt.Println(reply)
+						}
+						return nil
+					},
+				},
 				{
 					Name:  "data",
 					Usage: "\"import data -f configuration_file -p partial -s stop",
@@ -1281,7 +1377,7 @@ func main() {
 		{
 			Name:    "export",
 			Aliases: []string{"export"},
-			Usage:   "export ruleset | data $ruleset_file [ -r rules ]",
+			Usage:   "export ruleset | data $ruleset_file [ -r rules ] | export savepoint $rule_name $savepoint_name $savepoint_file",
 			Subcommands: []cli.Command{
 				{
 					Name:  "ruleset",
@@ -1301,6 +1397,25 @@ func main() {
 						return nil
 					},
 				},
+				{
+					Name:  "savepoint",
+					Usage: "export savepoint $rule_name $savepoint_name $savepoint_file",
+					Action: func(c *cli.Context) error {
+						if len(c.Args()) != 3 {
+							fmt.Printf("Expect rule name, savepoint name and exported file name.\n")
+							return nil
+						}
+						args := &model.SavepointDesc{Rule: c.Args()[0], Name: c.Args()[1], FileName: c.Args()[2]}
+						var reply string
+						err = client.Call("Server.ExportSavepoint", args, &reply)
+						if err != nil {
+							fmt.Println(err)
+						} else {
+							fmt.Println(reply)
+						}
+						return nil
+					},
+				},
 				{
 					Name:  "data",
 					Usage: "export data $configuration_file [ -r rules ]",
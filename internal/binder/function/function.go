@@ -97,6 +97,36 @@ func IsAnalyticFunc(name string) bool {
 	return ok
 }
 
+// volatileFuncs are the built-in functions which may return different results for the same arguments
+var volatileFuncs = map[string]struct{}{
+	"rand":              {},
+	"newuuid":           {},
+	"now":               {},
+	"current_timestamp": {},
+	"local_time":        {},
+	"local_timestamp":   {},
+	"cur_date":          {},
+	"current_date":      {},
+	"cur_time":          {},
+	"current_time":      {},
+	"tstamp":            {},
+	"array_shuffle":     {},
+	"delay":             {},
+	"get_keyed_state":   {},
+}
+
+// IsDeterministicFunc returns true if the function is a built-in function which always returns the same
+// result for the same arguments and the same tuple. The plugin functions are never regarded as deterministic.
+func IsDeterministicFunc(name string) bool {
+	if _, ok := builtins[name]; !ok {
+		return false
+	}
+	if _, ok := volatileFuncs[name]; ok {
+		return false
+	}
+	return !IsAnalyticFunc(name)
+}
+
 type Manager struct{}
 
 // Function the name is converted to lowercase if needed during parsing
@@ -84,3 +84,17 @@ func TestRegister(t *testing.T) {
 	_, err := m.Function("nouse")
 	assert.NoError(t, err)
 }
+
+func TestIsDeterministicFunc(t *testing.T) {
+	tests := map[string]bool{
+		"abs":      true,
+		"upper":    true,
+		"rand":     false,
+		"now":      false,
+		"lag":      false,
+		"notexist": false,
+	}
+	for name, exp := range tests {
+		assert.Equal(t, exp, IsDeterministicFunc(name), name)
+	}
+}
@@ -46,6 +46,7 @@ func init() {
 	modules.RegisterSink("logToMemory", sink.NewLogSinkToMemory)
 	modules.RegisterSink("mqtt", mqtt.GetSink)
 	modules.RegisterSink("rest", func() api.Sink { return http.GetSink() })
+	modules.RegisterSink("promremotewrite", http.GetRemoteWriteSink)
 	modules.RegisterSink("nop", func() api.Sink { return &sink.NopSink{} })
 	modules.RegisterSink("memory", func() api.Sink { return memory.GetSink() })
 	modules.RegisterSink("neuron", neuron.GetSink)
@@ -54,6 +55,7 @@ func init() {
 
 	modules.RegisterLookupSource("memory", memory.GetLookupSource)
 	modules.RegisterLookupSource("httppull", http.GetLookUpSource)
+	modules.RegisterLookupSource("file", file.GetLookupSource)
 
 	modules.RegisterConnection("mqtt", mqtt.CreateConnection)
 	modules.RegisterConnection("nng", nng.CreateConnection)
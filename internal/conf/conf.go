@@ -465,6 +465,11 @@ func ValidateRuleOption(option *def.RuleOption) error {
 		Log.Warnf("bufferLength is negative, set to 1024")
 		errs = errors.Join(errs, errors.New("invalidBufferLength:bufferLength must be greater than 0"))
 	}
+	if option.MemoryBudget < 0 {
+		option.MemoryBudget = 0
+		Log.Warnf("memoryBudget is negative, set to 0")
+		errs = errors.Join(errs, errors.New("invalidMemoryBudget:memoryBudget must not be negative"))
+	}
 	if option.LateTol < 0 {
 		option.LateTol = cast.DurationConf(time.Second)
 		Log.Warnf("lateTol is negative, set to 1 second")
@@ -226,6 +226,20 @@ func TestRuleOptionValidate(t *testing.T) {
 			},
 			err: "invalidRestartMultiplier:restart multiplier must be greater than 0\ninvalidRestartAttempts:restart attempts must be greater than 0\ninvalidRestartDelay:restart delay must be greater than 0\ninvalidRestartMaxDelay:restart maxDelay must be greater than 0\ninvalidRestartJitterFactor:restart jitterFactor must between [0, 1)",
 		},
+		{
+			s: &def.RuleOption{
+				LateTol:      cast.DurationConf(time.Second),
+				Concurrency:  1,
+				BufferLength: 1024,
+				MemoryBudget: -1,
+			},
+			e: &def.RuleOption{
+				LateTol:      cast.DurationConf(time.Second),
+				Concurrency:  1,
+				BufferLength: 1024,
+			},
+			err: "invalidMemoryBudget:memoryBudget must not be negative",
+		},
 	}
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
 	for i, tt := range tests {
@@ -0,0 +1,146 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CanRead checks if the data written with the writer schema can be read with the reader schema by the schema
+// resolution rules of the avro specification.
+func CanRead(reader *Schema, writer *Schema) error {
+	return canRead(reader, writer, "", make(map[[2]*Schema]bool))
+}
+
+func canRead(r *Schema, w *Schema, path string, seen map[[2]*Schema]bool) error {
+	// Each branch of the writer union must be readable
+	if w.Type == Union {
+		for _, wt := range w.Types {
+			if err := canRead(r, wt, path, seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if r.Type == Union {
+		for _, rt := range r.Types {
+			if canRead(rt, w, path, seen) == nil {
+				return nil
+			}
+		}
+		return pathErr(path, "%s does not match any type of the reader union", typeName(w))
+	}
+	if r.Type != w.Type {
+		if promotable(w.Type, r.Type) {
+			return nil
+		}
+		return pathErr(path, "cannot read %s as %s", typeName(w), typeName(r))
+	}
+	switch r.Type {
+	case Record, Enum, Fixed:
+		if shortName(r.Name) != shortName(w.Name) {
+			return pathErr(path, "cannot read %s as %s", w.Name, r.Name)
+		}
+	}
+	switch r.Type {
+	case Record:
+		// Recursive records are checked once
+		key := [2]*Schema{r, w}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		for _, rf := range r.Fields {
+			fp := rf.Name
+			if path != "" {
+				fp = path + "." + rf.Name
+			}
+			var wf *Field
+			for _, f := range w.Fields {
+				if f.Name == rf.Name {
+					wf = f
+					break
+				}
+			}
+			if wf == nil {
+				if !rf.HasDefault {
+					return pathErr(fp, "missing in the writer schema and has no default value")
+				}
+				continue
+			}
+			if err := canRead(rf.Type, wf.Type, fp, seen); err != nil {
+				return err
+			}
+		}
+	case Enum:
+		for _, ws := range w.Symbols {
+			found := false
+			for _, rs := range r.Symbols {
+				if rs == ws {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return pathErr(path, "symbol %s of enum %s is missing in the reader schema", ws, w.Name)
+			}
+		}
+	case Fixed:
+		if r.Size != w.Size {
+			return pathErr(path, "cannot read fixed %s of size %d as size %d", w.Name, w.Size, r.Size)
+		}
+	case Array:
+		return canRead(r.Items, w.Items, path, seen)
+	case Map:
+		return canRead(r.Values, w.Values, path, seen)
+	}
+	return nil
+}
+
+// promotable returns true if the writer type can be promoted to the reader type
+func promotable(w Type, r Type) bool {
+	switch w {
+	case Int:
+		return r == Long || r == Float || r == Double
+	case Long:
+		return r == Float || r == Double
+	case Float:
+		return r == Double
+	case String:
+		return r == Bytes
+	case Bytes:
+		return r == String
+	}
+	return false
+}
+
+func typeName(s *Schema) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return string(s.Type)
+}
+
+func shortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func pathErr(path string, format string, args ...any) error {
+	if path == "" {
+		return fmt.Errorf(format, args...)
+	}
+	return fmt.Errorf("field %s: %s", path, fmt.Sprintf(format, args...))
+}
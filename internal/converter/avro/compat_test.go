@@ -0,0 +1,97 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanRead(t *testing.T) {
+	base := `{"type":"record","name":"Reading","namespace":"demo","fields":[{"name":"id","type":"string"},{"name":"value","type":"int"},{"name":"level","type":{"type":"enum","name":"Level","symbols":["LOW","HIGH"]}}]}`
+	tests := []struct {
+		name   string
+		reader string
+		writer string
+		err    string
+	}{
+		{
+			name:   "same",
+			reader: base,
+			writer: base,
+		},
+		{
+			name:   "promote and add field with default",
+			reader: `{"type":"record","name":"Reading","fields":[{"name":"id","type":"bytes"},{"name":"value","type":"double"},{"name":"level","type":{"type":"enum","name":"Level","symbols":["LOW","MEDIUM","HIGH"]}},{"name":"unit","type":["null","string"],"default":null}]}`,
+			writer: base,
+		},
+		{
+			name:   "add field without default",
+			reader: `{"type":"record","name":"Reading","fields":[{"name":"id","type":"string"},{"name":"unit","type":"string"}]}`,
+			writer: base,
+			err:    "field unit: missing in the writer schema and has no default value",
+		},
+		{
+			name:   "narrow type",
+			reader: `{"type":"record","name":"Reading","fields":[{"name":"value","type":"int"}]}`,
+			writer: `{"type":"record","name":"Reading","fields":[{"name":"value","type":"long"}]}`,
+			err:    "field value: cannot read long as int",
+		},
+		{
+			name:   "remove enum symbol",
+			reader: `{"type":"record","name":"Reading","fields":[{"name":"level","type":{"type":"enum","name":"Level","symbols":["LOW"]}}]}`,
+			writer: base,
+			err:    "field level: symbol HIGH of enum demo.Level is missing in the reader schema",
+		},
+		{
+			name:   "writer union",
+			reader: `{"type":"record","name":"Reading","fields":[{"name":"value","type":["null","long"]}]}`,
+			writer: `{"type":"record","name":"Reading","fields":[{"name":"value","type":["null","int","string"]}]}`,
+			err:    "field value: string does not match any type of the reader union",
+		},
+		{
+			name:   "nested array",
+			reader: `{"type":"record","name":"Reading","fields":[{"name":"values","type":{"type":"array","items":"float"}}]}`,
+			writer: `{"type":"record","name":"Reading","fields":[{"name":"values","type":{"type":"array","items":"double"}}]}`,
+			err:    "field values: cannot read double as float",
+		},
+		{
+			name:   "rename record",
+			reader: `{"type":"record","name":"Sample","fields":[]}`,
+			writer: base,
+			err:    "cannot read demo.Reading as Sample",
+		},
+		{
+			name:   "recursive",
+			reader: `{"type":"record","name":"Node","fields":[{"name":"next","type":["null","Node"]}]}`,
+			writer: `{"type":"record","name":"Node","fields":[{"name":"next","type":["null","Node"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.reader)
+			require.NoError(t, err)
+			w, err := Parse(tt.writer)
+			require.NoError(t, err)
+			err = CanRead(r, w)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
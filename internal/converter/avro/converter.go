@@ -0,0 +1,150 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+const (
+	WireFormatRaw       = "raw"
+	WireFormatConfluent = "confluent"
+
+	confluentMagic      = 0
+	confluentHeaderSize = 5
+)
+
+type Props struct {
+	// The url of the Confluent compatible schema registry
+	RegistryUrl string `json:"schemaRegistryUrl"`
+	// The subject to find the latest schema to encode with the confluent wire format
+	RegistrySubject string `json:"schemaRegistrySubject"`
+	// raw or confluent, which has a magic byte and a 4 bytes schema id before the avro payload
+	WireFormat string `json:"wireFormat"`
+}
+
+type Converter struct {
+	Props
+	// The schema from the local schema file. It may be nil if the schema registry is used
+	schema   *Schema
+	registry *registryClient
+	// The latest schema of the subject to encode, fetched once when encoding the first message
+	encodeId     int32
+	encodeSchema *Schema
+}
+
+// NewConverter creates the avro converter. The schemaFile is the path of the local avsc file and the
+// schemaName is the name of the record in the file to use. If the schemaName is empty, the root type is used.
+func NewConverter(schemaFile string, schemaName string, props map[string]any) (message.Converter, error) {
+	c := &Converter{}
+	if err := cast.MapToStruct(props, &c.Props); err != nil {
+		return nil, err
+	}
+	switch c.WireFormat {
+	case "":
+		c.WireFormat = WireFormatRaw
+	case WireFormatRaw, WireFormatConfluent:
+	default:
+		return nil, fmt.Errorf("invalid wireFormat %s, must be raw or confluent", c.WireFormat)
+	}
+	if c.RegistryUrl != "" {
+		c.registry = newRegistryClient(c.RegistryUrl)
+	}
+	if schemaFile != "" {
+		content, err := os.ReadFile(schemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read avro schema file %s: %v", schemaFile, err)
+		}
+		s, err := Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+		if schemaName != "" {
+			s = s.Named(schemaName)
+			if s == nil {
+				return nil, fmt.Errorf("cannot find avro type %s in %s", schemaName, schemaFile)
+			}
+		}
+		c.schema = s
+	}
+	if c.schema == nil {
+		if c.registry == nil {
+			return nil, fmt.Errorf("avro converter requires a schema file or schemaRegistryUrl")
+		}
+		if c.WireFormat != WireFormatConfluent {
+			return nil, fmt.Errorf("avro converter requires a schema file for the raw wire format")
+		}
+	}
+	if c.WireFormat == WireFormatConfluent && c.registry == nil {
+		return nil, fmt.Errorf("avro confluent wire format requires schemaRegistryUrl")
+	}
+	return c, nil
+}
+
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	if c.WireFormat == WireFormatRaw {
+		return Encode(c.schema, d)
+	}
+	if c.RegistrySubject == "" {
+		return nil, fmt.Errorf("schemaRegistrySubject is required to encode with the confluent wire format")
+	}
+	if c.encodeSchema == nil {
+		c.encodeId, c.encodeSchema, err = c.registry.GetLatest(c.RegistrySubject)
+		if err != nil {
+			return nil, err
+		}
+	}
+	payload, err := Encode(c.encodeSchema, d)
+	if err != nil {
+		return nil, err
+	}
+	b = make([]byte, confluentHeaderSize, confluentHeaderSize+len(payload))
+	b[0] = confluentMagic
+	binary.BigEndian.PutUint32(b[1:], uint32(c.encodeId))
+	return append(b, payload...), nil
+}
+
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (ma any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	if c.WireFormat == WireFormatRaw {
+		return Decode(c.schema, b)
+	}
+	if len(b) < confluentHeaderSize || b[0] != confluentMagic {
+		return nil, fmt.Errorf("invalid confluent avro message, missing magic byte and schema id")
+	}
+	id := int32(binary.BigEndian.Uint32(b[1:confluentHeaderSize]))
+	s, err := c.registry.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(s, b[confluentHeaderSize:])
+}
@@ -0,0 +1,267 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestEncodeDecodeRaw(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter("testdata/reading.avsc", "Reading", nil)
+	require.NoError(t, err)
+	ts := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	m := map[string]any{
+		"id":          int64(12),
+		"temperature": 21.5,
+		"status":      "FAIL",
+		"ts":          ts,
+		"price":       -12.34,
+		"tags":        []string{"a", "b"},
+		"labels":      map[string]any{"site": "s1"},
+		"location":    map[string]any{"lat": 1.5, "lng": 2.25},
+		"history":     []any{map[string]any{"lat": 0.5, "lng": 0.25}},
+	}
+	b, err := c.Encode(ctx, m)
+	require.NoError(t, err)
+	r, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"id":          int64(12),
+		"name":        nil,
+		"temperature": 21.5,
+		"status":      "FAIL",
+		"ts":          ts,
+		"price":       -12.34,
+		"tags":        []any{"a", "b"},
+		"labels":      map[string]any{"site": "s1"},
+		"location":    map[string]any{"lat": 1.5, "lng": 2.25},
+		"history":     []any{map[string]any{"lat": 0.5, "lng": 0.25}},
+	}, r)
+}
+
+func TestCodecBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		v      any
+		b      []byte
+		r      any
+	}{
+		{
+			name:   "record",
+			schema: `{"type":"record","name":"a","fields":[{"name":"id","type":"long"},{"name":"name","type":["null","string"]},{"name":"ok","type":"boolean"}]}`,
+			v:      map[string]any{"id": int64(-2), "name": "ab", "ok": true},
+			b:      []byte{0x03, 0x02, 0x04, 'a', 'b', 0x01},
+		},
+		{
+			name:   "union prefers exact type",
+			schema: `["null","string","long"]`,
+			v:      int64(64),
+			b:      []byte{0x04, 0x80, 0x01},
+		},
+		{
+			name:   "date",
+			schema: `{"type":"int","logicalType":"date"}`,
+			v:      time.Date(1970, 1, 3, 0, 0, 0, 0, time.UTC),
+			b:      []byte{0x04},
+		},
+		{
+			name:   "decimal fixed",
+			schema: `{"type":"fixed","name":"d","size":3,"logicalType":"decimal","precision":6,"scale":2}`,
+			v:      -1.0,
+			b:      []byte{0xff, 0xff, 0x9c},
+		},
+		{
+			name:   "timestamp micros",
+			schema: `{"type":"long","logicalType":"timestamp-micros"}`,
+			v:      time.UnixMicro(1).UTC(),
+			b:      []byte{0x02},
+		},
+		{
+			name:   "time millis",
+			schema: `{"type":"int","logicalType":"time-millis"}`,
+			v:      int64(1000),
+			b:      []byte{0xd0, 0x0f},
+		},
+		{
+			name:   "map",
+			schema: `{"type":"map","values":"float"}`,
+			v:      map[string]any{"k": 1.0},
+			b:      []byte{0x02, 0x02, 'k', 0x00, 0x00, 0x80, 0x3f, 0x00},
+		},
+		{
+			name:   "bytes",
+			schema: `"bytes"`,
+			v:      []byte{1, 2},
+			b:      []byte{0x04, 0x01, 0x02},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.schema)
+			require.NoError(t, err)
+			b, err := Encode(s, tt.v)
+			require.NoError(t, err)
+			require.Equal(t, tt.b, b)
+			r, err := Decode(s, b)
+			require.NoError(t, err)
+			require.Equal(t, tt.v, r)
+		})
+	}
+}
+
+func TestDecodeBlocks(t *testing.T) {
+	s, err := Parse(`{"type":"array","items":"int"}`)
+	require.NoError(t, err)
+	// a block with negative count followed by the block size and a normal block
+	r, err := Decode(s, []byte{0x03, 0x04, 0x02, 0x04, 0x02, 0x06, 0x00})
+	require.NoError(t, err)
+	require.Equal(t, []any{int64(1), int64(2), int64(3)}, r)
+	// the count is larger than the remaining bytes
+	ns, err := Parse(`{"type":"array","items":"null"}`)
+	require.NoError(t, err)
+	_, err = Decode(ns, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f})
+	require.EqualError(t, err, "invalid block count 4611686018427387903")
+	_, err = Decode(ns, []byte{0x04})
+	require.EqualError(t, err, "invalid block count 2")
+	r, err = Decode(ns, []byte{0x02, 0x00})
+	require.NoError(t, err)
+	require.Equal(t, []any{nil}, r)
+}
+
+func TestCodecError(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter("testdata/reading.avsc", "Reading", nil)
+	require.NoError(t, err)
+	_, err = c.Encode(ctx, []map[string]any{})
+	require.EqualError(t, err, "record com.example.Reading requires a map but got []map[string]interface {}")
+	_, err = c.Encode(ctx, map[string]any{"id": "abc"})
+	require.EqualError(t, err, "encode field id error: cannot convert string(abc) to int64")
+	_, err = c.Decode(ctx, []byte{0x02, 0x08})
+	require.EqualError(t, err, "decode field name error: union index 4 out of range")
+	_, err = c.Decode(ctx, []byte{0x02})
+	require.EqualError(t, err, "decode field name error: unexpected end of avro data")
+	// a huge length of string
+	_, err = c.Decode(ctx, []byte{0x02, 0x02, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	require.EqualError(t, err, "decode field name error: unexpected end of avro data")
+}
+
+func TestNewConverterError(t *testing.T) {
+	tests := []struct {
+		file  string
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{file: "testdata/reading.avsc", props: map[string]any{"wireFormat": "abc"}, err: "invalid wireFormat abc, must be raw or confluent"},
+		{file: "testdata/notexist.avsc", err: "fail to read avro schema file testdata/notexist.avsc: open testdata/notexist.avsc: no such file or directory"},
+		{file: "testdata/reading.avsc", name: "Other", err: "cannot find avro type Other in testdata/reading.avsc"},
+		{err: "avro converter requires a schema file or schemaRegistryUrl"},
+		{props: map[string]any{"schemaRegistryUrl": "http://localhost:8081"}, err: "avro converter requires a schema file for the raw wire format"},
+		{file: "testdata/reading.avsc", props: map[string]any{"wireFormat": "confluent"}, err: "avro confluent wire format requires schemaRegistryUrl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			_, err := NewConverter(tt.file, tt.name, tt.props)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestConfluent(t *testing.T) {
+	content, err := os.ReadFile("testdata/reading.avsc")
+	require.NoError(t, err)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var body any
+		switch r.URL.Path {
+		case "/schemas/ids/7":
+			body = map[string]any{"schema": string(content)}
+		case "/subjects/readings-value/versions/latest":
+			body = map[string]any{"id": 7, "version": 1, "subject": "readings-value", "schema": string(content)}
+		case "/schemas/ids/8":
+			body = map[string]any{"schema": `{"type":"record","name":"p","fields":[]}`, "schemaType": "PROTOBUF"}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			body = map[string]any{"error_code": 40403, "message": "Schema not found"}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter("", "", map[string]any{
+		"schemaRegistryUrl":     server.URL + "/",
+		"schemaRegistrySubject": "readings-value",
+		"wireFormat":            "confluent",
+	})
+	require.NoError(t, err)
+	m := map[string]any{"id": int64(1), "name": "n", "temperature": 1.0, "status": "OK", "ts": time.UnixMilli(1000).UTC(), "price": 1.0, "location": map[string]any{"lat": 0.0, "lng": 0.0}}
+	b, err := c.Encode(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 7}, b[:5])
+	// the latest schema is cached
+	_, err = c.Encode(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), requests.Load())
+
+	r, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, "n", r.(map[string]any)["name"])
+	require.Equal(t, time.UnixMilli(1000).UTC(), r.(map[string]any)["ts"])
+	// the schema of the id is cached when fetching the latest
+	require.Equal(t, int32(1), requests.Load())
+
+	_, err = c.Decode(ctx, []byte{1, 0, 0, 0, 7})
+	require.EqualError(t, err, "invalid confluent avro message, missing magic byte and schema id")
+	_, err = c.Decode(ctx, []byte{0, 0, 0, 0, 8})
+	require.EqualError(t, err, "schema 8 is PROTOBUF but not avro")
+	notFound := fmt.Sprintf("schema registry returns 404 for /schemas/ids/9: %s", `{"error_code":40403,"message":"Schema not found"}`+"\n")
+	requests.Store(0)
+	_, err = c.Decode(ctx, []byte{0, 0, 0, 0, 9})
+	require.EqualError(t, err, notFound)
+	// the failed id is not requested again until the backoff passes
+	_, err = c.Decode(ctx, []byte{0, 0, 0, 0, 9})
+	require.EqualError(t, err, notFound)
+	require.Equal(t, int32(1), requests.Load())
+	c.(*Converter).registry.backoff = 0
+	_, err = c.Decode(ctx, []byte{0, 0, 0, 0, 9})
+	require.EqualError(t, err, notFound)
+	require.Equal(t, int32(2), requests.Load())
+
+	// the local schema is not used to decode the data of another writer schema
+	lc, err := NewConverter("testdata/reading.avsc", "Reading", map[string]any{
+		"schemaRegistryUrl": server.URL,
+		"wireFormat":        "confluent",
+	})
+	require.NoError(t, err)
+	_, err = lc.Decode(ctx, append([]byte{0, 0, 0, 0, 9}, b[5:]...))
+	require.EqualError(t, err, notFound)
+	_, err = lc.Encode(ctx, m)
+	require.EqualError(t, err, "schemaRegistrySubject is required to encode with the confluent wire format")
+}
@@ -0,0 +1,233 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+var errShortBuffer = errors.New("unexpected end of avro data")
+
+type decoder struct {
+	b   []byte
+	pos int
+}
+
+// Decode decodes the avro binary data by the schema. Records and maps are decoded to map[string]any
+// and unions are decoded to the value of the selected branch.
+func Decode(s *Schema, b []byte) (any, error) {
+	d := &decoder{b: b}
+	return d.decode(s)
+}
+
+func (d *decoder) decode(s *Schema) (any, error) {
+	switch s.Type {
+	case Null:
+		return nil, nil
+	case Boolean:
+		if d.pos >= len(d.b) {
+			return nil, errShortBuffer
+		}
+		v := d.b[d.pos] != 0
+		d.pos++
+		return v, nil
+	case Int, Long:
+		v, err := d.readLong()
+		if err != nil {
+			return nil, err
+		}
+		return convertLogicalInt(s, v), nil
+	case Float:
+		bs, err := d.readN(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(bs))), nil
+	case Double:
+		bs, err := d.readN(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(bs)), nil
+	case Bytes, String:
+		bs, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return convertLogicalBytes(s, bs), nil
+	case Fixed:
+		bs, err := d.readN(s.Size)
+		if err != nil {
+			return nil, err
+		}
+		return convertLogicalBytes(s, bs), nil
+	case Enum:
+		i, err := d.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(s.Symbols) {
+			return nil, fmt.Errorf("enum index %d out of range of %s", i, s.Name)
+		}
+		return s.Symbols[i], nil
+	case Union:
+		i, err := d.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(s.Types) {
+			return nil, fmt.Errorf("union index %d out of range", i)
+		}
+		return d.decode(s.Types[i])
+	case Record:
+		m := make(map[string]any, len(s.Fields))
+		for _, f := range s.Fields {
+			v, err := d.decode(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("decode field %s error: %v", f.Name, err)
+			}
+			m[f.Name] = v
+		}
+		return m, nil
+	case Array:
+		r := make([]any, 0)
+		err := d.readBlocks(func() error {
+			v, err := d.decode(s.Items)
+			if err != nil {
+				return err
+			}
+			r = append(r, v)
+			return nil
+		})
+		return r, err
+	case Map:
+		r := make(map[string]any)
+		err := d.readBlocks(func() error {
+			k, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v, err := d.decode(s.Values)
+			if err != nil {
+				return err
+			}
+			r[string(k)] = v
+			return nil
+		})
+		return r, err
+	default:
+		return nil, fmt.Errorf("unsupported avro type %s", s.Type)
+	}
+}
+
+func (d *decoder) readLong() (int64, error) {
+	v, n := binary.Uvarint(d.b[d.pos:])
+	if n <= 0 {
+		return 0, errShortBuffer
+	}
+	d.pos += n
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (d *decoder) readN(n int) ([]byte, error) {
+	if n < 0 || n > len(d.b)-d.pos {
+		return nil, errShortBuffer
+	}
+	r := d.b[d.pos : d.pos+n]
+	d.pos += n
+	return r, nil
+}
+
+func (d *decoder) readBytes() ([]byte, error) {
+	l, err := d.readLong()
+	if err != nil {
+		return nil, err
+	}
+	return d.readN(int(l))
+}
+
+// readBlocks reads the blocks of array or map. A negative count is followed by the block size in bytes.
+func (d *decoder) readBlocks(item func() error) error {
+	for {
+		c, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		if c == 0 {
+			return nil
+		}
+		if c < 0 {
+			c = -c
+			if _, err := d.readLong(); err != nil {
+				return err
+			}
+		}
+		// Even an item of zero bytes is encoded in a block with count, so a larger count must be malformed
+		if c < 0 || c > int64(len(d.b)-d.pos) {
+			return fmt.Errorf("invalid block count %d", c)
+		}
+		for i := int64(0); i < c; i++ {
+			if err := item(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func convertLogicalInt(s *Schema, v int64) any {
+	switch s.Logical {
+	case LogicalDate:
+		return time.Unix(v*86400, 0).UTC()
+	case LogicalTimestampMillis, LogicalLocalTsMillis:
+		return time.UnixMilli(v).UTC()
+	case LogicalTimestampMicros, LogicalLocalTsMicros:
+		return time.UnixMicro(v).UTC()
+	case LogicalTimestampNanos, LogicalLocalTsNanos:
+		return time.Unix(0, v).UTC()
+	default:
+		// time-millis and time-micros are kept as the int value
+		return v
+	}
+}
+
+func convertLogicalBytes(s *Schema, bs []byte) any {
+	switch {
+	case s.Logical == LogicalDecimal:
+		return decimalToFloat(bs, s.Scale)
+	case s.Type == String:
+		return string(bs)
+	default:
+		// copy to not refer to the input buffer
+		r := make([]byte, len(bs))
+		copy(r, bs)
+		return r
+	}
+}
+
+// decimalToFloat converts the two's-complement big-endian unscaled value to float
+func decimalToFloat(bs []byte, scale int) float64 {
+	i := new(big.Int).SetBytes(bs)
+	if len(bs) > 0 && bs[0]&0x80 != 0 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(len(bs)*8)))
+	}
+	r := new(big.Rat).SetFrac(i, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	f, _ := r.Float64()
+	return f
+}
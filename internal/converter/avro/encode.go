@@ -0,0 +1,286 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+type encoder struct {
+	buf *bytes.Buffer
+	sn  cast.Strictness
+}
+
+// Encode encodes the data to avro binary by the schema. The data is converted to the schema type if possible.
+func Encode(s *Schema, d any) ([]byte, error) {
+	e := &encoder{buf: &bytes.Buffer{}, sn: cast.CONVERT_ALL}
+	if err := e.encode(s, d); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (e *encoder) encode(s *Schema, d any) error {
+	switch s.Type {
+	case Null:
+		if d != nil {
+			return fmt.Errorf("cannot convert %[1]T(%[1]v) to null", d)
+		}
+		return nil
+	case Boolean:
+		v, err := cast.ToBool(d, e.sn)
+		if err != nil {
+			return err
+		}
+		if v {
+			e.buf.WriteByte(1)
+		} else {
+			e.buf.WriteByte(0)
+		}
+		return nil
+	case Int, Long:
+		v, err := e.logicalInt(s, d)
+		if err != nil {
+			return err
+		}
+		if s.Type == Int && (v > math.MaxInt32 || v < math.MinInt32) {
+			return fmt.Errorf("value %d overflows avro int", v)
+		}
+		e.writeLong(v)
+		return nil
+	case Float:
+		v, err := cast.ToFloat64(d, e.sn)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(e.buf, binary.LittleEndian, math.Float32bits(float32(v)))
+		return nil
+	case Double:
+		v, err := cast.ToFloat64(d, e.sn)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(e.buf, binary.LittleEndian, math.Float64bits(v))
+		return nil
+	case String:
+		v, err := cast.ToString(d, e.sn)
+		if err != nil {
+			return err
+		}
+		e.writeLong(int64(len(v)))
+		e.buf.WriteString(v)
+		return nil
+	case Bytes:
+		v, err := e.logicalBytes(s, d)
+		if err != nil {
+			return err
+		}
+		e.writeLong(int64(len(v)))
+		e.buf.Write(v)
+		return nil
+	case Fixed:
+		v, err := e.logicalBytes(s, d)
+		if err != nil {
+			return err
+		}
+		if len(v) != s.Size {
+			return fmt.Errorf("fixed %s requires %d bytes but got %d", s.Name, s.Size, len(v))
+		}
+		e.buf.Write(v)
+		return nil
+	case Enum:
+		v, err := cast.ToString(d, e.sn)
+		if err != nil {
+			return err
+		}
+		for i, sym := range s.Symbols {
+			if sym == v {
+				e.writeLong(int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is not a symbol of enum %s", v, s.Name)
+	case Union:
+		return e.encodeUnion(s, d)
+	case Record:
+		m, ok := d.(map[string]any)
+		if !ok {
+			return fmt.Errorf("record %s requires a map but got %T", s.Name, d)
+		}
+		for _, f := range s.Fields {
+			v, ok := m[f.Name]
+			if !ok && f.HasDefault {
+				v = f.Default
+			}
+			if err := e.encode(f.Type, v); err != nil {
+				return fmt.Errorf("encode field %s error: %v", f.Name, err)
+			}
+		}
+		return nil
+	case Array:
+		if d == nil {
+			e.writeLong(0)
+			return nil
+		}
+		items := cast.ConvertSlice(d)
+		if items == nil {
+			return fmt.Errorf("array requires a slice but got %T", d)
+		}
+		if len(items) > 0 {
+			e.writeLong(int64(len(items)))
+			for _, item := range items {
+				if err := e.encode(s.Items, item); err != nil {
+					return err
+				}
+			}
+		}
+		e.writeLong(0)
+		return nil
+	case Map:
+		if d == nil {
+			e.writeLong(0)
+			return nil
+		}
+		m, ok := d.(map[string]any)
+		if !ok {
+			return fmt.Errorf("map requires a map but got %T", d)
+		}
+		if len(m) > 0 {
+			e.writeLong(int64(len(m)))
+			for k, v := range m {
+				e.writeLong(int64(len(k)))
+				e.buf.WriteString(k)
+				if err := e.encode(s.Values, v); err != nil {
+					return err
+				}
+			}
+		}
+		e.writeLong(0)
+		return nil
+	default:
+		return fmt.Errorf("unsupported avro type %s", s.Type)
+	}
+}
+
+// encodeUnion selects the first branch which accepts the value without conversion.
+// If none matches, the first branch which can convert the value is selected.
+func (e *encoder) encodeUnion(s *Schema, d any) error {
+	for _, sn := range []cast.Strictness{cast.STRICT, cast.CONVERT_ALL} {
+		for i, t := range s.Types {
+			if (d == nil) != (t.Type == Null) {
+				continue
+			}
+			sub := &encoder{buf: &bytes.Buffer{}, sn: sn}
+			if err := sub.encode(t, d); err != nil {
+				continue
+			}
+			e.writeLong(int64(i))
+			e.buf.Write(sub.buf.Bytes())
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot convert %[1]T(%[1]v) to any type of the union", d)
+}
+
+func (e *encoder) writeLong(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], uint64((v<<1)^(v>>63)))
+	e.buf.Write(b[:n])
+}
+
+func (e *encoder) logicalInt(s *Schema, d any) (int64, error) {
+	switch s.Logical {
+	case LogicalDate, LogicalTimestampMillis, LogicalTimestampMicros, LogicalTimestampNanos,
+		LogicalLocalTsMillis, LogicalLocalTsMicros, LogicalLocalTsNanos:
+		var t time.Time
+		switch v := d.(type) {
+		case time.Time:
+			t = v
+		case string:
+			var err error
+			t, err = cast.InterfaceToTime(v, "")
+			if err != nil {
+				return 0, err
+			}
+		default:
+			return cast.ToInt64(d, e.sn)
+		}
+		switch s.Logical {
+		case LogicalDate:
+			return t.Unix() / 86400, nil
+		case LogicalTimestampMillis, LogicalLocalTsMillis:
+			return t.UnixMilli(), nil
+		case LogicalTimestampMicros, LogicalLocalTsMicros:
+			return t.UnixMicro(), nil
+		default:
+			return t.UnixNano(), nil
+		}
+	default:
+		return cast.ToInt64(d, e.sn)
+	}
+}
+
+func (e *encoder) logicalBytes(s *Schema, d any) ([]byte, error) {
+	if s.Logical == LogicalDecimal {
+		if b, ok := d.([]byte); ok {
+			return b, nil
+		}
+		f, err := cast.ToFloat64(d, e.sn)
+		if err != nil {
+			return nil, err
+		}
+		return floatToDecimal(f, s.Scale, s.Size)
+	}
+	return cast.ToByteA(d, e.sn)
+}
+
+// floatToDecimal converts the float to the two's-complement big-endian unscaled value.
+// If size is set, the result is sign extended to the size.
+func floatToDecimal(f float64, scale int, size int) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("cannot convert %v to decimal", f)
+	}
+	r := new(big.Rat).SetFloat64(f)
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	// round half away from zero
+	i := new(big.Int).Quo(new(big.Int).Add(new(big.Int).Mul(r.Num(), big.NewInt(2)), new(big.Int).Mul(r.Denom(), big.NewInt(int64(r.Sign())))), new(big.Int).Mul(r.Denom(), big.NewInt(2)))
+	l := i.BitLen()/8 + 1
+	if size > 0 {
+		if l > size {
+			return nil, fmt.Errorf("decimal %v overflows fixed size %d", f, size)
+		}
+		l = size
+	}
+	neg := i.Sign() < 0
+	if neg {
+		i.Add(i, new(big.Int).Lsh(big.NewInt(1), uint(l*8)))
+	}
+	b := i.Bytes()
+	r2 := make([]byte, l)
+	copy(r2[l-len(b):], b)
+	if neg {
+		for j := 0; j < l-len(b); j++ {
+			r2[j] = 0xff
+		}
+	}
+	return r2, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failureBackoff is how long a failed id is not requested again
+const failureBackoff = 30 * time.Second
+
+// registryClient fetches schemas from a Confluent compatible schema registry. Schemas are immutable once
+// registered, so they are cached by id. Failed ids are cached for a while to avoid a request for each message.
+type registryClient struct {
+	url     string
+	client  *http.Client
+	backoff time.Duration
+
+	sync.RWMutex
+	schemas  map[int32]*Schema
+	failures map[int32]*registryFailure
+}
+
+type registryFailure struct {
+	err error
+	at  time.Time
+}
+
+type registrySchema struct {
+	Id         int32  `json:"id"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+func newRegistryClient(u string) *registryClient {
+	return &registryClient{
+		url:      strings.TrimSuffix(u, "/"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		backoff:  failureBackoff,
+		schemas:  make(map[int32]*Schema),
+		failures: make(map[int32]*registryFailure),
+	}
+}
+
+// GetById returns the schema of the id
+func (r *registryClient) GetById(id int32) (*Schema, error) {
+	r.RLock()
+	s, ok := r.schemas[id]
+	f := r.failures[id]
+	r.RUnlock()
+	if ok {
+		return s, nil
+	}
+	if f != nil && time.Since(f.at) < r.backoff {
+		return nil, f.err
+	}
+	s, err := r.fetchById(id)
+	r.Lock()
+	if err != nil {
+		r.failures[id] = &registryFailure{err: err, at: time.Now()}
+	} else {
+		delete(r.failures, id)
+	}
+	r.Unlock()
+	return s, err
+}
+
+func (r *registryClient) fetchById(id int32) (*Schema, error) {
+	rs, err := r.get(fmt.Sprintf("/schemas/ids/%d", id))
+	if err != nil {
+		return nil, err
+	}
+	rs.Id = id
+	return r.parse(rs)
+}
+
+// GetLatest returns the id and the schema of the latest version of the subject
+func (r *registryClient) GetLatest(subject string) (int32, *Schema, error) {
+	rs, err := r.get(fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject)))
+	if err != nil {
+		return 0, nil, err
+	}
+	s, err := r.parse(rs)
+	return rs.Id, s, err
+}
+
+func (r *registryClient) parse(rs *registrySchema) (*Schema, error) {
+	if rs.SchemaType != "" && !strings.EqualFold(rs.SchemaType, "avro") {
+		return nil, fmt.Errorf("schema %d is %s but not avro", rs.Id, rs.SchemaType)
+	}
+	s, err := Parse(rs.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %d from registry: %v", rs.Id, err)
+	}
+	r.Lock()
+	r.schemas[rs.Id] = s
+	r.Unlock()
+	return s, nil
+}
+
+func (r *registryClient) get(path string) (*registrySchema, error) {
+	resp, err := r.client.Get(r.url + path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to request schema registry: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read schema registry response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry returns %d for %s: %s", resp.StatusCode, path, body)
+	}
+	rs := &registrySchema{}
+	if err := json.Unmarshal(body, rs); err != nil {
+		return nil, fmt.Errorf("invalid schema registry response: %v", err)
+	}
+	return rs, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Type string
+
+const (
+	Null    Type = "null"
+	Boolean Type = "boolean"
+	Int     Type = "int"
+	Long    Type = "long"
+	Float   Type = "float"
+	Double  Type = "double"
+	Bytes   Type = "bytes"
+	String  Type = "string"
+	Record  Type = "record"
+	Enum    Type = "enum"
+	Array   Type = "array"
+	Map     Type = "map"
+	Union   Type = "union"
+	Fixed   Type = "fixed"
+)
+
+// Logical types which are mapped to eKuiper types
+const (
+	LogicalDecimal         = "decimal"
+	LogicalUUID            = "uuid"
+	LogicalDate            = "date"
+	LogicalTimeMillis      = "time-millis"
+	LogicalTimeMicros      = "time-micros"
+	LogicalTimestampMillis = "timestamp-millis"
+	LogicalTimestampMicros = "timestamp-micros"
+	LogicalTimestampNanos  = "timestamp-nanos"
+	LogicalLocalTsMillis   = "local-timestamp-millis"
+	LogicalLocalTsMicros   = "local-timestamp-micros"
+	LogicalLocalTsNanos    = "local-timestamp-nanos"
+)
+
+// Schema is a parsed avro schema. Named types referenced by name share the same instance so that
+// recursive types are supported.
+type Schema struct {
+	Type    Type
+	Name    string // The full name of named types
+	Logical string
+	Scale   int
+	Size    int       // fixed only
+	Fields  []*Field  // record only
+	Symbols []string  // enum only
+	Items   *Schema   // array only
+	Values  *Schema   // map only
+	Types   []*Schema // union only
+	// The named types defined in the schema, only set in the root
+	names map[string]*Schema
+}
+
+type Field struct {
+	Name       string
+	Type       *Schema
+	Default    any
+	HasDefault bool
+}
+
+// Parse parses the avro schema in json format
+func Parse(content string) (*Schema, error) {
+	var v any
+	if err := json.Unmarshal([]byte(content), &v); err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %v", err)
+	}
+	p := &parser{names: make(map[string]*Schema)}
+	s, err := p.parse(v, "")
+	if err != nil {
+		return nil, err
+	}
+	s.names = p.names
+	return s, nil
+}
+
+// Named finds the named type defined in the schema by its full name or simple name
+func (s *Schema) Named(name string) *Schema {
+	if r, ok := s.names[name]; ok {
+		return r
+	}
+	for fn, r := range s.names {
+		if fn[strings.LastIndex(fn, ".")+1:] == name {
+			return r
+		}
+	}
+	return nil
+}
+
+type parser struct {
+	names map[string]*Schema
+}
+
+func (p *parser) parse(v any, namespace string) (*Schema, error) {
+	switch t := v.(type) {
+	case string:
+		return p.parseName(t, namespace)
+	case []any:
+		s := &Schema{Type: Union, Types: make([]*Schema, 0, len(t))}
+		for _, b := range t {
+			bs, err := p.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if bs.Type == Union {
+				return nil, fmt.Errorf("union cannot contain union directly")
+			}
+			s.Types = append(s.Types, bs)
+		}
+		return s, nil
+	case map[string]any:
+		return p.parseComplex(t, namespace)
+	default:
+		return nil, fmt.Errorf("invalid avro schema %v", v)
+	}
+}
+
+func (p *parser) parseName(name string, namespace string) (*Schema, error) {
+	switch Type(name) {
+	case Null, Boolean, Int, Long, Float, Double, Bytes, String:
+		return &Schema{Type: Type(name)}, nil
+	}
+	if s, ok := p.names[fullName(name, namespace)]; ok {
+		return s, nil
+	}
+	if s, ok := p.names[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown avro type %s", name)
+}
+
+func (p *parser) parseComplex(m map[string]any, namespace string) (*Schema, error) {
+	tv, ok := m["type"]
+	if !ok {
+		return nil, fmt.Errorf("avro schema %v missing type", m)
+	}
+	tn, ok := tv.(string)
+	if !ok {
+		// type is a nested schema such as {"type": {"type": "array", ...}}
+		return p.parse(tv, namespace)
+	}
+	logical, _ := m["logicalType"].(string)
+	switch Type(tn) {
+	case Record, "error", Enum, Fixed:
+		name, _ := m["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("avro %s missing name", tn)
+		}
+		if ns, ok := m["namespace"].(string); ok && !strings.Contains(name, ".") {
+			namespace = ns
+		}
+		s := &Schema{Name: fullName(name, namespace), Logical: logical}
+		if _, ok := p.names[s.Name]; ok {
+			return nil, fmt.Errorf("duplicate avro type name %s", s.Name)
+		}
+		if i := strings.LastIndex(s.Name, "."); i > 0 {
+			namespace = s.Name[:i]
+		}
+		p.names[s.Name] = s
+		switch Type(tn) {
+		case Enum:
+			s.Type = Enum
+			syms, _ := m["symbols"].([]any)
+			for _, sym := range syms {
+				ss, ok := sym.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid symbol %v of enum %s", sym, s.Name)
+				}
+				s.Symbols = append(s.Symbols, ss)
+			}
+		case Fixed:
+			s.Type = Fixed
+			size, ok := m["size"].(float64)
+			if !ok {
+				return nil, fmt.Errorf("fixed %s missing size", s.Name)
+			}
+			s.Size = int(size)
+			s.Scale = intProp(m, "scale")
+		default:
+			s.Type = Record
+			fields, ok := m["fields"].([]any)
+			if !ok {
+				return nil, fmt.Errorf("record %s missing fields", s.Name)
+			}
+			for _, f := range fields {
+				fm, ok := f.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("invalid field %v of record %s", f, s.Name)
+				}
+				fn, _ := fm["name"].(string)
+				if fn == "" {
+					return nil, fmt.Errorf("field of record %s missing name", s.Name)
+				}
+				ft, err := p.parse(fm["type"], namespace)
+				if err != nil {
+					return nil, fmt.Errorf("invalid field %s of record %s: %v", fn, s.Name, err)
+				}
+				df, hasDefault := fm["default"]
+				s.Fields = append(s.Fields, &Field{Name: fn, Type: ft, Default: df, HasDefault: hasDefault})
+			}
+		}
+		return s, nil
+	case Array:
+		items, err := p.parse(m["items"], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array items: %v", err)
+		}
+		return &Schema{Type: Array, Items: items, Logical: logical}, nil
+	case Map:
+		values, err := p.parse(m["values"], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid map values: %v", err)
+		}
+		return &Schema{Type: Map, Values: values, Logical: logical}, nil
+	default:
+		s, err := p.parseName(tn, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if logical == "" {
+			return s, nil
+		}
+		if s.Name != "" {
+			return nil, fmt.Errorf("logical type cannot be set to the reference of %s", s.Name)
+		}
+		s.Logical = logical
+		s.Scale = intProp(m, "scale")
+		return s, nil
+	}
+}
+
+func fullName(name string, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func intProp(m map[string]any, key string) int {
+	if v, ok := m[key].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse(`{"type":"record","name":"Node","namespace":"ns","fields":[
+		{"name":"value","type":"int"},
+		{"name":"children","type":{"type":"array","items":"Node"}},
+		{"name":"kind","type":{"type":"enum","name":"other.Kind","symbols":["A","B"]}},
+		{"name":"id","type":{"type":"fixed","name":"Id","size":16,"logicalType":"uuid"}},
+		{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":8,"scale":3}}
+	]}`)
+	require.NoError(t, err)
+	require.Equal(t, Record, s.Type)
+	require.Equal(t, "ns.Node", s.Name)
+	require.Len(t, s.Fields, 5)
+	// recursive reference shares the same schema
+	require.Same(t, s, s.Fields[1].Type.Items)
+	require.Equal(t, []string{"A", "B"}, s.Fields[2].Type.Symbols)
+	require.Same(t, s.Fields[2].Type, s.Named("other.Kind"))
+	require.Same(t, s.Fields[2].Type, s.Named("Kind"))
+	require.Same(t, s.Fields[3].Type, s.Named("ns.Id"))
+	require.Equal(t, 16, s.Fields[3].Type.Size)
+	require.Equal(t, LogicalDecimal, s.Fields[4].Type.Logical)
+	require.Equal(t, 3, s.Fields[4].Type.Scale)
+	require.Nil(t, s.Named("NotExist"))
+
+	p, err := Parse(`"string"`)
+	require.NoError(t, err)
+	require.Equal(t, String, p.Type)
+	u, err := Parse(`["null",{"type":"long","logicalType":"timestamp-micros"}]`)
+	require.NoError(t, err)
+	require.Equal(t, Union, u.Type)
+	require.Equal(t, LogicalTimestampMicros, u.Types[1].Logical)
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		schema string
+		err    string
+	}{
+		{schema: `{`, err: "invalid avro schema: unexpected end of JSON input"},
+		{schema: `"unknown"`, err: "unknown avro type unknown"},
+		{schema: `{"name":"a"}`, err: "avro schema map[name:a] missing type"},
+		{schema: `{"type":"record","fields":[]}`, err: "avro record missing name"},
+		{schema: `{"type":"record","name":"a"}`, err: "record a missing fields"},
+		{schema: `{"type":"record","name":"a","fields":[{"name":"b","type":"c"}]}`, err: "invalid field b of record a: unknown avro type c"},
+		{schema: `{"type":"fixed","name":"a"}`, err: "fixed a missing size"},
+		{schema: `[["null"]]`, err: "union cannot contain union directly"},
+		{schema: `{"type":"record","name":"a","fields":[{"name":"b","type":{"type":"enum","name":"a","symbols":[]}}]}`, err: "invalid field b of record a: duplicate avro type name a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			_, err := Parse(tt.schema)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cbor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/typed"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+var (
+	// Integers are decoded as int64 and the time tags as time.Time
+	decMode, _ = cbor.DecOptions{
+		IntDec:          cbor.IntDecConvertSignedOrFail,
+		TimeTagToAny:    cbor.TimeTagToTime,
+		MaxNestedLevels: 64,
+	}.DecMode()
+	// Use the shortest float to save bytes and encode time with the epoch tag
+	encMode, _ = cbor.EncOptions{
+		ShortestFloat: cbor.ShortestFloat16,
+		Time:          cbor.TimeUnixDynamic,
+		TimeTag:       cbor.EncTagRequired,
+	}.EncMode()
+)
+
+type Converter struct {
+	sync.RWMutex
+	schema map[string]*ast.JsonStreamField
+}
+
+func NewConverter(schema map[string]*ast.JsonStreamField) message.Converter {
+	return &Converter{schema: schema}
+}
+
+func (c *Converter) ResetSchema(schema map[string]*ast.JsonStreamField) {
+	c.Lock()
+	defer c.Unlock()
+	c.schema = schema
+}
+
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	return encMode.Marshal(d)
+}
+
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (ma any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	var v any
+	if err := decMode.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return typed.Convert(v, c.schema)
+}
+
+// DecodeField decodes a top level field and keeps the other fields as raw bytes
+func (c *Converter) DecodeField(_ api.StreamContext, b []byte, f string) (any, error) {
+	var m map[any]cbor.RawMessage
+	if err := decMode.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("cbor data is not a map: %v", err)
+	}
+	raw, ok := m[f]
+	if !ok {
+		return nil, nil
+	}
+	var v any
+	if err := decMode.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return typed.ConvertField(f, v, c.schema)
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cbor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestConverter(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c := NewConverter(nil)
+	ts := time.Unix(1700000000, 0).UTC()
+	m := map[string]any{"id": int64(1), "name": "dev", "temp": 20.5, "tags": []any{"a"}, "loc": map[string]any{"lat": 1.5}, "ts": ts, "raw": []byte{1, 2}}
+	b, err := c.Encode(ctx, m)
+	require.NoError(t, err)
+	r, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r.(map[string]any)["id"])
+	require.Equal(t, 20.5, r.(map[string]any)["temp"])
+	require.Equal(t, map[string]any{"lat": 1.5}, r.(map[string]any)["loc"])
+	require.True(t, ts.Equal(r.(map[string]any)["ts"].(time.Time)))
+	require.Equal(t, []byte{1, 2}, r.(map[string]any)["raw"])
+
+	// shortest float encoding
+	b, err = c.Encode(ctx, map[string]any{"a": 1.5})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xa1, 0x61, 'a', 0xf9, 0x3e, 0x00}, b)
+
+	c.(message.SchemaResetAbleConverter).ResetSchema(map[string]*ast.JsonStreamField{
+		"a": {Type: "float"},
+		"b": {Type: "bigint"},
+	})
+	// {1: "x", "a": 1, "b": 2.0} with an integer key
+	b = []byte{0xa3, 0x01, 0x61, 'x', 0x61, 'a', 0x01, 0x61, 'b', 0xf9, 0x40, 0x00}
+	r, err = c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"a": 1.0, "b": int64(2)}, r)
+
+	pd := c.(message.PartialDecoder)
+	v, err := pd.DecodeField(ctx, b, "a")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, v)
+	v, err = pd.DecodeField(ctx, b, "none")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	c.(message.SchemaResetAbleConverter).ResetSchema(nil)
+	// [{"a": 1}, {"a": -1}]
+	r, err = c.Decode(ctx, []byte{0x82, 0xa1, 0x61, 'a', 0x01, 0xa1, 0x61, 'a', 0x20})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"a": int64(1)}, {"a": int64(-1)}}, r)
+}
+
+func TestConverterError(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c := NewConverter(map[string]*ast.JsonStreamField{"a": {Type: "bigint"}})
+	_, err := c.Decode(ctx, []byte{0xa1, 0x61})
+	require.EqualError(t, err, "unexpected EOF")
+	_, err = c.Decode(ctx, []byte{0x01})
+	require.EqualError(t, err, "only map[string]interface{} and []map[string]interface{} is supported")
+	_, err = c.Decode(ctx, []byte{0xa1, 0x61, 'a', 0x61, 'b'})
+	require.EqualError(t, err, "a has wrong type:string, expect:bigint")
+	_, err = c.(message.PartialDecoder).DecodeField(ctx, []byte{0x81, 0x01}, "a")
+	require.Error(t, err)
+	_, err = c.Encode(ctx, make(chan int))
+	require.Error(t, err)
+}
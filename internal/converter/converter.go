@@ -21,10 +21,17 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/converter/binary"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/cbor"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/delimited"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/json"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/lineprotocol"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/msgpack"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/prometheus"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/sparkplugb"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/urlencoded"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/xml"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
@@ -32,11 +39,21 @@ import (
 )
 
 func init() {
-	modules.RegisterConverter(message.FormatJson, func(_ api.StreamContext, _ string, schema map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
-		return json.NewFastJsonConverter(schema, props), nil
+	modules.RegisterConverter(message.FormatJson, func(_ api.StreamContext, schemaId string, logicalSchema map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+		c := json.NewFastJsonConverter(logicalSchema, props)
+		if schemaId == "" {
+			return c, nil
+		}
+		return newReloadConverter(def.JSONSCHEMA, strings.SplitN(schemaId, ".", 2)[0], func() (message.Converter, error) {
+			s, err := schema.GetJsonSchema(schemaId)
+			if err != nil {
+				return nil, err
+			}
+			return json.NewValidatingConverter(c, s), nil
+		})
 	})
 	modules.RegisterConverter(message.FormatXML, func(ctx api.StreamContext, schemaId string, logicalSchema map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
-		return xml.NewXMLConverter(), nil
+		return xml.NewConverter(logicalSchema, props)
 	})
 	modules.RegisterConverter(message.FormatBinary, func(_ api.StreamContext, _ string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
 		return binary.GetConverter()
@@ -47,6 +64,22 @@ func init() {
 	modules.RegisterConverter(message.FormatUrlEncoded, func(_ api.StreamContext, _ string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
 		return urlencoded.NewConverter(props)
 	})
+	modules.RegisterConverter(message.FormatMsgpack, func(_ api.StreamContext, _ string, logicalSchema map[string]*ast.JsonStreamField, _ map[string]any) (message.Converter, error) {
+		return msgpack.NewConverter(logicalSchema), nil
+	})
+	modules.RegisterConverter(message.FormatCbor, func(_ api.StreamContext, _ string, logicalSchema map[string]*ast.JsonStreamField, _ map[string]any) (message.Converter, error) {
+		return cbor.NewConverter(logicalSchema), nil
+	})
+	modules.RegisterConverter(message.FormatSparkplugB, func(_ api.StreamContext, schemaId string, _ map[string]*ast.JsonStreamField, _ map[string]any) (message.Converter, error) {
+		// The schemaId is the message type to encode
+		return sparkplugb.NewConverter(schemaId)
+	})
+	modules.RegisterConverter(message.FormatPrometheus, func(_ api.StreamContext, _ string, _ map[string]*ast.JsonStreamField, _ map[string]any) (message.Converter, error) {
+		return prometheus.NewConverter(), nil
+	})
+	modules.RegisterConverter(message.FormatLineProtocol, func(_ api.StreamContext, _ string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+		return lineprotocol.NewConverter(props)
+	})
 }
 
 func GetOrCreateConverter(ctx api.StreamContext, format string, schemaId string, schema map[string]*ast.JsonStreamField, props map[string]any) (c message.Converter, err error) {
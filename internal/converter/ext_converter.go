@@ -21,6 +21,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/internal/converter/avro"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/protobuf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/schema"
@@ -40,10 +41,30 @@ func init() {
 				schemaName = r[1]
 			}
 		}
-		ffs, err := schema.GetSchemaFile(def.PROTOBUF, schemaFile)
-		if err != nil {
-			return nil, err
+		return newReloadConverter(def.PROTOBUF, schemaFile, func() (message.Converter, error) {
+			ffs, err := schema.GetSchemaFile(def.PROTOBUF, schemaFile)
+			if err != nil {
+				return nil, err
+			}
+			return protobuf.NewConverter(ffs.SchemaFile, ffs.SoFile, schemaName)
+		})
+	})
+	modules.RegisterConverter(message.FormatAvro, func(_ api.StreamContext, schemaId string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+		// The schema file is optional if the schema is resolved from the schema registry
+		if schemaId == "" {
+			return avro.NewConverter("", "", props)
 		}
-		return protobuf.NewConverter(ffs.SchemaFile, ffs.SoFile, schemaName)
+		r := strings.SplitN(schemaId, ".", 2)
+		schemaName := ""
+		if len(r) == 2 {
+			schemaName = r[1]
+		}
+		return newReloadConverter(def.AVRO, r[0], func() (message.Converter, error) {
+			ffs, err := schema.GetSchemaFile(def.AVRO, r[0])
+			if err != nil {
+				return nil, err
+			}
+			return avro.NewConverter(ffs.SchemaFile, schemaName, props)
+		})
 	})
 }
@@ -81,6 +81,10 @@ func (f *FastJsonConverter) DecodeField(_ api.StreamContext, b []byte, field str
 	if err != nil {
 		return nil, err
 	}
+	return f.decodeField(v, field)
+}
+
+func (f *FastJsonConverter) decodeField(v *fastjson.Value, field string) (any, error) {
 	switch v.Type() {
 	case fastjson.TypeObject:
 		obj, err := v.Object()
@@ -109,6 +113,10 @@ func (f *FastJsonConverter) decodeWithSchema(b []byte, schema map[string]*ast.Js
 	if err != nil {
 		return nil, err
 	}
+	return f.decodeValue(v, schema)
+}
+
+func (f *FastJsonConverter) decodeValue(v *fastjson.Value, schema map[string]*ast.JsonStreamField) (interface{}, error) {
 	switch v.Type() {
 	case fastjson.TypeArray:
 		array, err := v.Array()
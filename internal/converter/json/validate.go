@@ -0,0 +1,125 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"strconv"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/valyala/fastjson"
+
+	"github.com/lf-edge/ekuiper/v2/internal/schema/jsonschema"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+// ValidatingConverter validates each decoded message against the json schema before converting it
+type ValidatingConverter struct {
+	*FastJsonConverter
+	validator *jsonschema.Schema
+	// If the schema is not for an array, each item of an array payload is validated
+	validateItems bool
+}
+
+func NewValidatingConverter(c *FastJsonConverter, s *jsonschema.Schema) *ValidatingConverter {
+	validateItems := true
+	for _, t := range s.Types {
+		if t == "array" {
+			validateItems = false
+		}
+	}
+	return &ValidatingConverter{FastJsonConverter: c, validator: s, validateItems: validateItems}
+}
+
+func (c *ValidatingConverter) Decode(_ api.StreamContext, b []byte) (m any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	v, err := c.parse(b)
+	if err != nil {
+		return nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.decodeValue(v, c.schema)
+}
+
+func (c *ValidatingConverter) DecodeField(_ api.StreamContext, b []byte, field string) (any, error) {
+	v, err := c.parse(b)
+	if err != nil {
+		return nil, errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+	}
+	return c.decodeField(v, field)
+}
+
+// parse parses the payload once and validates the parsed value
+func (c *ValidatingConverter) parse(b []byte) (*fastjson.Value, error) {
+	var p fastjson.Parser
+	v, err := p.ParseBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validate(toPlain(v)); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (c *ValidatingConverter) validate(raw any) error {
+	items, ok := raw.([]any)
+	if !ok || !c.validateItems {
+		return c.validator.Validate(raw)
+	}
+	for i, item := range items {
+		if err := c.validator.Validate(item); err != nil {
+			if ve, ok := err.(*jsonschema.ValidationError); ok {
+				ve.Pointer = "/" + strconv.Itoa(i) + ve.Pointer
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// toPlain converts the parsed value to the types of encoding/json which the validator accepts
+func toPlain(v *fastjson.Value) any {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		obj, _ := v.Object()
+		m := make(map[string]any, obj.Len())
+		obj.Visit(func(k []byte, vv *fastjson.Value) {
+			m[string(k)] = toPlain(vv)
+		})
+		return m
+	case fastjson.TypeArray:
+		array, _ := v.Array()
+		a := make([]any, len(array))
+		for i, vv := range array {
+			a[i] = toPlain(vv)
+		}
+		return a
+	case fastjson.TypeString:
+		return string(v.GetStringBytes())
+	case fastjson.TypeNumber:
+		return v.GetFloat64()
+	case fastjson.TypeTrue:
+		return true
+	case fastjson.TypeFalse:
+		return false
+	default:
+		return nil
+	}
+}
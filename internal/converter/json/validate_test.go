@@ -0,0 +1,70 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/schema/jsonschema"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestValidatingConverter(t *testing.T) {
+	s, err := jsonschema.Compile([]byte(`{"type":"object","required":["a"],"properties":{"a":{"type":"integer","maximum":10},"b":{"type":"string"}}}`))
+	require.NoError(t, err)
+	c := NewValidatingConverter(NewFastJsonConverter(map[string]*ast.JsonStreamField{
+		"a": {Type: "bigint"},
+	}, nil), s)
+	ctx := mockContext.NewMockContext("test", "op1")
+	tests := []struct {
+		payload string
+		r       any
+		err     string
+	}{
+		{payload: `{"a":1,"b":"x"}`, r: map[string]any{"a": int64(1)}},
+		{payload: `[{"a":1},{"a":2}]`, r: []map[string]any{{"a": int64(1)}, {"a": int64(2)}}},
+		{payload: `{"b":"x"}`, err: `json schema validation failed at "": missing required property a`},
+		{payload: `{"a":11}`, err: `json schema validation failed at "/a": 11 is greater than maximum 10`},
+		{payload: `[{"a":1},{"a":1,"b":2}]`, err: `json schema validation failed at "/1/b": expected string but got integer`},
+		{payload: `{"a":`, err: `cannot parse JSON: cannot parse object: cannot parse object value: cannot parse empty string; unparsed tail: ""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			r, err := c.Decode(ctx, []byte(tt.payload))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				var ec errorx.ErrorWithCode
+				require.ErrorAs(t, err, &ec)
+				require.Equal(t, errorx.CovnerterErr, ec.Code())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.r, r)
+		})
+	}
+	// partial decode is validated too
+	v, err := c.DecodeField(ctx, []byte(`{"a":3}`), "a")
+	require.NoError(t, err)
+	require.Equal(t, float64(3), v)
+	_, err = c.DecodeField(ctx, []byte(`{"a":11}`), "a")
+	require.EqualError(t, err, `json schema validation failed at "/a": 11 is greater than maximum 10`)
+	var ec errorx.ErrorWithCode
+	require.ErrorAs(t, err, &ec)
+	require.Equal(t, errorx.CovnerterErr, ec.Code())
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lineprotocol
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	protocol "github.com/influxdata/line-protocol"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// The fields of each decoded line, the same as the telegraf json format
+const (
+	FieldMeasurement = "measurement"
+	FieldTags        = "tags"
+	FieldFields      = "fields"
+	FieldTimestamp   = "timestamp"
+)
+
+type c struct {
+	// The unit of the timestamps in the lines: ns, us, ms or s
+	TimestampPrecision string `json:"timestampPrecision"`
+}
+
+// Converter decodes the InfluxDB line protocol into one map for each line and encodes the maps back.
+// The timestamp field is always in milliseconds to be used as the event time.
+type Converter struct {
+	precision time.Duration
+}
+
+func NewConverter(props map[string]any) (message.Converter, error) {
+	conf := &c{TimestampPrecision: "ns"}
+	if err := cast.MapToStruct(props, conf); err != nil {
+		return nil, err
+	}
+	var p time.Duration
+	switch conf.TimestampPrecision {
+	case "ns", "":
+		p = time.Nanosecond
+	case "us":
+		p = time.Microsecond
+	case "ms":
+		p = time.Millisecond
+	case "s":
+		p = time.Second
+	default:
+		return nil, fmt.Errorf("timestampPrecision %s is not supported, expect one of ns, us, ms and s", conf.TimestampPrecision)
+	}
+	return &Converter{precision: p}, nil
+}
+
+// Decode parses the lines. The lines without timestamp use the current time.
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (ma any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	handler := protocol.NewMetricHandler()
+	handler.SetTimePrecision(c.precision)
+	handler.SetTimeFunc(timex.GetNow)
+	metrics, err := protocol.NewParser(handler).Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]any, 0, len(metrics))
+	for _, m := range metrics {
+		tags := make(map[string]any, len(m.TagList()))
+		for _, t := range m.TagList() {
+			tags[t.Key] = t.Value
+		}
+		fields := make(map[string]any, len(m.FieldList()))
+		for _, f := range m.FieldList() {
+			switch v := f.Value.(type) {
+			case uint64:
+				if v > math.MaxInt64 {
+					fields[f.Key] = float64(v)
+				} else {
+					fields[f.Key] = int64(v)
+				}
+			default:
+				fields[f.Key] = v
+			}
+		}
+		result = append(result, map[string]any{
+			FieldMeasurement: m.Name(),
+			FieldTags:        tags,
+			FieldFields:      fields,
+			FieldTimestamp:   m.Time().UnixMilli(),
+		})
+	}
+	return result, nil
+}
+
+// Encode writes each map as one line. The timestamp is optional in milliseconds or datetime.
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	var buf bytes.Buffer
+	e := protocol.NewEncoder(&buf)
+	e.SetPrecision(c.precision)
+	e.SetFieldSortOrder(protocol.SortFields)
+	e.FailOnFieldErr(true)
+	switch dt := d.(type) {
+	case map[string]any:
+		err = encodeLine(e, dt)
+	case []map[string]any:
+		for _, m := range dt {
+			if err = encodeLine(e, m); err != nil {
+				break
+			}
+		}
+	default:
+		err = fmt.Errorf("unsupported type %v, must be a map or a list of map", d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeLine(e *protocol.Encoder, m map[string]any) error {
+	name, err := cast.ToString(m[FieldMeasurement], cast.STRICT)
+	if err != nil || name == "" {
+		return fmt.Errorf("line must have a string %s field", FieldMeasurement)
+	}
+	var tags map[string]string
+	if tv, ok := m[FieldTags]; ok && tv != nil {
+		tm, err := cast.ToStringMap(tv)
+		if err != nil {
+			return fmt.Errorf("%s has invalid tags: %v", name, err)
+		}
+		tags = make(map[string]string, len(tm))
+		for k, v := range tm {
+			if v != nil {
+				tags[k] = cast.ToStringAlways(v)
+			}
+		}
+	}
+	fields, err := cast.ToStringMap(m[FieldFields])
+	if err != nil || len(fields) == 0 {
+		return fmt.Errorf("%s must have at least one field", name)
+	}
+	var tm time.Time
+	if tv, ok := m[FieldTimestamp]; ok && tv != nil {
+		tm, err = cast.InterfaceToTime(tv, "")
+		if err != nil {
+			return fmt.Errorf("%s has invalid timestamp: %v", name, err)
+		}
+	}
+	metric, err := protocol.New(name, tags, fields, tm)
+	if err != nil {
+		return err
+	}
+	if _, err := e.Encode(metric); err != nil {
+		return fmt.Errorf("encode %s failed: %v", name, err)
+	}
+	return nil
+}
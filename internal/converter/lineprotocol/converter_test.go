@@ -0,0 +1,160 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lineprotocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestDecode(t *testing.T) {
+	timex.Set(1000)
+	ctx := mockContext.NewMockContext("test", "op1")
+	tests := []struct {
+		name      string
+		precision string
+		payload   string
+		result    []map[string]any
+	}{
+		{
+			name:    "default ns",
+			payload: "cpu,host=a,region=west usage=0.5,count=3i,total=18446744073709551615u,small=5u,status=\"ok\",up=true 1700000000123456789\nmem free=1024i\n",
+			result: []map[string]any{
+				{
+					"measurement": "cpu",
+					"tags":        map[string]any{"host": "a", "region": "west"},
+					"fields":      map[string]any{"usage": 0.5, "count": int64(3), "total": float64(18446744073709551615), "small": int64(5), "status": "ok", "up": true},
+					"timestamp":   int64(1700000000123),
+				},
+				{
+					"measurement": "mem",
+					"tags":        map[string]any{},
+					"fields":      map[string]any{"free": int64(1024)},
+					"timestamp":   int64(1000),
+				},
+			},
+		},
+		{
+			name:      "ms",
+			precision: "ms",
+			payload:   "cpu usage=1 1700000000123",
+			result: []map[string]any{
+				{"measurement": "cpu", "tags": map[string]any{}, "fields": map[string]any{"usage": 1.0}, "timestamp": int64(1700000000123)},
+			},
+		},
+		{
+			name:      "s",
+			precision: "s",
+			payload:   "cpu usage=1 1700000000",
+			result: []map[string]any{
+				{"measurement": "cpu", "tags": map[string]any{}, "fields": map[string]any{"usage": 1.0}, "timestamp": int64(1700000000000)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(map[string]any{"timestampPrecision": tt.precision})
+			require.NoError(t, err)
+			r, err := c.Decode(ctx, []byte(tt.payload))
+			require.NoError(t, err)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter(nil)
+	require.NoError(t, err)
+	_, err = c.Decode(ctx, []byte("cpu"))
+	require.Error(t, err)
+	_, err = NewConverter(map[string]any{"timestampPrecision": "m"})
+	require.EqualError(t, err, "timestampPrecision m is not supported, expect one of ns, us, ms and s")
+}
+
+func TestEncode(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter(map[string]any{"timestampPrecision": "ms"})
+	require.NoError(t, err)
+	tests := []struct {
+		name   string
+		data   any
+		result string
+		err    string
+	}{
+		{
+			name:   "map",
+			data:   map[string]any{"measurement": "cpu", "tags": map[string]any{"host": "a", "id": 1}, "fields": map[string]any{"usage": 0.5, "count": int64(3), "status": "ok"}, "timestamp": int64(1700000000123)},
+			result: "cpu,host=a,id=1 count=3i,status=\"ok\",usage=0.5 1700000000123\n",
+		},
+		{
+			name: "list",
+			data: []map[string]any{
+				{"measurement": "cpu", "fields": map[string]any{"usage": 1.0}, "timestamp": time.UnixMilli(1700000000123)},
+				{"measurement": "mem", "fields": map[string]any{"free": int64(1024)}},
+			},
+			result: "cpu usage=1 1700000000123\nmem free=1024i\n",
+		},
+		{
+			name: "no measurement",
+			data: map[string]any{"fields": map[string]any{"usage": 1.0}},
+			err:  "line must have a string measurement field",
+		},
+		{
+			name: "no fields",
+			data: map[string]any{"measurement": "cpu", "fields": map[string]any{}},
+			err:  "cpu must have at least one field",
+		},
+		{
+			name: "invalid timestamp",
+			data: map[string]any{"measurement": "cpu", "fields": map[string]any{"usage": 1.0}, "timestamp": true},
+			err:  "cpu has invalid timestamp: unsupported type to convert to timestamp true",
+		},
+		{
+			name: "invalid type",
+			data: 12,
+			err:  "unsupported type 12, must be a map or a list of map",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := c.Encode(ctx, tt.data)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(b))
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter(nil)
+	require.NoError(t, err)
+	payload := "weather,location=us-midwest temperature=82,humidity=71i 1465839830100400200\n"
+	r, err := c.Decode(ctx, []byte(payload))
+	require.NoError(t, err)
+	b, err := c.Encode(ctx, r)
+	require.NoError(t, err)
+	// The timestamp is truncated to milliseconds
+	require.Equal(t, "weather,location=us-midwest humidity=71i,temperature=82 1465839830100000000\n", string(b))
+}
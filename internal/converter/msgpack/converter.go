@@ -0,0 +1,105 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgpack
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/ugorji/go/codec"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/typed"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// The handle is read only after the initialization, so it is shared by all the converters.
+// Integers are decoded as int64 so the unsigned integers larger than the max int64 overflow. Strings are decoded
+// as string, binaries as []byte, maps as map[any]any and the timestamp extension as time.Time.
+// The new spec with the str and bin types and the timestamp extension is used to encode.
+var handle = newHandle()
+
+func newHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.WriteExt = true
+	h.SignedInteger = true
+	h.Raw = true
+	return h
+}
+
+type Converter struct {
+	sync.RWMutex
+	schema map[string]*ast.JsonStreamField
+}
+
+func NewConverter(schema map[string]*ast.JsonStreamField) message.Converter {
+	return &Converter{schema: schema}
+}
+
+func (c *Converter) ResetSchema(schema map[string]*ast.JsonStreamField) {
+	c.Lock()
+	defer c.Unlock()
+	c.schema = schema
+}
+
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	err = codec.NewEncoderBytes(&b, handle).Encode(d)
+	return b, err
+}
+
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (ma any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	var v any
+	dec := codec.NewDecoderBytes(b, handle)
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if n := dec.NumBytesRead(); n != len(b) {
+		return nil, fmt.Errorf("unexpected %d bytes after the msgpack data", len(b)-n)
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return typed.Convert(v, c.schema)
+}
+
+// DecodeField decodes a top level field and keeps the other fields as raw bytes
+func (c *Converter) DecodeField(_ api.StreamContext, b []byte, f string) (any, error) {
+	var m map[any]codec.Raw
+	if err := codec.NewDecoderBytes(b, handle).Decode(&m); err != nil {
+		return nil, fmt.Errorf("msgpack data is not a map: %v", err)
+	}
+	raw, ok := m[f]
+	if !ok {
+		return nil, nil
+	}
+	var v any
+	if err := codec.NewDecoderBytes(raw, handle).Decode(&v); err != nil {
+		return nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return typed.ConvertField(f, v, c.schema)
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgpack
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestCodec(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		b    []byte
+		r    any
+	}{
+		{name: "nil", v: nil, b: []byte{0xc0}},
+		{name: "bool", v: true, b: []byte{0xc3}},
+		{name: "fixint", v: int64(5), b: []byte{0x05}},
+		{name: "negative fixint", v: int64(-3), b: []byte{0xfd}},
+		{name: "int16", v: int64(300), b: []byte{0xd1, 0x01, 0x2c}},
+		{name: "uint", v: uint64(300), b: []byte{0xcd, 0x01, 0x2c}, r: int64(300)},
+		{name: "float32", v: float32(1.5), b: []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}, r: 1.5},
+		{name: "float64", v: 1.5, b: []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{name: "fixstr", v: "ab", b: []byte{0xa2, 'a', 'b'}},
+		{name: "str8", v: strings.Repeat("a", 32), b: append([]byte{0xd9, 32}, []byte(strings.Repeat("a", 32))...)},
+		{name: "bin8", v: []byte{1, 2}, b: []byte{0xc4, 0x02, 0x01, 0x02}},
+		{name: "array", v: []any{int64(1), "a"}, b: []byte{0x92, 0x01, 0xa1, 'a'}},
+		{name: "map", v: map[string]any{"a": int64(1)}, b: []byte{0x81, 0xa1, 'a', 0x01}, r: map[any]any{"a": int64(1)}},
+		{name: "timestamp", v: time.Unix(1, 2).UTC(), b: []byte{0xd7, 0xff, 0, 0, 0, 0x08, 0, 0, 0, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b []byte
+			require.NoError(t, codec.NewEncoderBytes(&b, handle).Encode(tt.v))
+			require.Equal(t, tt.b, b)
+			var r any
+			require.NoError(t, codec.NewDecoderBytes(tt.b, handle).Decode(&r))
+			exp := tt.r
+			if exp == nil {
+				exp = tt.v
+			}
+			require.Equal(t, exp, r)
+		})
+	}
+}
+
+func TestConverter(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c := NewConverter(nil)
+	m := map[string]any{"id": int64(1), "name": "dev", "temp": 20.5, "tags": []any{"a"}, "loc": map[string]any{"lat": 1.5}}
+	b, err := c.Encode(ctx, m)
+	require.NoError(t, err)
+	r, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, m, r)
+
+	// typed by the schema
+	c.(message.SchemaResetAbleConverter).ResetSchema(map[string]*ast.JsonStreamField{
+		"id":   {Type: "float"},
+		"temp": {Type: "float"},
+	})
+	r, err = c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": 1.0, "temp": 20.5}, r)
+
+	pd := c.(message.PartialDecoder)
+	v, err := pd.DecodeField(ctx, b, "id")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, v)
+	v, err = pd.DecodeField(ctx, b, "none")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	ms := []map[string]any{{"a": int64(1)}, {"a": int64(2)}}
+	b, err = c.Encode(ctx, ms)
+	require.NoError(t, err)
+	c.(message.SchemaResetAbleConverter).ResetSchema(nil)
+	r, err = c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, ms, r)
+}
+
+func TestConverterError(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c := NewConverter(map[string]*ast.JsonStreamField{"a": {Type: "bigint"}})
+	_, err := c.Decode(ctx, []byte{0x81, 0xa1})
+	require.EqualError(t, err, "unexpected EOF")
+	_, err = c.Decode(ctx, []byte{0x01, 0x02})
+	require.EqualError(t, err, "unexpected 1 bytes after the msgpack data")
+	_, err = c.Decode(ctx, []byte{0x01})
+	require.EqualError(t, err, "only map[string]interface{} and []map[string]interface{} is supported")
+	_, err = c.Decode(ctx, []byte{0x81, 0xa1, 'a', 0xa1, 'b'})
+	require.EqualError(t, err, "a has wrong type:string, expect:bigint")
+	_, err = c.Decode(ctx, []byte{0xc1})
+	require.EqualError(t, err, "msgpack decode error [pos 1]: cannot infer value: unrecognized descriptor byte: Oxc1/193/unknown")
+	_, err = c.(message.PartialDecoder).DecodeField(ctx, []byte{0x01}, "a")
+	require.EqualError(t, err, "msgpack data is not a map: msgpack decode error [pos 1]: cannot read container length: unrecognized descriptor byte: hex: 1, decimal: 1")
+}
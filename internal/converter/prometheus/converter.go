@@ -0,0 +1,212 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// The fields of each decoded sample
+const (
+	FieldName      = "name"
+	FieldLabels    = "labels"
+	FieldValue     = "value"
+	FieldTimestamp = "timestamp"
+)
+
+// Converter decodes the Prometheus text exposition format to a list of samples.
+// Each sample is a map of name, labels, value and timestamp in milliseconds.
+type Converter struct{}
+
+func NewConverter() message.Converter {
+	return &Converter{}
+}
+
+// Encode converts the samples back to the text exposition format without the type information
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	var buf bytes.Buffer
+	switch dt := d.(type) {
+	case map[string]any:
+		err = writeSample(&buf, dt)
+	case []map[string]any:
+		for _, m := range dt {
+			if err = writeSample(&buf, m); err != nil {
+				break
+			}
+		}
+	default:
+		err = fmt.Errorf("unsupported type %v, must be a map or a list of map", d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeSample(buf *bytes.Buffer, m map[string]any) error {
+	name, err := cast.ToString(m[FieldName], cast.STRICT)
+	if err != nil || name == "" {
+		return fmt.Errorf("sample must have a string %s field", FieldName)
+	}
+	value, err := cast.ToFloat64(m[FieldValue], cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return fmt.Errorf("sample %s has invalid value: %v", name, err)
+	}
+	buf.WriteString(name)
+	if lv, ok := m[FieldLabels]; ok && lv != nil {
+		labels, err := cast.ToStringMap(lv)
+		if err != nil {
+			return fmt.Errorf("sample %s has invalid labels: %v", name, err)
+		}
+		if len(labels) > 0 {
+			keys := make([]string, 0, len(labels))
+			for k := range labels {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			buf.WriteByte('{')
+			for i, k := range keys {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				buf.WriteString(k)
+				buf.WriteString(`="`)
+				buf.WriteString(labelEscaper.Replace(cast.ToStringAlways(labels[k])))
+				buf.WriteByte('"')
+			}
+			buf.WriteByte('}')
+		}
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(value))
+	if tv, ok := m[FieldTimestamp]; ok && tv != nil {
+		ts, err := cast.ToInt64(tv, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return fmt.Errorf("sample %s has invalid timestamp: %v", name, err)
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(ts, 10))
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Decode parses the text exposition format. Histograms and summaries are expanded to the
+// _bucket, _sum and _count samples like the text format. The samples without timestamp
+// use the current time.
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (ma any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(families))
+	for n := range families {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	now := timex.GetNow().UnixMilli()
+	result := make([]map[string]any, 0, len(families))
+	for _, n := range names {
+		mf := families[n]
+		for _, m := range mf.GetMetric() {
+			ts := now
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			labels := make(map[string]any, len(m.GetLabel()))
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			add := func(name string, value float64, extraName, extraValue string) {
+				ls := labels
+				if extraName != "" {
+					ls = make(map[string]any, len(labels)+1)
+					for k, v := range labels {
+						ls[k] = v
+					}
+					ls[extraName] = extraValue
+				}
+				result = append(result, map[string]any{
+					FieldName:      name,
+					FieldLabels:    ls,
+					FieldValue:     value,
+					FieldTimestamp: ts,
+				})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(n, m.GetCounter().GetValue(), "", "")
+			case dto.MetricType_GAUGE:
+				add(n, m.GetGauge().GetValue(), "", "")
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add(n, q.GetValue(), "quantile", formatFloat(q.GetQuantile()))
+				}
+				add(n+"_sum", s.GetSampleSum(), "", "")
+				add(n+"_count", float64(s.GetSampleCount()), "", "")
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, bk := range h.GetBucket() {
+					add(n+"_bucket", float64(bk.GetCumulativeCount()), "le", formatFloat(bk.GetUpperBound()))
+				}
+				add(n+"_sum", h.GetSampleSum(), "", "")
+				add(n+"_count", float64(h.GetSampleCount()), "", "")
+			default:
+				add(n, m.GetUntyped().GetValue(), "", "")
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestDecode(t *testing.T) {
+	timex.Set(1000)
+	ctx := mockContext.NewMockContext("test", "op1")
+	c := NewConverter()
+	payload := `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"}    3 1395066363000
+# TYPE temperature gauge
+temperature{room="a \"b\""} -1.5
+# TYPE latency histogram
+latency_bucket{le="0.5"} 2
+latency_bucket{le="+Inf"} 3
+latency_sum 1.2
+latency_count 3
+# TYPE rpc summary
+rpc{quantile="0.9"} 0.02
+rpc_sum 0.1
+rpc_count 5
+untyped_metric NaN
+`
+	r, err := c.Decode(ctx, []byte(payload))
+	require.NoError(t, err)
+	result := r.([]map[string]any)
+	require.Len(t, result, 11)
+	// NaN cannot be compared by equal
+	require.Equal(t, "untyped_metric", result[10][FieldName])
+	require.True(t, math.IsNaN(result[10][FieldValue].(float64)))
+	require.Equal(t, []map[string]any{
+		{"name": "http_requests_total", "labels": map[string]any{"method": "post", "code": "200"}, "value": 1027.0, "timestamp": int64(1395066363000)},
+		{"name": "http_requests_total", "labels": map[string]any{"method": "post", "code": "400"}, "value": 3.0, "timestamp": int64(1395066363000)},
+		{"name": "latency_bucket", "labels": map[string]any{"le": "0.5"}, "value": 2.0, "timestamp": int64(1000)},
+		{"name": "latency_bucket", "labels": map[string]any{"le": "+Inf"}, "value": 3.0, "timestamp": int64(1000)},
+		{"name": "latency_sum", "labels": map[string]any{}, "value": 1.2, "timestamp": int64(1000)},
+		{"name": "latency_count", "labels": map[string]any{}, "value": 3.0, "timestamp": int64(1000)},
+		{"name": "rpc", "labels": map[string]any{"quantile": "0.9"}, "value": 0.02, "timestamp": int64(1000)},
+		{"name": "rpc_sum", "labels": map[string]any{}, "value": 0.1, "timestamp": int64(1000)},
+		{"name": "rpc_count", "labels": map[string]any{}, "value": 5.0, "timestamp": int64(1000)},
+		{"name": "temperature", "labels": map[string]any{"room": `a "b"`}, "value": -1.5, "timestamp": int64(1000)},
+	}, result[:10])
+
+	_, err = c.Decode(ctx, []byte("metric{a=b} 1\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected '\"' at start of label value")
+}
+
+func TestEncode(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c := NewConverter()
+	b, err := c.Encode(ctx, []map[string]any{
+		{"name": "temperature", "labels": map[string]any{"room": "a \"b\"\n", "floor": 1}, "value": 21.5, "timestamp": int64(1000)},
+		{"name": "up", "value": int64(1)},
+		{"name": "inf", "value": math.Inf(1)},
+	})
+	require.NoError(t, err)
+	exp := "temperature{floor=\"1\",room=\"a \\\"b\\\"\\n\"} 21.5 1000\nup 1\ninf +Inf\n"
+	require.Equal(t, exp, string(b))
+	// the encoded text can be decoded again
+	r, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"floor": "1", "room": "a \"b\"\n"}, r.([]map[string]any)[1]["labels"])
+
+	_, err = c.Encode(ctx, map[string]any{"value": 1})
+	require.EqualError(t, err, "sample must have a string name field")
+	_, err = c.Encode(ctx, map[string]any{"name": "a", "value": "x"})
+	require.EqualError(t, err, "sample a has invalid value: cannot convert string(x) to float64")
+	_, err = c.Encode(ctx, "a")
+	require.EqualError(t, err, "unsupported type a, must be a map or a list of map")
+}
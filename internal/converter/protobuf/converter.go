@@ -16,13 +16,17 @@ package protobuf
 
 import (
 	"fmt"
+	"math"
 
+	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
 	"github.com/jhump/protoreflect/desc"            //nolint:staticcheck
 	"github.com/jhump/protoreflect/desc/protoparse" //nolint:staticcheck
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	kconf "github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/static"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
@@ -59,6 +63,23 @@ func NewConverter(schemaFile string, soFile string, messageName string) (message
 	}
 }
 
+// FindService finds the service definition in the schema file. The name can be fully qualified or the simple name.
+func FindService(schemaFile string, serviceName string) (*desc.ServiceDescriptor, error) {
+	fds, err := protoParser.ParseFiles(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed: %s", schemaFile, err)
+	}
+	if sd := fds[0].FindService(serviceName); sd != nil {
+		return sd, nil
+	}
+	for _, sd := range fds[0].GetServices() {
+		if sd.GetName() == serviceName {
+			return sd, nil
+		}
+	}
+	return nil, fmt.Errorf("service %s not found in schema file %s", serviceName, schemaFile)
+}
+
 func (c *Converter) Encode(ctx api.StreamContext, d any) (b []byte, err error) {
 	defer func() {
 		if err != nil {
@@ -67,7 +88,7 @@ func (c *Converter) Encode(ctx api.StreamContext, d any) (b []byte, err error) {
 	}()
 	switch m := d.(type) {
 	case map[string]interface{}:
-		msg, err := c.fc.encodeMap(c.descriptor, m)
+		msg, err := c.fc.EncodeMap(c.descriptor, m)
 		if err != nil {
 			return nil, err
 		}
@@ -90,3 +111,94 @@ func (c *Converter) Decode(ctx api.StreamContext, b []byte) (m any, err error) {
 	}
 	return c.fc.DecodeMessage(result, c.descriptor), nil
 }
+
+// DecodeField decodes a top level scalar field by scanning the wire format without unmarshalling the whole message.
+// It returns nil if the field is not a singular scalar field.
+func (c *Converter) DecodeField(_ api.StreamContext, b []byte, f string) (any, error) {
+	fd := c.descriptor.FindFieldByName(f)
+	if fd == nil || fd.IsRepeated() || fd.GetType() == dpb.FieldDescriptorProto_TYPE_MESSAGE || fd.GetType() == dpb.FieldDescriptorProto_TYPE_GROUP {
+		return nil, nil
+	}
+	var (
+		v     any
+		found bool
+	)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if int32(num) != fd.GetNumber() {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		} else {
+			// The last one wins for the duplicate scalar fields
+			v, n = consumeScalar(fd, typ, b)
+			found = true
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	if !found {
+		if fd.GetOneOf() != nil {
+			return nil, nil
+		}
+		v = fd.GetDefaultValue()
+	}
+	return c.fc.DecodeField(v, fd, cast.CONVERT_SAMEKIND)
+}
+
+func consumeScalar(fd *desc.FieldDescriptor, typ protowire.Type, b []byte) (any, int) {
+	switch typ {
+	case protowire.VarintType:
+		x, n := protowire.ConsumeVarint(b)
+		switch fd.GetType() {
+		case dpb.FieldDescriptorProto_TYPE_INT32, dpb.FieldDescriptorProto_TYPE_ENUM:
+			return int32(x), n
+		case dpb.FieldDescriptorProto_TYPE_INT64:
+			return int64(x), n
+		case dpb.FieldDescriptorProto_TYPE_UINT32:
+			return uint32(x), n
+		case dpb.FieldDescriptorProto_TYPE_UINT64:
+			return x, n
+		case dpb.FieldDescriptorProto_TYPE_SINT32:
+			return int32(protowire.DecodeZigZag(x & math.MaxUint32)), n
+		case dpb.FieldDescriptorProto_TYPE_SINT64:
+			return protowire.DecodeZigZag(x), n
+		case dpb.FieldDescriptorProto_TYPE_BOOL:
+			return x != 0, n
+		}
+	case protowire.Fixed32Type:
+		x, n := protowire.ConsumeFixed32(b)
+		switch fd.GetType() {
+		case dpb.FieldDescriptorProto_TYPE_FIXED32:
+			return x, n
+		case dpb.FieldDescriptorProto_TYPE_SFIXED32:
+			return int32(x), n
+		case dpb.FieldDescriptorProto_TYPE_FLOAT:
+			return math.Float32frombits(x), n
+		}
+	case protowire.Fixed64Type:
+		x, n := protowire.ConsumeFixed64(b)
+		switch fd.GetType() {
+		case dpb.FieldDescriptorProto_TYPE_FIXED64:
+			return x, n
+		case dpb.FieldDescriptorProto_TYPE_SFIXED64:
+			return int64(x), n
+		case dpb.FieldDescriptorProto_TYPE_DOUBLE:
+			return math.Float64frombits(x), n
+		}
+	case protowire.BytesType:
+		x, n := protowire.ConsumeBytes(b)
+		switch fd.GetType() {
+		case dpb.FieldDescriptorProto_TYPE_STRING:
+			return string(x), n
+		case dpb.FieldDescriptorProto_TYPE_BYTES:
+			return x, n
+		}
+	}
+	// wire type mismatch
+	return nil, -1
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/testx"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
@@ -285,6 +286,51 @@ func TestEncodeDecodeForAllTypes(t *testing.T) {
 	}
 }
 
+func TestDecodeField(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter("../../schema/test/alltypes.proto", "", "AllTypesTest")
+	require.NoError(t, err)
+	b, err := c.Encode(ctx, map[string]any{
+		"adouble":    20.44,
+		"afloat":     20.44,
+		"anint32":    -67,
+		"anint64":    -67,
+		"auint32":    67,
+		"abool":      true,
+		"abytes":     []byte{0x01, 0x02, 0x03},
+		"int32_list": []int64{1, 2, 3},
+	})
+	require.NoError(t, err)
+	full, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	pd := c.(message.PartialDecoder)
+	// Must be the same as the full decoded value including the default value
+	for _, f := range []string{"adouble", "afloat", "anint32", "anint64", "auint32", "auint64", "abool", "abytes"} {
+		v, err := pd.DecodeField(ctx, b, f)
+		require.NoError(t, err)
+		assert.Equal(t, full.(map[string]any)[f], v, f)
+	}
+	// Not a singular scalar field
+	for _, f := range []string{"int32_list", "notexist"} {
+		v, err := pd.DecodeField(ctx, b, f)
+		require.NoError(t, err)
+		assert.Nil(t, v)
+	}
+	_, err = pd.DecodeField(ctx, []byte{0x09, 0x01}, "anint32")
+	assert.Error(t, err)
+	// oneof field
+	c, err = NewConverter("../../schema/test/test5.proto", "", "Book")
+	require.NoError(t, err)
+	pd = c.(message.PartialDecoder)
+	b = []byte{0x0A, 0x03, 0x31, 0x32, 0x33, 0x1A, 0x04, 0x31, 0x32, 0x33, 0x34}
+	v, err := pd.DecodeField(ctx, b, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "1234", v)
+	v, err = pd.DecodeField(ctx, b, "d")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
 func TestErr(t *testing.T) {
 	ctx := mockContext.NewMockContext("test", "op1")
 	c, err := NewConverter("../../schema/test/test1.proto", "", "Person")
@@ -300,3 +346,16 @@ func TestErr(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, errorx.CovnerterErr, errWithCode.Code())
 }
+
+func TestFindService(t *testing.T) {
+	sd, err := FindService("../../service/test/schemas/hw.proto", "Greeter")
+	require.NoError(t, err)
+	require.Equal(t, "helloworld.Greeter", sd.GetFullyQualifiedName())
+	sd, err = FindService("../../service/test/schemas/hw.proto", "helloworld.Greeter")
+	require.NoError(t, err)
+	require.Equal(t, "helloworld.Greeter", sd.GetFullyQualifiedName())
+	_, err = FindService("../../service/test/schemas/hw.proto", "Other")
+	require.EqualError(t, err, "service Other not found in schema file ../../service/test/schemas/hw.proto")
+	_, err = FindService("../../service/test/schemas/notexist.proto", "Greeter")
+	require.Error(t, err)
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// reloadConverter follows the latest version of a schema. It recreates the converter once the schema is updated so
+// that the running rules do not need to restart.
+type reloadConverter struct {
+	sync.Mutex
+	schemaType def.SchemaType
+	name       string
+	create     func() (message.Converter, error)
+	// The registry revision and the schema version of the current converter
+	revision atomic.Int64
+	version  int
+	current  atomic.Pointer[converterHolder]
+}
+
+type converterHolder struct {
+	c message.Converter
+}
+
+// partialReloadConverter is the reloadConverter for the converters which support partial decode
+type partialReloadConverter struct {
+	*reloadConverter
+}
+
+// newReloadConverter creates the converter by the create function. The name is the schema name which may have a
+// version. If the version is pinned or the schema cannot be reloaded like the static one, the converter is returned
+// directly.
+func newReloadConverter(schemaType def.SchemaType, name string, create func() (message.Converter, error)) (message.Converter, error) {
+	base, version, err := schema.SplitVersion(name)
+	if err != nil {
+		return nil, err
+	}
+	rev := schema.Revision()
+	c, err := create()
+	if err != nil || version > 0 {
+		return c, err
+	}
+	ffs, err := schema.GetSchemaFile(schemaType, base)
+	if err != nil {
+		return nil, err
+	}
+	if ffs.SchemaFile == "" || ffs.SoFile != "" {
+		return c, nil
+	}
+	r := &reloadConverter{
+		schemaType: schemaType,
+		name:       base,
+		create:     create,
+		version:    ffs.Version,
+	}
+	r.revision.Store(rev)
+	r.current.Store(&converterHolder{c: c})
+	if _, ok := c.(message.PartialDecoder); ok {
+		return &partialReloadConverter{reloadConverter: r}, nil
+	}
+	return r, nil
+}
+
+func (r *reloadConverter) get(ctx api.StreamContext) message.Converter {
+	if rev := schema.Revision(); rev != r.revision.Load() {
+		r.reload(ctx, rev)
+	}
+	return r.current.Load().c
+}
+
+// reload recreates the converter if the schema version changes. If failed, the previous converter is kept.
+func (r *reloadConverter) reload(ctx api.StreamContext, rev int64) {
+	r.Lock()
+	defer r.Unlock()
+	if r.revision.Load() == rev {
+		return
+	}
+	r.revision.Store(rev)
+	ffs, err := schema.GetSchemaFile(r.schemaType, r.name)
+	if err != nil {
+		ctx.GetLogger().Warnf("cannot find schema %s.%s, keep using version %d: %v", r.schemaType, r.name, r.version, err)
+		return
+	}
+	if ffs.Version == r.version {
+		return
+	}
+	c, err := r.create()
+	if err != nil {
+		ctx.GetLogger().Errorf("reload schema %s.%s version %d failed, keep using version %d: %v", r.schemaType, r.name, ffs.Version, r.version, err)
+		return
+	}
+	r.version = ffs.Version
+	r.current.Store(&converterHolder{c: c})
+	ctx.GetLogger().Infof("converter reloaded with schema %s.%s version %d", r.schemaType, r.name, ffs.Version)
+}
+
+func (r *reloadConverter) Encode(ctx api.StreamContext, d any) ([]byte, error) {
+	return r.get(ctx).Encode(ctx, d)
+}
+
+func (r *reloadConverter) Decode(ctx api.StreamContext, b []byte) (any, error) {
+	return r.get(ctx).Decode(ctx, b)
+}
+
+func (r *partialReloadConverter) DecodeField(ctx api.StreamContext, b []byte, f string) (any, error) {
+	if pd, ok := r.get(ctx).(message.PartialDecoder); ok {
+		return pd.DecodeField(ctx, b, f)
+	}
+	return nil, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestReloadConverter(t *testing.T) {
+	testx.InitEnv("converter")
+	require.NoError(t, schema.InitRegistry())
+	require.NoError(t, schema.Register(&schema.Info{Type: def.PROTOBUF, Name: "reload", Content: `syntax = "proto3";message Reading {string id = 1;}`}))
+	defer func() {
+		require.NoError(t, schema.DeleteSchema(def.PROTOBUF, "reload"))
+	}()
+	ctx := mockContext.NewMockContext("test", "op1")
+	latest, err := GetOrCreateConverter(ctx, message.FormatProtobuf, "reload.Reading", nil, nil)
+	require.NoError(t, err)
+	_, ok := latest.(message.PartialDecoder)
+	require.True(t, ok)
+	pinned, err := GetOrCreateConverter(ctx, message.FormatProtobuf, "reload@1.Reading", nil, nil)
+	require.NoError(t, err)
+	data := map[string]any{"id": "a", "value": 1.5}
+
+	b, err := latest.Encode(ctx, data)
+	require.NoError(t, err)
+	r, err := latest.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": "a"}, r)
+
+	// The converter following the latest version reloads after update
+	require.NoError(t, schema.UpdateSchema(&schema.Info{Type: def.PROTOBUF, Name: "reload", Content: `syntax = "proto3";message Reading {string id = 1;double value = 2;}`}))
+	b, err = latest.Encode(ctx, data)
+	require.NoError(t, err)
+	r, err = latest.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, data, r)
+	v, err := latest.(message.PartialDecoder).DecodeField(ctx, b, "value")
+	require.NoError(t, err)
+	require.Equal(t, 1.5, v)
+	// The pinned one keeps the old version
+	r, err = pinned.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": "a"}, r)
+
+	// The previous converter is kept if the new version cannot be loaded
+	require.NoError(t, schema.UpdateSchema(&schema.Info{Type: def.PROTOBUF, Name: "reload", Content: `syntax = "proto3";message Sample {string id = 1;}`}))
+	r, err = latest.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, data, r)
+
+	_, err = GetOrCreateConverter(ctx, message.FormatProtobuf, "reload@5.Reading", nil, nil)
+	require.EqualError(t, err, "schema type protobuf, file reload version 5 not found")
+}
+
+func TestReloadJsonConverter(t *testing.T) {
+	testx.InitEnv("converter")
+	require.NoError(t, schema.InitRegistry())
+	require.NoError(t, schema.Register(&schema.Info{Type: def.JSONSCHEMA, Name: "reloadjson", Content: `{"type":"object","properties":{"a":{"type":"integer","maximum":10}}}`}))
+	defer func() {
+		require.NoError(t, schema.DeleteSchema(def.JSONSCHEMA, "reloadjson"))
+	}()
+	ctx := mockContext.NewMockContext("test", "op1")
+	latest, err := GetOrCreateConverter(ctx, message.FormatJson, "reloadjson", nil, nil)
+	require.NoError(t, err)
+	_, ok := latest.(message.PartialDecoder)
+	require.True(t, ok)
+	pinned, err := GetOrCreateConverter(ctx, message.FormatJson, "reloadjson@1", nil, nil)
+	require.NoError(t, err)
+	b := []byte(`{"a":20}`)
+
+	_, err = latest.Decode(ctx, b)
+	require.EqualError(t, err, `json schema validation failed at "/a": 20 is greater than maximum 10`)
+
+	// The converter following the latest version validates by the new schema after update
+	require.NoError(t, schema.UpdateSchema(&schema.Info{Type: def.JSONSCHEMA, Name: "reloadjson", Content: `{"type":"object","properties":{"a":{"type":"integer","maximum":100}}}`}))
+	r, err := latest.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"a": float64(20)}, r)
+	// The pinned one keeps the old version
+	_, err = pinned.Decode(ctx, b)
+	require.EqualError(t, err, `json schema validation failed at "/a": 20 is greater than maximum 10`)
+}
@@ -0,0 +1,317 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// Converter decodes the Sparkplug B payload to a flat map of metric name to value.
+// The metric aliases are resolved with the birth certificates of the edge nodes and devices,
+// so the converter must decode the BIRTH messages before the DATA messages of the same edge node.
+type Converter struct {
+	sync.Mutex
+	// the birth information keyed by the edge node
+	nodes map[string]*node
+	// for encode, command messages do not have a sequence number
+	command bool
+	seq     uint64
+}
+
+type node struct {
+	// aliases are unique in the edge node and all its devices
+	aliases map[uint64]*metricDef
+	// data types by the device id and the metric name, the device id is empty for node metrics
+	types map[string]uint32
+}
+
+type metricDef struct {
+	name     string
+	datatype uint32
+}
+
+// NewConverter creates the converter. The messageType is only used by encode to decide whether to
+// set the sequence number. It is DDATA by default.
+func NewConverter(messageType string) (message.Converter, error) {
+	c := &Converter{nodes: make(map[string]*node)}
+	switch strings.ToUpper(messageType) {
+	case "", NDATA, DDATA:
+	case NCMD, DCMD:
+		c.command = true
+	default:
+		return nil, fmt.Errorf("unsupported sparkplug message type %s to encode, expect one of NDATA, DDATA, NCMD and DCMD", messageType)
+	}
+	return c, nil
+}
+
+// Encode converts the map to a payload with one metric for each field. The metrics are sorted by the name.
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	m, ok := d.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %v, must be a map. Set sendSingle to true to encode a list", d)
+	}
+	now := uint64(timex.GetNow().UnixMilli())
+	metrics, err := toMetrics(m, now)
+	if err != nil {
+		return nil, err
+	}
+	p := &Payload{
+		Timestamp: now,
+		Metrics:   metrics,
+	}
+	if !c.command {
+		c.Lock()
+		p.Seq = c.seq
+		p.HasSeq = true
+		c.seq = (c.seq + 1) % 256
+		c.Unlock()
+	}
+	return Marshal(p)
+}
+
+func toMetrics(m map[string]any, ts uint64) ([]*Metric, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	metrics := make([]*Metric, 0, len(keys))
+	for _, k := range keys {
+		metric := &Metric{Name: k, Timestamp: ts}
+		switch v := m[k].(type) {
+		case nil:
+			metric.IsNull = true
+		case map[string]any:
+			sub, err := toMetrics(v, ts)
+			if err != nil {
+				return nil, err
+			}
+			metric.Datatype = TypeTemplate
+			metric.Value = &Template{Metrics: sub}
+		default:
+			if ds, ok, err := toDataSet(v); ok {
+				if err != nil {
+					return nil, fmt.Errorf("metric %s: %v", k, err)
+				}
+				metric.Datatype = TypeDataSet
+				metric.Value = ds
+				break
+			}
+			t, wv, err := fromValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("metric %s: %v", k, err)
+			}
+			metric.Datatype = t
+			metric.Value = wv
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, nil
+}
+
+// toDataSet converts a list of maps to a data set whose columns and types are decided by the first row
+func toDataSet(v any) (*DataSet, bool, error) {
+	var rows []map[string]any
+	switch l := v.(type) {
+	case []map[string]any:
+		rows = l
+	case []any:
+		if len(l) == 0 {
+			return nil, false, nil
+		}
+		if _, ok := l[0].(map[string]any); !ok {
+			return nil, false, nil
+		}
+		rows = make([]map[string]any, len(l))
+		for i, e := range l {
+			r, ok := e.(map[string]any)
+			if !ok {
+				return nil, true, fmt.Errorf("dataset row %d is %T but expect map", i, e)
+			}
+			rows[i] = r
+		}
+	default:
+		return nil, false, nil
+	}
+	ds := &DataSet{}
+	if len(rows) == 0 {
+		return ds, true, nil
+	}
+	for k := range rows[0] {
+		ds.Columns = append(ds.Columns, k)
+	}
+	sort.Strings(ds.Columns)
+	ds.Types = make([]uint32, len(ds.Columns))
+	for i, r := range rows {
+		row := make([]any, len(ds.Columns))
+		for j, col := range ds.Columns {
+			t, wv, err := fromValue(r[col])
+			if err != nil {
+				return nil, true, fmt.Errorf("dataset column %s: %v", col, err)
+			}
+			if i == 0 {
+				ds.Types[j] = t
+			} else if t != ds.Types[j] && t != TypeUnknown {
+				return nil, true, fmt.Errorf("dataset column %s has inconsistent types", col)
+			}
+			switch t {
+			case TypeBytes, TypeDateTimeArray, TypeBooleanArray, TypeStringArray, TypeInt64Array, TypeDoubleArray:
+				return nil, true, fmt.Errorf("dataset column %s has unsupported type %d", col, t)
+			}
+			row[j] = wv
+		}
+		ds.Rows = append(ds.Rows, row)
+	}
+	return ds, true, nil
+}
+
+// Decode decodes the payload without the topic. Only the metrics with names are kept because the
+// aliases cannot be resolved.
+func (c *Converter) Decode(ctx api.StreamContext, b []byte) (any, error) {
+	return c.DecodeWithMeta(ctx, b, nil)
+}
+
+// DecodeWithMeta decodes the payload with the help of the topic in the metadata. The birth certificates
+// are recorded to resolve the aliases of the later data. The group, edge node and device ids, the message type,
+// the sequence number and the timestamp are added to the metadata.
+func (c *Converter) DecodeWithMeta(ctx api.StreamContext, b []byte, meta map[string]any) (ma any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	p, err := Unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var t *Topic
+	if tpc, ok := meta["topic"].(string); ok {
+		t, err = ParseTopic(tpc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if t == nil {
+		return flatten(ctx, p.Metrics, nil, "")
+	}
+	meta["groupId"] = t.GroupId
+	meta["edgeNodeId"] = t.EdgeNodeId
+	meta["messageType"] = t.MessageType
+	if t.DeviceId != "" {
+		meta["deviceId"] = t.DeviceId
+	}
+	if p.HasSeq {
+		meta["seq"] = int64(p.Seq)
+	}
+	if p.Timestamp > 0 {
+		meta["timestamp"] = int64(p.Timestamp)
+	}
+	if p.Uuid != "" {
+		meta["uuid"] = p.Uuid
+	}
+	c.Lock()
+	defer c.Unlock()
+	key := t.NodeKey()
+	n := c.nodes[key]
+	switch t.MessageType {
+	case NBIRTH:
+		// a new session of the edge node, all the aliases of the node and devices are renewed
+		n = &node{aliases: make(map[uint64]*metricDef), types: make(map[string]uint32)}
+		c.nodes[key] = n
+		n.learn(p.Metrics, t.DeviceId)
+	case DBIRTH:
+		if n == nil {
+			ctx.GetLogger().Warnf("receive DBIRTH of %s before the NBIRTH of the edge node", t)
+			n = &node{aliases: make(map[uint64]*metricDef), types: make(map[string]uint32)}
+			c.nodes[key] = n
+		}
+		n.learn(p.Metrics, t.DeviceId)
+	case NDEATH:
+		delete(c.nodes, key)
+	}
+	return flatten(ctx, p.Metrics, n, t.DeviceId)
+}
+
+func (n *node) learn(metrics []*Metric, deviceId string) {
+	for _, m := range metrics {
+		if m.Name == "" {
+			continue
+		}
+		if m.HasAlias {
+			n.aliases[m.Alias] = &metricDef{name: m.Name, datatype: m.Datatype}
+		}
+		n.types[deviceId+"/"+m.Name] = m.Datatype
+	}
+}
+
+func flatten(ctx api.StreamContext, metrics []*Metric, n *node, deviceId string) (map[string]any, error) {
+	result := make(map[string]any, len(metrics))
+	for _, m := range metrics {
+		name, datatype := m.Name, m.Datatype
+		if name == "" {
+			if !m.HasAlias {
+				continue
+			}
+			var def *metricDef
+			if n != nil {
+				def = n.aliases[m.Alias]
+			}
+			if def == nil {
+				ctx.GetLogger().Warnf("drop sparkplug metric of unknown alias %d, waiting for the birth certificate", m.Alias)
+				continue
+			}
+			name = def.name
+			if datatype == TypeUnknown {
+				datatype = def.datatype
+			}
+		} else if datatype == TypeUnknown && n != nil {
+			datatype = n.types[deviceId+"/"+name]
+		}
+		if m.IsNull || m.Value == nil {
+			result[name] = nil
+			continue
+		}
+		if tpl, ok := m.Value.(*Template); ok {
+			v, err := flatten(ctx, tpl.Metrics, nil, "")
+			if err != nil {
+				return nil, fmt.Errorf("template %s: %v", name, err)
+			}
+			result[name] = v
+			continue
+		}
+		v, err := toValue(datatype, m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %v", name, err)
+		}
+		result[name] = v
+	}
+	return result, nil
+}
+
+var _ message.MetaDecoder = &Converter{}
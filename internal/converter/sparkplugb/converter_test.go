@@ -0,0 +1,193 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestDecodeWithBirth(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter("")
+	require.NoError(t, err)
+	md := c.(message.MetaDecoder)
+	tests := []struct {
+		name    string
+		topic   string
+		payload *Payload
+		r       map[string]any
+		meta    map[string]any
+	}{
+		{
+			name:  "nbirth",
+			topic: "spBv1.0/plant/NBIRTH/edge1",
+			payload: &Payload{Timestamp: 100, Seq: 0, HasSeq: true, Metrics: []*Metric{
+				{Name: "bdSeq", Datatype: TypeInt64, Value: uint64(0)},
+				{Name: "Node Control/Rebirth", Alias: 1, HasAlias: true, Datatype: TypeBoolean, Value: false},
+				{Name: "cpu", Alias: 2, HasAlias: true, Datatype: TypeFloat, Value: float32(0.5)},
+			}},
+			r:    map[string]any{"bdSeq": int64(0), "Node Control/Rebirth": false, "cpu": 0.5},
+			meta: map[string]any{"groupId": "plant", "edgeNodeId": "edge1", "messageType": NBIRTH, "seq": int64(0), "timestamp": int64(100)},
+		},
+		{
+			name:  "dbirth",
+			topic: "spBv1.0/plant/DBIRTH/edge1/pump1",
+			payload: &Payload{Timestamp: 101, Seq: 1, HasSeq: true, Metrics: []*Metric{
+				{Name: "temperature", Alias: 3, HasAlias: true, Datatype: TypeInt16, Value: uint32(20)},
+				{Name: "running", Alias: 4, HasAlias: true, Datatype: TypeBoolean, Value: true},
+				{Name: "mode", Datatype: TypeInt8, Value: uint32(1)},
+			}},
+			r:    map[string]any{"temperature": int64(20), "running": true, "mode": int64(1)},
+			meta: map[string]any{"groupId": "plant", "edgeNodeId": "edge1", "deviceId": "pump1", "messageType": DBIRTH, "seq": int64(1), "timestamp": int64(101)},
+		},
+		{
+			name:  "ddata by alias",
+			topic: "spBv1.0/plant/DDATA/edge1/pump1",
+			payload: &Payload{Timestamp: 102, Seq: 2, HasSeq: true, Metrics: []*Metric{
+				{Alias: 3, HasAlias: true, Value: uint32(0xffff)},
+				{Name: "mode", Value: uint32(0xff)},
+				{Alias: 9, HasAlias: true, Value: uint32(1)},
+				{Alias: 4, HasAlias: true, Datatype: TypeBoolean, IsNull: true},
+			}},
+			r:    map[string]any{"temperature": int64(-1), "mode": int64(-1), "running": nil},
+			meta: map[string]any{"groupId": "plant", "edgeNodeId": "edge1", "deviceId": "pump1", "messageType": DDATA, "seq": int64(2), "timestamp": int64(102)},
+		},
+		{
+			name:    "ndata by alias",
+			topic:   "spBv1.0/plant/NDATA/edge1",
+			payload: &Payload{Timestamp: 103, Seq: 3, HasSeq: true, Metrics: []*Metric{{Alias: 2, HasAlias: true, Value: float32(0.25)}}},
+			r:       map[string]any{"cpu": 0.25},
+			meta:    map[string]any{"groupId": "plant", "edgeNodeId": "edge1", "messageType": NDATA, "seq": int64(3), "timestamp": int64(103)},
+		},
+		{
+			name:    "ndeath",
+			topic:   "spBv1.0/plant/NDEATH/edge1",
+			payload: &Payload{Metrics: []*Metric{{Name: "bdSeq", Datatype: TypeInt64, Value: uint64(0)}}},
+			r:       map[string]any{"bdSeq": int64(0)},
+			meta:    map[string]any{"groupId": "plant", "edgeNodeId": "edge1", "messageType": NDEATH},
+		},
+		{
+			name:    "ndata after death",
+			topic:   "spBv1.0/plant/NDATA/edge1",
+			payload: &Payload{Timestamp: 104, Seq: 4, HasSeq: true, Metrics: []*Metric{{Alias: 2, HasAlias: true, Value: float32(0.25)}}},
+			r:       map[string]any{},
+			meta:    map[string]any{"groupId": "plant", "edgeNodeId": "edge1", "messageType": NDATA, "seq": int64(4), "timestamp": int64(104)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := map[string]any{"topic": tt.topic}
+			r, err := md.DecodeWithMeta(ctx, mustBytes(t, tt.payload), meta)
+			require.NoError(t, err)
+			require.Equal(t, tt.r, r)
+			tt.meta["topic"] = tt.topic
+			require.Equal(t, tt.meta, meta)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter("")
+	require.NoError(t, err)
+	b, err := Marshal(&Payload{Metrics: []*Metric{
+		{Name: "a", Datatype: TypeInt32, Value: uint32(1)},
+		{Alias: 1, HasAlias: true, Value: uint32(2)},
+		{Name: "tpl", Datatype: TypeTemplate, Value: &Template{Metrics: []*Metric{{Name: "b", Datatype: TypeString, Value: "s"}}}},
+	}})
+	require.NoError(t, err)
+	r, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"a": int64(1), "tpl": map[string]any{"b": "s"}}, r)
+
+	_, err = c.Decode(ctx, []byte{0x12, 0x01})
+	require.EqualError(t, err, "invalid sparkplug payload: unexpected EOF")
+	_, err = c.(message.MetaDecoder).DecodeWithMeta(ctx, b, map[string]any{"topic": "a/b"})
+	require.EqualError(t, err, "invalid sparkplug topic a/b")
+	_, err = c.Decode(ctx, mustBytes(t, &Payload{Metrics: []*Metric{{Name: "a", Datatype: TypeBoolean, Value: "s"}}}))
+	require.EqualError(t, err, "metric a: expect boolean value but got string")
+}
+
+func mustBytes(t *testing.T, p *Payload) []byte {
+	b, err := Marshal(p)
+	require.NoError(t, err)
+	return b
+}
+
+func TestEncode(t *testing.T) {
+	timex.Set(1000)
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter("DDATA")
+	require.NoError(t, err)
+	data := map[string]any{
+		"temperature": 20.5,
+		"count":       int64(3),
+		"running":     true,
+		"name":        "pump",
+		"empty":       nil,
+		"ts":          time.UnixMilli(2000).UTC(),
+		"tags":        []any{"a", "b"},
+		"motor":       map[string]any{"rpm": int64(100)},
+		"rows":        []any{map[string]any{"id": int64(1), "v": 1.5}, map[string]any{"id": int64(2), "v": 2.5}},
+	}
+	for i := 0; i < 2; i++ {
+		b, err := c.Encode(ctx, data)
+		require.NoError(t, err)
+		p, err := Unmarshal(b)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1000), p.Timestamp)
+		require.True(t, p.HasSeq)
+		require.Equal(t, uint64(i), p.Seq)
+		require.Len(t, p.Metrics, len(data))
+		require.Equal(t, "count", p.Metrics[0].Name)
+		r, err := c.Decode(ctx, b)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"temperature": 20.5,
+			"count":       int64(3),
+			"running":     true,
+			"name":        "pump",
+			"empty":       nil,
+			"ts":          time.UnixMilli(2000).UTC(),
+			"tags":        []any{"a", "b"},
+			"motor":       map[string]any{"rpm": int64(100)},
+			"rows":        []any{map[string]any{"id": int64(1), "v": 1.5}, map[string]any{"id": int64(2), "v": 2.5}},
+		}, r)
+	}
+	// command has no sequence
+	c, err = NewConverter("dcmd")
+	require.NoError(t, err)
+	b, err := c.Encode(ctx, map[string]any{"Device Control/Reset": true})
+	require.NoError(t, err)
+	p, err := Unmarshal(b)
+	require.NoError(t, err)
+	require.False(t, p.HasSeq)
+	// errors
+	_, err = c.Encode(ctx, []map[string]any{{"a": 1}})
+	require.EqualError(t, err, "unsupported type [map[a:1]], must be a map. Set sendSingle to true to encode a list")
+	_, err = c.Encode(ctx, map[string]any{"a": struct{}{}})
+	require.EqualError(t, err, "metric a: unsupported value type struct {}")
+	_, err = c.Encode(ctx, map[string]any{"a": []any{map[string]any{"b": 1}, 2}})
+	require.EqualError(t, err, "metric a: dataset row 1 is int but expect map")
+	_, err = NewConverter("NBIRTH")
+	require.EqualError(t, err, "unsupported sparkplug message type NBIRTH to encode, expect one of NDATA, DDATA, NCMD and DCMD")
+}
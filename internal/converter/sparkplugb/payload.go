@@ -0,0 +1,568 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Data types defined by the Sparkplug B specification
+const (
+	TypeUnknown uint32 = iota
+	TypeInt8
+	TypeInt16
+	TypeInt32
+	TypeInt64
+	TypeUInt8
+	TypeUInt16
+	TypeUInt32
+	TypeUInt64
+	TypeFloat
+	TypeDouble
+	TypeBoolean
+	TypeString
+	TypeDateTime
+	TypeText
+	TypeUUID
+	TypeDataSet
+	TypeBytes
+	TypeFile
+	TypeTemplate
+	TypePropertySet
+	TypePropertySetList
+	TypeInt8Array
+	TypeInt16Array
+	TypeInt32Array
+	TypeInt64Array
+	TypeUInt8Array
+	TypeUInt16Array
+	TypeUInt32Array
+	TypeUInt64Array
+	TypeFloatArray
+	TypeDoubleArray
+	TypeBooleanArray
+	TypeStringArray
+	TypeDateTimeArray
+)
+
+// Payload is the Sparkplug B protobuf payload. Only the parts used by the converter are kept,
+// the metric metadata, property sets and extensions are skipped when decoding.
+type Payload struct {
+	Timestamp uint64
+	Metrics   []*Metric
+	Seq       uint64
+	HasSeq    bool
+	Uuid      string
+	Body      []byte
+}
+
+// Metric is a single Sparkplug B metric. Value holds the raw wire value whose go type tells the
+// value field: uint32, uint64, float32, float64, bool, string, []byte, *DataSet or *Template.
+type Metric struct {
+	Name         string
+	Alias        uint64
+	HasAlias     bool
+	Timestamp    uint64
+	Datatype     uint32
+	IsHistorical bool
+	IsTransient  bool
+	IsNull       bool
+	Value        any
+}
+
+type DataSet struct {
+	Columns []string
+	Types   []uint32
+	// Rows elements are uint32, uint64, float32, float64, bool or string
+	Rows [][]any
+}
+
+type Template struct {
+	Version      string
+	Metrics      []*Metric
+	TemplateRef  string
+	IsDefinition bool
+}
+
+// Unmarshal decodes the Sparkplug B protobuf payload
+func Unmarshal(b []byte) (*Payload, error) {
+	p := &Payload{}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			p.Timestamp = v
+			return n, nil
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			m, err := unmarshalMetric(v)
+			if err != nil {
+				return 0, err
+			}
+			p.Metrics = append(p.Metrics, m)
+			return n, nil
+		case num == 3 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			p.Seq = v
+			p.HasSeq = true
+			return n, nil
+		case num == 4 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			p.Uuid = string(v)
+			return n, nil
+		case num == 5 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			p.Body = append([]byte(nil), v...)
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid sparkplug payload: %v", err)
+	}
+	return p, nil
+}
+
+// PeekSeq reads the sequence number of the payload without decoding the metrics
+func PeekSeq(b []byte) (uint64, bool, error) {
+	var (
+		seq    uint64
+		hasSeq bool
+	)
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 3 && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(b)
+			seq = v
+			hasSeq = true
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return seq, hasSeq, err
+}
+
+func unmarshalMetric(b []byte) (*Metric, error) {
+	m := &Metric{}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			if typ == protowire.BytesType {
+				v, n := protowire.ConsumeBytes(b)
+				m.Name = string(v)
+				return n, nil
+			}
+		case 2:
+			if typ == protowire.VarintType {
+				v, n := protowire.ConsumeVarint(b)
+				m.Alias = v
+				m.HasAlias = true
+				return n, nil
+			}
+		case 3:
+			if typ == protowire.VarintType {
+				v, n := protowire.ConsumeVarint(b)
+				m.Timestamp = v
+				return n, nil
+			}
+		case 4:
+			if typ == protowire.VarintType {
+				v, n := protowire.ConsumeVarint(b)
+				m.Datatype = uint32(v)
+				return n, nil
+			}
+		case 5, 6, 7:
+			if typ == protowire.VarintType {
+				v, n := protowire.ConsumeVarint(b)
+				switch num {
+				case 5:
+					m.IsHistorical = v != 0
+				case 6:
+					m.IsTransient = v != 0
+				default:
+					m.IsNull = v != 0
+				}
+				return n, nil
+			}
+		case 17:
+			if typ == protowire.BytesType {
+				v, n := protowire.ConsumeBytes(b)
+				if n < 0 {
+					return n, nil
+				}
+				ds, err := unmarshalDataSet(v)
+				if err != nil {
+					return 0, err
+				}
+				m.Value = ds
+				return n, nil
+			}
+		case 18:
+			if typ == protowire.BytesType {
+				v, n := protowire.ConsumeBytes(b)
+				if n < 0 {
+					return n, nil
+				}
+				t, err := unmarshalTemplate(v)
+				if err != nil {
+					return 0, err
+				}
+				m.Value = t
+				return n, nil
+			}
+		default:
+			if num >= 10 && num <= 16 {
+				v, n := consumeValue(num-10, typ, b)
+				if n >= 0 && v != nil {
+					m.Value = v
+					return n, nil
+				}
+			}
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return m, err
+}
+
+func unmarshalDataSet(b []byte) (*DataSet, error) {
+	ds := &DataSet{}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			ds.Columns = append(ds.Columns, string(v))
+			return n, nil
+		case num == 3 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			ds.Types = append(ds.Types, uint32(v))
+			return n, nil
+		case num == 3 && typ == protowire.BytesType:
+			// packed encoding
+			v, n := protowire.ConsumeBytes(b)
+			for len(v) > 0 {
+				t, l := protowire.ConsumeVarint(v)
+				if l < 0 {
+					return l, nil
+				}
+				ds.Types = append(ds.Types, uint32(t))
+				v = v[l:]
+			}
+			return n, nil
+		case num == 4 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			var row []any
+			err := consumeFields(v, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+				if num == 1 && typ == protowire.BytesType {
+					e, n := protowire.ConsumeBytes(b)
+					if n < 0 {
+						return n, nil
+					}
+					var ev any
+					err := consumeFields(e, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+						if num >= 1 && num <= 6 {
+							v, n := consumeValue(num-1, typ, b)
+							if n >= 0 && v != nil {
+								ev = v
+								return n, nil
+							}
+						}
+						return protowire.ConsumeFieldValue(num, typ, b), nil
+					})
+					if err != nil {
+						return 0, err
+					}
+					row = append(row, ev)
+					return n, nil
+				}
+				return protowire.ConsumeFieldValue(num, typ, b), nil
+			})
+			if err != nil {
+				return 0, err
+			}
+			ds.Rows = append(ds.Rows, row)
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return ds, err
+}
+
+func unmarshalTemplate(b []byte) (*Template, error) {
+	t := &Template{}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			t.Version = string(v)
+			return n, nil
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			m, err := unmarshalMetric(v)
+			if err != nil {
+				return 0, err
+			}
+			t.Metrics = append(t.Metrics, m)
+			return n, nil
+		case num == 4 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			t.TemplateRef = string(v)
+			return n, nil
+		case num == 5 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			t.IsDefinition = v != 0
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return t, err
+}
+
+// consumeValue reads the scalar value by its offset in the value oneof which is shared by
+// metric (starting at 10) and data set value (starting at 1): int, long, float, double, bool, string, bytes
+func consumeValue(offset protowire.Number, typ protowire.Type, b []byte) (any, int) {
+	switch offset {
+	case 0, 1, 4:
+		if typ != protowire.VarintType {
+			return nil, 0
+		}
+		v, n := protowire.ConsumeVarint(b)
+		switch offset {
+		case 0:
+			return uint32(v), n
+		case 1:
+			return v, n
+		default:
+			return v != 0, n
+		}
+	case 2:
+		if typ != protowire.Fixed32Type {
+			return nil, 0
+		}
+		v, n := protowire.ConsumeFixed32(b)
+		return math.Float32frombits(v), n
+	case 3:
+		if typ != protowire.Fixed64Type {
+			return nil, 0
+		}
+		v, n := protowire.ConsumeFixed64(b)
+		return math.Float64frombits(v), n
+	case 5:
+		if typ != protowire.BytesType {
+			return nil, 0
+		}
+		v, n := protowire.ConsumeBytes(b)
+		return string(v), n
+	case 6:
+		if typ != protowire.BytesType {
+			return nil, 0
+		}
+		v, n := protowire.ConsumeBytes(b)
+		return append([]byte(nil), v...), n
+	}
+	return nil, 0
+}
+
+// consumeFields iterates the fields of a message. The handler returns the consumed length of the field value.
+func consumeFields(b []byte, handler func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n, err := handler(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// Marshal encodes the payload to the Sparkplug B protobuf format
+func Marshal(p *Payload) ([]byte, error) {
+	var b []byte
+	if p.Timestamp > 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, p.Timestamp)
+	}
+	for _, m := range p.Metrics {
+		mb, err := marshalMetric(m)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, mb)
+	}
+	if p.HasSeq {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, p.Seq)
+	}
+	if p.Uuid != "" {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, p.Uuid)
+	}
+	if len(p.Body) > 0 {
+		b = protowire.AppendTag(b, 5, protowire.BytesType)
+		b = protowire.AppendBytes(b, p.Body)
+	}
+	return b, nil
+}
+
+func marshalMetric(m *Metric) ([]byte, error) {
+	var b []byte
+	if m.Name != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.Name)
+	}
+	if m.HasAlias {
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, m.Alias)
+	}
+	if m.Timestamp > 0 {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, m.Timestamp)
+	}
+	b = protowire.AppendTag(b, 4, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(m.Datatype))
+	if m.IsHistorical {
+		b = protowire.AppendTag(b, 5, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	if m.IsTransient {
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	if m.IsNull {
+		b = protowire.AppendTag(b, 7, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+		return b, nil
+	}
+	switch v := m.Value.(type) {
+	case *DataSet:
+		b = protowire.AppendTag(b, 17, protowire.BytesType)
+		b = protowire.AppendBytes(b, marshalDataSet(v))
+	case *Template:
+		tb, err := marshalTemplate(v)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, 18, protowire.BytesType)
+		b = protowire.AppendBytes(b, tb)
+	default:
+		var err error
+		b, err = appendValue(b, 10, v)
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %v", m.Name, err)
+		}
+	}
+	return b, nil
+}
+
+func marshalDataSet(ds *DataSet) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(len(ds.Columns)))
+	for _, c := range ds.Columns {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendString(b, c)
+	}
+	for _, t := range ds.Types {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(t))
+	}
+	for _, row := range ds.Rows {
+		var rb []byte
+		for _, e := range row {
+			eb, _ := appendValue(nil, 1, e)
+			rb = protowire.AppendTag(rb, 1, protowire.BytesType)
+			rb = protowire.AppendBytes(rb, eb)
+		}
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendBytes(b, rb)
+	}
+	return b
+}
+
+func marshalTemplate(t *Template) ([]byte, error) {
+	var b []byte
+	if t.Version != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, t.Version)
+	}
+	for _, m := range t.Metrics {
+		mb, err := marshalMetric(m)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, mb)
+	}
+	if t.TemplateRef != "" {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, t.TemplateRef)
+	}
+	if t.IsDefinition {
+		b = protowire.AppendTag(b, 5, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	return b, nil
+}
+
+// appendValue writes the scalar value into the value oneof starting at the base field number
+func appendValue(b []byte, base protowire.Number, v any) ([]byte, error) {
+	switch vv := v.(type) {
+	case nil:
+		return b, nil
+	case uint32:
+		b = protowire.AppendTag(b, base, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(vv))
+	case uint64:
+		b = protowire.AppendTag(b, base+1, protowire.VarintType)
+		b = protowire.AppendVarint(b, vv)
+	case float32:
+		b = protowire.AppendTag(b, base+2, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, math.Float32bits(vv))
+	case float64:
+		b = protowire.AppendTag(b, base+3, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(vv))
+	case bool:
+		b = protowire.AppendTag(b, base+4, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(vv))
+	case string:
+		b = protowire.AppendTag(b, base+5, protowire.BytesType)
+		b = protowire.AppendString(b, vv)
+	case []byte:
+		b = protowire.AppendTag(b, base+6, protowire.BytesType)
+		b = protowire.AppendBytes(b, vv)
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+	return b, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadWire(t *testing.T) {
+	b := []byte{
+		0x08, 0x01, // timestamp
+		0x12, 0x09, 0x0a, 0x01, 'a', 0x10, 0x01, 0x20, 0x03, 0x50, 0x05, // metric a, alias 1, Int32 5
+		0x18, 0x02, // seq
+	}
+	p := &Payload{
+		Timestamp: 1,
+		Metrics:   []*Metric{{Name: "a", Alias: 1, HasAlias: true, Datatype: TypeInt32, Value: uint32(5)}},
+		Seq:       2,
+		HasSeq:    true,
+	}
+	r, err := Marshal(p)
+	require.NoError(t, err)
+	require.Equal(t, b, r)
+	pp, err := Unmarshal(b)
+	require.NoError(t, err)
+	require.Equal(t, p, pp)
+	seq, hasSeq, err := PeekSeq(b)
+	require.NoError(t, err)
+	require.True(t, hasSeq)
+	require.Equal(t, uint64(2), seq)
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	p := &Payload{
+		Timestamp: 1700000000000,
+		Uuid:      "u1",
+		Body:      []byte{1, 2},
+		Metrics: []*Metric{
+			{Name: "long", Timestamp: 1700000000000, Datatype: TypeInt64, Value: uint64(1 << 40)},
+			{Name: "float", Datatype: TypeFloat, Value: float32(1.5)},
+			{Name: "double", Datatype: TypeDouble, Value: 2.5},
+			{Name: "bool", Datatype: TypeBoolean, Value: true, IsHistorical: true},
+			{Name: "string", Datatype: TypeString, Value: "s", IsTransient: true},
+			{Name: "bytes", Datatype: TypeBytes, Value: []byte{3}},
+			{Name: "null", Datatype: TypeString, IsNull: true},
+			{Name: "ds", Datatype: TypeDataSet, Value: &DataSet{
+				Columns: []string{"c1", "c2"},
+				Types:   []uint32{TypeInt32, TypeString},
+				Rows:    [][]any{{uint32(1), "a"}, {uint32(2), "b"}},
+			}},
+			{Name: "tpl", Datatype: TypeTemplate, Value: &Template{
+				Version:     "v1",
+				TemplateRef: "motor",
+				Metrics:     []*Metric{{Name: "rpm", Datatype: TypeDouble, Value: 3.5}},
+			}},
+		},
+	}
+	b, err := Marshal(p)
+	require.NoError(t, err)
+	r, err := Unmarshal(b)
+	require.NoError(t, err)
+	require.Equal(t, p, r)
+	_, hasSeq, err := PeekSeq(b)
+	require.NoError(t, err)
+	require.False(t, hasSeq)
+}
+
+func TestPayloadErr(t *testing.T) {
+	_, err := Unmarshal([]byte{0x12, 0x09, 0x0a})
+	require.EqualError(t, err, "invalid sparkplug payload: unexpected EOF")
+	_, err = Marshal(&Payload{Metrics: []*Metric{{Name: "a", Value: 1}}})
+	require.EqualError(t, err, "metric a: unsupported value type int")
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"fmt"
+	"strings"
+)
+
+const Namespace = "spBv1.0"
+
+// Message types of the Sparkplug B topic
+const (
+	NBIRTH = "NBIRTH"
+	NDEATH = "NDEATH"
+	DBIRTH = "DBIRTH"
+	DDEATH = "DDEATH"
+	NDATA  = "NDATA"
+	DDATA  = "DDATA"
+	NCMD   = "NCMD"
+	DCMD   = "DCMD"
+)
+
+// Topic is the parsed topic in the form of spBv1.0/{group_id}/{message_type}/{edge_node_id}[/{device_id}]
+type Topic struct {
+	GroupId     string
+	MessageType string
+	EdgeNodeId  string
+	DeviceId    string
+}
+
+func ParseTopic(topic string) (*Topic, error) {
+	parts := strings.Split(topic, "/")
+	if len(parts) < 4 || len(parts) > 5 || parts[0] != Namespace {
+		return nil, fmt.Errorf("invalid sparkplug topic %s", topic)
+	}
+	t := &Topic{
+		GroupId:     parts[1],
+		MessageType: parts[2],
+		EdgeNodeId:  parts[3],
+	}
+	if len(parts) == 5 {
+		t.DeviceId = parts[4]
+	}
+	switch t.MessageType {
+	case NBIRTH, NDEATH, NDATA, NCMD:
+		if t.DeviceId != "" {
+			return nil, fmt.Errorf("invalid sparkplug topic %s: %s must not have a device id", topic, t.MessageType)
+		}
+	case DBIRTH, DDEATH, DDATA, DCMD:
+		if t.DeviceId == "" {
+			return nil, fmt.Errorf("invalid sparkplug topic %s: %s requires a device id", topic, t.MessageType)
+		}
+	default:
+		return nil, fmt.Errorf("invalid sparkplug topic %s: unknown message type %s", topic, t.MessageType)
+	}
+	return t, nil
+}
+
+// NodeKey identifies the edge node which owns the alias and the sequence number
+func (t *Topic) NodeKey() string {
+	return t.GroupId + "/" + t.EdgeNodeId
+}
+
+// IsCommand returns true for NCMD and DCMD which do not carry a sequence number
+func (t *Topic) IsCommand() bool {
+	return t.MessageType == NCMD || t.MessageType == DCMD
+}
+
+func (t *Topic) String() string {
+	s := Namespace + "/" + t.GroupId + "/" + t.MessageType + "/" + t.EdgeNodeId
+	if t.DeviceId != "" {
+		s += "/" + t.DeviceId
+	}
+	return s
+}
+
+// RebirthRequest builds the NCMD topic and payload to ask the edge node to republish its birth certificates
+func RebirthRequest(t *Topic, now int64) (string, []byte, error) {
+	nt := &Topic{GroupId: t.GroupId, MessageType: NCMD, EdgeNodeId: t.EdgeNodeId}
+	b, err := Marshal(&Payload{
+		Timestamp: uint64(now),
+		Metrics: []*Metric{{
+			Name:      "Node Control/Rebirth",
+			Timestamp: uint64(now),
+			Datatype:  TypeBoolean,
+			Value:     true,
+		}},
+	})
+	return nt.String(), b, err
+}
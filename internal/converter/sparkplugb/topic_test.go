@@ -0,0 +1,83 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestParseTopic(t *testing.T) {
+	tests := []struct {
+		topic string
+		t     *Topic
+		err   string
+	}{
+		{
+			topic: "spBv1.0/g1/NBIRTH/e1",
+			t:     &Topic{GroupId: "g1", MessageType: NBIRTH, EdgeNodeId: "e1"},
+		},
+		{
+			topic: "spBv1.0/g1/DDATA/e1/d1",
+			t:     &Topic{GroupId: "g1", MessageType: DDATA, EdgeNodeId: "e1", DeviceId: "d1"},
+		},
+		{
+			topic: "spAv1.0/g1/NDATA/e1",
+			err:   "invalid sparkplug topic spAv1.0/g1/NDATA/e1",
+		},
+		{
+			topic: "spBv1.0/STATE/host1",
+			err:   "invalid sparkplug topic spBv1.0/STATE/host1",
+		},
+		{
+			topic: "spBv1.0/g1/NDATA/e1/d1",
+			err:   "invalid sparkplug topic spBv1.0/g1/NDATA/e1/d1: NDATA must not have a device id",
+		},
+		{
+			topic: "spBv1.0/g1/DCMD/e1",
+			err:   "invalid sparkplug topic spBv1.0/g1/DCMD/e1: DCMD requires a device id",
+		},
+		{
+			topic: "spBv1.0/g1/NOPE/e1",
+			err:   "invalid sparkplug topic spBv1.0/g1/NOPE/e1: unknown message type NOPE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			r, err := ParseTopic(tt.topic)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.t, r)
+			require.Equal(t, tt.topic, r.String())
+		})
+	}
+}
+
+func TestRebirthRequest(t *testing.T) {
+	tpc, b, err := RebirthRequest(&Topic{GroupId: "g1", MessageType: DDATA, EdgeNodeId: "e1", DeviceId: "d1"}, 100)
+	require.NoError(t, err)
+	require.Equal(t, "spBv1.0/g1/NCMD/e1", tpc)
+	p, err := Unmarshal(b)
+	require.NoError(t, err)
+	require.False(t, p.HasSeq)
+	require.Equal(t, []*Metric{{Name: "Node Control/Rebirth", Timestamp: 100, Datatype: TypeBoolean, Value: true}}, p.Metrics)
+	// boolean is encoded in field 14
+	require.Contains(t, string(b), string(protowire.AppendTag(nil, 14, protowire.VarintType)))
+}
@@ -0,0 +1,305 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// toValue converts the wire value to the eKuiper value by the sparkplug data type
+func toValue(datatype uint32, v any) (any, error) {
+	switch datatype {
+	case TypeInt8, TypeInt16, TypeInt32:
+		i, ok := v.(uint32)
+		if !ok {
+			return nil, fmt.Errorf("expect int value for type %d but got %T", datatype, v)
+		}
+		switch datatype {
+		case TypeInt8:
+			return int64(int8(i)), nil
+		case TypeInt16:
+			return int64(int16(i)), nil
+		default:
+			return int64(int32(i)), nil
+		}
+	case TypeUInt8, TypeUInt16, TypeUInt32:
+		// some implementations write small unsigned values to the long value
+		switch i := v.(type) {
+		case uint32:
+			return int64(i), nil
+		case uint64:
+			return int64(i), nil
+		}
+		return nil, fmt.Errorf("expect int value for type %d but got %T", datatype, v)
+	case TypeInt64, TypeUInt64, TypeDateTime:
+		var l uint64
+		switch i := v.(type) {
+		case uint64:
+			l = i
+		case uint32:
+			l = uint64(i)
+		default:
+			return nil, fmt.Errorf("expect long value for type %d but got %T", datatype, v)
+		}
+		switch datatype {
+		case TypeInt64:
+			return int64(l), nil
+		case TypeUInt64:
+			if l > math.MaxInt64 {
+				return float64(l), nil
+			}
+			return int64(l), nil
+		default:
+			return time.UnixMilli(int64(l)).UTC(), nil
+		}
+	case TypeFloat, TypeDouble:
+		switch f := v.(type) {
+		case float32:
+			return float64(f), nil
+		case float64:
+			return f, nil
+		}
+		return nil, fmt.Errorf("expect float value for type %d but got %T", datatype, v)
+	case TypeBoolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("expect boolean value but got %T", v)
+	case TypeString, TypeText, TypeUUID:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("expect string value for type %d but got %T", datatype, v)
+	case TypeBytes, TypeFile:
+		if b, ok := v.([]byte); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("expect bytes value for type %d but got %T", datatype, v)
+	case TypeDataSet:
+		ds, ok := v.(*DataSet)
+		if !ok {
+			return nil, fmt.Errorf("expect dataset value but got %T", v)
+		}
+		return dataSetValue(ds)
+	}
+	if datatype >= TypeInt8Array && datatype <= TypeDateTimeArray {
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("expect bytes value for array type %d but got %T", datatype, v)
+		}
+		return arrayValue(datatype, b)
+	}
+	// Unknown type, convert by the wire type
+	switch vv := v.(type) {
+	case uint32:
+		return int64(vv), nil
+	case uint64:
+		return int64(vv), nil
+	case float32:
+		return float64(vv), nil
+	}
+	return v, nil
+}
+
+func dataSetValue(ds *DataSet) ([]any, error) {
+	result := make([]any, 0, len(ds.Rows))
+	for i, row := range ds.Rows {
+		if len(row) != len(ds.Columns) {
+			return nil, fmt.Errorf("dataset row %d has %d elements but there are %d columns", i, len(row), len(ds.Columns))
+		}
+		m := make(map[string]any, len(row))
+		for j, e := range row {
+			var t uint32
+			if j < len(ds.Types) {
+				t = ds.Types[j]
+			}
+			v, err := toValue(t, e)
+			if err != nil {
+				return nil, fmt.Errorf("dataset column %s: %v", ds.Columns[j], err)
+			}
+			m[ds.Columns[j]] = v
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// arrayValue decodes the array types which are encoded as little endian bytes
+func arrayValue(datatype uint32, b []byte) ([]any, error) {
+	var size int
+	switch datatype {
+	case TypeInt8Array, TypeUInt8Array:
+		size = 1
+	case TypeInt16Array, TypeUInt16Array:
+		size = 2
+	case TypeInt32Array, TypeUInt32Array, TypeFloatArray:
+		size = 4
+	case TypeInt64Array, TypeUInt64Array, TypeDoubleArray, TypeDateTimeArray:
+		size = 8
+	case TypeBooleanArray:
+		if len(b) < 4 {
+			return nil, fmt.Errorf("invalid boolean array")
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		b = b[4:]
+		if len(b)*8 < n {
+			return nil, fmt.Errorf("invalid boolean array: %d bits for %d elements", len(b)*8, n)
+		}
+		result := make([]any, n)
+		for i := 0; i < n; i++ {
+			result[i] = b[i/8]&(0x80>>(i%8)) != 0
+		}
+		return result, nil
+	case TypeStringArray:
+		s := strings.TrimSuffix(string(b), "\x00")
+		if s == "" {
+			return []any{}, nil
+		}
+		parts := strings.Split(s, "\x00")
+		result := make([]any, len(parts))
+		for i, p := range parts {
+			result[i] = p
+		}
+		return result, nil
+	}
+	if len(b)%size != 0 {
+		return nil, fmt.Errorf("invalid array of type %d: length %d is not a multiple of %d", datatype, len(b), size)
+	}
+	result := make([]any, len(b)/size)
+	for i := range result {
+		e := b[i*size : (i+1)*size]
+		switch datatype {
+		case TypeInt8Array:
+			result[i] = int64(int8(e[0]))
+		case TypeUInt8Array:
+			result[i] = int64(e[0])
+		case TypeInt16Array:
+			result[i] = int64(int16(binary.LittleEndian.Uint16(e)))
+		case TypeUInt16Array:
+			result[i] = int64(binary.LittleEndian.Uint16(e))
+		case TypeInt32Array:
+			result[i] = int64(int32(binary.LittleEndian.Uint32(e)))
+		case TypeUInt32Array:
+			result[i] = int64(binary.LittleEndian.Uint32(e))
+		case TypeFloatArray:
+			result[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(e)))
+		case TypeInt64Array, TypeUInt64Array:
+			result[i] = int64(binary.LittleEndian.Uint64(e))
+		case TypeDoubleArray:
+			result[i] = math.Float64frombits(binary.LittleEndian.Uint64(e))
+		case TypeDateTimeArray:
+			result[i] = time.UnixMilli(int64(binary.LittleEndian.Uint64(e))).UTC()
+		}
+	}
+	return result, nil
+}
+
+// fromValue infers the sparkplug data type of the eKuiper value and converts it to the wire value
+func fromValue(v any) (uint32, any, error) {
+	switch vv := v.(type) {
+	case nil:
+		return TypeUnknown, nil, nil
+	case bool:
+		return TypeBoolean, vv, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		i, err := cast.ToInt64(vv, cast.STRICT)
+		if err != nil {
+			return 0, nil, err
+		}
+		return TypeInt64, uint64(i), nil
+	case float32:
+		return TypeFloat, vv, nil
+	case float64:
+		return TypeDouble, vv, nil
+	case string:
+		return TypeString, vv, nil
+	case []byte:
+		return TypeBytes, vv, nil
+	case time.Time:
+		return TypeDateTime, uint64(vv.UnixMilli()), nil
+	case []any:
+		return fromArray(vv)
+	}
+	return 0, nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+// fromArray encodes a list as the array type decided by its first element
+func fromArray(l []any) (uint32, any, error) {
+	if len(l) == 0 {
+		return TypeStringArray, []byte{}, nil
+	}
+	switch l[0].(type) {
+	case bool:
+		b := make([]byte, 4+(len(l)+7)/8)
+		binary.LittleEndian.PutUint32(b, uint32(len(l)))
+		for i, e := range l {
+			v, err := cast.ToBool(e, cast.STRICT)
+			if err != nil {
+				return 0, nil, err
+			}
+			if v {
+				b[4+i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		return TypeBooleanArray, b, nil
+	case string:
+		var sb strings.Builder
+		for _, e := range l {
+			s, err := cast.ToString(e, cast.STRICT)
+			if err != nil {
+				return 0, nil, err
+			}
+			sb.WriteString(s)
+			sb.WriteByte(0)
+		}
+		return TypeStringArray, []byte(sb.String()), nil
+	case float32, float64:
+		b := make([]byte, 8*len(l))
+		for i, e := range l {
+			f, err := cast.ToFloat64(e, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return 0, nil, err
+			}
+			binary.LittleEndian.PutUint64(b[i*8:], math.Float64bits(f))
+		}
+		return TypeDoubleArray, b, nil
+	case time.Time:
+		b := make([]byte, 8*len(l))
+		for i, e := range l {
+			t, ok := e.(time.Time)
+			if !ok {
+				return 0, nil, fmt.Errorf("array element %d is %T but expect datetime", i, e)
+			}
+			binary.LittleEndian.PutUint64(b[i*8:], uint64(t.UnixMilli()))
+		}
+		return TypeDateTimeArray, b, nil
+	default:
+		b := make([]byte, 8*len(l))
+		for i, e := range l {
+			v, err := cast.ToInt64(e, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return 0, nil, err
+			}
+			binary.LittleEndian.PutUint64(b[i*8:], uint64(v))
+		}
+		return TypeInt64Array, b, nil
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sparkplugb
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		datatype uint32
+		v        any
+		r        any
+		err      string
+	}{
+		{name: "int8", datatype: TypeInt8, v: uint32(0xff), r: int64(-1)},
+		{name: "int16", datatype: TypeInt16, v: uint32(0xfffe), r: int64(-2)},
+		{name: "int32", datatype: TypeInt32, v: uint32(0xfffffffd), r: int64(-3)},
+		{name: "uint32", datatype: TypeUInt32, v: uint32(0xfffffffd), r: int64(4294967293)},
+		{name: "uint16 as long", datatype: TypeUInt16, v: uint64(7), r: int64(7)},
+		{name: "int64", datatype: TypeInt64, v: uint64(math.MaxUint64), r: int64(-1)},
+		{name: "uint64", datatype: TypeUInt64, v: uint64(math.MaxUint64), r: float64(math.MaxUint64)},
+		{name: "datetime", datatype: TypeDateTime, v: uint64(1000), r: time.UnixMilli(1000).UTC()},
+		{name: "float", datatype: TypeFloat, v: float32(1.5), r: 1.5},
+		{name: "text", datatype: TypeText, v: "t", r: "t"},
+		{name: "unknown", datatype: TypeUnknown, v: uint32(1), r: int64(1)},
+		{name: "int8 array", datatype: TypeInt8Array, v: []byte{0xff, 0x01}, r: []any{int64(-1), int64(1)}},
+		{name: "uint16 array", datatype: TypeUInt16Array, v: []byte{0x01, 0x01}, r: []any{int64(257)}},
+		{name: "int32 array", datatype: TypeInt32Array, v: []byte{0xff, 0xff, 0xff, 0xff}, r: []any{int64(-1)}},
+		{name: "double array", datatype: TypeDoubleArray, v: []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}, r: []any{1.5}},
+		{name: "float array", datatype: TypeFloatArray, v: []byte{0, 0, 0xc0, 0x3f}, r: []any{1.5}},
+		{name: "boolean array", datatype: TypeBooleanArray, v: []byte{3, 0, 0, 0, 0xa0}, r: []any{true, false, true}},
+		{name: "string array", datatype: TypeStringArray, v: []byte("ab\x00c\x00"), r: []any{"ab", "c"}},
+		{name: "dataset", datatype: TypeDataSet, v: &DataSet{Columns: []string{"a"}, Types: []uint32{TypeInt8}, Rows: [][]any{{uint32(0xff)}}}, r: []any{map[string]any{"a": int64(-1)}}},
+		{name: "wrong type", datatype: TypeBoolean, v: "t", err: "expect boolean value but got string"},
+		{name: "invalid array", datatype: TypeInt32Array, v: []byte{1}, err: "invalid array of type 24: length 1 is not a multiple of 4"},
+		{name: "invalid dataset", datatype: TypeDataSet, v: &DataSet{Columns: []string{"a"}, Rows: [][]any{{}}}, err: "dataset row 0 has 0 elements but there are 1 columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := toValue(tt.datatype, tt.v)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.r, r)
+		})
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	tm := time.UnixMilli(1000).UTC()
+	tests := []struct {
+		name string
+		v    any
+		r    any
+	}{
+		{name: "int", v: 5, r: int64(5)},
+		{name: "float", v: 1.5},
+		{name: "bool", v: true},
+		{name: "string", v: "s"},
+		{name: "bytes", v: []byte{1}},
+		{name: "datetime", v: tm},
+		{name: "bool array", v: []any{true, false, true}},
+		{name: "string array", v: []any{"a", "b"}},
+		{name: "double array", v: []any{1.5, 2.0}},
+		{name: "int array", v: []any{int64(1), 2}, r: []any{int64(1), int64(2)}},
+		{name: "datetime array", v: []any{tm}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dt, wv, err := fromValue(tt.v)
+			require.NoError(t, err)
+			r, err := toValue(dt, wv)
+			require.NoError(t, err)
+			exp := tt.r
+			if exp == nil {
+				exp = tt.v
+			}
+			require.Equal(t, exp, r)
+		})
+	}
+	_, _, err := fromValue(map[int]int{})
+	require.EqualError(t, err, "unsupported value type map[int]int")
+}
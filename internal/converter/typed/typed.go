@@ -0,0 +1,192 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package typed converts the generic values decoded by binary formats such as msgpack and cbor into the types of
+// the stream schema. The fields not defined in the schema are dropped like the json converter.
+package typed
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// Convert converts the decoded map or array of maps by the schema. If the schema is nil, all fields are kept.
+// The result is map[string]any or []map[string]any.
+func Convert(v any, schema map[string]*ast.JsonStreamField) (any, error) {
+	switch t := v.(type) {
+	case map[string]any, map[any]any:
+		m, _ := toStringMap(t)
+		return convertMap(m, schema)
+	case []any:
+		ms := make([]map[string]any, len(t))
+		for i, item := range t {
+			m, ok := toStringMap(item)
+			if !ok {
+				return nil, fmt.Errorf("only map[string]interface{} and []map[string]interface{} is supported")
+			}
+			r, err := convertMap(m, schema)
+			if err != nil {
+				return nil, err
+			}
+			ms[i] = r
+		}
+		return ms, nil
+	default:
+		return nil, fmt.Errorf("only map[string]interface{} and []map[string]interface{} is supported")
+	}
+}
+
+// ConvertField converts a top level field by the schema
+func ConvertField(key string, v any, schema map[string]*ast.JsonStreamField) (any, error) {
+	var field *ast.JsonStreamField
+	if schema != nil {
+		field = schema[key]
+	}
+	return convertValue(key, v, field)
+}
+
+func convertMap(m map[string]any, schema map[string]*ast.JsonStreamField) (map[string]any, error) {
+	r := make(map[string]any, len(m))
+	for k, v := range m {
+		var field *ast.JsonStreamField
+		if schema != nil {
+			f, ok := schema[k]
+			if !ok {
+				continue
+			}
+			field = f
+		}
+		cv, err := convertValue(k, v, field)
+		if err != nil {
+			return nil, err
+		}
+		r[k] = cv
+	}
+	return r, nil
+}
+
+func convertValue(key string, v any, field *ast.JsonStreamField) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if field == nil {
+		return normalize(v), nil
+	}
+	var (
+		r   any
+		err error
+	)
+	switch field.Type {
+	case "bigint":
+		r, err = cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+	case "float":
+		r, err = cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	case "string":
+		switch v.(type) {
+		case map[string]any, map[any]any, []any:
+			err = fmt.Errorf("cannot convert to string")
+		default:
+			r, err = cast.ToString(v, cast.CONVERT_ALL)
+		}
+	case "boolean":
+		if i, ok := v.(int64); ok {
+			r = i != 0
+		} else {
+			r, err = cast.ToBool(v, cast.CONVERT_ALL)
+		}
+	case "bytea":
+		r, err = cast.ToByteA(v, cast.CONVERT_ALL)
+	case "datetime":
+		switch t := v.(type) {
+		case time.Time:
+			r = t
+		case string, int64, uint64, float64:
+			// keep the raw value to be parsed by the timestamp format like json
+			r = normalize(t)
+		default:
+			err = fmt.Errorf("cannot convert to datetime")
+		}
+	case "struct":
+		m, ok := toStringMap(v)
+		if !ok {
+			err = fmt.Errorf("cannot convert to struct")
+			break
+		}
+		r, err = convertMap(m, field.Properties)
+	case "array":
+		a, ok := v.([]any)
+		if !ok {
+			err = fmt.Errorf("cannot convert to array")
+			break
+		}
+		items := make([]any, len(a))
+		for i, item := range a {
+			items[i], err = convertValue(key, item, field.Items)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r = items
+	default:
+		r = normalize(v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v has wrong type:%T, expect:%v", key, v, field.Type)
+	}
+	return r, nil
+}
+
+// normalize converts the maps with any keys to map[string]any recursively and unsigned integers to int64
+func normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any, map[any]any:
+		m, _ := toStringMap(t)
+		r := make(map[string]any, len(m))
+		for k, vv := range m {
+			r[k] = normalize(vv)
+		}
+		return r
+	case []any:
+		r := make([]any, len(t))
+		for i, vv := range t {
+			r[i] = normalize(vv)
+		}
+		return r
+	case uint64:
+		if t <= 1<<63-1 {
+			return int64(t)
+		}
+		return float64(t)
+	default:
+		return v
+	}
+}
+
+func toStringMap(v any) (map[string]any, bool) {
+	switch t := v.(type) {
+	case map[string]any:
+		return t, true
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, vv := range t {
+			m[cast.ToStringAlways(k)] = vv
+		}
+		return m, true
+	default:
+		return nil, false
+	}
+}
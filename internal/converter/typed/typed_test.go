@@ -0,0 +1,109 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestConvert(t *testing.T) {
+	schema := map[string]*ast.JsonStreamField{
+		"id":    {Type: "bigint"},
+		"temp":  {Type: "float"},
+		"name":  {Type: "string"},
+		"ok":    {Type: "boolean"},
+		"raw":   {Type: "bytea"},
+		"ts":    {Type: "datetime"},
+		"tags":  {Type: "array", Items: &ast.JsonStreamField{Type: "bigint"}},
+		"loc":   {Type: "struct", Properties: map[string]*ast.JsonStreamField{"lat": {Type: "float"}}},
+		"extra": nil,
+	}
+	now := time.UnixMilli(1000)
+	tests := []struct {
+		name   string
+		v      any
+		schema map[string]*ast.JsonStreamField
+		r      any
+		err    string
+	}{
+		{
+			name:   "typed",
+			v:      map[any]any{"id": uint64(3), "temp": int64(20), "name": int64(1), "ok": int64(1), "raw": []byte{1}, "ts": now, "tags": []any{1.0, int64(2)}, "loc": map[any]any{"lat": int64(1), "lng": 2.0}, "extra": map[any]any{int64(1): "a"}, "other": 1},
+			schema: schema,
+			r:      map[string]any{"id": int64(3), "temp": 20.0, "name": "1", "ok": true, "raw": []byte{1}, "ts": now, "tags": []any{int64(1), int64(2)}, "loc": map[string]any{"lat": 1.0}, "extra": map[string]any{"1": "a"}},
+		},
+		{
+			name: "schemaless",
+			v:    []any{map[any]any{"a": uint64(1), "b": nil}, map[string]any{"c": []any{map[any]any{"d": true}}}},
+			r:    []map[string]any{{"a": int64(1), "b": nil}, {"c": []any{map[string]any{"d": true}}}},
+		},
+		{
+			name:   "null",
+			v:      map[any]any{"id": nil},
+			schema: schema,
+			r:      map[string]any{"id": nil},
+		},
+		{
+			name:   "wrong type",
+			v:      map[any]any{"id": "a"},
+			schema: schema,
+			err:    "id has wrong type:string, expect:bigint",
+		},
+		{
+			name:   "wrong struct",
+			v:      map[any]any{"loc": []any{}},
+			schema: schema,
+			err:    "loc has wrong type:[]interface {}, expect:struct",
+		},
+		{
+			name:   "wrong array item",
+			v:      map[any]any{"tags": []any{"a"}},
+			schema: schema,
+			err:    "tags has wrong type:string, expect:bigint",
+		},
+		{
+			name: "not map",
+			v:    int64(1),
+			err:  "only map[string]interface{} and []map[string]interface{} is supported",
+		},
+		{
+			name: "not map item",
+			v:    []any{int64(1)},
+			err:  "only map[string]interface{} and []map[string]interface{} is supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Convert(tt.v, tt.schema)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.r, r)
+		})
+	}
+	r, err := ConvertField("temp", int64(1), schema)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, r)
+	r, err = ConvertField("temp", uint64(1), nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r)
+}
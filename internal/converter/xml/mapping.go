@@ -0,0 +1,642 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+const (
+	// LayoutCompact maps attributes to "@name" keys, text to "#text" and repeated elements to arrays
+	LayoutCompact = "compact"
+
+	attrPrefix  = "@"
+	textKey     = "#text"
+	defaultRoot = "root"
+	defaultItem = "item"
+)
+
+// XPathField maps one field to the nodes selected by the path.
+type XPathField struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Array always decodes the field as an array even if only one node matches
+	Array bool `json:"array"`
+}
+
+type xmlConf struct {
+	XPaths        []XPathField      `json:"xpaths"`
+	Namespaces    map[string]string `json:"namespaces"`
+	ArrayElements []string          `json:"arrayElements"`
+	Layout        string            `json:"xmlLayout"`
+	Root          string            `json:"xmlRoot"`
+	Item          string            `json:"xmlItem"`
+}
+
+type decodePath struct {
+	XPathField
+	path etree.Path
+	// the terminal step which selects an attribute or the text instead of the element
+	attr    string
+	attrURI string
+	text    bool
+}
+
+type encodePath struct {
+	name    string
+	parents []string
+	tag     string
+	attr    string
+	text    bool
+}
+
+// NewConverter creates the xml converter. Without xpaths or layout, the generic legacy mapping is used.
+func NewConverter(logicalSchema map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+	c := &xmlConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, err
+	}
+	if len(c.XPaths) == 0 && c.Layout == "" {
+		return NewXMLConverter(), nil
+	}
+	if c.Layout != "" && c.Layout != LayoutCompact {
+		return nil, fmt.Errorf("unsupported xmlLayout %s, only %s is supported", c.Layout, LayoutCompact)
+	}
+	if c.Root == "" {
+		c.Root = defaultRoot
+	}
+	if c.Item == "" {
+		c.Item = defaultItem
+	}
+	x := &XMLConverter{
+		conf:       c,
+		arrays:     make(map[string]struct{}, len(c.ArrayElements)),
+		prefixes:   make(map[string]string, len(c.Namespaces)),
+		decodePath: make([]*decodePath, 0, len(c.XPaths)),
+	}
+	if logicalSchema != nil {
+		x.schema = &ast.JsonStreamField{Type: "struct", Properties: logicalSchema}
+	}
+	for _, name := range c.ArrayElements {
+		x.arrays[name] = struct{}{}
+	}
+	for prefix, uri := range c.Namespaces {
+		x.prefixes[uri] = prefix
+	}
+	for _, f := range c.XPaths {
+		if f.Name == "" || f.Path == "" {
+			return nil, fmt.Errorf("xpath must have name and path: %v", f)
+		}
+		p, err := x.compileDecodePath(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpath %s for field %s: %v", f.Path, f.Name, err)
+		}
+		x.decodePath = append(x.decodePath, p)
+	}
+	// The paths may be only used for decoding, so the encoding error is only reported when encoding
+	if len(c.XPaths) > 0 {
+		x.encodeRoot, x.encodePath, x.encodeErr = x.compileEncodePaths(c.XPaths)
+	}
+	return x, nil
+}
+
+// splitPath splits the path by the slashes outside the filters
+func splitPath(path string) []string {
+	var (
+		segs  []string
+		depth int
+		quote rune
+		start int
+	)
+	for i, r := range path {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == '/' && depth == 0:
+			segs = append(segs, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(segs, path[start:])
+}
+
+func (x *XMLConverter) compileDecodePath(f XPathField) (*decodePath, error) {
+	p := &decodePath{XPathField: f}
+	segs := splitPath(f.Path)
+	last := segs[len(segs)-1]
+	switch {
+	case strings.HasPrefix(last, attrPrefix):
+		prefix, local := splitKey(last[1:])
+		if prefix != "" {
+			if uri, ok := x.conf.Namespaces[prefix]; ok {
+				p.attrURI = uri
+			} else {
+				local = last[1:]
+			}
+		}
+		p.attr = local
+		segs = segs[:len(segs)-1]
+	case last == "text()":
+		p.text = true
+		segs = segs[:len(segs)-1]
+	}
+	for i, seg := range segs {
+		selector, filters := seg, ""
+		if j := strings.IndexByte(seg, '['); j >= 0 {
+			selector, filters = seg[:j], seg[j:]
+		}
+		prefix, local := splitKey(selector)
+		if uri, ok := x.conf.Namespaces[prefix]; ok && prefix != "" {
+			segs[i] = fmt.Sprintf("%s[namespace-uri()='%s']%s", local, uri, filters)
+		}
+	}
+	path := strings.Join(segs, "/")
+	if path == "" {
+		path = "."
+	}
+	cp, err := etree.CompilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	p.path = cp
+	return p, nil
+}
+
+func (x *XMLConverter) compileEncodePaths(fields []XPathField) (string, []*encodePath, error) {
+	root := ""
+	result := make([]*encodePath, 0, len(fields))
+	for _, f := range fields {
+		if !strings.HasPrefix(f.Path, "/") || strings.HasPrefix(f.Path, "//") {
+			return "", nil, fmt.Errorf("xpath %s of field %s must be an absolute path to encode", f.Path, f.Name)
+		}
+		segs := strings.Split(f.Path[1:], "/")
+		p := &encodePath{name: f.Name}
+		last := segs[len(segs)-1]
+		switch {
+		case strings.HasPrefix(last, attrPrefix):
+			p.attr = last[1:]
+			segs = segs[:len(segs)-1]
+		case last == "text()":
+			p.text = true
+			segs = segs[:len(segs)-1]
+		}
+		if len(segs) == 0 {
+			return "", nil, fmt.Errorf("xpath %s of field %s has no element to encode", f.Path, f.Name)
+		}
+		for _, seg := range segs {
+			if seg == "" {
+				return "", nil, fmt.Errorf("xpath %s of field %s has empty element to encode", f.Path, f.Name)
+			}
+		}
+		for _, seg := range append(segs, p.attr) {
+			if err := x.validateName(seg); err != nil {
+				return "", nil, fmt.Errorf("xpath %s of field %s cannot be encoded: %v", f.Path, f.Name, err)
+			}
+		}
+		if root == "" {
+			root = segs[0]
+		} else if root != segs[0] {
+			return "", nil, fmt.Errorf("all xpaths must have the same root element to encode, got %s and %s", root, segs[0])
+		}
+		segs = segs[1:]
+		if !p.text && p.attr == "" && len(segs) > 0 {
+			p.tag = segs[len(segs)-1]
+			segs = segs[:len(segs)-1]
+		}
+		p.parents = segs
+		result = append(result, p)
+	}
+	return root, result, nil
+}
+
+// validateName only allows plain names with declared prefixes in the encoding paths
+func (x *XMLConverter) validateName(name string) error {
+	if strings.ContainsAny(name, "[]()*@.'\" ") {
+		return fmt.Errorf("%s is not a plain element or attribute name", name)
+	}
+	prefix, _ := splitKey(name)
+	if prefix != "" && prefix != "xml" {
+		if _, ok := x.conf.Namespaces[prefix]; !ok {
+			return fmt.Errorf("namespace prefix %s is not declared", prefix)
+		}
+	}
+	return nil
+}
+
+func (x *XMLConverter) decodeMapping(b []byte) (any, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(b); err != nil {
+		return nil, err
+	}
+	if len(x.decodePath) > 0 {
+		return x.decodeXPath(doc)
+	}
+	root := doc.Root()
+	if root == nil {
+		return nil, fmt.Errorf("no root element in xml data")
+	}
+	v, err := x.elementValue(root, x.schema)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := v.(map[string]any); ok {
+		return m, nil
+	}
+	return map[string]any{textKey: v}, nil
+}
+
+func (x *XMLConverter) decodeXPath(doc *etree.Document) (map[string]any, error) {
+	result := make(map[string]any, len(x.decodePath))
+	for _, p := range x.decodePath {
+		fs := property(x.schema, p.Name)
+		isArray := p.Array || fs != nil && fs.Type == "array"
+		itemSchema := fs
+		if isArray && fs != nil {
+			itemSchema = fs.Items
+		}
+		values := make([]any, 0)
+		for _, e := range doc.FindElementsPath(p.path) {
+			var (
+				v   any
+				err error
+			)
+			switch {
+			case p.attr != "":
+				a := p.findAttr(e)
+				if a == nil {
+					continue
+				}
+				v, err = parseText(a.Value, itemSchema)
+			case p.text:
+				v, err = parseText(e.Text(), itemSchema)
+			default:
+				v, err = x.elementValue(e, itemSchema)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("decode field %s error: %v", p.Name, err)
+			}
+			values = append(values, v)
+			if !isArray {
+				break
+			}
+		}
+		if isArray {
+			result[p.Name] = values
+		} else if len(values) > 0 {
+			result[p.Name] = values[0]
+		}
+	}
+	return result, nil
+}
+
+func (p *decodePath) findAttr(e *etree.Element) *etree.Attr {
+	for i := range e.Attr {
+		a := &e.Attr[i]
+		if p.attrURI != "" {
+			if a.Key == p.attr && a.NamespaceURI() == p.attrURI {
+				return a
+			}
+		} else if a.FullKey() == p.attr {
+			return a
+		}
+	}
+	return nil
+}
+
+// elementValue decodes the element in compact layout. A leaf element without attributes decodes to its value.
+func (x *XMLConverter) elementValue(e *etree.Element, schema *ast.JsonStreamField) (any, error) {
+	children := e.ChildElements()
+	attrs := make([]etree.Attr, 0, len(e.Attr))
+	for _, a := range e.Attr {
+		if a.Space != "xmlns" && a.Key != "xmlns" {
+			attrs = append(attrs, a)
+		}
+	}
+	if len(attrs) == 0 && len(children) == 0 {
+		return parseText(e.Text(), schema)
+	}
+	result := make(map[string]any, len(attrs)+len(children))
+	for _, a := range attrs {
+		key := attrPrefix + x.attrName(a)
+		fs := property(schema, key)
+		if fs == nil {
+			fs = property(schema, attrPrefix+a.Key)
+		}
+		v, err := parseText(a.Value, fs)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	if text := strings.TrimSpace(e.Text()); text != "" {
+		v, err := parseText(text, property(schema, textKey))
+		if err != nil {
+			return nil, err
+		}
+		result[textKey] = v
+	}
+	var names []string
+	groups := make(map[string][]*etree.Element)
+	for _, c := range children {
+		name := x.elementName(c)
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], c)
+	}
+	for _, name := range names {
+		group := groups[name]
+		fs := property(schema, name)
+		if fs == nil && name != group[0].Tag {
+			fs = property(schema, group[0].Tag)
+		}
+		if x.isArrayElement(name, group[0].Tag) || len(group) > 1 || fs != nil && fs.Type == "array" {
+			var itemSchema *ast.JsonStreamField
+			if fs != nil && fs.Type == "array" {
+				itemSchema = fs.Items
+			}
+			arr := make([]any, 0, len(group))
+			for _, c := range group {
+				v, err := x.elementValue(c, itemSchema)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+			result[name] = arr
+		} else {
+			v, err := x.elementValue(group[0], fs)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = v
+		}
+	}
+	return result, nil
+}
+
+func (x *XMLConverter) isArrayElement(name, local string) bool {
+	if _, ok := x.arrays[name]; ok {
+		return true
+	}
+	_, ok := x.arrays[local]
+	return ok
+}
+
+// elementName uses the configured prefix of the element namespace, otherwise the local name
+func (x *XMLConverter) elementName(e *etree.Element) string {
+	if uri := e.NamespaceURI(); uri != "" {
+		if prefix, ok := x.prefixes[uri]; ok {
+			return prefix + ":" + e.Tag
+		}
+	}
+	return e.Tag
+}
+
+func (x *XMLConverter) attrName(a etree.Attr) string {
+	if uri := a.NamespaceURI(); uri != "" {
+		if prefix, ok := x.prefixes[uri]; ok {
+			return prefix + ":" + a.Key
+		}
+	}
+	return a.Key
+}
+
+func property(schema *ast.JsonStreamField, name string) *ast.JsonStreamField {
+	if schema == nil || schema.Properties == nil {
+		return nil
+	}
+	return schema.Properties[name]
+}
+
+// parseText converts the text by the schema type, or infers the type if no schema
+func parseText(text string, schema *ast.JsonStreamField) (any, error) {
+	if schema == nil {
+		return inferText(text), nil
+	}
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" && schema.Type != "string" {
+		return nil, nil
+	}
+	switch schema.Type {
+	case "bigint":
+		v, err := strconv.ParseInt(trimmed, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %s to bigint", trimmed)
+		}
+		return v, nil
+	case "float":
+		v, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %s to float", trimmed)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %s to boolean", trimmed)
+		}
+		return v, nil
+	case "bytea":
+		v, err := base64.StdEncoding.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %s to bytea", trimmed)
+		}
+		return v, nil
+	case "string", "datetime":
+		return text, nil
+	default:
+		return inferText(text), nil
+	}
+}
+
+func inferText(text string) any {
+	trimmed := strings.TrimSpace(text)
+	if iv, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return iv
+	}
+	if fv, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		return fv
+	}
+	switch trimmed {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return text
+}
+
+func (x *XMLConverter) encodeMapping(d any) ([]byte, error) {
+	doc := etree.NewDocument()
+	if len(x.encodePath) > 0 || x.encodeErr != nil {
+		if x.encodeErr != nil {
+			return nil, x.encodeErr
+		}
+		m, ok := d.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("xpaths can only encode a single map, got %T, try sendSingle", d)
+		}
+		root := doc.CreateElement(x.encodeRoot)
+		x.declareNamespaces(root)
+		for _, p := range x.encodePath {
+			v, ok := m[p.name]
+			if !ok || v == nil {
+				continue
+			}
+			parent := root
+			for _, tag := range p.parents {
+				parent = childElement(parent, tag)
+			}
+			switch {
+			case p.attr != "":
+				parent.CreateAttr(p.attr, formatValue(v))
+			case p.text:
+				parent.SetText(formatValue(v))
+			case p.tag == "":
+				writeElement(parent, v)
+			default:
+				for _, item := range toSlice(v) {
+					writeElement(parent.CreateElement(p.tag), item)
+				}
+			}
+		}
+	} else {
+		root := doc.CreateElement(x.conf.Root)
+		x.declareNamespaces(root)
+		switch dt := d.(type) {
+		case map[string]any:
+			writeElement(root, dt)
+		case []map[string]any, []any:
+			for _, item := range toSlice(dt) {
+				writeElement(root.CreateElement(x.conf.Item), item)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported type %T to encode", d)
+		}
+	}
+	return doc.WriteToBytes()
+}
+
+func (x *XMLConverter) declareNamespaces(root *etree.Element) {
+	prefixes := make([]string, 0, len(x.conf.Namespaces))
+	for prefix := range x.conf.Namespaces {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			root.CreateAttr("xmlns", x.conf.Namespaces[prefix])
+		} else {
+			root.CreateAttr("xmlns:"+prefix, x.conf.Namespaces[prefix])
+		}
+	}
+}
+
+// childElement reuses the last child with the tag, so that the fields of the same parent are grouped
+func childElement(parent *etree.Element, tag string) *etree.Element {
+	children := parent.ChildElements()
+	for i := len(children) - 1; i >= 0; i-- {
+		if children[i].FullTag() == tag {
+			return children[i]
+		}
+	}
+	return parent.CreateElement(tag)
+}
+
+// writeElement writes the value in compact layout. The map keys are sorted to make the output stable.
+func writeElement(e *etree.Element, v any) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		e.SetText(formatValue(v))
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		val := m[k]
+		if val == nil {
+			continue
+		}
+		switch {
+		case k == textKey:
+			e.SetText(formatValue(val))
+		case strings.HasPrefix(k, attrPrefix):
+			e.CreateAttr(k[1:], formatValue(val))
+		default:
+			for _, item := range toSlice(val) {
+				writeElement(e.CreateElement(k), item)
+			}
+		}
+	}
+}
+
+func toSlice(v any) []any {
+	switch vt := v.(type) {
+	case []any:
+		return vt
+	case []map[string]any:
+		result := make([]any, len(vt))
+		for i, m := range vt {
+			result[i] = m
+		}
+		return result
+	default:
+		return []any{v}
+	}
+}
+
+func formatValue(v any) string {
+	switch vt := v.(type) {
+	case string:
+		return vt
+	case []byte:
+		return base64.StdEncoding.EncodeToString(vt)
+	case time.Time:
+		return vt.Format(time.RFC3339Nano)
+	default:
+		return cast.ToStringAlways(v)
+	}
+}
+
+func wrapErr(err error) error {
+	if err != nil {
+		return errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+const orderXml = `<?xml version="1.0"?>
+<m:order xmlns:m="urn:mes:order" xmlns:q="urn:mes:quality" m:id="A01" line="L2">
+  <m:station>S1</m:station>
+  <m:item sku="p1"><m:qty>3</m:qty></m:item>
+  <m:item sku="p2"><m:qty>5</m:qty></m:item>
+  <q:check q:result="ok">0.98</q:check>
+  <m:note>urgent</m:note>
+</m:order>`
+
+func TestDecodeXPath(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	schema := map[string]*ast.JsonStreamField{
+		"station": {Type: "string"},
+		"qty":     {Type: "array", Items: &ast.JsonStreamField{Type: "float"}},
+		"score":   {Type: "float"},
+		"note":    {Type: "array", Items: &ast.JsonStreamField{Type: "string"}},
+	}
+	c, err := NewConverter(schema, map[string]any{
+		"namespaces": map[string]any{"o": "urn:mes:order", "qa": "urn:mes:quality"},
+		"xpaths": []any{
+			map[string]any{"name": "id", "path": "/o:order/@o:id"},
+			map[string]any{"name": "line", "path": "/o:order/@line"},
+			map[string]any{"name": "station", "path": "/o:order/o:station"},
+			map[string]any{"name": "skus", "path": "//o:item/@sku", "array": true},
+			map[string]any{"name": "qty", "path": "//o:item/o:qty/text()"},
+			map[string]any{"name": "firstSku", "path": "//o:item/@sku"},
+			map[string]any{"name": "p2", "path": "//o:item[@sku='p2']"},
+			map[string]any{"name": "score", "path": "/o:order/qa:check/text()"},
+			map[string]any{"name": "result", "path": "/o:order/qa:check/@qa:result"},
+			map[string]any{"name": "note", "path": "/o:order/o:note"},
+			map[string]any{"name": "missing", "path": "/o:order/o:none"},
+			map[string]any{"name": "empty", "path": "/o:order/o:none", "array": true},
+		},
+	})
+	require.NoError(t, err)
+	got, err := c.Decode(ctx, []byte(orderXml))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"id":       "A01",
+		"line":     "L2",
+		"station":  "S1",
+		"skus":     []any{"p1", "p2"},
+		"qty":      []any{float64(3), float64(5)},
+		"firstSku": "p1",
+		"p2":       map[string]any{"@sku": "p2", "o:qty": int64(5)},
+		"score":    0.98,
+		"result":   "ok",
+		"note":     []any{"urgent"},
+		"empty":    []any{},
+	}, got)
+
+	_, err = c.Decode(ctx, []byte(`<o:order xmlns:o="urn:mes:order"><o:station>S1</o:station>`))
+	require.Error(t, err)
+}
+
+func TestDecodeCompact(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	c, err := NewConverter(map[string]*ast.JsonStreamField{
+		"temperature": {Type: "float"},
+		"tags":        {Type: "array", Items: &ast.JsonStreamField{Type: "string"}},
+	}, map[string]any{
+		"xmlLayout":     "compact",
+		"namespaces":    map[string]any{"d": "urn:device"},
+		"arrayElements": []any{"sensor"},
+	})
+	require.NoError(t, err)
+	got, err := c.Decode(ctx, []byte(`<data xmlns="urn:device" id="7"><temperature>21</temperature><tags>a</tags><sensor type="t">1.5</sensor><status ok="true"/><v>1</v><v>2</v></data>`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"@id":           int64(7),
+		"d:temperature": float64(21),
+		"d:tags":        []any{"a"},
+		"d:sensor":      []any{map[string]any{"@type": "t", "#text": 1.5}},
+		"d:status":      map[string]any{"@ok": true},
+		"d:v":           []any{int64(1), int64(2)},
+	}, got)
+	// without namespace configured, the local names are used and the schema applies
+	c, err = NewConverter(map[string]*ast.JsonStreamField{
+		"temperature": {Type: "float"},
+		"tags":        {Type: "array", Items: &ast.JsonStreamField{Type: "string"}},
+	}, map[string]any{"xmlLayout": "compact"})
+	require.NoError(t, err)
+	got, err = c.Decode(ctx, []byte(`<data xmlns="urn:device"><temperature>21</temperature><tags>1</tags></data>`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"temperature": float64(21), "tags": []any{"1"}}, got)
+
+	_, err = c.Decode(ctx, []byte(`<data><temperature>hot</temperature></data>`))
+	require.EqualError(t, err, "cannot convert hot to float")
+}
+
+func TestEncodeXPath(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	c, err := NewConverter(nil, map[string]any{
+		"namespaces": map[string]string{"m": "urn:mes:order"},
+		"xpaths": []map[string]any{
+			{"name": "id", "path": "/m:order/@id"},
+			{"name": "station", "path": "/m:order/m:header/m:station"},
+			{"name": "shift", "path": "/m:order/m:header/@shift"},
+			{"name": "items", "path": "/m:order/m:items/m:item"},
+			{"name": "remark", "path": "/m:order/m:remark/text()"},
+			{"name": "absent", "path": "/m:order/m:absent"},
+		},
+	})
+	require.NoError(t, err)
+	got, err := c.Encode(ctx, map[string]any{
+		"id":      "A01",
+		"station": "S1",
+		"shift":   int64(2),
+		"items": []any{
+			map[string]any{"@sku": "p1", "qty": 3},
+			map[string]any{"@sku": "p2", "qty": 5.5},
+		},
+		"remark": "a<b",
+	})
+	require.NoError(t, err)
+	require.Equal(t, `<m:order xmlns:m="urn:mes:order" id="A01"><m:header shift="2"><m:station>S1</m:station></m:header><m:items><m:item sku="p1"><qty>3</qty></m:item><m:item sku="p2"><qty>5.5</qty></m:item></m:items><m:remark>a&lt;b</m:remark></m:order>`, string(got))
+
+	_, err = c.Encode(ctx, []map[string]any{{"id": "A01"}})
+	require.EqualError(t, err, "xpaths can only encode a single map, got []map[string]interface {}, try sendSingle")
+
+	// Paths with filters can decode but cannot encode
+	c, err = NewConverter(nil, map[string]any{
+		"xpaths": []any{map[string]any{"name": "id", "path": "//item[@sku='p1']"}},
+	})
+	require.NoError(t, err)
+	_, err = c.Encode(ctx, map[string]any{"id": 1})
+	require.EqualError(t, err, "xpath //item[@sku='p1'] of field id must be an absolute path to encode")
+}
+
+func TestEncodeCompact(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	c, err := NewConverter(nil, map[string]any{
+		"xmlLayout":  "compact",
+		"xmlRoot":    "d:data",
+		"namespaces": map[string]any{"d": "urn:device"},
+	})
+	require.NoError(t, err)
+	data := map[string]any{"@id": int64(7), "d:temperature": 21.5, "d:sensor": []any{map[string]any{"@type": "t", "#text": 1.5}, map[string]any{"@type": "h", "#text": 60}}, "d:empty": nil}
+	got, err := c.Encode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, `<d:data xmlns:d="urn:device" id="7"><d:sensor type="t">1.5</d:sensor><d:sensor type="h">60</d:sensor><d:temperature>21.5</d:temperature></d:data>`, string(got))
+	// round trip
+	c, err = NewConverter(nil, map[string]any{"xmlLayout": "compact", "namespaces": map[string]any{"d": "urn:device"}})
+	require.NoError(t, err)
+	decoded, err := c.Decode(ctx, got)
+	require.NoError(t, err)
+	delete(data, "d:empty")
+	require.Equal(t, map[string]any{"@id": int64(7), "d:temperature": 21.5, "d:sensor": []any{map[string]any{"@type": "t", "#text": 1.5}, map[string]any{"@type": "h", "#text": int64(60)}}}, decoded)
+
+	got, err = c.Encode(ctx, []map[string]any{{"a": 1}, {"a": 2}})
+	require.NoError(t, err)
+	require.Equal(t, `<root xmlns:d="urn:device"><item><a>1</a></item><item><a>2</a></item></root>`, string(got))
+}
+
+func TestNewConverterErr(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "layout",
+			props: map[string]any{"xmlLayout": "flat"},
+			err:   "unsupported xmlLayout flat, only compact is supported",
+		},
+		{
+			name:  "no path",
+			props: map[string]any{"xpaths": []any{map[string]any{"name": "a"}}},
+			err:   "xpath must have name and path: {a  false}",
+		},
+		{
+			name:  "invalid path",
+			props: map[string]any{"xpaths": []any{map[string]any{"name": "a", "path": "/a[@b"}}},
+			err:   "invalid xpath /a[@b for field a: etree: path has invalid filter [brackets].",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(nil, tt.props)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+	// no mapping props keeps the legacy converter
+	c, err := NewConverter(nil, map[string]any{"delimiter": ","})
+	require.NoError(t, err)
+	require.Equal(t, NewXMLConverter(), c)
+}
@@ -24,13 +24,28 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
 const xmlValue = "@value"
 
-type XMLConverter struct{}
+// XMLConverter maps xml generically by default. If xpaths or layout is configured, the mapping is used instead.
+type XMLConverter struct {
+	conf       *xmlConf
+	schema     *ast.JsonStreamField
+	arrays     map[string]struct{}
+	prefixes   map[string]string
+	decodePath []*decodePath
+	encodeRoot string
+	encodePath []*encodePath
+	encodeErr  error
+}
 
 func (x *XMLConverter) Encode(ctx api.StreamContext, d any) ([]byte, error) {
+	if x.conf != nil {
+		b, err := x.encodeMapping(d)
+		return b, wrapErr(err)
+	}
 	return covertToEncodingXml(d)
 }
 
@@ -150,6 +165,10 @@ func (x *XMLConverter) Decode(ctx api.StreamContext, b []byte) (got any, err err
 			err = fmt.Errorf("xml decode panic: %v", r)
 		}
 	}()
+	if x.conf != nil {
+		got, err = x.decodeMapping(b)
+		return got, wrapErr(err)
+	}
 	return decodeXML(b)
 }
 
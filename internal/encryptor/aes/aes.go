@@ -50,6 +50,27 @@ func GetEncryptor(props map[string]any) (message.Encryptor, error) {
 	}
 }
 
+// GetDecryptor returns the decryptor which reverts the result of the encryptor with the same props
+func GetDecryptor(props map[string]any) (message.Decryptor, error) {
+	if conf.Config == nil || conf.Config.AesKey == nil {
+		return nil, fmt.Errorf("AES key is not defined")
+	}
+	key := conf.Config.AesKey
+	cc := &c{Mode: "cfb"}
+	err := cast.MapToStruct(props, cc)
+	if err != nil {
+		return nil, err
+	}
+	switch cc.Mode {
+	case "cfb":
+		return NewStreamDecrypter(key)
+	case "gcm":
+		return NewGcmDecrypter(key, cc)
+	default:
+		return nil, fmt.Errorf("unsupported AES decryption mode: %s", cc.Mode)
+	}
+}
+
 func GetEncryptWriter(output io.Writer, props map[string]any) (io.Writer, error) {
 	if conf.Config == nil || conf.Config.AesKey == nil {
 		return nil, fmt.Errorf("AES key is not defined")
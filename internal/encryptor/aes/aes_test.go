@@ -214,3 +214,62 @@ func decryptWithProps(mode string, key []byte, secret []byte, iv []byte, aad []b
 		return nil, fmt.Errorf("Unknown mode: %s", mode)
 	}
 }
+
+func TestDecryptor(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	iv := base64.StdEncoding.EncodeToString([]byte("0123456789ab"))
+	aad := base64.StdEncoding.EncodeToString([]byte("helloworld"))
+	if conf.Config == nil {
+		conf.Config = &conf.KuiperConf{}
+	}
+	conf.Config.AesKey = key
+	pt := []byte(`{"temperature":23.5,"humidity":66}`)
+	tests := []struct {
+		name  string
+		props map[string]any
+	}{
+		{
+			name:  "cfb",
+			props: map[string]any{"mode": "cfb"},
+		},
+		{
+			name:  "gcm",
+			props: map[string]any{"mode": "gcm"},
+		},
+		{
+			name:  "gcm with iv",
+			props: map[string]any{"mode": "gcm", "iv": iv},
+		},
+		{
+			name:  "gcm with aad",
+			props: map[string]any{"mode": "gcm", "iv": iv, "aad": aad},
+		},
+		{
+			name:  "gcm with aad and tag size",
+			props: map[string]any{"mode": "gcm", "iv": iv, "aad": aad, "tagsize": 32},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := GetEncryptor(tt.props)
+			assert.NoError(t, err)
+			dec, err := GetDecryptor(tt.props)
+			assert.NoError(t, err)
+			secret, err := enc.Encrypt(pt)
+			assert.NoError(t, err)
+			revert, err := dec.Decrypt(secret)
+			assert.NoError(t, err)
+			assert.Equal(t, pt, revert)
+		})
+	}
+	_, err := GetDecryptor(map[string]any{"mode": "gcm", "aad": aad})
+	assert.EqualError(t, err, "iv is required to decrypt with aad")
+	_, err = GetDecryptor(map[string]any{"mode": "ecb"})
+	assert.EqualError(t, err, "unsupported AES decryption mode: ecb")
+	dec, err := GetDecryptor(map[string]any{"mode": "gcm"})
+	assert.NoError(t, err)
+	_, err = dec.Decrypt([]byte("short"))
+	assert.EqualError(t, err, "ciphertext too short")
+	_, err = dec.Decrypt([]byte("0123456789abcdefghijklmnopqrstuvwxyz"))
+	assert.Error(t, err)
+}
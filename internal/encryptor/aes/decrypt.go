@@ -0,0 +1,117 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+)
+
+// StreamDecrypter decrypts the data in the format of iv + ciphertext which is produced by StreamEncrypter
+type StreamDecrypter struct {
+	block cipher.Block
+}
+
+func (a *StreamDecrypter) Decrypt(data []byte) ([]byte, error) {
+	if len(data) < aes.BlockSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	iv := data[:aes.BlockSize]
+	ciphertext := data[aes.BlockSize:]
+	plaintext := make([]byte, len(ciphertext))
+	stream := cipher.NewCFBDecrypter(a.block, iv)
+	stream.XORKeyStream(plaintext, ciphertext)
+	return plaintext, nil
+}
+
+func NewStreamDecrypter(key []byte) (*StreamDecrypter, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamDecrypter{block: block}, nil
+}
+
+// GcmDecrypter decrypts the data produced by GcmEncrypter.
+// Without aad, the data is nonce + ciphertext + tag.
+// With aad, the data is tag(may be padded to tagSize) + ciphertext and the nonce must be set as iv.
+type GcmDecrypter struct {
+	gcm           cipher.AEAD
+	constantNonce []byte
+	aad           []byte
+	tagSize       int
+}
+
+func (a *GcmDecrypter) Decrypt(data []byte) ([]byte, error) {
+	if a.aad == nil {
+		nonceSize := a.gcm.NonceSize()
+		if len(data) < nonceSize {
+			return nil, fmt.Errorf("ciphertext too short")
+		}
+		return a.gcm.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	}
+	overhead := a.gcm.Overhead()
+	tagLen := overhead
+	if a.tagSize > tagLen {
+		tagLen = a.tagSize
+	}
+	if len(data) < tagLen {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	sealed := make([]byte, 0, len(data)-tagLen+overhead)
+	sealed = append(sealed, data[tagLen:]...)
+	sealed = append(sealed, data[:overhead]...)
+	return a.gcm.Open(nil, a.constantNonce, sealed, a.aad)
+}
+
+func NewGcmDecrypter(key []byte, cc *c) (*GcmDecrypter, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	dec := &GcmDecrypter{
+		gcm: gcm,
+	}
+	if cc.Iv != "" {
+		iv, err := base64.StdEncoding.DecodeString(cc.Iv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IV setting")
+		}
+		if len(iv) != gcm.NonceSize() {
+			return nil, fmt.Errorf("invalid IV length")
+		}
+		dec.constantNonce = iv
+	}
+	if cc.Aad != "" {
+		aad, err := base64.StdEncoding.DecodeString(cc.Aad)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Aad setting")
+		}
+		if dec.constantNonce == nil {
+			return nil, fmt.Errorf("iv is required to decrypt with aad")
+		}
+		dec.aad = aad
+	}
+	if cc.TagSize > 16 {
+		dec.tagSize = cc.TagSize
+	}
+	return dec, nil
+}
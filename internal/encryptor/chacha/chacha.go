@@ -0,0 +1,89 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chacha implements ChaCha20-Poly1305 encryption. The data format is nonce + ciphertext + tag.
+package chacha
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+type c struct {
+	// Key is the base64 encoded 32 bytes key. If not set, use the AES key in the config
+	Key string `json:"key"`
+	Aad string `json:"aad"`
+}
+
+type Cipher struct {
+	aead cipher.AEAD
+	aad  []byte
+}
+
+func (a *Cipher) Encrypt(data []byte) ([]byte, error) {
+	nonce := make([]byte, a.aead.NonceSize(), a.aead.NonceSize()+len(data)+a.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return a.aead.Seal(nonce, nonce, data, a.aad), nil
+}
+
+func (a *Cipher) Decrypt(data []byte) ([]byte, error) {
+	nonceSize := a.aead.NonceSize()
+	if len(data) < nonceSize+a.aead.Overhead() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return a.aead.Open(nil, data[:nonceSize], data[nonceSize:], a.aad)
+}
+
+// NewCipher creates the cipher which can both encrypt and decrypt
+func NewCipher(props map[string]any) (*Cipher, error) {
+	cc := &c{}
+	if err := cast.MapToStruct(props, cc); err != nil {
+		return nil, err
+	}
+	var key []byte
+	if cc.Key != "" {
+		k, err := base64.StdEncoding.DecodeString(cc.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key setting")
+		}
+		key = k
+	} else if conf.Config != nil {
+		key = conf.Config.AesKey
+	}
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("chacha20poly1305 key must be %d bytes", chacha20poly1305.KeySize)
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	result := &Cipher{aead: aead}
+	if cc.Aad != "" {
+		aad, err := base64.StdEncoding.DecodeString(cc.Aad)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Aad setting")
+		}
+		result.aad = aad
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chacha
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+)
+
+func TestCipher(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	aad := base64.StdEncoding.EncodeToString([]byte("device1"))
+	pt := []byte(`{"temperature":23.5}`)
+	for _, props := range []map[string]any{
+		{"key": key},
+		{"key": key, "aad": aad},
+	} {
+		c, err := NewCipher(props)
+		require.NoError(t, err)
+		secret, err := c.Encrypt(pt)
+		require.NoError(t, err)
+		require.Len(t, secret, len(pt)+12+16)
+		revert, err := c.Decrypt(secret)
+		require.NoError(t, err)
+		require.Equal(t, pt, revert)
+	}
+	// aad mismatch
+	c1, err := NewCipher(map[string]any{"key": key, "aad": aad})
+	require.NoError(t, err)
+	c2, err := NewCipher(map[string]any{"key": key})
+	require.NoError(t, err)
+	secret, err := c1.Encrypt(pt)
+	require.NoError(t, err)
+	_, err = c2.Decrypt(secret)
+	require.Error(t, err)
+	_, err = c2.Decrypt([]byte("short"))
+	require.EqualError(t, err, "ciphertext too short")
+}
+
+func TestCipherKey(t *testing.T) {
+	_, err := NewCipher(map[string]any{"key": "notbase64!"})
+	require.EqualError(t, err, "invalid key setting")
+	_, err = NewCipher(map[string]any{"key": base64.StdEncoding.EncodeToString([]byte("short"))})
+	require.EqualError(t, err, "chacha20poly1305 key must be 32 bytes")
+	conf.Config = &conf.KuiperConf{AesKey: []byte("0123456789abcdef0123456789abcdef")}
+	defer func() {
+		conf.Config = nil
+	}()
+	_, err = NewCipher(nil)
+	require.NoError(t, err)
+}
@@ -19,13 +19,47 @@ import (
 	"io"
 
 	"github.com/lf-edge/ekuiper/v2/internal/encryptor/aes"
+	"github.com/lf-edge/ekuiper/v2/internal/encryptor/chacha"
+	"github.com/lf-edge/ekuiper/v2/internal/encryptor/envelope"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
+const (
+	AES              = "aes"
+	CHACHA20POLY1305 = "chacha20poly1305"
+	ENVELOPE         = "envelope"
+)
+
+type DecryptorInstantiator func(props map[string]any) (message.Decryptor, error)
+
+var decryptors = map[string]DecryptorInstantiator{
+	AES: aes.GetDecryptor,
+	CHACHA20POLY1305: func(props map[string]any) (message.Decryptor, error) {
+		return chacha.NewCipher(props)
+	},
+	ENVELOPE: func(props map[string]any) (message.Decryptor, error) {
+		return envelope.GetDecryptor(props)
+	},
+}
+
+// RegisterDecryptor registers a decryption algorithm which can be referred by the decryption property of sources
+func RegisterDecryptor(name string, ins DecryptorInstantiator) {
+	decryptors[name] = ins
+}
+
+func GetDecryptor(name string, decryptProps map[string]any) (message.Decryptor, error) {
+	if ins, ok := decryptors[name]; ok {
+		return ins(decryptProps)
+	}
+	return nil, fmt.Errorf("decryptor '%s' is not supported", name)
+}
+
 func GetEncryptor(name string, encryptProps map[string]any) (message.Encryptor, error) {
 	switch name {
-	case "aes":
+	case AES:
 		return aes.GetEncryptor(encryptProps)
+	case CHACHA20POLY1305:
+		return chacha.NewCipher(encryptProps)
 	default:
 		return nil, fmt.Errorf("encryptor '%s' is not supported", name)
 	}
@@ -33,7 +67,7 @@ func GetEncryptor(name string, encryptProps map[string]any) (message.Encryptor,
 
 func GetEncryptWriter(name string, output io.Writer) (io.Writer, error) {
 	// TODO support encryption props later
-	if name == "aes" {
+	if name == AES {
 		return aes.GetEncryptWriter(output, nil)
 	}
 	return nil, fmt.Errorf("unsupported encryptor: %s", name)
@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
 func TestGetEncryptor(t *testing.T) {
@@ -33,3 +34,20 @@ func TestGetEncryptor(t *testing.T) {
 	_, err = GetEncryptor("aes", map[string]any{"mode": "abc"})
 	assert.Error(t, err)
 }
+
+func TestGetDecryptor(t *testing.T) {
+	conf.InitConf()
+	_, err := GetDecryptor("aes", map[string]any{"mode": "gcm"})
+	assert.NoError(t, err)
+	_, err = GetDecryptor("chacha20poly1305", map[string]any{"key": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	assert.NoError(t, err)
+	_, err = GetDecryptor("envelope", map[string]any{"keys": map[string]any{"k1": "MDEyMzQ1Njc4OWFiY2RlZg=="}})
+	assert.NoError(t, err)
+	_, err = GetDecryptor("unknown", nil)
+	assert.EqualError(t, err, "decryptor 'unknown' is not supported")
+	RegisterDecryptor("mock", func(props map[string]any) (message.Decryptor, error) {
+		return nil, nil
+	})
+	_, err = GetDecryptor("mock", nil)
+	assert.NoError(t, err)
+}
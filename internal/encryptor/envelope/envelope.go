@@ -0,0 +1,145 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envelope implements the envelope decryption. Each message is encrypted by a random data key
+// and the data key is wrapped by a key encryption key(KEK). The KEK is looked up by the key id in the message metadata.
+//
+// The payload format is:
+//
+//	2 bytes big endian length n | wrapped data key (n bytes) | encrypted data
+//
+// The wrapped data key is nonce + AES-GCM ciphertext of the data key by the KEK.
+// The encrypted data is nonce + ciphertext + tag by the data key with the configured algorithm.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	AesGcm           = "aes-gcm"
+	ChaCha20Poly1305 = "chacha20poly1305"
+)
+
+type c struct {
+	// KeyIdField is the metadata key to find the key id
+	KeyIdField string `json:"keyIdField"`
+	// Keys map the key id to the base64 encoded KEK
+	Keys map[string]string `json:"keys"`
+	// Algorithm to decrypt the data by the data key
+	Algorithm string `json:"algorithm"`
+}
+
+type Decrypter struct {
+	keyIdField string
+	keks       map[string]cipher.AEAD
+	algorithm  string
+}
+
+func GetDecryptor(props map[string]any) (*Decrypter, error) {
+	cc := &c{KeyIdField: "keyId", Algorithm: AesGcm}
+	if err := cast.MapToStruct(props, cc); err != nil {
+		return nil, err
+	}
+	if len(cc.Keys) == 0 {
+		return nil, fmt.Errorf("keys are required for envelope decryption")
+	}
+	switch cc.Algorithm {
+	case AesGcm, ChaCha20Poly1305:
+	default:
+		return nil, fmt.Errorf("unsupported envelope data algorithm: %s", cc.Algorithm)
+	}
+	d := &Decrypter{
+		keyIdField: cc.KeyIdField,
+		keks:       make(map[string]cipher.AEAD, len(cc.Keys)),
+		algorithm:  cc.Algorithm,
+	}
+	for id, k := range cc.Keys {
+		key, err := base64.StdEncoding.DecodeString(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s setting", id)
+		}
+		aead, err := newGcm(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", id, err)
+		}
+		d.keks[id] = aead
+	}
+	return d, nil
+}
+
+func (d *Decrypter) Decrypt(_ []byte) ([]byte, error) {
+	return nil, fmt.Errorf("envelope decryption requires the key id in metadata %s", d.keyIdField)
+}
+
+func (d *Decrypter) DecryptWithMeta(data []byte, meta map[string]any) ([]byte, error) {
+	v, ok := meta[d.keyIdField]
+	if !ok {
+		return nil, fmt.Errorf("key id not found in metadata %s", d.keyIdField)
+	}
+	keyId, err := cast.ToString(v, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key id: %v", err)
+	}
+	kek, ok := d.keks[keyId]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %s", keyId)
+	}
+	if len(data) < 2 {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+n {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	dataKey, err := open(kek, data[2:2+n])
+	if err != nil {
+		return nil, fmt.Errorf("fail to unwrap data key: %v", err)
+	}
+	var dek cipher.AEAD
+	switch d.algorithm {
+	case ChaCha20Poly1305:
+		dek, err = chacha20poly1305.New(dataKey)
+	default:
+		dek, err = newGcm(dataKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %v", err)
+	}
+	return open(dek, data[2+n:])
+}
+
+func newGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+}
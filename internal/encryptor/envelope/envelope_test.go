@@ -0,0 +1,97 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func seal(t *testing.T, aead cipher.AEAD, data []byte) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	_, err := rand.Read(nonce)
+	require.NoError(t, err)
+	return aead.Seal(nonce, nonce, data, nil)
+}
+
+// envelopeEncrypt is what the devices do
+func envelopeEncrypt(t *testing.T, kek []byte, algorithm string, data []byte) []byte {
+	dataKey := make([]byte, 32)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+	kekAead, err := newGcm(kek)
+	require.NoError(t, err)
+	wrapped := seal(t, kekAead, dataKey)
+	var dek cipher.AEAD
+	if algorithm == ChaCha20Poly1305 {
+		dek, err = chacha20poly1305.New(dataKey)
+	} else {
+		dek, err = newGcm(dataKey)
+	}
+	require.NoError(t, err)
+	result := binary.BigEndian.AppendUint16(nil, uint16(len(wrapped)))
+	result = append(result, wrapped...)
+	return append(result, seal(t, dek, data)...)
+}
+
+func TestEnvelope(t *testing.T) {
+	kek1 := []byte("0123456789abcdef0123456789abcdef")
+	kek2 := []byte("fedcba9876543210")
+	keys := map[string]any{
+		"k1": base64.StdEncoding.EncodeToString(kek1),
+		"k2": base64.StdEncoding.EncodeToString(kek2),
+	}
+	pt := []byte(`{"temperature":23.5}`)
+	for _, alg := range []string{AesGcm, ChaCha20Poly1305} {
+		t.Run(alg, func(t *testing.T) {
+			d, err := GetDecryptor(map[string]any{"keys": keys, "algorithm": alg, "keyIdField": "kid"})
+			require.NoError(t, err)
+			r, err := d.DecryptWithMeta(envelopeEncrypt(t, kek1, alg, pt), map[string]any{"kid": "k1"})
+			require.NoError(t, err)
+			require.Equal(t, pt, r)
+			r, err = d.DecryptWithMeta(envelopeEncrypt(t, kek2, alg, pt), map[string]any{"kid": "k2"})
+			require.NoError(t, err)
+			require.Equal(t, pt, r)
+			// wrong key id
+			_, err = d.DecryptWithMeta(envelopeEncrypt(t, kek2, alg, pt), map[string]any{"kid": "k1"})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEnvelopeErr(t *testing.T) {
+	_, err := GetDecryptor(map[string]any{})
+	require.EqualError(t, err, "keys are required for envelope decryption")
+	_, err = GetDecryptor(map[string]any{"keys": map[string]any{"k1": "MTIz"}, "algorithm": "des"})
+	require.EqualError(t, err, "unsupported envelope data algorithm: des")
+	_, err = GetDecryptor(map[string]any{"keys": map[string]any{"k1": "MTIz"}})
+	require.EqualError(t, err, "invalid key k1: crypto/aes: invalid key size 3")
+	d, err := GetDecryptor(map[string]any{"keys": map[string]any{"k1": "MDEyMzQ1Njc4OWFiY2RlZg=="}})
+	require.NoError(t, err)
+	_, err = d.Decrypt([]byte("abc"))
+	require.EqualError(t, err, "envelope decryption requires the key id in metadata keyId")
+	_, err = d.DecryptWithMeta([]byte("abc"), map[string]any{})
+	require.EqualError(t, err, "key id not found in metadata keyId")
+	_, err = d.DecryptWithMeta([]byte("abc"), map[string]any{"keyId": "k2"})
+	require.EqualError(t, err, "unknown key id k2")
+	_, err = d.DecryptWithMeta([]byte{0, 10, 1}, map[string]any{"keyId": "k1"})
+	require.EqualError(t, err, "ciphertext too short")
+}
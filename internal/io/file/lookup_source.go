@@ -0,0 +1,347 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+// reloadDelay debounces the burst of events when the file is being written
+const reloadDelay = 200 * time.Millisecond
+
+type LookupConfig struct {
+	FileName string `json:"datasource"`
+	FileType string `json:"fileType"`
+	Path     string `json:"path"`
+	// The primary key of the table, it is always indexed
+	Key string `json:"key"`
+	// The secondary keys to be indexed
+	Indexes []string `json:"indexes"`
+}
+
+// LookupSource loads the whole file into memory as a lookup table.
+// The rows are indexed by the configured keys and the file is reloaded once it is changed.
+type LookupSource struct {
+	file   string
+	config *LookupConfig
+	props  map[string]any
+	keys   []string
+
+	mu   sync.RWMutex
+	data *lookupData
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+type lookupData struct {
+	rows []map[string]any
+	// index key -> the string form of the value -> row indexes
+	indexes map[string]map[string][]int
+}
+
+func (s *LookupSource) Provision(ctx api.StreamContext, props map[string]any) error {
+	cfg := &LookupConfig{
+		FileType: string(JSON_TYPE),
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	switch FileType(cfg.FileType) {
+	case JSON_TYPE, CSV_TYPE, LINES_TYPE, PARQUET_TYPE:
+	default:
+		return fmt.Errorf("invalid fileType %s for lookup table, must be json, csv, lines or parquet", cfg.FileType)
+	}
+	if FileType(cfg.FileType) != JSON_TYPE {
+		if _, ok := modules.GetFileStreamReader(ctx, cfg.FileType); !ok {
+			return fmt.Errorf("file type %s reader is not found", cfg.FileType)
+		}
+	}
+	if cfg.FileName == "" {
+		return errors.New("missing property datasource")
+	}
+	if cfg.Path == "" {
+		return errors.New("missing property Path")
+	}
+	if !filepath.IsAbs(cfg.Path) {
+		p, err := conf.GetLoc(cfg.Path)
+		if err != nil {
+			return fmt.Errorf("invalid path %s", cfg.Path)
+		}
+		cfg.Path = p
+	}
+	s.file = filepath.Join(cfg.Path, cfg.FileName)
+	fi, err := os.Stat(s.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file %s not exist", s.file)
+		}
+		return err
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("lookup table file %s must not be a directory", s.file)
+	}
+	s.keys = nil
+	seen := make(map[string]struct{})
+	for _, k := range append([]string{cfg.Key}, cfg.Indexes...) {
+		if _, ok := seen[k]; ok || k == "" {
+			continue
+		}
+		seen[k] = struct{}{}
+		s.keys = append(s.keys, k)
+	}
+	s.config = cfg
+	s.props = props
+	return nil
+}
+
+// Connect loads the file and watches the changes of it
+func (s *LookupSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	data, err := s.load(ctx)
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.mu.Lock()
+	s.data = data
+	s.mu.Unlock()
+	ctx.GetLogger().Infof("lookup table file %s is loaded with %d rows", s.file, len(data.rows))
+	// Watch the directory because the file may be replaced instead of written in place
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	err = watcher.Add(filepath.Dir(s.file))
+	if err != nil {
+		_ = watcher.Close()
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.watcher = watcher
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := infra.SafeRun(func() error {
+			s.watch(ctx)
+			return nil
+		})
+		if err != nil {
+			ctx.GetLogger().Error(err)
+		}
+	}()
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *LookupSource) watch(ctx api.StreamContext) {
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != s.file || !(event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+				continue
+			}
+			ctx.GetLogger().Debugf("lookup table file %s receive event %s", s.file, event.Op)
+			timer.Reset(reloadDelay)
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			ctx.GetLogger().Errorf("lookup table file watch error: %v", err)
+		case <-timer.C:
+			data, err := s.load(ctx)
+			if err != nil {
+				ctx.GetLogger().Errorf("reload lookup table file %s error, keep the previous data: %v", s.file, err)
+				continue
+			}
+			s.mu.Lock()
+			s.data = data
+			s.mu.Unlock()
+			ctx.GetLogger().Infof("lookup table file %s is reloaded with %d rows", s.file, len(data.rows))
+		}
+	}
+}
+
+func (s *LookupSource) load(ctx api.StreamContext) (*lookupData, error) {
+	var rows []map[string]any
+	if FileType(s.config.FileType) == JSON_TYPE {
+		content, err := os.ReadFile(s.file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(content, &rows); err != nil {
+			return nil, fmt.Errorf("invalid json array in %s: %v", s.file, err)
+		}
+	} else {
+		reader, ok := modules.GetFileStreamReader(ctx, s.config.FileType)
+		if !ok {
+			return nil, fmt.Errorf("file type %s reader is not found", s.config.FileType)
+		}
+		if err := reader.Provision(ctx, s.props); err != nil {
+			return nil, err
+		}
+		f, err := os.Open(s.file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		maxSize := 1 << 20
+		if info, err := f.Stat(); err == nil && info.Size() > int64(maxSize) {
+			maxSize = int(info.Size())
+		}
+		if err := reader.Bind(ctx, f, maxSize); err != nil {
+			return nil, err
+		}
+		defer reader.Close(ctx)
+		for ln := 1; ; ln++ {
+			v, err := reader.Read(ctx)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("read %s error: %v", s.file, err)
+			}
+			switch t := v.(type) {
+			case map[string]any:
+				rows = append(rows, t)
+			case []byte:
+				// Each line of the lines file is a json object
+				if len(bytes.TrimSpace(t)) == 0 {
+					continue
+				}
+				m := make(map[string]any)
+				if err := json.Unmarshal(t, &m); err != nil {
+					return nil, fmt.Errorf("invalid json in line %d of %s: %v", ln, s.file, err)
+				}
+				rows = append(rows, m)
+			default:
+				return nil, fmt.Errorf("unsupported row type %T in %s", v, s.file)
+			}
+		}
+	}
+	data := &lookupData{
+		rows:    rows,
+		indexes: make(map[string]map[string][]int, len(s.keys)),
+	}
+	for _, k := range s.keys {
+		index := make(map[string][]int)
+		for i, row := range rows {
+			if v, ok := row[k]; ok && v != nil {
+				sv := cast.ToStringAlways(v)
+				index[sv] = append(index[sv], i)
+			}
+		}
+		data.indexes[k] = index
+	}
+	return data, nil
+}
+
+// Lookup finds the rows whose values of the keys are equal to the values.
+// The values are compared by their string form so that a number can match the text column such as csv.
+func (s *LookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string, values []any) ([]map[string]any, error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("the length of keys %d and values %d mismatch", len(keys), len(values))
+	}
+	s.mu.RLock()
+	data := s.data
+	s.mu.RUnlock()
+	if data == nil {
+		return nil, fmt.Errorf("lookup table file %s is not loaded", s.file)
+	}
+	svs := make([]string, len(values))
+	for i, v := range values {
+		svs[i] = cast.ToStringAlways(v)
+	}
+	var candidates []int
+	indexed := false
+	for i, k := range keys {
+		if index, ok := data.indexes[k]; ok {
+			candidates = index[svs[i]]
+			indexed = true
+			break
+		}
+	}
+	if !indexed {
+		ctx.GetLogger().Debugf("lookup table file %s has no index on %v, scan all rows", s.file, keys)
+		candidates = make([]int, len(data.rows))
+		for i := range data.rows {
+			candidates[i] = i
+		}
+	}
+	var result []map[string]any
+	for _, i := range candidates {
+		row := data.rows[i]
+		match := true
+		for j, k := range keys {
+			if v, ok := row[k]; !ok || v == nil || cast.ToStringAlways(v) != svs[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			r := make(map[string]any, len(row))
+			for k, v := range row {
+				r[k] = v
+			}
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (s *LookupSource) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("lookup table file %s is closing", s.file)
+	if s.watcher != nil {
+		close(s.done)
+		err := s.watcher.Close()
+		s.wg.Wait()
+		s.watcher = nil
+		return err
+	}
+	return nil
+}
+
+func GetLookupSource() api.Source {
+	return &LookupSource{}
+}
+
+var _ api.LookupSource = &LookupSource{}
@@ -0,0 +1,171 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestLookupProvisionErr(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.csv"), []byte("id\n1\n"), 0o644))
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid file type",
+			props: map[string]any{"path": dir, "datasource": "a.csv", "fileType": "xml"},
+			err:   "invalid fileType xml for lookup table, must be json, csv, lines or parquet",
+		},
+		{
+			name:  "missing datasource",
+			props: map[string]any{"path": dir, "fileType": "csv"},
+			err:   "missing property datasource",
+		},
+		{
+			name:  "file not exist",
+			props: map[string]any{"path": dir, "datasource": "b.csv", "fileType": "csv"},
+			err:   "file " + filepath.Join(dir, "b.csv") + " not exist",
+		},
+		{
+			name:  "directory",
+			props: map[string]any{"path": filepath.Dir(dir), "datasource": filepath.Base(dir), "fileType": "csv"},
+			err:   "lookup table file " + dir + " must not be a directory",
+		},
+	}
+	ctx := mockContext.NewMockContext("1", "2")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GetLookupSource().Provision(ctx, tt.props)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestLookupFileTypes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets.csv"), []byte("id,site,name\n1,s1,a\n2,s1,b\n3,s2,c\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets.jsonl"), []byte("{\"id\":1,\"site\":\"s1\",\"name\":\"a\"}\n\n{\"id\":2,\"site\":\"s1\",\"name\":\"b\"}\n{\"id\":3,\"site\":\"s2\",\"name\":\"c\"}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets.json"), []byte(`[{"id":1,"site":"s1","name":"a"},{"id":2,"site":"s1","name":"b"},{"id":3,"site":"s2","name":"c"}]`), 0o644))
+	tests := []struct {
+		name   string
+		props  map[string]any
+		keys   []string
+		values []any
+		exp    []map[string]any
+	}{
+		{
+			name:   "csv primary key",
+			props:  map[string]any{"path": dir, "datasource": "assets.csv", "fileType": "csv", "hasHeader": true, "key": "id", "indexes": []string{"site"}},
+			keys:   []string{"id"},
+			values: []any{int64(2)},
+			exp:    []map[string]any{{"id": "2", "site": "s1", "name": "b"}},
+		},
+		{
+			name:   "csv secondary index",
+			props:  map[string]any{"path": dir, "datasource": "assets.csv", "fileType": "csv", "hasHeader": true, "key": "id", "indexes": []string{"site"}},
+			keys:   []string{"site"},
+			values: []any{"s1"},
+			exp:    []map[string]any{{"id": "1", "site": "s1", "name": "a"}, {"id": "2", "site": "s1", "name": "b"}},
+		},
+		{
+			name:   "csv index and filter",
+			props:  map[string]any{"path": dir, "datasource": "assets.csv", "fileType": "csv", "hasHeader": true, "key": "id", "indexes": []string{"site"}},
+			keys:   []string{"name", "site"},
+			values: []any{"b", "s1"},
+			exp:    []map[string]any{{"id": "2", "site": "s1", "name": "b"}},
+		},
+		{
+			name:   "lines scan",
+			props:  map[string]any{"path": dir, "datasource": "assets.jsonl", "fileType": "lines"},
+			keys:   []string{"name"},
+			values: []any{"c"},
+			exp:    []map[string]any{{"id": float64(3), "site": "s2", "name": "c"}},
+		},
+		{
+			name:   "json not found",
+			props:  map[string]any{"path": dir, "datasource": "assets.json", "fileType": "json", "key": "id"},
+			keys:   []string{"id"},
+			values: []any{int64(4)},
+			exp:    nil,
+		},
+		{
+			name:   "json number key",
+			props:  map[string]any{"path": dir, "datasource": "assets.json", "key": "id"},
+			keys:   []string{"id"},
+			values: []any{int64(3)},
+			exp:    []map[string]any{{"id": float64(3), "site": "s2", "name": "c"}},
+		},
+	}
+	ctx := mockContext.NewMockContext("1", "2")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := GetLookupSource().(*LookupSource)
+			require.NoError(t, ls.Provision(ctx, tt.props))
+			require.NoError(t, ls.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer ls.Close(ctx)
+			got, err := ls.Lookup(ctx, nil, tt.keys, tt.values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.exp, got)
+		})
+	}
+}
+
+func TestLookupReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "assets.csv")
+	require.NoError(t, os.WriteFile(file, []byte("id,name\n1,a\n"), 0o644))
+	ctx := mockContext.NewMockContext("1", "2")
+	ls := GetLookupSource().(*LookupSource)
+	require.NoError(t, ls.Provision(ctx, map[string]any{"path": dir, "datasource": "assets.csv", "fileType": "csv", "hasHeader": true, "key": "id"}))
+	require.NoError(t, ls.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer ls.Close(ctx)
+	got, err := ls.Lookup(ctx, nil, []string{"id"}, []any{"1"})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"id": "1", "name": "a"}}, got)
+
+	// Replace the file
+	tmp := filepath.Join(dir, "assets.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("id,name\n1,b\n2,c\n"), 0o644))
+	require.NoError(t, os.Rename(tmp, file))
+	assert.Eventually(t, func() bool {
+		got, err := ls.Lookup(ctx, nil, []string{"id"}, []any{"2"})
+		return err == nil && len(got) == 1 && got[0]["name"] == "c"
+	}, 5*time.Second, 50*time.Millisecond)
+	got, err = ls.Lookup(ctx, nil, []string{"id"}, []any{"1"})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"id": "1", "name": "b"}}, got)
+
+	// Invalid content keeps the previous data
+	require.NoError(t, os.WriteFile(file, []byte("id,name\n1,\"d\n"), 0o644))
+	time.Sleep(2 * reloadDelay)
+	got, err = ls.Lookup(ctx, nil, []string{"id"}, []any{"2"})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"id": "2", "name": "c"}}, got)
+}
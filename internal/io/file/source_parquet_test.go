@@ -22,9 +22,11 @@ import (
 	"testing"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
 
 	_ "github.com/lf-edge/ekuiper/v2/internal/io/file/reader"
 	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
@@ -58,3 +60,24 @@ func TestParquet(t *testing.T) {
 		// do nothing
 	})
 }
+
+func TestLookupParquet(t *testing.T) {
+	path, err := os.Getwd()
+	require.NoError(t, err)
+	path = filepath.Join(path, "test")
+	ctx := mockContext.NewMockContext("1", "2")
+	ls := GetLookupSource().(*LookupSource)
+	require.NoError(t, ls.Provision(ctx, map[string]any{
+		"fileType":   "parquet",
+		"path":       path,
+		"datasource": "parquet/simple.parq",
+		"key":        "id",
+	}))
+	require.NoError(t, ls.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer ls.Close(ctx)
+	got, err := ls.Lookup(ctx, nil, []string{"id"}, []any{float64(7)})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"id": int64(7), "name": "user7"}}, got)
+}
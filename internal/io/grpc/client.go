@@ -0,0 +1,101 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpc provides the gRPC streaming source and sink. The service and messages are defined by the
+// protobuf schemas in the schema registry so that the data is typed without any extra format setting.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc" //nolint:staticcheck
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/protobuf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+type commonConf struct {
+	// Server is the address of the gRPC server like localhost:50051
+	Server string `json:"server"`
+	// SchemaId is the protobuf schema name and the service name, like telemetry.TelemetryService
+	SchemaId string `json:"schemaId"`
+	// ConnectTimeout is the timeout to wait for the connection ready
+	ConnectTimeout cast.DurationConf `json:"connectTimeout"`
+}
+
+func (c *commonConf) validate() error {
+	if c.Server == "" {
+		return fmt.Errorf("server is required")
+	}
+	if c.SchemaId == "" {
+		return fmt.Errorf("schemaId is required")
+	}
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = cast.DurationConf(5 * time.Second)
+	}
+	return nil
+}
+
+// findMethod finds the method descriptor by the schemaId in the format of schemaName.ServiceName
+func findMethod(schemaId string, method string) (*desc.MethodDescriptor, error) {
+	r := strings.SplitN(schemaId, ".", 2)
+	if len(r) != 2 || r[0] == "" || r[1] == "" {
+		return nil, fmt.Errorf("invalid schemaId %s, must be in the format of schemaName.ServiceName", schemaId)
+	}
+	ffs, err := schema.GetSchemaFile(def.PROTOBUF, r[0])
+	if err != nil {
+		return nil, err
+	}
+	if ffs.SchemaFile == "" {
+		return nil, fmt.Errorf("schema %s has no proto file", r[0])
+	}
+	sd, err := protobuf.FindService(ffs.SchemaFile, r[1])
+	if err != nil {
+		return nil, err
+	}
+	md := sd.FindMethodByName(method)
+	if md == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", method, sd.GetFullyQualifiedName())
+	}
+	return md, nil
+}
+
+// dial connects to the server and waits until it is ready or timeout
+func dial(ctx context.Context, server string, timeout time.Duration) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(server, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn.Connect()
+	for {
+		s := conn.GetState()
+		if s == connectivity.Ready {
+			return conn, nil
+		}
+		if !conn.WaitForStateChange(tctx, s) {
+			_ = conn.Close()
+			return nil, fmt.Errorf("connect to %s timeout, last state %s", server, s)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"            //nolint:staticcheck
+	"github.com/jhump/protoreflect/desc/protoparse" //nolint:staticcheck
+	"github.com/jhump/protoreflect/dynamic"         //nolint:staticcheck
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+)
+
+// telemetryServer is a test server implemented with dynamic messages
+type telemetryServer struct {
+	sd       *desc.ServiceDescriptor
+	mu       sync.Mutex
+	reported []map[string]any
+}
+
+func (ts *telemetryServer) reading(device string, temp float64) *dynamic.Message {
+	m := dynamic.NewMessage(ts.sd.FindMethodByName("Subscribe").GetOutputType())
+	m.SetFieldByName("device", device)
+	m.SetFieldByName("temperature", temp)
+	m.SetFieldByName("ts", int64(1000))
+	return m
+}
+
+func (ts *telemetryServer) subscribe(_ any, stream grpc.ServerStream) error {
+	req := dynamic.NewMessage(ts.sd.FindMethodByName("Subscribe").GetInputType())
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	device := req.GetFieldByName("device").(string)
+	for _, t := range []float64{20.5, 21.5} {
+		if err := stream.SendMsg(ts.reading(device, t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ts *telemetryServer) exchange(_ any, stream grpc.ServerStream) error {
+	for {
+		req := dynamic.NewMessage(ts.sd.FindMethodByName("Exchange").GetInputType())
+		err := stream.RecvMsg(req)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.SendMsg(ts.reading(req.GetFieldByName("device").(string), 30)); err != nil {
+			return err
+		}
+	}
+}
+
+func (ts *telemetryServer) report(_ any, stream grpc.ServerStream) error {
+	count := int64(0)
+	for {
+		m := dynamic.NewMessage(ts.sd.FindMethodByName("Report").GetInputType())
+		err := stream.RecvMsg(m)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		ts.mu.Lock()
+		ts.reported = append(ts.reported, map[string]any{
+			"device":      m.GetFieldByName("device"),
+			"temperature": m.GetFieldByName("temperature"),
+			"ts":          m.GetFieldByName("ts"),
+		})
+		ts.mu.Unlock()
+		count++
+	}
+	ack := dynamic.NewMessage(ts.sd.FindMethodByName("Report").GetOutputType())
+	ack.SetFieldByName("count", count)
+	return stream.SendMsg(ack)
+}
+
+func (ts *telemetryServer) getReported() []map[string]any {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.reported
+}
+
+// prepare registers the test schema and starts the test server
+func prepare(t *testing.T) (string, *telemetryServer) {
+	testx.InitEnv("grpc")
+	require.NoError(t, schema.InitRegistry())
+	content, err := os.ReadFile("test/telemetry.proto")
+	require.NoError(t, err)
+	require.NoError(t, schema.CreateOrUpdateSchema(&schema.Info{Type: def.PROTOBUF, Name: "telemetry", Content: string(content)}))
+	fds, err := (&protoparse.Parser{}).ParseFiles("test/telemetry.proto")
+	require.NoError(t, err)
+	ts := &telemetryServer{sd: fds[0].FindService("telemetry.TelemetryService")}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "telemetry.TelemetryService",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{
+			{StreamName: "Subscribe", Handler: ts.subscribe, ServerStreams: true},
+			{StreamName: "Exchange", Handler: ts.exchange, ServerStreams: true, ClientStreams: true},
+			{StreamName: "Report", Handler: ts.report, ClientStreams: true},
+		},
+	}, ts)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), ts
+}
+
+func TestFindMethod(t *testing.T) {
+	prepare(t)
+	md, err := findMethod("telemetry.TelemetryService", "Subscribe")
+	require.NoError(t, err)
+	require.Equal(t, "telemetry.TelemetryService.Subscribe", md.GetFullyQualifiedName())
+	md, err = findMethod("telemetry.telemetry.TelemetryService", "Report")
+	require.NoError(t, err)
+	require.Equal(t, "telemetry.TelemetryService.Report", md.GetFullyQualifiedName())
+	_, err = findMethod("telemetry", "Report")
+	require.EqualError(t, err, "invalid schemaId telemetry, must be in the format of schemaName.ServiceName")
+	_, err = findMethod("telemetry.Other", "Report")
+	require.Error(t, err)
+	_, err = findMethod("telemetry.TelemetryService", "Unknown")
+	require.EqualError(t, err, "method Unknown not found in service telemetry.TelemetryService")
+	_, err = findMethod("notexist.TelemetryService", "Report")
+	require.EqualError(t, err, "schema type protobuf, file notexist not found")
+}
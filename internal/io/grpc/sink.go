@@ -0,0 +1,142 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"                //nolint:staticcheck
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic" //nolint:staticcheck
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"google.golang.org/grpc"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/protobuf"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+type sinkConf struct {
+	commonConf
+	// Method is the client streaming method name
+	Method string `json:"method"`
+}
+
+// Sink sends each tuple as a message of a client streaming method. The stream is kept open
+// until the rule stops and is reopened if broken.
+type Sink struct {
+	conf   *sinkConf
+	method *desc.MethodDescriptor
+	conn   *grpc.ClientConn
+	stream *grpcdynamic.ClientStream
+	fc     *protobuf.FieldConverter
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &sinkConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return err
+	}
+	if err := cast.MapToStruct(props, &c.commonConf); err != nil {
+		return err
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+	md, err := findMethod(c.SchemaId, c.Method)
+	if err != nil {
+		return err
+	}
+	if !md.IsClientStreaming() || md.IsServerStreaming() {
+		return fmt.Errorf("method %s must be client streaming", md.GetFullyQualifiedName())
+	}
+	s.conf = c
+	s.method = md
+	s.fc = protobuf.GetFieldConverter()
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, err := dial(ctx, s.conf.Server, time.Duration(s.conf.ConnectTimeout))
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.conn = conn
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	return s.send(ctx, item.ToMap())
+}
+
+func (s *Sink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	var err error
+	items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+		err = s.send(ctx, tuple.ToMap())
+		return err == nil
+	})
+	return err
+}
+
+func (s *Sink) send(ctx api.StreamContext, data map[string]any) error {
+	msg, err := s.fc.EncodeMap(s.method.GetInputType(), data)
+	if err != nil {
+		return fmt.Errorf("encode grpc message error: %v", err)
+	}
+	if s.stream == nil {
+		stub := grpcdynamic.NewStub(s.conn)
+		s.stream, err = stub.InvokeRpcClientStream(ctx, s.method)
+		if err != nil {
+			return errorx.NewIOErr(fmt.Sprintf("open grpc stream error: %v", err))
+		}
+	}
+	if err = s.stream.SendMsg(msg); err != nil {
+		// SendMsg returns io.EOF if the stream is broken, the real error is returned by CloseAndReceive
+		if _, rerr := s.stream.CloseAndReceive(); rerr != nil {
+			err = rerr
+		}
+		s.stream = nil
+		return errorx.NewIOErr(fmt.Sprintf("send grpc message error: %v", err))
+	}
+	return nil
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	// The method is not set if the provision fails
+	if s.conf != nil {
+		ctx.GetLogger().Infof("closing grpc sink %s", s.conf.Method)
+	}
+	if s.stream != nil {
+		resp, err := s.stream.CloseAndReceive()
+		if err != nil {
+			ctx.GetLogger().Warnf("close grpc stream error: %v", err)
+		} else {
+			ctx.GetLogger().Infof("grpc stream closed with response %v", resp)
+		}
+		s.stream = nil
+	}
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var _ api.TupleCollector = &Sink{}
@@ -0,0 +1,61 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestSinkProvision(t *testing.T) {
+	prepare(t)
+	ctx := mockContext.NewMockContext("test", "op")
+	s := GetSink()
+	err := s.Provision(ctx, map[string]any{"server": "localhost:1", "schemaId": "telemetry.TelemetryService", "method": "Subscribe"})
+	require.EqualError(t, err, "method telemetry.TelemetryService.Subscribe must be client streaming")
+	// close after the provision fails
+	require.NoError(t, s.Close(ctx))
+	err = GetSink().Provision(ctx, map[string]any{"server": "localhost:1", "schemaId": "telemetry.TelemetryService", "method": "Exchange"})
+	require.EqualError(t, err, "method telemetry.TelemetryService.Exchange must be client streaming")
+	err = GetSink().Provision(ctx, map[string]any{"server": "localhost:1", "schemaId": "telemetry.TelemetryService", "method": "Report"})
+	require.NoError(t, err)
+}
+
+func TestSinkClientStreaming(t *testing.T) {
+	addr, ts := prepare(t)
+	data := []any{
+		&xsql.Tuple{Message: map[string]any{"device": "d1", "temperature": 20.5, "ts": 1000}},
+		&xsql.WindowTuples{Content: []xsql.Row{
+			&xsql.Tuple{Message: map[string]any{"device": "d2", "temperature": 21}},
+			&xsql.Tuple{Message: map[string]any{"device": "d3", "temperature": 22.5, "ts": 3000}},
+		}},
+	}
+	err := mock.RunTupleSinkCollect(GetSink().(*Sink), data, map[string]any{
+		"server":   addr,
+		"schemaId": "telemetry.TelemetryService",
+		"method":   "Report",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{
+		{"device": "d1", "temperature": 20.5, "ts": int64(1000)},
+		{"device": "d2", "temperature": float64(21), "ts": int64(0)},
+		{"device": "d3", "temperature": 22.5, "ts": int64(3000)},
+	}, ts.getReported())
+}
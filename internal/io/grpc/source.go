@@ -0,0 +1,186 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"                  //nolint:staticcheck
+	"github.com/jhump/protoreflect/desc"                //nolint:staticcheck
+	"github.com/jhump/protoreflect/dynamic"             //nolint:staticcheck
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic" //nolint:staticcheck
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"google.golang.org/grpc"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/protobuf"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type sourceConf struct {
+	commonConf
+	// Method is the server streaming or bidirectional streaming method name. It is set by the stream datasource.
+	Method string `json:"datasource"`
+	// Request is the message sent to open the stream
+	Request map[string]any `json:"request"`
+	// ReconnectInterval is the interval to reopen the stream after it is broken
+	ReconnectInterval cast.DurationConf `json:"reconnectInterval"`
+}
+
+// Source calls a server streaming or bidirectional streaming method and ingests each received message as a tuple
+type Source struct {
+	conf   *sourceConf
+	method *desc.MethodDescriptor
+	conn   *grpc.ClientConn
+	sch    api.StatusChangeHandler
+	fc     *protobuf.FieldConverter
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &sourceConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return err
+	}
+	if err := cast.MapToStruct(props, &c.commonConf); err != nil {
+		return err
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+	if c.ReconnectInterval <= 0 {
+		c.ReconnectInterval = cast.DurationConf(time.Second)
+	}
+	md, err := findMethod(c.SchemaId, c.Method)
+	if err != nil {
+		return err
+	}
+	if !md.IsServerStreaming() {
+		return fmt.Errorf("method %s must be server streaming or bidirectional streaming", md.GetFullyQualifiedName())
+	}
+	s.conf = c
+	s.method = md
+	s.fc = protobuf.GetFieldConverter()
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	s.sch = sch
+	conn, err := dial(ctx, s.conf.Server, time.Duration(s.conf.ConnectTimeout))
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.conn = conn
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	req, err := s.fc.EncodeMap(s.method.GetInputType(), s.conf.Request)
+	if err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+	go func() {
+		for {
+			err := s.receive(ctx, req, ingest)
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			if err != nil {
+				ingestError(ctx, err)
+				s.sch(api.ConnectionDisconnected, err.Error())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(s.conf.ReconnectInterval)):
+				ctx.GetLogger().Infof("reopen grpc stream %s", s.method.GetFullyQualifiedName())
+			}
+		}
+	}()
+	return nil
+}
+
+type recvStream interface {
+	RecvMsg() (proto.Message, error)
+}
+
+// receive opens the stream and ingests messages until the stream ends
+func (s *Source) receive(ctx api.StreamContext, req *dynamic.Message, ingest api.TupleIngest) error {
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stub := grpcdynamic.NewStub(s.conn)
+	var stream recvStream
+	if s.method.IsClientStreaming() {
+		bs, err := stub.InvokeRpcBidiStream(sctx, s.method)
+		if err != nil {
+			return fmt.Errorf("open grpc stream error: %v", err)
+		}
+		if len(s.conf.Request) > 0 {
+			if err := bs.SendMsg(req); err != nil {
+				return fmt.Errorf("send grpc request error: %v", err)
+			}
+		}
+		stream = bs
+	} else {
+		ss, err := stub.InvokeRpcServerStream(sctx, s.method, req)
+		if err != nil {
+			return fmt.Errorf("open grpc stream error: %v", err)
+		}
+		stream = ss
+	}
+	s.sch(api.ConnectionConnected, "")
+	meta := map[string]any{
+		"method": s.method.GetFullyQualifiedName(),
+	}
+	for {
+		msg, err := stream.RecvMsg()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				ctx.GetLogger().Infof("grpc stream %s ends", s.method.GetFullyQualifiedName())
+				return nil
+			}
+			return fmt.Errorf("receive grpc message error: %v", err)
+		}
+		dm, err := dynamic.AsDynamicMessage(msg)
+		if err != nil {
+			return fmt.Errorf("parse grpc message error: %v", err)
+		}
+		ingest(ctx, s.fc.DecodeMessage(dm, s.method.GetOutputType()), meta, timex.GetNow())
+	}
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	// The method is not set if the provision fails
+	if s.conf != nil {
+		ctx.GetLogger().Infof("closing grpc source %s", s.conf.Method)
+	}
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var _ api.TupleSource = &Source{}
@@ -0,0 +1,120 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mockclock"
+	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+func TestSourceProvision(t *testing.T) {
+	prepare(t)
+	ctx := mockContext.NewMockContext("test", "op")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no server",
+			props: map[string]any{"schemaId": "telemetry.TelemetryService", "datasource": "Subscribe"},
+			err:   "server is required",
+		},
+		{
+			name:  "no schema",
+			props: map[string]any{"server": "localhost:1", "datasource": "Subscribe"},
+			err:   "schemaId is required",
+		},
+		{
+			name:  "unary",
+			props: map[string]any{"server": "localhost:1", "schemaId": "telemetry.TelemetryService", "datasource": "Get"},
+			err:   "method telemetry.TelemetryService.Get must be server streaming or bidirectional streaming",
+		},
+		{
+			name:  "client streaming",
+			props: map[string]any{"server": "localhost:1", "schemaId": "telemetry.TelemetryService", "datasource": "Report"},
+			err:   "method telemetry.TelemetryService.Report must be server streaming or bidirectional streaming",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GetSource()
+			err := s.Provision(ctx, tt.props)
+			require.EqualError(t, err, tt.err)
+			require.NoError(t, s.Close(ctx))
+		})
+	}
+}
+
+func TestSourceServerStreaming(t *testing.T) {
+	addr, _ := prepare(t)
+	mc := mockclock.GetMockClock()
+	meta := map[string]any{"method": "telemetry.TelemetryService.Subscribe"}
+	expected := []api.MessageTuple{
+		model.NewDefaultSourceTuple(map[string]any{"device": "d1", "temperature": 20.5, "ts": int64(1000)}, meta, mc.Now()),
+		model.NewDefaultSourceTuple(map[string]any{"device": "d1", "temperature": 21.5, "ts": int64(1000)}, meta, mc.Now()),
+	}
+	mock.TestSourceConnector(t, GetSource(), map[string]any{
+		"server":     addr,
+		"schemaId":   "telemetry.TelemetryService",
+		"datasource": "Subscribe",
+		"request":    map[string]any{"device": "d1"},
+	}, expected, func() {
+		// the server pushes data once subscribed
+	})
+}
+
+func TestSourceBidiStreaming(t *testing.T) {
+	addr, _ := prepare(t)
+	mc := mockclock.GetMockClock()
+	meta := map[string]any{"method": "telemetry.TelemetryService.Exchange"}
+	expected := []api.MessageTuple{
+		model.NewDefaultSourceTuple(map[string]any{"device": "d2", "temperature": float64(30), "ts": int64(1000)}, meta, mc.Now()),
+	}
+	mock.TestSourceConnector(t, GetSource(), map[string]any{
+		"server":     addr,
+		"schemaId":   "telemetry.TelemetryService",
+		"datasource": "Exchange",
+		"request":    map[string]any{"device": "d2"},
+	}, expected, func() {
+		// the server responds to the request
+	})
+}
+
+func TestSourceConnectFail(t *testing.T) {
+	prepare(t)
+	ctx := mockContext.NewMockContext("test", "op")
+	s := GetSource()
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":         "127.0.0.1:1",
+		"schemaId":       "telemetry.TelemetryService",
+		"datasource":     "Subscribe",
+		"connectTimeout": "100ms",
+	}))
+	var status string
+	err := s.Connect(ctx, func(s string, _ string) {
+		status = s
+	})
+	assert.Error(t, err)
+	assert.Equal(t, api.ConnectionDisconnected, status)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
 	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/connection"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
@@ -93,6 +94,10 @@ func (h *HttpPushSource) Connect(ctx api.StreamContext, sch api.StatusChangeHand
 
 func (h *HttpPushSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
 	ch := pubsub.CreateSub(h.topic, nil, h.sourceID, 1024)
+	// Let the server respond 429 when the rule cannot catch up only if the rule opts into backpressure
+	if ctx.Value(topoContext.RuleFlowKey) != nil {
+		pubsub.EnableBackpressure(h.sourceID)
+	}
 	h.ch = ch
 	go func(ctx api.StreamContext) {
 		for {
@@ -114,4 +119,10 @@ func (h *HttpPushSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest
 	return nil
 }
 
+// ExclusiveSubscription tells the rule that the ingestion runs in the goroutine of this subscription only, so it
+// can block for backpressure and the buffer of the subscription fills up to reject the requests.
+func (h *HttpPushSource) ExclusiveSubscription() bool {
+	return true
+}
+
 var _ api.BytesSource = &HttpPushSource{}
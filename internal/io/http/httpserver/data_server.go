@@ -120,7 +120,12 @@ func (m *GlobalServerManager) RegisterEndpoint(endpoint string, method string) (
 			handleError(w, err, "Fail to decode data")
 			return
 		}
-		pubsub.ProduceAny(topoContext.Background(), topic, data)
+		// The consumer rules cannot catch up, ask the client to retry later instead of dropping the data
+		if !pubsub.TryProduceAny(topoContext.Background(), topic, data) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many requests, the consumer buffer is full", http.StatusTooManyRequests)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	}
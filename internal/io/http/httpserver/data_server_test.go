@@ -15,6 +15,7 @@
 package httpserver
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"testing"
@@ -22,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/testx"
 )
 
@@ -60,6 +62,41 @@ func TestEndpoints(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestEndpointFull(t *testing.T) {
+	ip := "127.0.0.1"
+	port := 10083
+	InitGlobalServerManager(ip, port, nil)
+	defer ShutDown()
+	topic, err := RegisterEndpoint("/full", "POST")
+	require.NoError(t, err)
+	defer UnregisterEndpoint("/full", "POST")
+	ch := pubsub.CreateSub(topic, nil, "full_source", 1)
+	defer pubsub.CloseSourceConsumerChannel(topic, "full_source")
+	// The consumer without backpressure never rejects the requests
+	_ = pubsub.CreateSub(topic, nil, "drop_source", 1)
+	defer pubsub.CloseSourceConsumerChannel(topic, "drop_source")
+	pubsub.EnableBackpressure("full_source")
+
+	url := fmt.Sprintf("http://%v:%v/full", ip, port)
+	client := &http.Client{}
+	for i := 0; i < 3; i++ {
+		err = testx.TestHttp(client, url, "POST")
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 500)
+	}
+	require.NoError(t, err)
+	// The consumer buffer is full
+	resp, err := client.Post(url, "application/json", bytes.NewBufferString("{}"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("Retry-After"))
+	_ = resp.Body.Close()
+	<-ch
+	require.NoError(t, testx.TestHttp(client, url, "POST"))
+}
+
 func GetEndpoints() map[string]struct{} {
 	return manager.GetEndpoints()
 }
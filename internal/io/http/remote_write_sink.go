@@ -0,0 +1,325 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// remoteWriteConf maps the rule output to the Prometheus time series
+type remoteWriteConf struct {
+	Url string `json:"url"`
+	// The metric name template. If set, each tuple is one sample whose value is the valueField
+	MetricName string `json:"metricName"`
+	ValueField string `json:"valueField"`
+	// Otherwise, each field is one sample whose metric name is the prefix plus the field name
+	MetricPrefix string   `json:"metricPrefix"`
+	Fields       []string `json:"fields"`
+	// The label name to the value template
+	Labels map[string]string `json:"labels"`
+	// The fields to be added as labels. If the field is a map, each entry is a label
+	LabelFields []string `json:"labelFields"`
+	// The field of the timestamp in milliseconds, use the current time if not set
+	TsFieldName string `json:"tsFieldName"`
+}
+
+// RemoteWriteSink writes the rule output to the Prometheus remote write endpoint such as Prometheus or VictoriaMetrics.
+// Batching is done by the batchSize and lingerInterval of the sink node, each batch is sent as one write request.
+type RemoteWriteSink struct {
+	*ClientConf
+	conf *remoteWriteConf
+	// fields excluded when using all fields as samples
+	excluded map[string]struct{}
+}
+
+type promSample struct {
+	value float64
+	ts    int64
+}
+
+type promSeries struct {
+	// sorted by name
+	labels  [][2]string
+	samples []promSample
+}
+
+func (r *RemoteWriteSink) Provision(_ api.StreamContext, configs map[string]any) error {
+	c := &remoteWriteConf{ValueField: "value"}
+	if err := cast.MapToStruct(configs, c); err != nil {
+		return fmt.Errorf("fail to parse the properties: %v", err)
+	}
+	if c.Url == "" {
+		return fmt.Errorf("url is required")
+	}
+	for k := range c.Labels {
+		if !model.LabelName(k).IsValid() || k == model.MetricNameLabel {
+			return fmt.Errorf("invalid label name %s", k)
+		}
+	}
+	if c.MetricName == "" {
+		for _, f := range c.Fields {
+			if !model.IsValidMetricName(model.LabelValue(c.MetricPrefix + f)) {
+				return fmt.Errorf("invalid metric name %s", c.MetricPrefix+f)
+			}
+		}
+	}
+	props := make(map[string]any, len(configs)+2)
+	for k, v := range configs {
+		props[k] = v
+	}
+	props["method"] = http.MethodPost
+	props["bodyType"] = "binary"
+	r.ClientConf = &ClientConf{}
+	if err := r.InitConf("", props); err != nil {
+		return err
+	}
+	r.conf = c
+	r.excluded = make(map[string]struct{}, len(c.LabelFields)+1)
+	for _, f := range c.LabelFields {
+		r.excluded[f] = struct{}{}
+	}
+	if c.TsFieldName != "" {
+		r.excluded[c.TsFieldName] = struct{}{}
+	}
+	return nil
+}
+
+func (r *RemoteWriteSink) Connect(_ api.StreamContext, sch api.StatusChangeHandler) error {
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (r *RemoteWriteSink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	return r.collect(ctx, []map[string]any{item.ToMap()})
+}
+
+func (r *RemoteWriteSink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	return r.collect(ctx, items.ToMaps())
+}
+
+func (r *RemoteWriteSink) collect(ctx api.StreamContext, data []map[string]any) error {
+	series, err := r.toSeries(ctx, data)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		return nil
+	}
+	body := snappy.Encode(nil, encodeWriteRequest(series))
+	headers := make(map[string]string, len(r.config.Headers)+3)
+	for k, v := range r.config.Headers {
+		headers[k] = v
+	}
+	headers["Content-Type"] = "application/x-protobuf"
+	headers["Content-Encoding"] = "snappy"
+	headers["X-Prometheus-Remote-Write-Version"] = "0.1.0"
+	resp, err := httpx.Send(ctx.GetLogger(), r.client, "binary", http.MethodPost, r.config.Url, headers, body)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("promremotewrite sink fails to send out the data: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		ctx.GetLogger().Debugf("promremotewrite sink sent %d series", len(series))
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("promremotewrite sink receives status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	// Server errors and rate limit are retryable, the other client errors like bad samples are not
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return errorx.NewIOErr(err.Error())
+	}
+	return err
+}
+
+// toSeries maps the tuples to series, samples of the same labels are grouped into one series
+func (r *RemoteWriteSink) toSeries(ctx api.StreamContext, data []map[string]any) ([]*promSeries, error) {
+	var result []*promSeries
+	index := make(map[string]*promSeries)
+	add := func(name string, labels map[string]string, s promSample) {
+		ls := make([][2]string, 0, len(labels)+1)
+		ls = append(ls, [2]string{model.MetricNameLabel, name})
+		for k, v := range labels {
+			// empty label is the same as no label
+			if v != "" {
+				ls = append(ls, [2]string{k, v})
+			}
+		}
+		sort.Slice(ls, func(i, j int) bool { return ls[i][0] < ls[j][0] })
+		var sb strings.Builder
+		for _, l := range ls {
+			sb.WriteString(l[0])
+			sb.WriteByte(0)
+			sb.WriteString(l[1])
+			sb.WriteByte(0)
+		}
+		key := sb.String()
+		ps, ok := index[key]
+		if !ok {
+			ps = &promSeries{labels: ls}
+			index[key] = ps
+			result = append(result, ps)
+		}
+		ps.samples = append(ps.samples, s)
+	}
+	for _, m := range data {
+		ts := timex.GetNow().UnixMilli()
+		if r.conf.TsFieldName != "" {
+			v, err := cast.ToInt64(m[r.conf.TsFieldName], cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return nil, fmt.Errorf("time field %s can not convert to timestamp(int64) : %v", r.conf.TsFieldName, m[r.conf.TsFieldName])
+			}
+			ts = v
+		}
+		labels, err := r.labels(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		if r.conf.MetricName != "" {
+			name, err := ctx.ParseTemplate(r.conf.MetricName, m)
+			if err != nil {
+				return nil, fmt.Errorf("parse metric name template %s failed, err:%v", r.conf.MetricName, err)
+			}
+			v, ok, err := sampleValue(m[r.conf.ValueField])
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", r.conf.ValueField, err)
+			}
+			if ok {
+				add(name, labels, promSample{value: v, ts: ts})
+			}
+			continue
+		}
+		fields := r.conf.Fields
+		if len(fields) == 0 {
+			fields = make([]string, 0, len(m))
+			for k, v := range m {
+				if _, ok := r.excluded[k]; ok {
+					continue
+				}
+				switch v.(type) {
+				case int, int64, float64, float32, bool:
+					fields = append(fields, k)
+				}
+			}
+			sort.Strings(fields)
+		}
+		for _, f := range fields {
+			v, ok, err := sampleValue(m[f])
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", f, err)
+			}
+			if ok {
+				add(r.conf.MetricPrefix+f, labels, promSample{value: v, ts: ts})
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *RemoteWriteSink) labels(ctx api.StreamContext, m map[string]any) (map[string]string, error) {
+	labels := make(map[string]string, len(r.conf.Labels)+len(r.conf.LabelFields))
+	for _, f := range r.conf.LabelFields {
+		switch v := m[f].(type) {
+		case nil:
+		case map[string]any:
+			for k, lv := range v {
+				labels[k] = cast.ToStringAlways(lv)
+			}
+		default:
+			labels[f] = cast.ToStringAlways(v)
+		}
+	}
+	for k, v := range r.conf.Labels {
+		vv, err := ctx.ParseTemplate(v, m)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s label template %s failed, err:%v", k, v, err)
+		}
+		// convertAll has no error
+		vs, _ := cast.ToString(vv, cast.CONVERT_ALL)
+		labels[k] = vs
+	}
+	return labels, nil
+}
+
+// sampleValue converts the field to the float sample. Nil is skipped.
+func sampleValue(v any) (float64, bool, error) {
+	switch vv := v.(type) {
+	case nil:
+		return 0, false, nil
+	case bool:
+		if vv {
+			return 1, true, nil
+		}
+		return 0, true, nil
+	}
+	f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return 0, false, err
+	}
+	return f, true, nil
+}
+
+// encodeWriteRequest encodes the prometheus.WriteRequest protobuf message
+func encodeWriteRequest(series []*promSeries) []byte {
+	var b []byte
+	for _, s := range series {
+		var sb []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l[0])
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l[1])
+			sb = protowire.AppendTag(sb, 1, protowire.BytesType)
+			sb = protowire.AppendBytes(sb, lb)
+		}
+		for _, p := range s.samples {
+			var pb []byte
+			pb = protowire.AppendTag(pb, 1, protowire.Fixed64Type)
+			pb = protowire.AppendFixed64(pb, math.Float64bits(p.value))
+			pb = protowire.AppendTag(pb, 2, protowire.VarintType)
+			pb = protowire.AppendVarint(pb, uint64(p.ts))
+			sb = protowire.AppendTag(sb, 2, protowire.BytesType)
+			sb = protowire.AppendBytes(sb, pb)
+		}
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	return b
+}
+
+func (r *RemoteWriteSink) Close(_ api.StreamContext) error {
+	return nil
+}
+
+func GetRemoteWriteSink() api.Sink {
+	return &RemoteWriteSink{}
+}
+
+var _ api.TupleCollector = &RemoteWriteSink{}
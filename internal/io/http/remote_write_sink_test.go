@@ -0,0 +1,254 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type testSeries struct {
+	Labels  map[string]string
+	Samples [][2]float64
+}
+
+// decodeWriteRequest decodes the write request for verification
+func decodeWriteRequest(t *testing.T, b []byte) []testSeries {
+	var result []testSeries
+	fields := func(b []byte, f func(num protowire.Number, v []byte, n uint64)) {
+		for len(b) > 0 {
+			num, typ, l := protowire.ConsumeTag(b)
+			require.True(t, l > 0)
+			b = b[l:]
+			switch typ {
+			case protowire.BytesType:
+				v, l := protowire.ConsumeBytes(b)
+				require.True(t, l > 0)
+				f(num, v, 0)
+				b = b[l:]
+			case protowire.Fixed64Type:
+				v, l := protowire.ConsumeFixed64(b)
+				require.True(t, l > 0)
+				f(num, nil, v)
+				b = b[l:]
+			case protowire.VarintType:
+				v, l := protowire.ConsumeVarint(b)
+				require.True(t, l > 0)
+				f(num, nil, v)
+				b = b[l:]
+			default:
+				t.Fatalf("unexpected wire type %d", typ)
+			}
+		}
+	}
+	fields(b, func(num protowire.Number, v []byte, _ uint64) {
+		require.Equal(t, protowire.Number(1), num)
+		s := testSeries{Labels: map[string]string{}}
+		fields(v, func(num protowire.Number, v []byte, _ uint64) {
+			switch num {
+			case 1:
+				var name, value string
+				fields(v, func(num protowire.Number, v []byte, _ uint64) {
+					if num == 1 {
+						name = string(v)
+					} else {
+						value = string(v)
+					}
+				})
+				s.Labels[name] = value
+			case 2:
+				var sample [2]float64
+				fields(v, func(num protowire.Number, _ []byte, n uint64) {
+					if num == 1 {
+						sample[0] = math.Float64frombits(n)
+					} else {
+						sample[1] = float64(int64(n))
+					}
+				})
+				s.Samples = append(s.Samples, sample)
+			}
+		})
+		result = append(result, s)
+	})
+	return result
+}
+
+type remoteWriteServer struct {
+	sync.Mutex
+	status   int
+	requests [][]testSeries
+	headers  []http.Header
+}
+
+func (s *remoteWriteServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		s.Lock()
+		defer s.Unlock()
+		s.requests = append(s.requests, decodeWriteRequest(t, b))
+		s.headers = append(s.headers, r.Header)
+		if s.status != 0 {
+			w.WriteHeader(s.status)
+			_, _ = w.Write([]byte("out of order sample"))
+		}
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	timex.Set(1000)
+	rs := &remoteWriteServer{}
+	server := httptest.NewServer(rs.handler(t))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("test", "op1")
+
+	tests := []struct {
+		name  string
+		props map[string]any
+		data  []map[string]any
+		exp   []testSeries
+	}{
+		{
+			name: "all fields",
+			props: map[string]any{
+				"metricPrefix": "ekuiper_",
+				"labels":       map[string]any{"device": "{{.id}}", "job": "edge"},
+				"labelFields":  []any{"id"},
+				"tsFieldName":  "ts",
+			},
+			data: []map[string]any{
+				{"id": "d1", "temperature": 21.5, "running": true, "name": "pump", "ts": int64(10)},
+				{"id": "d1", "temperature": 22.0, "running": false, "ts": int64(20)},
+				{"id": "d2", "temperature": int64(23), "running": nil, "ts": int64(20)},
+			},
+			exp: []testSeries{
+				{Labels: map[string]string{"__name__": "ekuiper_running", "device": "d1", "id": "d1", "job": "edge"}, Samples: [][2]float64{{1, 10}, {0, 20}}},
+				{Labels: map[string]string{"__name__": "ekuiper_temperature", "device": "d1", "id": "d1", "job": "edge"}, Samples: [][2]float64{{21.5, 10}, {22, 20}}},
+				{Labels: map[string]string{"__name__": "ekuiper_temperature", "device": "d2", "id": "d2", "job": "edge"}, Samples: [][2]float64{{23, 20}}},
+			},
+		},
+		{
+			name:  "selected fields",
+			props: map[string]any{"fields": []any{"temperature"}, "labels": map[string]any{"empty": "{{.none}}"}},
+			data: []map[string]any{
+				{"temperature": 21.5, "humidity": 50, "none": ""},
+			},
+			exp: []testSeries{
+				{Labels: map[string]string{"__name__": "temperature"}, Samples: [][2]float64{{21.5, 1000}}},
+			},
+		},
+		{
+			name: "prometheus samples",
+			props: map[string]any{
+				"metricName":  "{{.name}}",
+				"labelFields": []any{"labels"},
+				"tsFieldName": "timestamp",
+			},
+			data: []map[string]any{
+				{"name": "http_requests_total", "labels": map[string]any{"code": "200"}, "value": 1027.0, "timestamp": int64(5)},
+				{"name": "http_requests_total", "labels": map[string]any{"code": "400"}, "value": 3.0, "timestamp": int64(5)},
+			},
+			exp: []testSeries{
+				{Labels: map[string]string{"__name__": "http_requests_total", "code": "200"}, Samples: [][2]float64{{1027, 5}}},
+				{Labels: map[string]string{"__name__": "http_requests_total", "code": "400"}, Samples: [][2]float64{{3, 5}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs.requests = nil
+			tt.props["url"] = server.URL
+			tt.props["headers"] = map[string]any{"Authorization": "Bearer abc"}
+			s := GetRemoteWriteSink().(*RemoteWriteSink)
+			require.NoError(t, s.Provision(ctx, tt.props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {}))
+			list := &xsql.TransformedTupleList{}
+			for _, d := range tt.data {
+				list.Content = append(list.Content, &xsql.Tuple{Message: d})
+			}
+			require.NoError(t, s.CollectList(ctx, list))
+			require.Len(t, rs.requests, 1)
+			require.Equal(t, tt.exp, rs.requests[0])
+			h := rs.headers[len(rs.headers)-1]
+			require.Equal(t, "snappy", h.Get("Content-Encoding"))
+			require.Equal(t, "application/x-protobuf", h.Get("Content-Type"))
+			require.Equal(t, "0.1.0", h.Get("X-Prometheus-Remote-Write-Version"))
+			require.Equal(t, "Bearer abc", h.Get("Authorization"))
+			require.NoError(t, s.Close(ctx))
+		})
+	}
+}
+
+func TestRemoteWriteErr(t *testing.T) {
+	rs := &remoteWriteServer{}
+	server := httptest.NewServer(rs.handler(t))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("test", "op1")
+	s := GetRemoteWriteSink().(*RemoteWriteSink)
+	require.NoError(t, s.Provision(ctx, map[string]any{"url": server.URL}))
+	var item api.MessageTuple = &xsql.Tuple{Message: map[string]any{"a": 1.0}}
+
+	rs.status = http.StatusBadRequest
+	err := s.Collect(ctx, item)
+	require.EqualError(t, err, "promremotewrite sink receives status 400: out of order sample")
+	require.False(t, errorx.IsIOError(err))
+	rs.status = http.StatusServiceUnavailable
+	err = s.Collect(ctx, item)
+	require.True(t, errorx.IsIOError(err))
+	// no sample, no request
+	rs.requests = nil
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"a": "x"}}))
+	require.Len(t, rs.requests, 0)
+}
+
+func TestRemoteWriteProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	tests := []struct {
+		props map[string]any
+		err   string
+	}{
+		{props: map[string]any{}, err: "url is required"},
+		{props: map[string]any{"url": "http://localhost:9090", "labels": map[string]any{"a-b": "x"}}, err: "invalid label name a-b"},
+		{props: map[string]any{"url": "http://localhost:9090", "labels": map[string]any{"__name__": "x"}}, err: "invalid label name __name__"},
+		{props: map[string]any{"url": "http://localhost:9090", "fields": []any{"a-b"}}, err: "invalid metric name a-b"},
+		{props: map[string]any{"url": "localhost:9090"}, err: "Invalid scheme localhost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			s := GetRemoteWriteSink()
+			require.EqualError(t, s.Provision(ctx, tt.props), tt.err)
+		})
+	}
+	s := GetRemoteWriteSink().(*RemoteWriteSink)
+	require.NoError(t, s.Provision(ctx, map[string]any{"url": "http://localhost:9090", "fields": []any{"a"}}))
+	_, err := s.toSeries(ctx, []map[string]any{{"a": "x"}})
+	require.EqualError(t, err, "field a: cannot convert string(x) to float64")
+}
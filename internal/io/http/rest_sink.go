@@ -70,8 +70,8 @@ func (r *RestSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 	if dp, ok := item.(api.HasDynamicProps); ok {
 		if !r.noHeaderTemplate {
 			r.noHeaderTemplate = true
+			headers = make(map[string]string, len(r.config.Headers))
 			for k, v := range r.config.Headers {
-				headers = make(map[string]string, len(r.config.Headers))
 				nv, ok := dp.DynamicProps(v)
 				if ok {
 					headers[k] = nv
@@ -291,3 +291,36 @@ func TestRestSinkRecoverErr(t *testing.T) {
 	require.Error(t, err)
 	require.True(t, errorx.IsIOError(err))
 }
+
+func TestRestSinkDynamicHeaders(t *testing.T) {
+	var received []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Clone())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("1", "2")
+	s := &RestSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":    server.URL,
+		"method": "post",
+		"headers": map[string]any{
+			"X-Device":    "dev1",
+			"X-Signature": "${signature}",
+		},
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	for _, sig := range []string{"c2lnMQ==", "c2lnMg=="} {
+		require.NoError(t, s.Collect(ctx, &xsql.RawTuple{
+			Rawdata: []byte(`{"a":1}`),
+			Props:   map[string]string{"${signature}": sig},
+		}))
+	}
+	require.Len(t, received, 2)
+	assert.Equal(t, "dev1", received[0].Get("X-Device"))
+	assert.Equal(t, "c2lnMQ==", received[0].Get("X-Signature"))
+	assert.Equal(t, "dev1", received[1].Get("X-Device"))
+	assert.Equal(t, "c2lnMg==", received[1].Get("X-Signature"))
+}
@@ -39,7 +39,9 @@ type subChan struct {
 var (
 	pubTopics = make(map[string]*pubConsumers)
 	subExps   = make(map[string]*subChan)
-	mu        = sync.RWMutex{}
+	// The consumers which opt into backpressure. TryProduceAny rejects the data if any of them is full
+	backpressureSubs = make(map[string]struct{})
+	mu               = sync.RWMutex{}
 )
 
 func CreatePub(topic string) {
@@ -82,9 +84,17 @@ func CreateSub(wildcard string, regex *regexp.Regexp, sourceId string, bufferLen
 	return ch
 }
 
+// EnableBackpressure makes TryProduceAny reject the data instead of dropping it when the consumer is full
+func EnableBackpressure(sourceId string) {
+	mu.Lock()
+	defer mu.Unlock()
+	backpressureSubs[sourceId] = struct{}{}
+}
+
 func CloseSourceConsumerChannel(topic string, sourceId string) {
 	mu.Lock()
 	defer mu.Unlock()
+	delete(backpressureSubs, sourceId)
 
 	if sc, exists := subExps[sourceId]; exists {
 		close(sc.ch)
@@ -127,6 +137,35 @@ func ProduceError(ctx api.StreamContext, topic string, err error) {
 	doProduce(ctx, topic, err)
 }
 
+// TryProduceAny sends the data only if all backpressure consumers have free buffer. Return false if any of them
+// is full so that the producer can reject the data instead of dropping it. Other consumers drop the data if full
+// like ProduceAny. The check and the send are under the write lock so that no other producer can fill the
+// buffers in between.
+func TryProduceAny(ctx api.StreamContext, topic string, data any) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	c, exists := pubTopics[topic]
+	if !exists {
+		return true
+	}
+	logger := ctx.GetLogger()
+	for name, out := range c.consumers {
+		if _, ok := backpressureSubs[name]; ok && cap(out) > 0 && len(out) >= cap(out) {
+			logger.Debugf("memory source topic %s consumer %s is full", topic, name)
+			return false
+		}
+	}
+	for name, out := range c.consumers {
+		select {
+		case out <- data:
+			logger.Debugf("memory source broadcast from topic %s to %s done", topic, name)
+		default:
+			logger.Errorf("memory source topic %s drop message to %s", topic, name)
+		}
+	}
+	return true
+}
+
 func doProduce(ctx api.StreamContext, topic string, data any) {
 	c, exists := pubTopics[topic]
 	if !exists {
@@ -197,4 +236,5 @@ func removePubConsumer(topic string, sourceId string, c *pubConsumers) {
 func Reset() {
 	pubTopics = make(map[string]*pubConsumers)
 	subExps = make(map[string]*subChan)
+	backpressureSubs = make(map[string]struct{})
 }
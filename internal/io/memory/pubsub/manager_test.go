@@ -23,6 +23,8 @@ import (
 
 	"github.com/gdexlab/go-render/render"
 	"github.com/stretchr/testify/assert"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
 func TestCreateAndClose(t *testing.T) {
@@ -175,3 +177,35 @@ func TestCreateBeforeDelete(t *testing.T) {
 	}
 	assert.Equal(t, expPub, pubTopics)
 }
+
+func TestTryProduce(t *testing.T) {
+	Reset()
+	ctx := mockContext.NewMockContext("test", "op")
+	CreatePub("test")
+	c1 := CreateSub("test", nil, "source1", 2)
+	c2 := CreateSub("test", nil, "source2", 1)
+	c3 := CreateSub("test", nil, "source3", 1)
+	EnableBackpressure("source1")
+	EnableBackpressure("source2")
+	assert.True(t, TryProduceAny(ctx, "test", 1))
+	// source2 is full, reject without sending to any consumer
+	assert.False(t, TryProduceAny(ctx, "test", 2))
+	assert.Equal(t, 1, len(c1))
+	assert.Equal(t, 1, len(c3))
+	assert.Equal(t, 1, <-c2)
+	// source3 does not opt into backpressure, so it drops the data when full
+	assert.True(t, TryProduceAny(ctx, "test", 3))
+	assert.Equal(t, 1, <-c1)
+	assert.Equal(t, 3, <-c1)
+	assert.Equal(t, 3, <-c2)
+	assert.Equal(t, 1, <-c3)
+	assert.Equal(t, 0, len(c3))
+	// no consumer
+	assert.True(t, TryProduceAny(ctx, "none", 4))
+	CloseSourceConsumerChannel("test", "source1")
+	CloseSourceConsumerChannel("test", "source2")
+	assert.Empty(t, backpressureSubs)
+	assert.True(t, TryProduceAny(ctx, "test", 5))
+	assert.True(t, TryProduceAny(ctx, "test", 6))
+	assert.Equal(t, 5, <-c3)
+}
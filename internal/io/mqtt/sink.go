@@ -109,11 +109,18 @@ func (ms *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 		if transformed {
 			tpc = temp
 		}
-		for k, v := range props {
-			nv, ok := dp.DynamicProps(v)
-			if ok {
-				props[k] = nv
+		if len(props) > 0 {
+			// do not change the config props so that the dynamic props can be found for the next message
+			nps := make(map[string]string, len(props))
+			for k, v := range props {
+				nv, ok := dp.DynamicProps(v)
+				if ok {
+					nps[k] = nv
+				} else {
+					nps[k] = v
+				}
 			}
+			props = nps
 		}
 	}
 	traced, _, span := tracenode.TraceInput(ctx, item, fmt.Sprintf("%s_emit", ctx.GetOpId()))
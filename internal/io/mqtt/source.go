@@ -18,12 +18,15 @@ import (
 	"bytes"
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/internal/converter/sparkplugb"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -38,6 +41,8 @@ type SourceConnector struct {
 	conId      string
 	eof        api.EOFIngest
 	eofPayload []byte
+	// only for sparkplugb format
+	sp *sparkplugTracker
 }
 
 type Conf struct {
@@ -45,6 +50,11 @@ type Conf struct {
 	Qos        int    `json:"qos"`
 	SelId      string `json:"connectionSelector"`
 	EofMessage string `json:"eofMessage"`
+	Format     string `json:"format"`
+	// Publish the rebirth requests to the Sparkplug B edge nodes whose births are missing
+	RequestRebirth bool `json:"requestRebirth"`
+	// The signature verification algorithm of the stream. The user properties are only attached to the meta when it is set.
+	Verification string `json:"verification"`
 }
 
 func (ms *SourceConnector) Provision(ctx api.StreamContext, props map[string]any) error {
@@ -67,6 +77,9 @@ func (ms *SourceConnector) Provision(ctx api.StreamContext, props map[string]any
 		}
 		ctx.GetLogger().Infof("Set eof message to %x", ms.eofPayload)
 	}
+	if strings.EqualFold(cfg.Format, message.FormatSparkplugB) && cfg.RequestRebirth {
+		ms.sp = newSparkplugTracker()
+	}
 	ms.props = props
 	ms.cfg = cfg
 	ms.tpc = cfg.Topic
@@ -123,10 +136,34 @@ func (ms *SourceConnector) onMessage(ctx api.StreamContext, msg any, ingest api.
 		if tid, ok := props["traceparent"]; ok {
 			meta["traceId"] = tid
 		}
+		// user properties of MQTT v5 which carry the signature
+		if ms.cfg.Verification != "" {
+			meta["properties"] = props
+		}
+	}
+	if ms.sp != nil {
+		if tpc, ok := meta["topic"].(string); ok {
+			if t := ms.sp.track(ctx, tpc, payload, rcvTime); t != nil {
+				// publish waits for the ack, do not block the subscription callback
+				go ms.requestRebirth(ctx, t)
+			}
+		}
 	}
 	ingest(ctx, payload, meta, rcvTime)
 }
 
+func (ms *SourceConnector) requestRebirth(ctx api.StreamContext, t *sparkplugb.Topic) {
+	tpc, payload, err := sparkplugb.RebirthRequest(t, timex.GetNow().UnixMilli())
+	if err == nil {
+		err = ms.cli.Publish(ctx, tpc, byte(ms.cfg.Qos), false, payload, nil)
+	}
+	if err != nil {
+		ctx.GetLogger().Errorf("request rebirth from %s failed: %v", tpc, err)
+	} else {
+		ctx.GetLogger().Infof("request rebirth from %s", tpc)
+	}
+}
+
 func (ms *SourceConnector) Close(ctx api.StreamContext) error {
 	ctx.GetLogger().Infof("Closing mqtt source connector to topic %s.", ms.tpc)
 	if ms.cli != nil {
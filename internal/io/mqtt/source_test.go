@@ -51,9 +51,10 @@ func TestProvision(t *testing.T) {
 	require.NoError(t, store.SetupDefault(dataDir))
 	require.NoError(t, connection.InitConnectionManager4Test())
 	tests := []struct {
-		name  string
-		props map[string]any
-		err   string
+		name    string
+		props   map[string]any
+		err     string
+		rebirth bool
 	}{
 		{
 			name: "Valid configuration",
@@ -79,17 +80,36 @@ func TestProvision(t *testing.T) {
 			},
 			err: "illegal base64 data at input byte 0",
 		},
+		{
+			name: "sparkplug without rebirth request",
+			props: map[string]any{
+				"server":     url,
+				"datasource": "spBv1.0/g1/#",
+				"format":     "sparkplugb",
+			},
+		},
+		{
+			name: "sparkplug with rebirth request",
+			props: map[string]any{
+				"server":         url,
+				"datasource":     "spBv1.0/g1/#",
+				"format":         "sparkplugb",
+				"requestRebirth": true,
+			},
+			rebirth: true,
+		},
 	}
-	sc := &SourceConnector{}
 	ctx := mockContext.NewMockContext("testprov", "source")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			sc := &SourceConnector{}
 			err := sc.Provision(ctx, tt.props)
 			if tt.err != "" {
 				assert.Error(t, err)
 				require.True(t, strings.HasPrefix(err.Error(), tt.err))
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.rebirth, sc.sp != nil)
 			}
 		})
 	}
@@ -0,0 +1,90 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/sparkplugb"
+)
+
+// rebirthInterval is the minimum interval to request rebirth from the same edge node again
+const rebirthInterval = 10 * time.Second
+
+// sparkplugTracker tracks the sequence numbers of the Sparkplug B edge nodes. When a message is missing
+// or the data arrives before the birth certificate, it asks the edge node to rebirth so that
+// the aliases can be resolved again.
+type sparkplugTracker struct {
+	nodes map[string]*edgeNode
+}
+
+type edgeNode struct {
+	born bool
+	seq  uint64
+	// the last time to request rebirth
+	rebirthAt time.Time
+}
+
+func newSparkplugTracker() *sparkplugTracker {
+	return &sparkplugTracker{nodes: make(map[string]*edgeNode)}
+}
+
+// track checks the message and returns the edge node topic to request rebirth if needed.
+// The messages are handled in order by the subscription callback, so there is no lock.
+func (t *sparkplugTracker) track(ctx api.StreamContext, topic string, payload []byte, now time.Time) *sparkplugb.Topic {
+	tpc, err := sparkplugb.ParseTopic(topic)
+	if err != nil {
+		ctx.GetLogger().Debugf("ignore non sparkplug topic %s: %v", topic, err)
+		return nil
+	}
+	if tpc.IsCommand() {
+		return nil
+	}
+	key := tpc.NodeKey()
+	n, ok := t.nodes[key]
+	if !ok {
+		n = &edgeNode{}
+		t.nodes[key] = n
+	}
+	if tpc.MessageType == sparkplugb.NDEATH {
+		n.born = false
+		return nil
+	}
+	seq, hasSeq, err := sparkplugb.PeekSeq(payload)
+	if err != nil {
+		ctx.GetLogger().Warnf("invalid sparkplug payload of %s: %v", topic, err)
+		return nil
+	}
+	if tpc.MessageType == sparkplugb.NBIRTH {
+		n.born = true
+		n.seq = seq
+		return nil
+	}
+	if n.born {
+		if hasSeq && seq == (n.seq+1)%256 {
+			n.seq = seq
+			return nil
+		}
+		ctx.GetLogger().Warnf("sparkplug message %s is out of order, expect seq %d but got %d", topic, (n.seq+1)%256, seq)
+		n.born = false
+	}
+	if now.Sub(n.rebirthAt) < rebirthInterval {
+		return nil
+	}
+	n.rebirthAt = now
+	return tpc
+}
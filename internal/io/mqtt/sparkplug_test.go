@@ -0,0 +1,66 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/sparkplugb"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestSparkplugTracker(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	tr := newSparkplugTracker()
+	now := time.UnixMilli(0)
+	payload := func(seq uint64) []byte {
+		b, err := sparkplugb.Marshal(&sparkplugb.Payload{Seq: seq, HasSeq: true})
+		require.NoError(t, err)
+		return b
+	}
+	rebirth := &sparkplugb.Topic{GroupId: "g1", MessageType: sparkplugb.NDATA, EdgeNodeId: "e1"}
+	tests := []struct {
+		name    string
+		topic   string
+		payload []byte
+		elapsed time.Duration
+		rebirth *sparkplugb.Topic
+	}{
+		{name: "data before birth", topic: "spBv1.0/g1/NDATA/e1", payload: payload(5), rebirth: rebirth},
+		{name: "throttled", topic: "spBv1.0/g1/NDATA/e1", payload: payload(6), elapsed: time.Second},
+		{name: "birth", topic: "spBv1.0/g1/NBIRTH/e1", payload: payload(0)},
+		{name: "dbirth", topic: "spBv1.0/g1/DBIRTH/e1/d1", payload: payload(1)},
+		{name: "ddata", topic: "spBv1.0/g1/DDATA/e1/d1", payload: payload(2)},
+		{name: "command ignored", topic: "spBv1.0/g1/DCMD/e1/d1", payload: payload(9)},
+		{name: "not sparkplug", topic: "a/b", payload: []byte("x")},
+		{name: "other node", topic: "spBv1.0/g1/NBIRTH/e2", payload: payload(0)},
+		{name: "gap", topic: "spBv1.0/g1/NDATA/e1", payload: payload(4), elapsed: rebirthInterval, rebirth: rebirth},
+		{name: "unborn after gap", topic: "spBv1.0/g1/NDATA/e1", payload: payload(5)},
+		{name: "rebirth", topic: "spBv1.0/g1/NBIRTH/e1", payload: payload(255)},
+		{name: "wrap", topic: "spBv1.0/g1/NDATA/e1", payload: payload(0)},
+		{name: "death", topic: "spBv1.0/g1/NDEATH/e2", payload: []byte{}},
+		{name: "data after death", topic: "spBv1.0/g1/NDATA/e2", payload: payload(1), rebirth: &sparkplugb.Topic{GroupId: "g1", MessageType: sparkplugb.NDATA, EdgeNodeId: "e2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.elapsed)
+			r := tr.track(ctx, tt.topic, tt.payload, now)
+			require.Equal(t, tt.rebirth, r)
+		})
+	}
+}
@@ -44,12 +44,18 @@ type RuleOption struct {
 	NotifySub                 bool                     `json:"notifySub,omitempty" yaml:"notifySub,omitempty"`
 	DisableBufferFullDiscard  bool                     `json:"disableBufferFullDiscard,omitempty" yaml:"disableBufferFullDiscard,omitempty"`
 	EnableSaveStateBeforeStop bool                     `json:"enableSaveStateBeforeStop,omitempty" yaml:"enableSaveStateBeforeStop,omitempty"`
+	BatchExecution            bool                     `json:"batchExecution,omitempty" yaml:"batchExecution,omitempty"`
+	// MemoryBudget is the estimated memory in bytes for the rows kept by the window and table join operators. 0 means no limit
+	MemoryBudget int64 `json:"memoryBudget,omitempty" yaml:"memoryBudget,omitempty"`
+	// Backpressure pauses the sources when the buffers are nearly full instead of dropping messages
+	Backpressure bool `json:"backpressure,omitempty" yaml:"backpressure,omitempty"`
 }
 
 type PlanOptimizeStrategy struct {
 	EnableIncrementalWindow bool `json:"enableIncrementalWindow" yaml:"enableIncrementalWindow"`
 	EnableAliasPushdown     bool `json:"enableAliasPushdown,omitempty" yaml:"enableAliasPushdown,omitempty"`
 	DisableAliasRefCal      bool `json:"disableAliasRefCal,omitempty" yaml:"disableAliasRefCal,omitempty"`
+	EnableSharedPrefix      bool `json:"enableSharedPrefix,omitempty" yaml:"enableSharedPrefix,omitempty"`
 }
 
 func (p *PlanOptimizeStrategy) IsAliasRefCalEnable() bool {
@@ -59,6 +65,14 @@ func (p *PlanOptimizeStrategy) IsAliasRefCalEnable() bool {
 	return !p.DisableAliasRefCal
 }
 
+// IsSharedPrefixEnable returns whether the operators above a shared stream can be shared with other rules
+func (p *PlanOptimizeStrategy) IsSharedPrefixEnable() bool {
+	if p == nil {
+		return false
+	}
+	return p.EnableSharedPrefix
+}
+
 type RestartStrategy struct {
 	Attempts     int               `json:"attempts,omitempty" yaml:"attempts,omitempty"`
 	Delay        cast.DurationConf `json:"delay,omitempty" yaml:"delay,omitempty"`
@@ -17,11 +17,15 @@ package def
 type SchemaType string
 
 const (
-	PROTOBUF SchemaType = "protobuf"
-	CUSTOM   SchemaType = "custom"
+	PROTOBUF   SchemaType = "protobuf"
+	CUSTOM     SchemaType = "custom"
+	AVRO       SchemaType = "avro"
+	JSONSCHEMA SchemaType = "jsonschema"
 )
 
 var SchemaTypes = []SchemaType{
 	PROTOBUF,
 	CUSTOM,
+	AVRO,
+	JSONSCHEMA,
 }
@@ -38,3 +38,9 @@ type ExportDataDesc struct {
 	Rules    []string
 	FileName string
 }
+
+type SavepointDesc struct {
+	Rule     string
+	Name     string
+	FileName string
+}
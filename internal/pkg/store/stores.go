@@ -106,7 +106,7 @@ func (s *stores) DropKV(table string) {
 
 	if ks, contains := s.kv[table]; contains {
 		_ = ks.Drop()
-		delete(s.ts, table)
+		delete(s.kv, table)
 	}
 }
 
@@ -196,6 +196,7 @@ func DropCacheKVForRule(rule string) error {
 		return fmt.Errorf("cache stores are not initialized")
 	}
 	cacheStores.DropRefKVs(path.Join("sink", rule))
+	cacheStores.DropRefKVs(path.Join("spill", rule))
 	return nil
 }
 
@@ -43,9 +43,13 @@ func (m *Manager) SourcePluginInfo(name string) (plugin.EXTENSION_TYPE, string,
 	}
 }
 
-func (m *Manager) LookupSource(_ string) (api.Source, error) {
-	// TODO add support
-	return nil, nil
+// LookupSource shares the source symbol name. The plugin must register the lookup symbol with the same name.
+func (m *Manager) LookupSource(name string) (api.Source, error) {
+	meta, ok := m.GetPluginMeta(plugin.SOURCE, name)
+	if !ok {
+		return nil, nil
+	}
+	return runtime.NewPortableLookupSource(name, meta), nil
 }
 
 func (m *Manager) Sink(name string) (api.Sink, error) {
@@ -119,6 +119,13 @@ type DataOutChannel interface {
 	Closable
 }
 
+// DataRepChannel serves the requests from the plugin
+type DataRepChannel interface {
+	Recv() ([]byte, error)
+	Send([]byte) error
+	Closable
+}
+
 type DataReqChannel interface {
 	Req([]byte) ([]byte, error)
 	Closable
@@ -210,6 +217,51 @@ func CreateFunctionChannel(symbolName string) (DataReqChannel, error) {
 	return &NanomsgReqRepChannel{sock: sock}, nil
 }
 
+// CreateLookupChannel creates the channel to send lookup requests to the lookup source symbol
+func CreateLookupChannel(m Meta) (DataReqChannel, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = rep.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new rep socket: %s", err)
+	}
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionRecvDeadline: conf.Config.Portable.RecvTimeout,
+		mangos.OptionSendDeadline: conf.Config.Portable.SendTimeout,
+		mangos.OptionRetryTime:    0,
+		mangos.OptionMaxRecvSize:  0,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_lookup.ipc", m.RuleId, m.OpId, m.InstanceId)
+	if err = listenWithRetry(sock, url); err != nil {
+		return nil, fmt.Errorf("can't listen on rep socket for %s: %s", url, err.Error())
+	}
+	conf.Log.Infof("lookup channel created: %s", url)
+	return &NanomsgReqRepChannel{sock: sock}, nil
+}
+
+// CreateStateChannel creates the channel to serve the state requests from the symbol of the rule
+func CreateStateChannel(ctx api.StreamContext) (DataRepChannel, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = rep.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new rep socket: %s", err)
+	}
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionRecvDeadline: conf.Config.Portable.RecvTimeout,
+		mangos.OptionSendDeadline: conf.Config.Portable.SendTimeout,
+		mangos.OptionMaxRecvSize:  0,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_state.ipc", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if err = listenWithRetry(sock, url); err != nil {
+		return nil, fmt.Errorf("can't listen on rep socket for %s: %s", url, err.Error())
+	}
+	conf.Log.Infof("state channel created: %s", url)
+	return sock, nil
+}
+
 func CreateSinkChannel(ctx api.StreamContext) (DataOutChannel, error) {
 	var (
 		sock mangos.Socket
@@ -0,0 +1,154 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+)
+
+// lookupSeq distinguishes the lookup sources of lookup tables which are not bound to any rule
+var lookupSeq atomic.Uint64
+
+// PortableLookupSource sends the lookup requests to the lookup symbol of the plugin and waits for the result
+type PortableLookupSource struct {
+	symbolName string
+	reg        *PluginMeta
+	props      map[string]any
+	topic      string
+	dataCh     DataReqChannel
+	clean      func() error
+}
+
+func NewPortableLookupSource(symbolName string, reg *PluginMeta) *PortableLookupSource {
+	return &PortableLookupSource{
+		symbolName: symbolName,
+		reg:        reg,
+	}
+}
+
+func (ps *PortableLookupSource) Provision(_ api.StreamContext, configs map[string]any) error {
+	ps.props = configs
+	if ds, ok := configs["datasource"].(string); ok {
+		ps.topic = ds
+	}
+	return nil
+}
+
+func (ps *PortableLookupSource) Connect(ctx api.StreamContext, _ api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Start running portable lookup source %s with datasource %s and conf %+v", ps.symbolName, ps.topic, ps.props)
+	pm := GetPluginInsManager()
+	ins, err := pm.GetOrStartProcess(ps.reg, PortbleConf)
+	if err != nil {
+		return err
+	}
+	m := Meta{
+		RuleId:     ctx.GetRuleId(),
+		OpId:       ctx.GetOpId(),
+		InstanceId: ctx.GetInstanceId(),
+	}
+	// lookup table is shared by rules
+	if m.RuleId == "" || m.OpId == "" {
+		m.RuleId = "lookup"
+		m.OpId = fmt.Sprintf("%s_%d", ps.symbolName, lookupSeq.Add(1))
+	}
+	dataCh, err := CreateLookupChannel(m)
+	if err != nil {
+		return err
+	}
+	c := &Control{
+		Meta:       m,
+		SymbolName: ps.symbolName,
+		PluginType: TYPE_LOOKUP,
+		DataSource: ps.topic,
+		Config:     ps.props,
+	}
+	err = ins.StartSymbol(ctx, c)
+	if err != nil {
+		_ = dataCh.Close()
+		return err
+	}
+	ps.dataCh = dataCh
+	ps.clean = func() error {
+		ctx.GetLogger().Info("clean up lookup source")
+		err1 := dataCh.Close()
+		err2 := ins.StopSymbol(ctx, c)
+		if err1 != nil {
+			err1 = fmt.Errorf("%s:%v", "dataCh", err1)
+		}
+		if err2 != nil {
+			err2 = fmt.Errorf("%s:%v", "symbol", err2)
+		}
+		return errors.Join(err1, err2)
+	}
+	return nil
+}
+
+func (ps *PortableLookupSource) Lookup(ctx api.StreamContext, fields []string, keys []string, values []any) ([]map[string]any, error) {
+	ctx.GetLogger().Debugf("lookup portable source %s with keys %v and values %v", ps.symbolName, keys, values)
+	arg, err := json.Marshal(&LookupData{
+		Fields: fields,
+		Keys:   keys,
+		Values: values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := ps.dataCh.Req(arg)
+	if err != nil {
+		return nil, handleTimeout(err, ps.reg.Name)
+	}
+	return decodeLookupReply(res)
+}
+
+func decodeLookupReply(res []byte) ([]map[string]any, error) {
+	fr := &struct {
+		State  bool            `json:"state"`
+		Result json.RawMessage `json:"result"`
+	}{}
+	err := json.Unmarshal(res, fr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lookup result %s: %v", string(res), err)
+	}
+	if !fr.State {
+		var msg string
+		if e := json.Unmarshal(fr.Result, &msg); e != nil {
+			msg = string(fr.Result)
+		}
+		return nil, fmt.Errorf("lookup error: %s", msg)
+	}
+	var result []map[string]any
+	if len(fr.Result) > 0 {
+		err = json.Unmarshal(fr.Result, &result)
+		if err != nil {
+			return nil, fmt.Errorf("lookup result must be an array of objects but got %s", string(fr.Result))
+		}
+	}
+	return result, nil
+}
+
+func (ps *PortableLookupSource) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing lookup source %s", ps.symbolName)
+	if ps.clean != nil {
+		return ps.clean()
+	}
+	return nil
+}
+
+var _ api.LookupSource = &PortableLookupSource{}
@@ -0,0 +1,139 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.nanomsg.org/mangos/v3"
+	"go.nanomsg.org/mangos/v3/protocol/req"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+)
+
+func TestLookup(t *testing.T) {
+	sctx := context.Background().WithMeta("rule1", "op1", &state.MemoryStore{})
+	m := Meta{RuleId: "rule1", OpId: "op1"}
+	ch, err := CreateLookupChannel(m)
+	require.NoError(t, err)
+	defer ch.Close()
+	client, err := createMockLookupClient(m)
+	require.NoError(t, err)
+	defer client.Close()
+	go func() {
+		_ = runMockLookupClient(client)
+	}()
+	ps := NewPortableLookupSource("mock", &PluginMeta{Name: "mock"})
+	ps.dataCh = ch
+
+	r, err := ps.Lookup(sctx, []string{"a", "b"}, []string{"id"}, []any{1})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"a": 1.0, "b": "id"}}, r)
+
+	_, err = ps.Lookup(sctx, []string{"a"}, []string{"id"}, []any{2})
+	assert.EqualError(t, err, "lookup error: key 2 not found")
+}
+
+func TestDecodeLookupReply(t *testing.T) {
+	tests := []struct {
+		name string
+		res  string
+		exp  []map[string]any
+		err  string
+	}{
+		{
+			name: "normal",
+			res:  `{"state":true,"result":[{"a":1},{"a":2}]}`,
+			exp:  []map[string]any{{"a": 1.0}, {"a": 2.0}},
+		},
+		{
+			name: "empty",
+			res:  `{"state":true,"result":null}`,
+		},
+		{
+			name: "error",
+			res:  `{"state":false,"result":"db error"}`,
+			err:  "lookup error: db error",
+		},
+		{
+			name: "invalid result",
+			res:  `{"state":true,"result":{"a":1}}`,
+			err:  `lookup result must be an array of objects but got {"a":1}`,
+		},
+		{
+			name: "invalid reply",
+			res:  `abc`,
+			err:  "invalid lookup result abc: invalid character 'a' looking for beginning of value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := decodeLookupReply([]byte(tt.res))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.exp, r)
+		})
+	}
+}
+
+func createMockLookupClient(m Meta) (mangos.Socket, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = req.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new req socket: %s", err)
+	}
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_lookup.ipc", m.RuleId, m.OpId, m.InstanceId)
+	if err = sock.Dial(url); err != nil {
+		return nil, fmt.Errorf("can't dial on req socket: %s", err.Error())
+	}
+	return sock, nil
+}
+
+// runMockLookupClient works like the lookup symbol in the plugin which returns the key as field b
+func runMockLookupClient(sock mangos.Socket) error {
+	err := sock.Send([]byte("handshake"))
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := sock.Recv()
+		if err != nil {
+			return err
+		}
+		d := &LookupData{}
+		if err := json.Unmarshal(msg, d); err != nil {
+			return err
+		}
+		var reply []byte
+		if d.Values[0] == 1.0 {
+			reply = encodeReply(true, []map[string]any{{"a": 1, "b": d.Keys[0]}})
+		} else {
+			reply = encodeReply(false, fmt.Sprintf("key %v not found", d.Values[0]))
+		}
+		if err := sock.Send(reply); err != nil {
+			return err
+		}
+	}
+}
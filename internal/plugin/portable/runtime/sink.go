@@ -65,6 +65,12 @@ func (ps *PortableSink) Connect(ctx api.StreamContext, _ api.StatusChangeHandler
 	if err != nil {
 		return err
 	}
+	stateCh, err := CreateStateChannel(ctx)
+	if err != nil {
+		_ = ackCh.Close()
+		return err
+	}
+	go serveState(ctx, stateCh)
 
 	// Control: send message to plugin to ask starting symbol
 	c := &Control{
@@ -79,6 +85,8 @@ func (ps *PortableSink) Connect(ctx api.StreamContext, _ api.StatusChangeHandler
 	}
 	err = ins.StartSymbol(ctx, c)
 	if err != nil {
+		_ = ackCh.Close()
+		_ = stateCh.Close()
 		return err
 	}
 
@@ -86,6 +94,8 @@ func (ps *PortableSink) Connect(ctx api.StreamContext, _ api.StatusChangeHandler
 	dataCh, err := CreateSinkChannel(ctx)
 	if err != nil {
 		_ = ins.StopSymbol(ctx, c)
+		_ = ackCh.Close()
+		_ = stateCh.Close()
 		return err
 	}
 
@@ -94,6 +104,7 @@ func (ps *PortableSink) Connect(ctx api.StreamContext, _ api.StatusChangeHandler
 		err1 := dataCh.Close()
 		err2 := ackCh.Close()
 		err3 := ins.StopSymbol(ctx, c)
+		err4 := stateCh.Close()
 		if err1 != nil {
 			err1 = fmt.Errorf("%s:%v", "close dataCh error", err1)
 		}
@@ -103,7 +114,10 @@ func (ps *PortableSink) Connect(ctx api.StreamContext, _ api.StatusChangeHandler
 		if err3 != nil {
 			err3 = fmt.Errorf("%s:%v", "close symbol error", err3)
 		}
-		return errors.Join(err1, err2, err3)
+		if err4 != nil {
+			err4 = fmt.Errorf("%s:%v", "close stateCh error", err4)
+		}
+		return errors.Join(err1, err2, err3, err4)
 	}
 	ps.dataCh = dataCh
 	ps.ackCh = ackCh
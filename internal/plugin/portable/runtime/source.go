@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"go.nanomsg.org/mangos/v3"
@@ -35,11 +36,20 @@ type PortableSource struct {
 
 	topic string
 	props map[string]any
+
+	ins     *PluginIns
+	ctrl    *Control
+	started bool
+	// offset is the latest offset reported by the symbol along with the message.
+	// It is updated by the receiving goroutine and read by the checkpoint.
+	offsetLock sync.RWMutex
+	offset     any
 }
 
 type messageWrapper struct {
 	Message map[string]any `json:"message"`
 	Meta    map[string]any `json:"meta"`
+	Offset  any            `json:"offset,omitempty"`
 }
 
 func (ps *PortableSource) Provision(ctx api.StreamContext, configs map[string]any) error {
@@ -61,9 +71,16 @@ func (ps *PortableSource) Connect(ctx api.StreamContext, _ api.StatusChangeHandl
 	if err != nil {
 		return err
 	}
+	stateCh, err := CreateStateChannel(ctx)
+	if err != nil {
+		_ = dataCh.Close()
+		return err
+	}
+	go serveState(ctx, stateCh)
 
-	// Control: send message to plugin to ask starting symbol
-	c := &Control{
+	// The symbol is started in Subscribe so that the offset to rewind can be sent along
+	ps.ins = ins
+	ps.ctrl = &Control{
 		Meta: Meta{
 			RuleId:     ctx.GetRuleId(),
 			OpId:       ctx.GetOpId(),
@@ -74,29 +91,40 @@ func (ps *PortableSource) Connect(ctx api.StreamContext, _ api.StatusChangeHandl
 		DataSource: ps.topic,
 		Config:     ps.props,
 	}
-	err = ins.StartSymbol(ctx, c)
-	if err != nil {
-		ctx.GetLogger().Error(err)
-		_ = dataCh.Close()
-		return err
-	}
 	ps.dataCh = dataCh
 	ps.clean = func() error {
 		ctx.GetLogger().Info("clean up source")
 		err1 := dataCh.Close()
-		err2 := ins.StopSymbol(ctx, c)
+		err2 := stateCh.Close()
+		var err3 error
+		if ps.started {
+			err3 = ins.StopSymbol(ctx, ps.ctrl)
+		}
 		if err1 != nil {
 			err1 = fmt.Errorf("%s:%v", "dataCh", err1)
 		}
 		if err2 != nil {
-			err2 = fmt.Errorf("%s:%v", "symbol", err2)
+			err2 = fmt.Errorf("%s:%v", "stateCh", err2)
+		}
+		if err3 != nil {
+			err3 = fmt.Errorf("%s:%v", "symbol", err3)
 		}
-		return errors.Join(err1, err2)
+		return errors.Join(err1, err2, err3)
 	}
 	return nil
 }
 
 func (ps *PortableSource) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	// Control: send message to plugin to ask starting symbol
+	ps.offsetLock.RLock()
+	ps.ctrl.Offset = ps.offset
+	ps.offsetLock.RUnlock()
+	err := ps.ins.StartSymbol(ctx, ps.ctrl)
+	if err != nil {
+		ctx.GetLogger().Error(err)
+		return err
+	}
+	ps.started = true
 	for {
 		var msg []byte
 		// make sure recv has timeout
@@ -128,6 +156,11 @@ func (ps *PortableSource) Subscribe(ctx api.StreamContext, ingest api.TupleInges
 					ingestError(ctx, e)
 					continue
 				}
+				if result.Offset != nil {
+					ps.offsetLock.Lock()
+					ps.offset = result.Offset
+					ps.offsetLock.Unlock()
+				}
 				ingest(ctx, result.Message, result.Meta, rcvTime)
 			}
 		}
@@ -147,6 +180,25 @@ func (ps *PortableSource) Configure(topic string, props map[string]interface{})
 	return nil
 }
 
+// GetOffset returns the offset of the last message. It is saved in the rule checkpoint.
+func (ps *PortableSource) GetOffset() (any, error) {
+	ps.offsetLock.RLock()
+	defer ps.offsetLock.RUnlock()
+	return ps.offset, nil
+}
+
+// Rewind records the offset which is sent to the symbol when starting it
+func (ps *PortableSource) Rewind(offset any) error {
+	ps.offsetLock.Lock()
+	defer ps.offsetLock.Unlock()
+	ps.offset = offset
+	return nil
+}
+
+func (ps *PortableSource) ResetOffset(_ map[string]any) error {
+	return fmt.Errorf("portable source %s does not support reset offset", ps.symbolName)
+}
+
 func (ps *PortableSource) Close(ctx api.StreamContext) error {
 	ctx.GetLogger().Infof("Closing source %s", ps.symbolName)
 	if ps.clean != nil {
@@ -155,4 +207,7 @@ func (ps *PortableSource) Close(ctx api.StreamContext) error {
 	return nil
 }
 
-var _ api.TupleSource = &PortableSource{}
+var (
+	_ api.TupleSource = &PortableSource{}
+	_ api.Rewindable  = &PortableSource{}
+)
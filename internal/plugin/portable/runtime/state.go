@@ -0,0 +1,81 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"go.nanomsg.org/mangos/v3"
+)
+
+// serveState answers the state requests from the symbol with the state of the rule until the channel is closed.
+// The state is saved along with the rule checkpoint, so the symbol can get it back after the rule restarts.
+func serveState(ctx api.StreamContext, ch DataRepChannel) {
+	for {
+		msg, err := ch.Recv()
+		switch err {
+		case nil:
+			// do nothing
+		case mangos.ErrRecvTimeout:
+			continue
+		case mangos.ErrClosed:
+			ctx.GetLogger().Info("stop state service after close")
+			return
+		default:
+			ctx.GetLogger().Errorf("state channel receive error: %v", err)
+			return
+		}
+		err = ch.Send(handleState(ctx, msg))
+		if err != nil {
+			ctx.GetLogger().Errorf("state channel reply error: %v", err)
+			if err == mangos.ErrClosed {
+				return
+			}
+		}
+	}
+}
+
+func handleState(ctx api.StreamContext, msg []byte) []byte {
+	d := &StateData{}
+	err := json.Unmarshal(msg, d)
+	if err != nil {
+		return encodeReply(false, fmt.Sprintf("invalid state request %s: %v", string(msg), err))
+	}
+	var result interface{}
+	switch d.Cmd {
+	case STATE_GET:
+		result, err = ctx.GetState(d.Key)
+	case STATE_PUT:
+		err = ctx.PutState(d.Key, d.Value)
+	case STATE_DELETE:
+		err = ctx.DeleteState(d.Key)
+	default:
+		err = fmt.Errorf("invalid state command %s", d.Cmd)
+	}
+	if err != nil {
+		return encodeReply(false, err.Error())
+	}
+	return encodeReply(true, result)
+}
+
+func encodeReply(state bool, arg interface{}) []byte {
+	r, _ := json.Marshal(FuncReply{
+		State:  state,
+		Result: arg,
+	})
+	return r
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.nanomsg.org/mangos/v3"
+	"go.nanomsg.org/mangos/v3/protocol/req"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+)
+
+func TestStateChannel(t *testing.T) {
+	sctx := context.Background().WithMeta("rule1", "op1", &state.MemoryStore{}).WithInstance(1)
+	ch, err := CreateStateChannel(sctx)
+	require.NoError(t, err)
+	go serveState(sctx, ch)
+	defer ch.Close()
+	client, err := createMockStateClient(sctx)
+	require.NoError(t, err)
+	defer client.Close()
+
+	tests := []struct {
+		name string
+		req  string
+		exp  FuncReply
+	}{
+		{
+			name: "get not exist",
+			req:  `{"cmd":"get","key":"count"}`,
+			exp:  FuncReply{State: true},
+		},
+		{
+			name: "put",
+			req:  `{"cmd":"put","key":"count","value":3}`,
+			exp:  FuncReply{State: true},
+		},
+		{
+			name: "get",
+			req:  `{"cmd":"get","key":"count"}`,
+			exp:  FuncReply{State: true, Result: 3.0},
+		},
+		{
+			name: "delete",
+			req:  `{"cmd":"delete","key":"count"}`,
+			exp:  FuncReply{State: true},
+		},
+		{
+			name: "get after delete",
+			req:  `{"cmd":"get","key":"count"}`,
+			exp:  FuncReply{State: true},
+		},
+		{
+			name: "invalid cmd",
+			req:  `{"cmd":"incr","key":"count"}`,
+			exp:  FuncReply{State: false, Result: "invalid state command incr"},
+		},
+		{
+			name: "invalid request",
+			req:  `{"cmd":`,
+			exp:  FuncReply{State: false, Result: "invalid state request {\"cmd\":: unexpected end of JSON input"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, client.Send([]byte(tt.req)))
+			msg, err := client.Recv()
+			require.NoError(t, err)
+			r := FuncReply{}
+			require.NoError(t, json.Unmarshal(msg, &r))
+			assert.Equal(t, tt.exp, r)
+		})
+	}
+}
+
+func createMockStateClient(ctx api.StreamContext) (mangos.Socket, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = req.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new req socket: %s", err)
+	}
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionRecvDeadline: 1000 * time.Millisecond,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_state.ipc", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if err = sock.Dial(url); err != nil {
+		return nil, fmt.Errorf("can't dial on req socket: %s", err.Error())
+	}
+	return sock, nil
+}
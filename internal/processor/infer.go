@@ -0,0 +1,512 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+const (
+	defaultInferSamples = 10
+	maxInferSamples     = 1000
+	defaultInferTimeout = 10 * time.Second
+	maxInferTimeout     = time.Minute
+
+	DriftAdded   = "added"
+	DriftChanged = "changed"
+	DriftMissing = "missing"
+
+	BaselineStream    = "stream"
+	BaselineInference = "inference"
+)
+
+// InferOptions are the options to infer the schema of a stream from the sampled messages
+type InferOptions struct {
+	// Samples is the count of messages to sample. Default to the INFER_SAMPLES option of the stream or 10.
+	Samples int `json:"samples"`
+	// Timeout is the max duration in milliseconds to wait for the samples. Default to 10 seconds and at most 1 minute.
+	Timeout int64 `json:"timeout"`
+	// Persist saves the inferred fields as the stream schema
+	Persist bool `json:"persist"`
+}
+
+// FieldDrift is a difference of a field between the baseline schema and the inferred schema
+type FieldDrift struct {
+	Field    string `json:"field"`
+	Kind     string `json:"kind"`
+	Previous string `json:"previous,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// InferResult is the schema inferred from the sampled messages of a stream
+type InferResult struct {
+	Samples  int                             `json:"samples"`
+	Fields   map[string]*ast.JsonStreamField `json:"fields"`
+	Nullable []string                        `json:"nullable,omitempty"`
+	// Untyped fields have conflicted types or only null values in the samples. They are not in the fields.
+	Untyped []string `json:"untyped,omitempty"`
+	// Baseline is the schema to detect drift from, either the declared stream schema or the last inference
+	Baseline  string       `json:"baseline,omitempty"`
+	Drift     []FieldDrift `json:"drift,omitempty"`
+	Persisted bool         `json:"persisted"`
+	Timestamp int64        `json:"timestamp"`
+}
+
+// InferStream samples the messages of the stream through its converter and infers the schema of them.
+// The inferred schema is compared with the declared schema of the stream or the last inferred schema to detect drift.
+func (p *StreamProcessor) InferStream(name string, opts *InferOptions) (r *InferResult, err error) {
+	defer func() {
+		if err != nil {
+			if _, ok := err.(errorx.ErrorWithCode); !ok {
+				err = errorx.NewWithCode(errorx.StreamTableError, err.Error())
+			}
+		}
+	}()
+	statement, err := p.GetStream(name, ast.TypeStream)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := xsql.NewParser(strings.NewReader(statement)).ParseCreateStmt()
+	if err != nil {
+		return nil, err
+	}
+	stmt, ok := parsed.(*ast.StreamStmt)
+	if !ok {
+		return nil, fmt.Errorf("invalid stream statement %s", statement)
+	}
+	if opts == nil {
+		opts = &InferOptions{}
+	}
+	size := opts.Samples
+	if size == 0 {
+		size = stmt.Options.INFER_SAMPLES
+	}
+	if size == 0 {
+		size = defaultInferSamples
+	}
+	if size < 0 || size > maxInferSamples {
+		return nil, fmt.Errorf("invalid samples %d, must be between 1 and %d", size, maxInferSamples)
+	}
+	timeout := defaultInferTimeout
+	if opts.Timeout < 0 || opts.Timeout > maxInferTimeout.Milliseconds() {
+		return nil, fmt.Errorf("invalid timeout %d, must be between 1 and %d", opts.Timeout, maxInferTimeout.Milliseconds())
+	} else if opts.Timeout > 0 {
+		timeout = time.Duration(opts.Timeout) * time.Millisecond
+	}
+
+	samples, err := p.sampleStream(name, statement, size, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("sample stream %s error: %v", name, err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no message is received from stream %s in %v", name, timeout)
+	}
+	inferer := NewSchemaInferer()
+	for _, m := range samples {
+		inferer.Add(m)
+	}
+	fields, nullable, untyped := inferer.Fields()
+	r = &InferResult{
+		Samples:   len(samples),
+		Fields:    fields.ToJsonSchema(),
+		Nullable:  nullable,
+		Untyped:   untyped,
+		Timestamp: timex.GetNowInMilli(),
+	}
+	if len(stmt.StreamFields) > 0 {
+		r.Baseline = BaselineStream
+		r.Drift = DetectDrift(stmt.StreamFields, fields)
+	} else if last, err := p.GetInference(name); err == nil {
+		var lastFields ast.StreamFields
+		if err := lastFields.UnmarshalFromMap(last.Fields); err == nil {
+			r.Baseline = BaselineInference
+			r.Drift = DetectDrift(lastFields, fields)
+		}
+	}
+	if opts.Persist {
+		if len(untyped) > 0 {
+			return nil, fmt.Errorf("cannot persist the schema of stream %s, fields %s have no determined type", name, strings.Join(untyped, ","))
+		}
+		newStatement, err := xsql.ReplaceStreamFields(statement, fields)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.ExecReplaceStream(name, newStatement, ast.TypeStream); err != nil {
+			return nil, err
+		}
+		r.Persisted = true
+	}
+	s, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("error when saving to db: %v.", err)
+	}
+	if err := p.inferDb.Set(name, string(s)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetInference returns the last inferred schema of the stream
+func (p *StreamProcessor) GetInference(name string) (*InferResult, error) {
+	var v string
+	ok, err := p.inferDb.Get(name, &v)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("stream %s has not been inferred", name))
+	}
+	r := &InferResult{}
+	if err := json.Unmarshal([]byte(v), r); err != nil {
+		return nil, fmt.Errorf("error unmarshall the inference of %s, the data in db may be corrupted", name)
+	}
+	return r, nil
+}
+
+// sampleStream runs a temporary rule to select the decoded messages of the stream.
+// The stream is planned as schemaless so that the fields out of the declared schema are kept.
+// The temporary rule reads the real source like any rule. It runs without checkpoint so no offset is saved by eKuiper,
+// but the sources consuming from a shared queue, such as a consumer group, may take the messages from the other
+// consumers and commit the offsets of the group by themselves.
+func (p *StreamProcessor) sampleStream(name, statement string, size int, timeout time.Duration) ([]map[string]any, error) {
+	schemaless, err := xsql.ReplaceStreamFields(statement, nil)
+	if err != nil {
+		return nil, err
+	}
+	info, err := json.Marshal(xsql.StreamInfo{StreamType: ast.TypeStream, Statement: schemaless})
+	if err != nil {
+		return nil, err
+	}
+	id := "$$_infer_" + uuid.New().String()
+	topic := "$$infer/" + id
+	rule := def.GetDefaultRule(id, fmt.Sprintf("SELECT * FROM `%s`", name))
+	rule.Actions = []map[string]any{
+		{
+			"memory": map[string]any{"topic": topic},
+		},
+	}
+	ch := pubsub.CreateSub(topic, nil, id, size)
+	defer pubsub.CloseSourceConsumerChannel(topic, id)
+	// The secrets referred by the source are recorded for the temporary rule during planning
+	defer secret.RemoveDependent(id)
+	tp, err := planner.PlanSQLWithStore(rule, &sampleStore{KeyValue: p.db, name: name, info: string(info)})
+	if err != nil {
+		return nil, err
+	}
+	defer tp.Cancel()
+	errCh := tp.Open()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	result := make([]map[string]any, 0, size)
+	collect := func(v any) {
+		switch t := v.(type) {
+		case pubsub.MemTuple:
+			result = append(result, t.ToMap())
+		case []pubsub.MemTuple:
+			for _, tt := range t {
+				if len(result) < size {
+					result = append(result, tt.ToMap())
+				}
+			}
+		}
+	}
+	for len(result) < size {
+		select {
+		case v := <-ch:
+			collect(v)
+		case err := <-errCh:
+			if errorx.IsUnexpectedErr(err) {
+				return nil, err
+			}
+			// The source is exhausted, collect the remaining outputs
+			for len(result) < size {
+				select {
+				case v := <-ch:
+					collect(v)
+				case <-timer.C:
+					return result, nil
+				}
+			}
+		case <-timer.C:
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// sampleStore overrides the definition of the sampled stream and reads the others from the stream db
+type sampleStore struct {
+	kv.KeyValue
+	name string
+	info string
+}
+
+func (s *sampleStore) Get(key string, val any) (bool, error) {
+	if v, ok := val.(*string); ok && key == s.name {
+		*v = s.info
+		return true, nil
+	}
+	return s.KeyValue.Get(key, val)
+}
+
+// SchemaInferer merges the types of the sampled messages into stream fields
+type SchemaInferer struct {
+	root *inferNode
+}
+
+type inferNode struct {
+	dt       ast.DataType
+	conflict bool
+	// the count of the values including null
+	seen int
+	null bool
+	// the count of the struct values whose sub fields are merged
+	structs int
+	keys    []string
+	fields  map[string]*inferNode
+	elem    *inferNode
+}
+
+func NewSchemaInferer() *SchemaInferer {
+	return &SchemaInferer{root: &inferNode{dt: ast.STRUCT}}
+}
+
+// Add merges the types of a sampled message
+func (s *SchemaInferer) Add(m map[string]any) {
+	s.root.add(m)
+}
+
+// Fields returns the inferred fields, the path of the nullable fields and the path of the fields without a determined type
+func (s *SchemaInferer) Fields() (fields ast.StreamFields, nullable []string, untyped []string) {
+	ft := s.root.build("", &nullable, &untyped)
+	if rt, ok := ft.(*ast.RecType); ok {
+		fields = rt.StreamFields
+	}
+	return fields, nullable, untyped
+}
+
+func (n *inferNode) add(v any) {
+	n.seen++
+	if v == nil {
+		n.null = true
+		return
+	}
+	dt := valueType(v)
+	switch {
+	case dt == ast.UNKNOWN:
+		n.conflict = true
+	case n.dt == ast.UNKNOWN || n.dt == dt:
+		n.dt = dt
+	case n.dt == ast.BIGINT && dt == ast.FLOAT:
+		n.dt = ast.FLOAT
+	case n.dt == ast.FLOAT && dt == ast.BIGINT:
+	default:
+		n.conflict = true
+	}
+	if n.conflict {
+		return
+	}
+	switch dt {
+	case ast.STRUCT:
+		n.structs++
+		if n.fields == nil {
+			n.fields = make(map[string]*inferNode)
+		}
+		for k, fv := range v.(map[string]any) {
+			f, ok := n.fields[k]
+			if !ok {
+				f = &inferNode{}
+				n.fields[k] = f
+				n.keys = append(n.keys, k)
+			}
+			f.add(fv)
+		}
+	case ast.ARRAY:
+		if n.elem == nil {
+			n.elem = &inferNode{}
+		}
+		rv := reflect.ValueOf(v)
+		for i := 0; i < rv.Len(); i++ {
+			n.elem.add(rv.Index(i).Interface())
+		}
+	}
+}
+
+func (n *inferNode) build(path string, nullable *[]string, untyped *[]string) ast.FieldType {
+	if n.conflict || n.dt == ast.UNKNOWN {
+		*untyped = append(*untyped, path)
+		return nil
+	}
+	switch n.dt {
+	case ast.STRUCT:
+		var fields ast.StreamFields
+		keys := make([]string, len(n.keys))
+		copy(keys, n.keys)
+		sort.Strings(keys)
+		for _, k := range keys {
+			f := n.fields[k]
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			ft := f.build(p, nullable, untyped)
+			if ft == nil {
+				continue
+			}
+			if f.null || f.seen < n.structs {
+				*nullable = append(*nullable, p)
+			}
+			fields = append(fields, ast.StreamField{Name: k, FieldType: ft})
+		}
+		if len(fields) == 0 {
+			if path != "" {
+				*untyped = append(*untyped, path)
+			}
+			return nil
+		}
+		return &ast.RecType{StreamFields: fields}
+	case ast.ARRAY:
+		if n.elem == nil || n.elem.seen == 0 {
+			*untyped = append(*untyped, path)
+			return nil
+		}
+		et := n.elem.build(path+"[]", nullable, untyped)
+		switch t := et.(type) {
+		case *ast.BasicType:
+			return &ast.ArrayType{Type: t.Type}
+		case *ast.RecType:
+			return &ast.ArrayType{Type: ast.STRUCT, FieldType: t}
+		case *ast.ArrayType:
+			return &ast.ArrayType{Type: ast.ARRAY, FieldType: t}
+		default:
+			return nil
+		}
+	default:
+		return &ast.BasicType{Type: n.dt}
+	}
+}
+
+func valueType(v any) ast.DataType {
+	switch t := v.(type) {
+	case bool:
+		return ast.BOOLEAN
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return ast.BIGINT
+	case float32:
+		return floatType(float64(t))
+	case float64:
+		return floatType(t)
+	case string:
+		return ast.STRINGS
+	case []byte:
+		return ast.BYTEA
+	case time.Time:
+		return ast.DATETIME
+	case map[string]any:
+		return ast.STRUCT
+	}
+	if reflect.TypeOf(v).Kind() == reflect.Slice {
+		return ast.ARRAY
+	}
+	return ast.UNKNOWN
+}
+
+// floatType infers the integral float such as the numbers decoded from JSON as bigint.
+// It is widened to float once a fractional value is sampled.
+func floatType(f float64) ast.DataType {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return ast.BIGINT
+	}
+	return ast.FLOAT
+}
+
+// DetectDrift compares the fields in the baseline with the current fields recursively
+func DetectDrift(baseline, current ast.StreamFields) []FieldDrift {
+	var result []FieldDrift
+	detectDrift("", baseline, current, &result)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Field < result[j].Field
+	})
+	return result
+}
+
+func detectDrift(path string, baseline, current ast.StreamFields, result *[]FieldDrift) {
+	prev := make(map[string]ast.FieldType, len(baseline))
+	for _, f := range baseline {
+		prev[f.Name] = f.FieldType
+	}
+	curr := make(map[string]struct{}, len(current))
+	for _, f := range current {
+		curr[f.Name] = struct{}{}
+		p := f.Name
+		if path != "" {
+			p = path + "." + f.Name
+		}
+		pt, ok := prev[f.Name]
+		if !ok {
+			*result = append(*result, FieldDrift{Field: p, Kind: DriftAdded, Current: printFieldType(f.FieldType)})
+			continue
+		}
+		if ps, cs, p, ok := subFields(pt, f.FieldType, p); ok {
+			detectDrift(p, ps, cs, result)
+			continue
+		}
+		if ptt, ctt := printFieldType(pt), printFieldType(f.FieldType); ptt != ctt {
+			*result = append(*result, FieldDrift{Field: p, Kind: DriftChanged, Previous: ptt, Current: ctt})
+		}
+	}
+	for _, f := range baseline {
+		if _, ok := curr[f.Name]; !ok {
+			p := f.Name
+			if path != "" {
+				p = path + "." + f.Name
+			}
+			*result = append(*result, FieldDrift{Field: p, Kind: DriftMissing, Previous: printFieldType(f.FieldType)})
+		}
+	}
+}
+
+// subFields returns the sub fields to compare if both types are struct or array of struct
+func subFields(prev, curr ast.FieldType, path string) (ast.StreamFields, ast.StreamFields, string, bool) {
+	switch pt := prev.(type) {
+	case *ast.RecType:
+		if ct, ok := curr.(*ast.RecType); ok {
+			return pt.StreamFields, ct.StreamFields, path, true
+		}
+	case *ast.ArrayType:
+		if ct, ok := curr.(*ast.ArrayType); ok && pt.FieldType != nil && ct.FieldType != nil {
+			return subFields(pt.FieldType, ct.FieldType, path+"[]")
+		}
+	}
+	return nil, nil, "", false
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestSchemaInferer(t *testing.T) {
+	inferer := NewSchemaInferer()
+	samples := []map[string]any{
+		{
+			"id":     float64(1),
+			"temp":   float64(20),
+			"tags":   []any{"a", "b"},
+			"meta":   map[string]any{"ok": true, "note": nil},
+			"mixed":  float64(1),
+			"points": []any{[]any{float64(1), 2.5}},
+			"empty":  []any{},
+			"ts":     time.UnixMilli(0),
+		},
+		{
+			"id":     int64(2),
+			"temp":   20.5,
+			"meta":   map[string]any{"ok": false, "items": []any{map[string]any{"k": "v"}}},
+			"mixed":  "x",
+			"points": []any{},
+			"raw":    []byte("a"),
+			"ts":     time.UnixMilli(1),
+			"null":   nil,
+		},
+	}
+	for _, s := range samples {
+		inferer.Add(s)
+	}
+	fields, nullable, untyped := inferer.Fields()
+	exp := ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "meta", FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+			{Name: "items", FieldType: &ast.ArrayType{Type: ast.STRUCT, FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+				{Name: "k", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+			}}}},
+			{Name: "ok", FieldType: &ast.BasicType{Type: ast.BOOLEAN}},
+		}}},
+		{Name: "points", FieldType: &ast.ArrayType{Type: ast.ARRAY, FieldType: &ast.ArrayType{Type: ast.FLOAT}}},
+		{Name: "raw", FieldType: &ast.BasicType{Type: ast.BYTEA}},
+		{Name: "tags", FieldType: &ast.ArrayType{Type: ast.STRINGS}},
+		{Name: "temp", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "ts", FieldType: &ast.BasicType{Type: ast.DATETIME}},
+	}
+	assert.Equal(t, exp, fields)
+	assert.Equal(t, []string{"meta.items", "raw", "tags"}, nullable)
+	assert.Equal(t, []string{"empty", "meta.note", "mixed", "null"}, untyped)
+}
+
+func TestDetectDrift(t *testing.T) {
+	baseline := ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "name", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "meta", FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+			{Name: "ok", FieldType: &ast.BasicType{Type: ast.BOOLEAN}},
+		}}},
+		{Name: "items", FieldType: &ast.ArrayType{Type: ast.STRUCT, FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+			{Name: "v", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		}}}},
+	}
+	current := ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "meta", FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+			{Name: "ok", FieldType: &ast.BasicType{Type: ast.BOOLEAN}},
+			{Name: "note", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		}}},
+		{Name: "items", FieldType: &ast.ArrayType{Type: ast.STRUCT, FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+			{Name: "v", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		}}}},
+		{Name: "extra", FieldType: &ast.ArrayType{Type: ast.BIGINT}},
+	}
+	exp := []FieldDrift{
+		{Field: "extra", Kind: DriftAdded, Current: "array(bigint)"},
+		{Field: "id", Kind: DriftChanged, Previous: "bigint", Current: "float"},
+		{Field: "items[].v", Kind: DriftChanged, Previous: "bigint", Current: "string"},
+		{Field: "meta.note", Kind: DriftAdded, Current: "string"},
+		{Field: "name", Kind: DriftMissing, Previous: "string"},
+	}
+	assert.Equal(t, exp, DetectDrift(baseline, current))
+	assert.Nil(t, DetectDrift(baseline, baseline))
+}
+
+func TestInferStream(t *testing.T) {
+	p := NewStreamProcessor()
+	_, err := p.ExecStmt(`CREATE STREAM inferTest () WITH (TYPE="memory", DATASOURCE="inferTopic", FORMAT="JSON", INFER_SAMPLES="2")`)
+	require.NoError(t, err)
+	defer p.ExecStmt("DROP STREAM inferTest")
+
+	_, err = p.InferStream("inferNone", nil)
+	assert.Equal(t, "inferNone is not found", err.Error())
+	_, err = p.InferStream("inferTest", &InferOptions{Samples: 2000})
+	assert.EqualError(t, err, "invalid samples 2000, must be between 1 and 1000")
+	_, err = p.InferStream("inferTest", &InferOptions{Timeout: 120000})
+	assert.EqualError(t, err, "invalid timeout 120000, must be between 1 and 60000")
+	_, err = p.GetInference("inferTest")
+	assert.EqualError(t, err, "stream inferTest has not been inferred")
+
+	publish := func(msgs []map[string]any) func() {
+		ctx := mockContext.NewMockContext("inferTest", "op")
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					pubsub.Produce(ctx, "inferTopic", &xsql.Tuple{Message: msgs[i%len(msgs)], Timestamp: timex.GetNow()})
+				}
+			}
+		}()
+		return func() {
+			close(done)
+			wg.Wait()
+		}
+	}
+
+	stop := publish([]map[string]any{
+		{"id": int64(1), "temp": 20.5, "meta": map[string]any{"ok": true}},
+		{"id": int64(2), "temp": 21.5},
+	})
+	r, err := p.InferStream("inferTest", &InferOptions{Timeout: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.Samples)
+	assert.Equal(t, []string{"meta"}, r.Nullable)
+	assert.Empty(t, r.Baseline)
+	assert.False(t, r.Persisted)
+	assert.Equal(t, map[string]*ast.JsonStreamField{
+		"id":   {Type: "bigint"},
+		"temp": {Type: "float"},
+		"meta": {Type: "struct", Properties: map[string]*ast.JsonStreamField{"ok": {Type: "boolean"}}},
+	}, r.Fields)
+	last, err := p.GetInference("inferTest")
+	require.NoError(t, err)
+	assert.Equal(t, r.Fields, last.Fields)
+
+	r, err = p.InferStream("inferTest", &InferOptions{Samples: 4, Timeout: 5000, Persist: true})
+	require.NoError(t, err)
+	stop()
+	assert.Equal(t, 4, r.Samples)
+	assert.Equal(t, BaselineInference, r.Baseline)
+	assert.Empty(t, r.Drift)
+	assert.True(t, r.Persisted)
+	stmt, err := p.DescStream("inferTest", ast.TypeStream)
+	require.NoError(t, err)
+	assert.Equal(t, ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "meta", FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+			{Name: "ok", FieldType: &ast.BasicType{Type: ast.BOOLEAN}},
+		}}},
+		{Name: "temp", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+	}, stmt.(*ast.StreamStmt).StreamFields)
+	assert.Equal(t, 2, stmt.(*ast.StreamStmt).Options.INFER_SAMPLES)
+
+	// The fields out of the persisted schema are still sampled
+	stop = publish([]map[string]any{
+		{"id": "a", "temp": 20.5, "meta": map[string]any{"ok": true}, "extra": "x"},
+	})
+	r, err = p.InferStream("inferTest", &InferOptions{Timeout: 5000})
+	stop()
+	require.NoError(t, err)
+	assert.Equal(t, BaselineStream, r.Baseline)
+	assert.Equal(t, []FieldDrift{
+		{Field: "extra", Kind: DriftAdded, Current: "string"},
+		{Field: "id", Kind: DriftChanged, Previous: "bigint", Current: "string"},
+	}, r.Drift)
+
+	_, err = p.InferStream("inferTest", &InferOptions{Samples: 1, Timeout: 100})
+	assert.EqualError(t, err, "no message is received from stream inferTest in 100ms")
+
+	_, err = p.DropStream("inferTest", ast.TypeStream)
+	require.NoError(t, err)
+	_, err = p.GetInference("inferTest")
+	assert.EqualError(t, err, "stream inferTest has not been inferred")
+}
@@ -40,6 +40,7 @@ type StreamProcessor struct {
 	db             kv.KeyValue
 	streamStatusDb kv.KeyValue
 	tableStatusDb  kv.KeyValue
+	inferDb        kv.KeyValue
 }
 
 type StreamDetail struct {
@@ -61,10 +62,15 @@ func NewStreamProcessor() *StreamProcessor {
 	if err != nil {
 		panic(fmt.Sprintf("Can not initialize store for the stream processor at path 'stream': %v", err))
 	}
+	inferDb, err := store.GetKV("streamInfer")
+	if err != nil {
+		panic(fmt.Sprintf("Can not initialize store for the stream processor at path 'streamInfer': %v", err))
+	}
 	processor := &StreamProcessor{
 		db:             db,
 		streamStatusDb: streamDb,
 		tableStatusDb:  tableDb,
+		inferDb:        inferDb,
 	}
 	return processor
 }
@@ -409,6 +415,9 @@ func printOptions(opts *ast.Options, buff *bytes.Buffer) {
 	if opts.RETAIN_SIZE != 0 {
 		buff.WriteString(fmt.Sprintf("RETAIN_SIZE: %d\n", opts.RETAIN_SIZE))
 	}
+	if opts.INFER_SAMPLES != 0 {
+		buff.WriteString(fmt.Sprintf("INFER_SAMPLES: %d\n", opts.INFER_SAMPLES))
+	}
 	if opts.SHARED {
 		buff.WriteString(fmt.Sprintf("SHARED: %v\n", opts.SHARED))
 	}
@@ -544,6 +553,7 @@ func (p *StreamProcessor) DropStream(name string, st ast.StreamType) (r string,
 	if err != nil {
 		return "", err
 	} else {
+		_ = p.inferDb.Delete(name)
 		return fmt.Sprintf("%s %s is dropped.", cases.Title(language.Und).String(ast.StreamTypeMap[st]), name), nil
 	}
 }
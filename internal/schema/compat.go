@@ -0,0 +1,60 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+)
+
+// The compatibility modes to check when updating a schema. The old schema is the latest version before updating.
+const (
+	CompatibilityNone = "none"
+	// CompatibilityBackward means the new schema can read the data written by the old schema
+	CompatibilityBackward = "backward"
+	// CompatibilityForward means the old schema can read the data written by the new schema
+	CompatibilityForward = "forward"
+	// CompatibilityFull means both backward and forward
+	CompatibilityFull = "full"
+)
+
+// compatChecker checks if the new schema file can read the data written by the old schema file for backward
+// compatibility, or the old one can read the data written by the new one for forward compatibility.
+type compatChecker func(oldFile string, newFile string, backward bool) error
+
+// init once and read only
+var compatCheckers = map[def.SchemaType]compatChecker{}
+
+func checkCompatibility(schemaType def.SchemaType, mode string, oldFile string, newFile string) error {
+	if mode == "" || mode == CompatibilityNone {
+		return nil
+	}
+	check, ok := compatCheckers[schemaType]
+	if !ok {
+		return fmt.Errorf("compatibility check is not supported for %s schema", schemaType)
+	}
+	if mode == CompatibilityBackward || mode == CompatibilityFull {
+		if err := check(oldFile, newFile, true); err != nil {
+			return err
+		}
+	}
+	if mode == CompatibilityForward || mode == CompatibilityFull {
+		if err := check(oldFile, newFile, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
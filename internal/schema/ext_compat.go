@@ -0,0 +1,138 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build schema || !core
+
+package schema
+
+import (
+	"fmt"
+	"os"
+
+	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/jhump/protoreflect/desc" //nolint:staticcheck
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/avro"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+)
+
+func init() {
+	compatCheckers[def.PROTOBUF] = checkProtobufCompat
+	compatCheckers[def.AVRO] = checkAvroCompat
+}
+
+// checkProtobufCompat checks the messages by the field numbers. The messages of the old schema cannot be removed.
+func checkProtobufCompat(oldFile string, newFile string, backward bool) error {
+	oldFds, err := protoParser.ParseFiles(oldFile)
+	if err != nil {
+		return fmt.Errorf("parse schema file %s failed: %s", oldFile, err)
+	}
+	newFds, err := protoParser.ParseFiles(newFile)
+	if err != nil {
+		return fmt.Errorf("parse schema file %s failed: %s", newFile, err)
+	}
+	for _, om := range allMessages(oldFds[0].GetMessageTypes()) {
+		nm := newFds[0].FindMessage(om.GetFullyQualifiedName())
+		if nm == nil {
+			return fmt.Errorf("message %s is removed", om.GetFullyQualifiedName())
+		}
+		if backward {
+			err = checkProtoMessage(nm, om)
+		} else {
+			err = checkProtoMessage(om, nm)
+		}
+		if err != nil {
+			return fmt.Errorf("message %s: %v", om.GetFullyQualifiedName(), err)
+		}
+	}
+	return nil
+}
+
+func allMessages(ms []*desc.MessageDescriptor) []*desc.MessageDescriptor {
+	result := make([]*desc.MessageDescriptor, 0, len(ms))
+	for _, m := range ms {
+		result = append(result, m)
+		result = append(result, allMessages(m.GetNestedMessageTypes())...)
+	}
+	return result
+}
+
+// checkProtoMessage checks if the reader message can read the data written by the writer message
+func checkProtoMessage(reader *desc.MessageDescriptor, writer *desc.MessageDescriptor) error {
+	for _, rf := range reader.GetFields() {
+		wf := writer.FindFieldByNumber(rf.GetNumber())
+		if wf == nil {
+			if rf.IsRequired() {
+				return fmt.Errorf("required field %s is missing in the writer schema", rf.GetName())
+			}
+			continue
+		}
+		if rf.IsRepeated() != wf.IsRepeated() {
+			return fmt.Errorf("field %s cannot change between repeated and singular", rf.GetName())
+		}
+		rt, wt := wireKind(rf), wireKind(wf)
+		if rt != wt {
+			return fmt.Errorf("field %s cannot change type from %s to %s", rf.GetName(), wt, rt)
+		}
+	}
+	return nil
+}
+
+// wireKind returns the kind of the field type which can be changed to each other without breaking the wire format
+func wireKind(f *desc.FieldDescriptor) string {
+	switch f.GetType() {
+	case dpb.FieldDescriptorProto_TYPE_INT32, dpb.FieldDescriptorProto_TYPE_INT64, dpb.FieldDescriptorProto_TYPE_UINT32,
+		dpb.FieldDescriptorProto_TYPE_UINT64, dpb.FieldDescriptorProto_TYPE_BOOL, dpb.FieldDescriptorProto_TYPE_ENUM:
+		return "varint"
+	case dpb.FieldDescriptorProto_TYPE_SINT32, dpb.FieldDescriptorProto_TYPE_SINT64:
+		return "zigzag"
+	case dpb.FieldDescriptorProto_TYPE_FIXED32, dpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return "fixed32"
+	case dpb.FieldDescriptorProto_TYPE_FIXED64, dpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return "fixed64"
+	case dpb.FieldDescriptorProto_TYPE_STRING, dpb.FieldDescriptorProto_TYPE_BYTES:
+		return "bytes"
+	case dpb.FieldDescriptorProto_TYPE_MESSAGE, dpb.FieldDescriptorProto_TYPE_GROUP:
+		return f.GetMessageType().GetFullyQualifiedName()
+	default:
+		return f.GetType().String()
+	}
+}
+
+func checkAvroCompat(oldFile string, newFile string, backward bool) error {
+	oldSchema, err := parseAvroFile(oldFile)
+	if err != nil {
+		return err
+	}
+	newSchema, err := parseAvroFile(newFile)
+	if err != nil {
+		return err
+	}
+	if backward {
+		return avro.CanRead(newSchema, oldSchema)
+	}
+	return avro.CanRead(oldSchema, newSchema)
+}
+
+func parseAvroFile(schemaFile string) (*avro.Schema, error) {
+	content, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("read schema file %s failed: %s", schemaFile, err)
+	}
+	s, err := avro.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed: %s", schemaFile, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build schema || !core
+
+package schema
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/avro"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+func init() {
+	inferes[message.FormatAvro] = InferAvro
+}
+
+// InferAvro infers the schema from the record of an avro schema file
+func InferAvro(schemaFile string, recordName string) (ast.StreamFields, error) {
+	ffs, err := GetSchemaFile(def.AVRO, schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(ffs.SchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("read schema file %s failed: %s", ffs.SchemaFile, err)
+	}
+	root, err := avro.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed: %s", ffs.SchemaFile, err)
+	}
+	s := root.Named(recordName)
+	if s == nil || s.Type != avro.Record {
+		return nil, fmt.Errorf("record type %s not found in schema file %s", recordName, schemaFile)
+	}
+	return convertAvroRecord(s, map[*avro.Schema]bool{})
+}
+
+func convertAvroRecord(s *avro.Schema, visiting map[*avro.Schema]bool) (ast.StreamFields, error) {
+	if visiting[s] {
+		return nil, fmt.Errorf("recursive record type %s is not supported", s.Name)
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+	result := make(ast.StreamFields, 0, len(s.Fields))
+	for _, f := range s.Fields {
+		ft, err := convertAvroType(f.Type, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type for field '%s': %v", f.Name, err)
+		}
+		result = append(result, ast.StreamField{Name: f.Name, FieldType: ft})
+	}
+	return result, nil
+}
+
+func convertAvroType(s *avro.Schema, visiting map[*avro.Schema]bool) (ast.FieldType, error) {
+	switch s.Logical {
+	case avro.LogicalDecimal:
+		return &ast.BasicType{Type: ast.FLOAT}, nil
+	case avro.LogicalDate, avro.LogicalTimestampMillis, avro.LogicalTimestampMicros, avro.LogicalTimestampNanos,
+		avro.LogicalLocalTsMillis, avro.LogicalLocalTsMicros, avro.LogicalLocalTsNanos:
+		return &ast.BasicType{Type: ast.DATETIME}, nil
+	}
+	switch s.Type {
+	case avro.Boolean:
+		return &ast.BasicType{Type: ast.BOOLEAN}, nil
+	case avro.Int, avro.Long:
+		return &ast.BasicType{Type: ast.BIGINT}, nil
+	case avro.Float, avro.Double:
+		return &ast.BasicType{Type: ast.FLOAT}, nil
+	case avro.String, avro.Enum:
+		return &ast.BasicType{Type: ast.STRINGS}, nil
+	case avro.Bytes, avro.Fixed:
+		return &ast.BasicType{Type: ast.BYTEA}, nil
+	case avro.Record:
+		sfs, err := convertAvroRecord(s, visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.RecType{StreamFields: sfs}, nil
+	case avro.Map:
+		// map keys are dynamic so that the struct has no predefined fields
+		return &ast.RecType{}, nil
+	case avro.Array:
+		ft, err := convertAvroType(s.Items, visiting)
+		if err != nil {
+			return nil, err
+		}
+		switch t := ft.(type) {
+		case *ast.BasicType:
+			return &ast.ArrayType{Type: t.Type}, nil
+		case *ast.RecType:
+			return &ast.ArrayType{Type: ast.STRUCT, FieldType: t}, nil
+		default:
+			return &ast.ArrayType{Type: ast.ARRAY, FieldType: t}, nil
+		}
+	case avro.Union:
+		// nullable type is the only supported union
+		var inner *avro.Schema
+		for _, t := range s.Types {
+			if t.Type == avro.Null {
+				continue
+			}
+			if inner != nil {
+				return nil, fmt.Errorf("union of multiple non-null types is not supported")
+			}
+			inner = t
+		}
+		if inner == nil {
+			return nil, fmt.Errorf("union of null only is not supported")
+		}
+		return convertAvroType(inner, visiting)
+	default:
+		return nil, fmt.Errorf("unsupported avro type %s", s.Type)
+	}
+}
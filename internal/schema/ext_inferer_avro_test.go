@@ -0,0 +1,67 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build schema || !core
+
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestInferAvro(t *testing.T) {
+	dataDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	etcDir := filepath.Join(dataDir, "schemas", "avro")
+	require.NoError(t, os.MkdirAll(etcDir, os.ModePerm))
+	defer func() {
+		require.NoError(t, os.RemoveAll(etcDir))
+	}()
+	bytesRead, err := os.ReadFile("test/test1.avsc")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(etcDir, "test1.avsc"), bytesRead, 0o755))
+	require.NoError(t, InitRegistry())
+
+	result, err := InferAvro("test1", "Reading")
+	require.NoError(t, err)
+	location := &ast.RecType{StreamFields: []ast.StreamField{
+		{Name: "lat", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "lng", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+	}}
+	expected := ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "name", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "temperature", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "status", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "ts", FieldType: &ast.BasicType{Type: ast.DATETIME}},
+		{Name: "price", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "tags", FieldType: &ast.ArrayType{Type: ast.STRINGS}},
+		{Name: "labels", FieldType: &ast.RecType{}},
+		{Name: "location", FieldType: location},
+		{Name: "history", FieldType: &ast.ArrayType{Type: ast.STRUCT, FieldType: location}},
+	}
+	require.Equal(t, expected, result)
+
+	_, err = InferAvro("test1", "NotExist")
+	require.EqualError(t, err, "record type NotExist not found in schema file test1")
+	_, err = InferAvro("test2", "Reading")
+	require.Error(t, err)
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
 type inferer func(schemaFileName string, SchemaMessageName string) (ast.StreamFields, error)
@@ -28,8 +29,12 @@ type inferer func(schemaFileName string, SchemaMessageName string) (ast.StreamFi
 var inferes = map[string]inferer{}
 
 func InferFromSchemaFile(schemaType string, schemaId string) (ast.StreamFields, error) {
-	if c, ok := inferes[schemaType]; ok {
+	if c, ok := inferes[strings.ToLower(schemaType)]; ok {
 		r := strings.Split(schemaId, ".")
+		// The json schema id without the name refers to the root schema of the file
+		if len(r) == 1 && strings.EqualFold(schemaType, message.FormatJson) {
+			r = append(r, "")
+		}
 		if len(r) != 2 {
 			return nil, fmt.Errorf("invalid schemaId: %s", schemaId)
 		}
@@ -0,0 +1,72 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema/jsonschema"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+func init() {
+	inferes[message.FormatJson] = InferJsonSchema
+}
+
+// InferJsonSchema infers the stream fields from a json schema file
+func InferJsonSchema(schemaFile string, name string) (ast.StreamFields, error) {
+	s, err := loadJsonSchema(schemaFile, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.StreamFields()
+}
+
+// GetJsonSchema returns the compiled json schema by the schemaId in the form of file.name. The name refers to
+// the definition in $defs or definitions of the file. If the name is not set, the root schema is used.
+func GetJsonSchema(schemaId string) (*jsonschema.Schema, error) {
+	r := strings.SplitN(schemaId, ".", 2)
+	name := ""
+	if len(r) == 2 {
+		name = r[1]
+	}
+	return loadJsonSchema(r[0], name)
+}
+
+func loadJsonSchema(schemaFile string, name string) (*jsonschema.Schema, error) {
+	ffs, err := GetSchemaFile(def.JSONSCHEMA, schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(ffs.SchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("read schema file %s failed: %s", ffs.SchemaFile, err)
+	}
+	root, err := jsonschema.Compile(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed: %s", ffs.SchemaFile, err)
+	}
+	if name == "" {
+		return root, nil
+	}
+	if d := root.Definition(name); d != nil {
+		return d, nil
+	}
+	return nil, fmt.Errorf("definition %s not found in schema file %s", name, schemaFile)
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestInferJsonSchema(t *testing.T) {
+	dataDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	etcDir := filepath.Join(dataDir, "schemas", "jsonschema")
+	require.NoError(t, os.MkdirAll(etcDir, os.ModePerm))
+	defer func() {
+		require.NoError(t, os.RemoveAll(etcDir))
+	}()
+	bytesRead, err := os.ReadFile("test/device.json")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(etcDir, "device.json"), bytesRead, 0o755))
+	require.NoError(t, InitRegistry())
+
+	result, err := InferJsonSchema("device", "v1")
+	require.NoError(t, err)
+	require.Equal(t, ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "temperature", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "ts", FieldType: &ast.BasicType{Type: ast.DATETIME}},
+	}, result)
+	// the root schema
+	result, err = InferJsonSchema("device", "")
+	require.NoError(t, err)
+	require.Equal(t, ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+	}, result)
+	_, err = InferJsonSchema("device", "other")
+	require.EqualError(t, err, "definition other not found in schema file device")
+
+	s, err := GetJsonSchema("device.v1")
+	require.NoError(t, err)
+	require.EqualError(t, s.Validate(map[string]any{"id": "a", "temperature": 120.0}), `json schema validation failed at "/temperature": 120 is greater than maximum 100`)
+	s, err = GetJsonSchema("device")
+	require.NoError(t, err)
+	require.NoError(t, s.Validate(map[string]any{"id": "a"}))
+	_, err = GetJsonSchema("device.v2")
+	require.EqualError(t, err, "definition v2 not found in schema file device")
+	_, err = GetJsonSchema("notexist.v1")
+	require.EqualError(t, err, "schema type jsonschema, file notexist not found")
+}
@@ -38,6 +38,11 @@ func TestInferFromSchemaFileError(t *testing.T) {
 			schemaType: "protobuf",
 			schemaId:   "aa",
 			err:        errors.New("invalid schemaId: aa"),
+		}, {
+			name:       "test json schema root",
+			schemaType: "json",
+			schemaId:   "aa",
+			err:        nil,
 		},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,122 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// StreamFields infers the stream fields from the properties of an object schema in the defined order
+func (s *Schema) StreamFields() (ast.StreamFields, error) {
+	return s.streamFields(map[*Schema]bool{})
+}
+
+func (s *Schema) streamFields(visiting map[*Schema]bool) (ast.StreamFields, error) {
+	s = s.deref()
+	if visiting[s] {
+		return nil, fmt.Errorf("recursive schema is not supported")
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+	if t := s.inferType(); t != "object" {
+		return nil, fmt.Errorf("the schema must be an object but got %s", t)
+	}
+	result := make(ast.StreamFields, 0, len(s.PropertyOrder))
+	for _, k := range s.PropertyOrder {
+		ft, err := s.Properties[k].fieldType(visiting)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type for field '%s': %v", k, err)
+		}
+		result = append(result, ast.StreamField{Name: k, FieldType: ft})
+	}
+	return result, nil
+}
+
+func (s *Schema) fieldType(visiting map[*Schema]bool) (ast.FieldType, error) {
+	s = s.deref()
+	switch t := s.inferType(); t {
+	case "boolean":
+		return &ast.BasicType{Type: ast.BOOLEAN}, nil
+	case "integer":
+		return &ast.BasicType{Type: ast.BIGINT}, nil
+	case "number":
+		return &ast.BasicType{Type: ast.FLOAT}, nil
+	case "string":
+		switch {
+		case s.Format == "date-time":
+			return &ast.BasicType{Type: ast.DATETIME}, nil
+		case s.Encoding == "base64":
+			return &ast.BasicType{Type: ast.BYTEA}, nil
+		default:
+			return &ast.BasicType{Type: ast.STRINGS}, nil
+		}
+	case "object":
+		sfs, err := s.streamFields(visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.RecType{StreamFields: sfs}, nil
+	case "array":
+		if s.Items == nil {
+			return nil, fmt.Errorf("cannot infer the type of array without items")
+		}
+		ft, err := s.Items.fieldType(visiting)
+		if err != nil {
+			return nil, err
+		}
+		switch at := ft.(type) {
+		case *ast.BasicType:
+			return &ast.ArrayType{Type: at.Type}, nil
+		case *ast.RecType:
+			return &ast.ArrayType{Type: ast.STRUCT, FieldType: at}, nil
+		default:
+			return &ast.ArrayType{Type: ast.ARRAY, FieldType: at}, nil
+		}
+	default:
+		return nil, fmt.Errorf("cannot infer the type of %s", t)
+	}
+}
+
+// inferType returns the only non-null type of the schema
+func (s *Schema) inferType() string {
+	var types []string
+	for _, t := range s.Types {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	switch {
+	case len(types) == 1:
+		return types[0]
+	case len(types) > 1:
+		return fmt.Sprintf("multiple types %v", types)
+	case s.Properties != nil:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	default:
+		return "schema without type"
+	}
+}
+
+// deref returns the referred schema if the schema is only a reference
+func (s *Schema) deref() *Schema {
+	for i := 0; i < maxRefDepth && s.ref != nil && len(s.Types) == 0 && s.Properties == nil; i++ {
+		s = s.ref
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestStreamFields(t *testing.T) {
+	s, err := Compile([]byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"count": {"type": ["integer", "null"]},
+			"temperature": {"type": "number"},
+			"ok": {"type": "boolean"},
+			"ts": {"type": "string", "format": "date-time"},
+			"raw": {"type": "string", "contentEncoding": "base64"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"location": {"$ref": "#/$defs/location"},
+			"history": {"type": "array", "items": {"$ref": "#/$defs/location"}},
+			"matrix": {"type": "array", "items": {"type": "array", "items": {"type": "number"}}}
+		},
+		"$defs": {
+			"location": {"properties": {"lat": {"type": "number"}, "lng": {"type": "number"}}}
+		}
+	}`))
+	require.NoError(t, err)
+	sfs, err := s.StreamFields()
+	require.NoError(t, err)
+	location := &ast.RecType{StreamFields: ast.StreamFields{
+		{Name: "lat", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "lng", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+	}}
+	require.Equal(t, ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "count", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "temperature", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "ok", FieldType: &ast.BasicType{Type: ast.BOOLEAN}},
+		{Name: "ts", FieldType: &ast.BasicType{Type: ast.DATETIME}},
+		{Name: "raw", FieldType: &ast.BasicType{Type: ast.BYTEA}},
+		{Name: "tags", FieldType: &ast.ArrayType{Type: ast.STRINGS}},
+		{Name: "location", FieldType: location},
+		{Name: "history", FieldType: &ast.ArrayType{Type: ast.STRUCT, FieldType: location}},
+		{Name: "matrix", FieldType: &ast.ArrayType{Type: ast.ARRAY, FieldType: &ast.ArrayType{Type: ast.FLOAT}}},
+	}, sfs)
+}
+
+func TestStreamFieldsError(t *testing.T) {
+	tests := []struct {
+		schema string
+		err    string
+	}{
+		{schema: `{"type":"string"}`, err: "the schema must be an object but got string"},
+		{schema: `{"properties":{"a":{}}}`, err: "invalid type for field 'a': cannot infer the type of schema without type"},
+		{schema: `{"properties":{"a":{"type":["string","integer"]}}}`, err: "invalid type for field 'a': cannot infer the type of multiple types [string integer]"},
+		{schema: `{"properties":{"a":{"type":"array"}}}`, err: "invalid type for field 'a': cannot infer the type of array without items"},
+		{schema: `{"$defs":{"n":{"properties":{"next":{"$ref":"#/$defs/n"}}}},"$ref":"#/$defs/n"}`, err: "invalid type for field 'next': recursive schema is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			require.NoError(t, err)
+			_, err = s.StreamFields()
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
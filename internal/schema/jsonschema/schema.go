@@ -0,0 +1,494 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Schema is a compiled JSON Schema. The supported keywords are the validation vocabulary of draft 7 and
+// 2020-12 without remote references. Unknown keywords are ignored as annotations.
+type Schema struct {
+	// Boolean schema, true accepts everything and false rejects everything
+	Bool *bool
+	// Only local reference like #/$defs/a is supported
+	Ref string
+	ref *Schema
+
+	Types    []string
+	Enum     []any
+	Const    any
+	HasConst bool
+	Format   string
+	// contentEncoding, base64 is inferred as bytea
+	Encoding string
+
+	// object
+	Properties           map[string]*Schema
+	PropertyOrder        []string
+	Required             []string
+	AdditionalProperties *Schema
+	MinProperties        *int
+	MaxProperties        *int
+
+	// array
+	Items       *Schema
+	PrefixItems []*Schema
+	MinItems    *int
+	MaxItems    *int
+	UniqueItems bool
+
+	// number
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum *float64
+	ExclusiveMaximum *float64
+	MultipleOf       *float64
+
+	// string
+	MinLength *int
+	MaxLength *int
+	Pattern   *regexp.Regexp
+
+	AllOf []*Schema
+	AnyOf []*Schema
+	OneOf []*Schema
+	Not   *Schema
+
+	// $defs or definitions, only set in the root
+	Defs map[string]*Schema
+}
+
+// Compile parses and compiles the JSON Schema document
+func Compile(content []byte) (*Schema, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	doc, err := readValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json schema: %v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid json schema: unexpected content after the schema")
+	}
+	c := &compiler{root: doc, cache: make(map[string]*Schema)}
+	s, err := c.compile(doc, "#")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.resolve(); err != nil {
+		return nil, err
+	}
+	s.Defs = make(map[string]*Schema)
+	for _, key := range []string{"definitions", "$defs"} {
+		if defs, ok := getObject(doc, key); ok {
+			for _, k := range defs.keys {
+				s.Defs[k] = c.cache["#/"+key+"/"+escapePointer(k)]
+			}
+		}
+	}
+	return s, nil
+}
+
+// Definition returns the schema defined in $defs or definitions of the root by name
+func (s *Schema) Definition(name string) *Schema {
+	return s.Defs[name]
+}
+
+// object is a json object which keeps the order of the keys
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func readValue(dec *json.Decoder) (any, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch d := t.(type) {
+	case json.Delim:
+		switch d {
+		case '{':
+			o := &object{values: make(map[string]any)}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				k := kt.(string)
+				v, err := readValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := o.values[k]; !ok {
+					o.keys = append(o.keys, k)
+				}
+				o.values[k] = v
+			}
+			_, err = dec.Token()
+			return o, err
+		case '[':
+			a := make([]any, 0)
+			for dec.More() {
+				v, err := readValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, v)
+			}
+			_, err = dec.Token()
+			return a, err
+		default:
+			return nil, fmt.Errorf("unexpected %v", d)
+		}
+	case json.Number:
+		return d.Float64()
+	default:
+		return t, nil
+	}
+}
+
+// toPlain converts the ordered object to map so that it can be compared with the data
+func toPlain(v any) any {
+	switch t := v.(type) {
+	case *object:
+		m := make(map[string]any, len(t.values))
+		for k, vv := range t.values {
+			m[k] = toPlain(vv)
+		}
+		return m
+	case []any:
+		a := make([]any, len(t))
+		for i, vv := range t {
+			a[i] = toPlain(vv)
+		}
+		return a
+	default:
+		return v
+	}
+}
+
+func getObject(v any, key string) (*object, bool) {
+	o, ok := v.(*object)
+	if !ok {
+		return nil, false
+	}
+	r, ok := o.values[key].(*object)
+	return r, ok
+}
+
+type compiler struct {
+	root any
+	// pointer -> compiled schema, used to resolve references including the recursive ones
+	cache map[string]*Schema
+	refs  []*Schema
+}
+
+func (c *compiler) compile(v any, ptr string) (*Schema, error) {
+	if s, ok := c.cache[ptr]; ok {
+		return s, nil
+	}
+	s := &Schema{}
+	c.cache[ptr] = s
+	switch t := v.(type) {
+	case bool:
+		s.Bool = &t
+		return s, nil
+	case *object:
+		return s, c.compileObject(s, t, ptr)
+	default:
+		return nil, fmt.Errorf("invalid json schema at %s: must be an object or boolean", ptr)
+	}
+}
+
+func (c *compiler) compileObject(s *Schema, o *object, ptr string) (err error) {
+	fail := func(key string, format string, args ...any) error {
+		return fmt.Errorf("invalid json schema at %s/%s: %s", ptr, key, fmt.Sprintf(format, args...))
+	}
+	sub := func(key string) (*Schema, error) {
+		v, ok := o.values[key]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(v, ptr+"/"+escapePointer(key))
+	}
+	subs := func(key string) ([]*Schema, error) {
+		v, ok := o.values[key]
+		if !ok {
+			return nil, nil
+		}
+		a, ok := v.([]any)
+		if !ok {
+			return nil, fail(key, "must be an array")
+		}
+		r := make([]*Schema, len(a))
+		for i, item := range a {
+			r[i], err = c.compile(item, ptr+"/"+key+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	}
+	number := func(key string) (*float64, error) {
+		v, ok := o.values[key]
+		if !ok {
+			return nil, nil
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fail(key, "must be a number")
+		}
+		return &f, nil
+	}
+	integer := func(key string) (*int, error) {
+		f, err := number(key)
+		if err != nil || f == nil {
+			return nil, err
+		}
+		if *f < 0 || *f != float64(int(*f)) {
+			return nil, fail(key, "must be a non-negative integer")
+		}
+		i := int(*f)
+		return &i, nil
+	}
+	for _, key := range o.keys {
+		v := o.values[key]
+		switch key {
+		case "$ref":
+			ref, ok := v.(string)
+			if !ok || !strings.HasPrefix(ref, "#") {
+				return fail(key, "only local reference is supported")
+			}
+			s.Ref = ref
+			c.refs = append(c.refs, s)
+		case "type":
+			switch t := v.(type) {
+			case string:
+				s.Types = []string{t}
+			case []any:
+				for _, tt := range t {
+					ts, ok := tt.(string)
+					if !ok {
+						return fail(key, "must be a string or an array of strings")
+					}
+					s.Types = append(s.Types, ts)
+				}
+			default:
+				return fail(key, "must be a string or an array of strings")
+			}
+			for _, ts := range s.Types {
+				switch ts {
+				case "null", "boolean", "object", "array", "number", "integer", "string":
+				default:
+					return fail(key, "unknown type %s", ts)
+				}
+			}
+		case "enum":
+			a, ok := v.([]any)
+			if !ok {
+				return fail(key, "must be an array")
+			}
+			s.Enum = toPlain(a).([]any)
+		case "const":
+			s.Const = toPlain(v)
+			s.HasConst = true
+		case "format":
+			s.Format, _ = v.(string)
+		case "contentEncoding":
+			s.Encoding, _ = v.(string)
+		case "properties":
+			po, ok := v.(*object)
+			if !ok {
+				return fail(key, "must be an object")
+			}
+			s.Properties = make(map[string]*Schema, len(po.keys))
+			for _, pk := range po.keys {
+				ps, err := c.compile(po.values[pk], ptr+"/properties/"+escapePointer(pk))
+				if err != nil {
+					return err
+				}
+				s.Properties[pk] = ps
+				s.PropertyOrder = append(s.PropertyOrder, pk)
+			}
+		case "required":
+			a, ok := v.([]any)
+			if !ok {
+				return fail(key, "must be an array of strings")
+			}
+			for _, r := range a {
+				rs, ok := r.(string)
+				if !ok {
+					return fail(key, "must be an array of strings")
+				}
+				s.Required = append(s.Required, rs)
+			}
+		case "additionalProperties":
+			s.AdditionalProperties, err = sub(key)
+		case "minProperties":
+			s.MinProperties, err = integer(key)
+		case "maxProperties":
+			s.MaxProperties, err = integer(key)
+		case "items":
+			// items in array form is the tuple validation before 2020-12
+			if _, ok := v.([]any); ok {
+				s.PrefixItems, err = subs(key)
+			} else {
+				s.Items, err = sub(key)
+			}
+		case "prefixItems":
+			s.PrefixItems, err = subs(key)
+		case "additionalItems":
+			if s.PrefixItems != nil || isArray(o.values["items"]) {
+				s.Items, err = sub(key)
+			}
+		case "minItems":
+			s.MinItems, err = integer(key)
+		case "maxItems":
+			s.MaxItems, err = integer(key)
+		case "uniqueItems":
+			s.UniqueItems, _ = v.(bool)
+		case "minimum":
+			s.Minimum, err = number(key)
+		case "maximum":
+			s.Maximum, err = number(key)
+		case "exclusiveMinimum", "exclusiveMaximum":
+			// draft 4 uses boolean to make minimum and maximum exclusive
+			if b, ok := v.(bool); ok {
+				if !b {
+					continue
+				}
+				if key == "exclusiveMinimum" {
+					s.ExclusiveMinimum, err = number("minimum")
+					s.Minimum = nil
+				} else {
+					s.ExclusiveMaximum, err = number("maximum")
+					s.Maximum = nil
+				}
+			} else if key == "exclusiveMinimum" {
+				s.ExclusiveMinimum, err = number(key)
+			} else {
+				s.ExclusiveMaximum, err = number(key)
+			}
+		case "multipleOf":
+			s.MultipleOf, err = number(key)
+			if err == nil && *s.MultipleOf <= 0 {
+				return fail(key, "must be greater than 0")
+			}
+		case "minLength":
+			s.MinLength, err = integer(key)
+		case "maxLength":
+			s.MaxLength, err = integer(key)
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				return fail(key, "must be a string")
+			}
+			s.Pattern, err = regexp.Compile(p)
+			if err != nil {
+				return fail(key, "invalid pattern: %v", err)
+			}
+		case "allOf":
+			s.AllOf, err = subs(key)
+		case "anyOf":
+			s.AnyOf, err = subs(key)
+		case "oneOf":
+			s.OneOf, err = subs(key)
+		case "not":
+			s.Not, err = sub(key)
+		case "$defs", "definitions":
+			do, ok := v.(*object)
+			if !ok {
+				return fail(key, "must be an object")
+			}
+			for _, dk := range do.keys {
+				if _, err := c.compile(do.values[dk], ptr+"/"+key+"/"+escapePointer(dk)); err != nil {
+					return err
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// draft 4 boolean exclusive keywords may appear before minimum and maximum
+	if b, ok := o.values["exclusiveMinimum"].(bool); ok && b {
+		s.Minimum = nil
+	}
+	if b, ok := o.values["exclusiveMaximum"].(bool); ok && b {
+		s.Maximum = nil
+	}
+	return nil
+}
+
+// resolve resolves all the references after the whole document is compiled
+func (c *compiler) resolve() error {
+	for i := 0; i < len(c.refs); i++ {
+		s := c.refs[i]
+		target, err := c.lookup(s.Ref)
+		if err != nil {
+			return err
+		}
+		rs, err := c.compile(target, strings.TrimSuffix(s.Ref, "/"))
+		if err != nil {
+			return err
+		}
+		s.ref = rs
+	}
+	return nil
+}
+
+func (c *compiler) lookup(ref string) (any, error) {
+	v := c.root
+	p := strings.TrimPrefix(ref, "#")
+	if p == "" || p == "/" {
+		return v, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(p, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch t := v.(type) {
+		case *object:
+			vv, ok := t.values[token]
+			if !ok {
+				return nil, fmt.Errorf("cannot resolve json schema reference %s", ref)
+			}
+			v = vv
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, fmt.Errorf("cannot resolve json schema reference %s", ref)
+			}
+			v = t[i]
+		default:
+			return nil, fmt.Errorf("cannot resolve json schema reference %s", ref)
+		}
+	}
+	return v, nil
+}
+
+func isArray(v any) bool {
+	_, ok := v.([]any)
+	return ok
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
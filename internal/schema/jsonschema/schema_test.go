@@ -0,0 +1,61 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	s, err := Compile([]byte(`{
+		"type": "object",
+		"properties": {"b": {"$ref": "#/$defs/pos"}, "a": {"type": "string"}},
+		"$defs": {"pos": {"type": "integer", "minimum": 0}},
+		"definitions": {"node": {"type": "object", "properties": {"next": {"$ref": "#/definitions/node"}}}}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "a"}, s.PropertyOrder)
+	require.Same(t, s.Definition("pos"), s.Properties["b"].ref)
+	node := s.Definition("node")
+	require.NotNil(t, node)
+	require.Same(t, node, node.Properties["next"].ref)
+	require.Nil(t, s.Definition("none"))
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []struct {
+		schema string
+		err    string
+	}{
+		{schema: `{`, err: "invalid json schema: unexpected end of JSON input"},
+		{schema: `{} {}`, err: "invalid json schema: unexpected content after the schema"},
+		{schema: `1`, err: "invalid json schema at #: must be an object or boolean"},
+		{schema: `{"type":"int"}`, err: "invalid json schema at #/type: unknown type int"},
+		{schema: `{"properties":{"a":{"minLength":-1}}}`, err: "invalid json schema at #/properties/a/minLength: must be a non-negative integer"},
+		{schema: `{"pattern":"("}`, err: "invalid json schema at #/pattern: invalid pattern: error parsing regexp: missing closing ): `(`"},
+		{schema: `{"$ref":"http://example.com/a.json"}`, err: "invalid json schema at #/$ref: only local reference is supported"},
+		{schema: `{"$ref":"#/$defs/a"}`, err: "cannot resolve json schema reference #/$defs/a"},
+		{schema: `{"multipleOf":0}`, err: "invalid json schema at #/multipleOf: must be greater than 0"},
+		{schema: `{"anyOf":{}}`, err: "invalid json schema at #/anyOf: must be an array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
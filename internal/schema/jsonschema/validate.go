@@ -0,0 +1,253 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth limits the references followed without descending into the data to detect reference loops
+const maxRefDepth = 32
+
+// ValidationError is the first violation found. The Pointer is the JSON pointer of the invalid value.
+type ValidationError struct {
+	Pointer string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("json schema validation failed at %q: %s", e.Pointer, e.Message)
+}
+
+// Validate validates the data decoded by encoding/json against the schema
+func (s *Schema) Validate(v any) error {
+	return s.validate(v, "", 0)
+}
+
+func (s *Schema) validate(v any, ptr string, depth int) error {
+	fail := func(format string, args ...any) error {
+		return &ValidationError{Pointer: ptr, Message: fmt.Sprintf(format, args...)}
+	}
+	if s.Bool != nil {
+		if !*s.Bool {
+			return fail("no value is allowed")
+		}
+		return nil
+	}
+	if s.ref != nil {
+		if depth >= maxRefDepth {
+			return fail("too many nested references of %s", s.Ref)
+		}
+		if err := s.ref.validate(v, ptr, depth+1); err != nil {
+			return err
+		}
+	}
+	if len(s.Types) > 0 {
+		vt := typeOf(v)
+		matched := false
+		for _, t := range s.Types {
+			if t == vt || (t == "number" && vt == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fail("expected %s but got %s", strings.Join(s.Types, " or "), vt)
+		}
+	}
+	if s.HasConst && !equal(v, s.Const) {
+		return fail("must be %v", s.Const)
+	}
+	if s.Enum != nil {
+		found := false
+		for _, e := range s.Enum {
+			if equal(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fail("must be one of %v", s.Enum)
+		}
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		if err := s.validateObject(t, ptr); err != nil {
+			return err
+		}
+	case []any:
+		if err := s.validateArray(t, ptr); err != nil {
+			return err
+		}
+	case float64:
+		if s.Minimum != nil && t < *s.Minimum {
+			return fail("%v is less than minimum %v", t, *s.Minimum)
+		}
+		if s.Maximum != nil && t > *s.Maximum {
+			return fail("%v is greater than maximum %v", t, *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && t <= *s.ExclusiveMinimum {
+			return fail("%v must be greater than %v", t, *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && t >= *s.ExclusiveMaximum {
+			return fail("%v must be less than %v", t, *s.ExclusiveMaximum)
+		}
+		if s.MultipleOf != nil {
+			q := t / *s.MultipleOf
+			if math.Abs(q-math.Round(q)) > 1e-9 {
+				return fail("%v is not a multiple of %v", t, *s.MultipleOf)
+			}
+		}
+	case string:
+		l := utf8.RuneCountInString(t)
+		if s.MinLength != nil && l < *s.MinLength {
+			return fail("length %d is less than minLength %d", l, *s.MinLength)
+		}
+		if s.MaxLength != nil && l > *s.MaxLength {
+			return fail("length %d is greater than maxLength %d", l, *s.MaxLength)
+		}
+		if s.Pattern != nil && !s.Pattern.MatchString(t) {
+			return fail("does not match pattern %s", s.Pattern.String())
+		}
+	}
+	for _, sub := range s.AllOf {
+		if err := sub.validate(v, ptr, depth); err != nil {
+			return err
+		}
+	}
+	if s.AnyOf != nil {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if sub.validate(v, ptr, depth) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fail("does not match any schema of anyOf")
+		}
+	}
+	if s.OneOf != nil {
+		c := 0
+		for _, sub := range s.OneOf {
+			if sub.validate(v, ptr, depth) == nil {
+				c++
+			}
+		}
+		if c != 1 {
+			return fail("must match exactly one schema of oneOf but matches %d", c)
+		}
+	}
+	if s.Not != nil && s.Not.validate(v, ptr, depth) == nil {
+		return fail("must not match the schema of not")
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(m map[string]any, ptr string) error {
+	for _, r := range s.Required {
+		if _, ok := m[r]; !ok {
+			return &ValidationError{Pointer: ptr, Message: fmt.Sprintf("missing required property %s", r)}
+		}
+	}
+	if s.MinProperties != nil && len(m) < *s.MinProperties {
+		return &ValidationError{Pointer: ptr, Message: fmt.Sprintf("has %d properties, less than minProperties %d", len(m), *s.MinProperties)}
+	}
+	if s.MaxProperties != nil && len(m) > *s.MaxProperties {
+		return &ValidationError{Pointer: ptr, Message: fmt.Sprintf("has %d properties, more than maxProperties %d", len(m), *s.MaxProperties)}
+	}
+	// validate in the order of the keys to report the same violation for the same data
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ps, ok := s.Properties[k]
+		if !ok {
+			ps = s.AdditionalProperties
+		}
+		if ps == nil {
+			continue
+		}
+		if err := ps.validate(m[k], ptr+"/"+escapePointer(k), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(a []any, ptr string) error {
+	if s.MinItems != nil && len(a) < *s.MinItems {
+		return &ValidationError{Pointer: ptr, Message: fmt.Sprintf("has %d items, less than minItems %d", len(a), *s.MinItems)}
+	}
+	if s.MaxItems != nil && len(a) > *s.MaxItems {
+		return &ValidationError{Pointer: ptr, Message: fmt.Sprintf("has %d items, more than maxItems %d", len(a), *s.MaxItems)}
+	}
+	for i, item := range a {
+		is := s.Items
+		if i < len(s.PrefixItems) {
+			is = s.PrefixItems[i]
+		}
+		if is == nil {
+			continue
+		}
+		if err := is.validate(item, ptr+"/"+strconv.Itoa(i), 0); err != nil {
+			return err
+		}
+	}
+	if s.UniqueItems {
+		for i := 1; i < len(a); i++ {
+			for j := 0; j < i; j++ {
+				if equal(a[i], a[j]) {
+					return &ValidationError{Pointer: ptr, Message: fmt.Sprintf("items %d and %d are equal", j, i)}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func typeOf(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if t == math.Trunc(t) && !math.IsInf(t, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	s, err := Compile([]byte(`{
+		"type": "object",
+		"required": ["id", "temperature"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "pattern": "^dev-[0-9]+$"},
+			"temperature": {"type": "number", "minimum": -40, "exclusiveMaximum": 100},
+			"count": {"type": "integer", "multipleOf": 2},
+			"status": {"enum": ["ok", "fail"]},
+			"version": {"const": 1},
+			"tags": {"type": "array", "items": {"type": "string", "maxLength": 3}, "maxItems": 2, "uniqueItems": true},
+			"location": {"$ref": "#/$defs/location"},
+			"note": {"type": ["string", "null"]},
+			"reading": {"oneOf": [{"type": "integer"}, {"type": "string"}]},
+			"flag": {"not": {"type": "string"}},
+			"point": {"prefixItems": [{"type": "number"}, {"type": "number"}], "items": false}
+		},
+		"$defs": {
+			"location": {"type": "object", "properties": {"a/b": {"type": "boolean"}}, "minProperties": 1}
+		}
+	}`))
+	require.NoError(t, err)
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{data: `{"id":"dev-1","temperature":20.5,"count":4,"status":"ok","version":1,"tags":["a","b"],"location":{"a/b":true},"note":null,"reading":"x","flag":1,"point":[1,2]}`},
+		{data: `[]`, err: `json schema validation failed at "": expected object but got array`},
+		{data: `{"id":"dev-1"}`, err: `json schema validation failed at "": missing required property temperature`},
+		{data: `{"id":"dev-1","temperature":20,"other":1}`, err: `json schema validation failed at "/other": no value is allowed`},
+		{data: `{"id":"dev-a","temperature":20}`, err: `json schema validation failed at "/id": does not match pattern ^dev-[0-9]+$`},
+		{data: `{"id":"dev-1","temperature":100}`, err: `json schema validation failed at "/temperature": 100 must be less than 100`},
+		{data: `{"id":"dev-1","temperature":-41}`, err: `json schema validation failed at "/temperature": -41 is less than minimum -40`},
+		{data: `{"id":"dev-1","temperature":"20"}`, err: `json schema validation failed at "/temperature": expected number but got string`},
+		{data: `{"id":"dev-1","temperature":20,"count":1.5}`, err: `json schema validation failed at "/count": expected integer but got number`},
+		{data: `{"id":"dev-1","temperature":20,"count":3}`, err: `json schema validation failed at "/count": 3 is not a multiple of 2`},
+		{data: `{"id":"dev-1","temperature":20,"status":"bad"}`, err: `json schema validation failed at "/status": must be one of [ok fail]`},
+		{data: `{"id":"dev-1","temperature":20,"version":2}`, err: `json schema validation failed at "/version": must be 1`},
+		{data: `{"id":"dev-1","temperature":20,"tags":["a","long"]}`, err: `json schema validation failed at "/tags/1": length 4 is greater than maxLength 3`},
+		{data: `{"id":"dev-1","temperature":20,"tags":["a","a"]}`, err: `json schema validation failed at "/tags": items 0 and 1 are equal`},
+		{data: `{"id":"dev-1","temperature":20,"tags":["a","b","c"]}`, err: `json schema validation failed at "/tags": has 3 items, more than maxItems 2`},
+		{data: `{"id":"dev-1","temperature":20,"location":{"a/b":1}}`, err: `json schema validation failed at "/location/a~1b": expected boolean but got integer`},
+		{data: `{"id":"dev-1","temperature":20,"location":{}}`, err: `json schema validation failed at "/location": has 0 properties, less than minProperties 1`},
+		{data: `{"id":"dev-1","temperature":20,"note":1}`, err: `json schema validation failed at "/note": expected string or null but got integer`},
+		{data: `{"id":"dev-1","temperature":20,"reading":true}`, err: `json schema validation failed at "/reading": must match exactly one schema of oneOf but matches 0`},
+		{data: `{"id":"dev-1","temperature":20,"flag":"a"}`, err: `json schema validation failed at "/flag": must not match the schema of not`},
+		{data: `{"id":"dev-1","temperature":20,"point":[1,2,3]}`, err: `json schema validation failed at "/point/2": no value is allowed`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &v))
+			err := s.Validate(v)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestValidateDraft4(t *testing.T) {
+	s, err := Compile([]byte(`{"exclusiveMinimum": true, "minimum": 0, "maximum": 10, "anyOf": [{"type": "integer"}, {"maximum": 5}]}`))
+	require.NoError(t, err)
+	require.NoError(t, s.Validate(0.5))
+	require.NoError(t, s.Validate(float64(10)))
+	require.EqualError(t, s.Validate(float64(0)), `json schema validation failed at "": 0 must be greater than 0`)
+	require.EqualError(t, s.Validate(7.5), `json schema validation failed at "": does not match any schema of anyOf`)
+}
+
+func TestValidateRefLoop(t *testing.T) {
+	s, err := Compile([]byte(`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`))
+	require.NoError(t, err)
+	require.EqualError(t, s.Validate(1.0), `json schema validation failed at "": too many nested references of #/$defs/a`)
+}
@@ -15,13 +15,17 @@
 package schema
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
@@ -41,15 +45,44 @@ var (
 type Files struct {
 	SchemaFile string
 	SoFile     string
+	// Version increases once the schema file content is updated
+	Version int
+	// Compatibility is the compatibility check mode when updating the schema
+	Compatibility string
 }
 
 // Registry is a global registry for schemas
 // It stores the schema ids and the ref to its file content in memory
 // The schema definition is stored in the file system and will only be loaded once used
+// The latest version is stored as name.ext and the previous versions are archived as name@version.ext
 type Registry struct {
 	sync.RWMutex
 	// The map of schema files for all types
 	schemas map[def.SchemaType]map[string]*Files
+	// The archived previous versions of each schema for all types
+	versions map[def.SchemaType]map[string]map[int]*Files
+}
+
+// revision increases once any schema is updated or deleted to notify the converters which follow the latest version
+var revision atomic.Int64
+
+// Revision returns the revision of the whole registry
+func Revision() int64 {
+	return revision.Load()
+}
+
+// SplitVersion splits the schema name in the form of name@version. The version is 0 for the latest one, which can
+// also be referred as name@latest.
+func SplitVersion(name string) (string, int, error) {
+	base, v, found := strings.Cut(name, "@")
+	if !found || v == "latest" {
+		return base, 0, nil
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil || version <= 0 {
+		return "", 0, fmt.Errorf("invalid schema version %s, must be a positive integer or latest", v)
+	}
+	return base, version, nil
 }
 
 // Registry provide the method to add, update, get and parse and delete schemas
@@ -57,7 +90,8 @@ type Registry struct {
 // InitRegistry initialize the registry, only called once by the server
 func InitRegistry() error {
 	registry = &Registry{
-		schemas: make(map[def.SchemaType]map[string]*Files, len(def.SchemaTypes)),
+		schemas:  make(map[def.SchemaType]map[string]*Files, len(def.SchemaTypes)),
+		versions: make(map[def.SchemaType]map[string]map[int]*Files, len(def.SchemaTypes)),
 	}
 	dataDir, err := conf.GetDataLoc()
 	if err != nil {
@@ -74,6 +108,7 @@ func InitRegistry() error {
 	for _, schemaType := range def.SchemaTypes {
 		schemaDir := filepath.Join(dataDir, "schemas", string(schemaType))
 		var newSchemas map[string]*Files
+		newVersions := make(map[string]map[int]*Files)
 		files, err := os.ReadDir(schemaDir)
 		if err != nil {
 			conf.Log.Warnf("cannot read schema directory: %s", err)
@@ -84,6 +119,13 @@ func InitRegistry() error {
 				fileName := filepath.Base(file.Name())
 				ext := filepath.Ext(fileName)
 				schemaId := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+				if base, version, err := SplitVersion(schemaId); err == nil && version > 0 {
+					if _, ok := newVersions[base]; !ok {
+						newVersions[base] = make(map[int]*Files)
+					}
+					newVersions[base][version] = &Files{SchemaFile: filepath.Join(schemaDir, file.Name()), Version: version}
+					continue
+				}
 				ffs, ok := newSchemas[schemaId]
 				if !ok {
 					ffs = &Files{}
@@ -98,7 +140,18 @@ func InitRegistry() error {
 				conf.Log.Infof("schema file %s.%s loaded", schemaType, schemaId)
 			}
 		}
+		for schemaId, ffs := range newSchemas {
+			// The latest version is the next one of the archived versions
+			ffs.Version = 1
+			for v := range newVersions[schemaId] {
+				if v >= ffs.Version {
+					ffs.Version = v + 1
+				}
+			}
+			ffs.Compatibility = loadCompatibility(schemaType, schemaId)
+		}
 		registry.schemas[schemaType] = newSchemas
+		registry.versions[schemaType] = newVersions
 	}
 	if hasInstallFlag() {
 		schemaInstallWhenReboot()
@@ -135,52 +188,155 @@ func Register(info *Info) error {
 	return nil
 }
 
+// CreateOrUpdateSchema creates the schema or updates it as a new version. The current version is archived and the
+// new content must be compatible with it by the compatibility mode of the schema.
+// The new files are downloaded and checked without holding the registry lock, and then swapped in the lock.
 func CreateOrUpdateSchema(info *Info) error {
 	if _, ok := registry.schemas[info.Type]; !ok {
 		return fmt.Errorf("schema type %s not found", info.Type)
 	}
+	if strings.Contains(info.Name, "@") {
+		return fmt.Errorf("schema name %s cannot contain @ which is reserved for the version", info.Name)
+	}
 	dataDir, _ := conf.GetDataLoc()
 	etcDir := filepath.Join(dataDir, "schemas", string(info.Type))
 	if err := os.MkdirAll(etcDir, os.ModePerm); err != nil {
 		return err
 	}
-	ffs := &Files{}
+	registry.RLock()
+	old := registry.schemas[info.Type][info.Name]
+	registry.RUnlock()
+	ffs := &Files{Version: 1, Compatibility: info.Compatibility}
+	if old != nil {
+		ffs.Version = old.Version
+		if ffs.Compatibility == "" {
+			ffs.Compatibility = old.Compatibility
+		}
+	}
+	// Keep the compatibility mode in the install script
+	info.Compatibility = ffs.Compatibility
+	// The temp dir is in the same file system so that the files can be renamed. It is not scanned as a schema type.
+	tmpDir, err := os.MkdirTemp(filepath.Join(dataDir, "schemas"), ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	var (
+		schemaFile, newSchemaFile string
+		oldContent                []byte
+		updated                   bool
+	)
 	if info.Content != "" || info.FilePath != "" {
-		schemaFile := filepath.Join(etcDir, info.Name+schemaExt[info.Type])
-		if _, err := os.Stat(schemaFile); os.IsNotExist(err) {
-			file, err := os.Create(schemaFile)
+		schemaFile = filepath.Join(etcDir, info.Name+schemaExt[info.Type])
+		newSchemaFile = filepath.Join(tmpDir, filepath.Base(schemaFile))
+		if err := writeSchemaFile(newSchemaFile, info); err != nil {
+			return err
+		}
+		updated = true
+		if old != nil && old.SchemaFile != "" {
+			oldContent, updated, err = checkSchemaFile(info, old, ffs.Compatibility, newSchemaFile)
 			if err != nil {
 				return err
 			}
-			defer file.Close()
 		}
-		if info.Content != "" {
-			err := os.WriteFile(schemaFile, cast.StringToBytes(info.Content), 0o666)
-			if err != nil {
-				return err
+	}
+	var newSoFile string
+	if info.SoPath != "" {
+		newSoFile = filepath.Join(tmpDir, info.Name+".so")
+		if err := httpx.DownloadFile(newSoFile, info.SoPath); err != nil {
+			return err
+		}
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.schemas[info.Type][info.Name] != old {
+		return fmt.Errorf("schema %s.%s is updated concurrently, please retry", info.Type, info.Name)
+	}
+	if schemaFile != "" {
+		if updated {
+			if oldContent != nil {
+				archive := filepath.Join(etcDir, fmt.Sprintf("%s@%d%s", info.Name, old.Version, filepath.Ext(schemaFile)))
+				if err := os.WriteFile(archive, oldContent, 0o666); err != nil {
+					return err
+				}
+				if _, ok := registry.versions[info.Type][info.Name]; !ok {
+					registry.versions[info.Type][info.Name] = make(map[int]*Files)
+				}
+				registry.versions[info.Type][info.Name][old.Version] = &Files{SchemaFile: archive, Version: old.Version}
 			}
-		} else {
-			err := httpx.DownloadFile(schemaFile, info.FilePath)
-			if err != nil {
+			if err := os.Rename(newSchemaFile, schemaFile); err != nil {
 				return err
 			}
+			if old != nil && old.SchemaFile != "" {
+				ffs.Version = old.Version + 1
+			}
 		}
 		ffs.SchemaFile = schemaFile
 	}
-
-	if info.SoPath != "" {
+	if newSoFile != "" {
 		soFile := filepath.Join(etcDir, info.Name+".so")
-		err := httpx.DownloadFile(soFile, info.SoPath)
-		if err != nil {
+		if err := os.Rename(newSoFile, soFile); err != nil {
 			return err
 		}
 		ffs.SoFile = soFile
 	}
 
 	registry.schemas[info.Type][info.Name] = ffs
+	if old != nil {
+		revision.Add(1)
+	}
 	return nil
 }
 
+func writeSchemaFile(schemaFile string, info *Info) error {
+	if info.Content != "" {
+		return os.WriteFile(schemaFile, cast.StringToBytes(info.Content), 0o666)
+	}
+	return httpx.DownloadFile(schemaFile, info.FilePath)
+}
+
+// checkSchemaFile checks the new schema file against the current version. It returns the content of the current
+// version to archive and false if the content is not changed. If the current version cannot be read, the returned
+// content is nil so that it is overwritten without archiving.
+func checkSchemaFile(info *Info, old *Files, mode string, newFile string) ([]byte, bool, error) {
+	content, err := os.ReadFile(newFile)
+	if err != nil {
+		return nil, false, err
+	}
+	oldContent, err := os.ReadFile(old.SchemaFile)
+	if err != nil {
+		conf.Log.Warnf("cannot read schema file %s, overwrite it without archiving: %s", old.SchemaFile, err)
+		return nil, true, nil
+	}
+	if bytes.Equal(content, oldContent) {
+		return nil, false, nil
+	}
+	if err := checkCompatibility(info.Type, mode, old.SchemaFile, newFile); err != nil {
+		return nil, false, fmt.Errorf("schema %s is not %s compatible with version %d: %v", info.Name, mode, old.Version, err)
+	}
+	return oldContent, true, nil
+}
+
+// GetVersions returns all the versions of a schema in ascending order. The last one is the latest.
+func GetVersions(schemaType def.SchemaType, name string) ([]int, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	if _, ok := registry.schemas[schemaType]; !ok {
+		return nil, fmt.Errorf("schema type %s not found", schemaType)
+	}
+	ffs, ok := registry.schemas[schemaType][name]
+	if !ok {
+		return nil, fmt.Errorf("schema %s.%s not found", schemaType, name)
+	}
+	result := make([]int, 0, len(registry.versions[schemaType][name])+1)
+	for v := range registry.versions[schemaType][name] {
+		result = append(result, v)
+	}
+	sort.Ints(result)
+	return append(result, ffs.Version), nil
+}
+
 func GetSchema(schemaType def.SchemaType, name string) (*Info, error) {
 	schemaFile, err := GetSchemaFile(schemaType, name)
 	if err != nil {
@@ -189,35 +345,51 @@ func GetSchema(schemaType def.SchemaType, name string) (*Info, error) {
 	if schemaFile.SchemaFile != "" {
 		content, err := os.ReadFile(schemaFile.SchemaFile)
 		if err != nil {
-			return nil, fmt.Errorf("cannot read schema file %s: %s", schemaFile, err)
+			return nil, fmt.Errorf("cannot read schema file %s: %s", schemaFile.SchemaFile, err)
 		}
 		return &Info{
-			Type:     schemaType,
-			Name:     name,
-			Content:  string(content),
-			FilePath: schemaFile.SchemaFile,
-			SoPath:   schemaFile.SoFile,
+			Type:          schemaType,
+			Name:          name,
+			Content:       string(content),
+			FilePath:      schemaFile.SchemaFile,
+			SoPath:        schemaFile.SoFile,
+			Version:       schemaFile.Version,
+			Compatibility: schemaFile.Compatibility,
 		}, nil
 	} else {
 		return &Info{
-			Type:   schemaType,
-			Name:   name,
-			SoPath: schemaFile.SoFile,
+			Type:          schemaType,
+			Name:          name,
+			SoPath:        schemaFile.SoFile,
+			Version:       schemaFile.Version,
+			Compatibility: schemaFile.Compatibility,
 		}, nil
 	}
 }
 
+// GetSchemaFile returns the files of the schema. The name can be in the form of name@version to get a specific
+// version, otherwise the latest version is returned.
 func GetSchemaFile(schemaType def.SchemaType, name string) (*Files, error) {
+	base, version, err := SplitVersion(name)
+	if err != nil {
+		return nil, err
+	}
 	registry.RLock()
 	defer registry.RUnlock()
 	if _, ok := registry.schemas[schemaType]; !ok {
 		return nil, fmt.Errorf("schema type %s not found in registry", schemaType)
 	}
-	if _, ok := registry.schemas[schemaType][name]; !ok {
-		return nil, fmt.Errorf("schema type %s, file %s not found", schemaType, name)
+	schemaFile, ok := registry.schemas[schemaType][base]
+	if !ok {
+		return nil, fmt.Errorf("schema type %s, file %s not found", schemaType, base)
 	}
-	schemaFile := registry.schemas[schemaType][name]
-	return schemaFile, nil
+	if version == 0 || version == schemaFile.Version {
+		return schemaFile, nil
+	}
+	if vf, ok := registry.versions[schemaType][base][version]; ok {
+		return vf, nil
+	}
+	return nil, fmt.Errorf("schema type %s, file %s version %d not found", schemaType, base, version)
 }
 
 func DeleteSchema(schemaType def.SchemaType, name string) error {
@@ -236,6 +408,12 @@ func DeleteSchema(schemaType def.SchemaType, name string) error {
 			conf.Log.Errorf("cannot delete schema file %s: %s", schemaFile.SchemaFile, err)
 		}
 	}
+	for _, vf := range registry.versions[schemaType][name] {
+		err := os.Remove(vf.SchemaFile)
+		if err != nil {
+			conf.Log.Errorf("cannot delete schema file %s: %s", vf.SchemaFile, err)
+		}
+	}
 	if schemaFile.SoFile != "" {
 		err := os.Remove(schemaFile.SoFile)
 		if err != nil {
@@ -243,7 +421,9 @@ func DeleteSchema(schemaType def.SchemaType, name string) error {
 		}
 	}
 	delete(registry.schemas[schemaType], name)
+	delete(registry.versions[schemaType], name)
 	removeSchemaInstallScript(schemaType, name)
+	revision.Add(1)
 	return nil
 }
 
@@ -365,6 +545,24 @@ func schemaInstallWhenReboot() {
 	}
 }
 
+// UpdateSchema updates the schema and its install script
+func UpdateSchema(info *Info) error {
+	if err := CreateOrUpdateSchema(info); err != nil {
+		return err
+	}
+	storeSchemaInstallScript(info)
+	return nil
+}
+
+func loadCompatibility(schemaType def.SchemaType, name string) string {
+	_, script := GetSchemaInstallScript(string(schemaType) + "_" + name)
+	info := &Info{}
+	if err := json.Unmarshal(cast.StringToBytes(script), info); err != nil {
+		return ""
+	}
+	return info.Compatibility
+}
+
 func storeSchemaInstallScript(info *Info) {
 	key := string(info.Type) + "_" + info.Name
 	val := info.InstallScript()
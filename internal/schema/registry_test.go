@@ -21,6 +21,9 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/testx"
@@ -83,6 +86,7 @@ func TestProtoRegistry(t *testing.T) {
 		Name:     "test1",
 		Content:  "syntax = \"proto2\";message Person {required string name = 1;optional int32 id = 2;optional string email = 3;repeated ListOfDoubles code = 4;}message ListOfDoubles {repeated double doubles = 1;}",
 		FilePath: filepath.Join(etcDir, "test1.proto"),
+		Version:  1,
 	}
 	gottenSchema, err := GetSchema("protobuf", "test1")
 	if !reflect.DeepEqual(gottenSchema, expectedSchema) {
@@ -122,7 +126,7 @@ func TestProtoRegistry(t *testing.T) {
 		return
 	}
 	expectedFiles := []string{
-		"init.proto", "test1.proto", "test2.proto", "test2.so",
+		"init.proto", "test1.proto", "test2.proto", "test2@1.proto", "test2.so",
 	}
 	checkFile(etcDir, expectedFiles, t)
 	// Delete 2
@@ -152,7 +156,7 @@ func TestProtoRegistry(t *testing.T) {
 		return
 	}
 	expectedFiles = []string{
-		"init.proto", "test1.proto",
+		"init.proto", "test1.proto", "test1@1.proto",
 	}
 	checkFile(etcDir, expectedFiles, t)
 	// Delete 1
@@ -225,9 +229,10 @@ func TestCustomRegistry(t *testing.T) {
 	}
 	// Get 1
 	expectedSchema := &Info{
-		Type:   "custom",
-		Name:   "test1",
-		SoPath: filepath.Join(etcDir, "test1.so"),
+		Type:    "custom",
+		Name:    "test1",
+		SoPath:  filepath.Join(etcDir, "test1.so"),
+		Version: 1,
 	}
 	gottenSchema, err := GetSchema("custom", "test1")
 	if !reflect.DeepEqual(gottenSchema, expectedSchema) {
@@ -303,3 +308,126 @@ func checkFile(etcDir string, schemas []string, t *testing.T) {
 		}
 	}
 }
+
+func TestSchemaVersions(t *testing.T) {
+	etcDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	etcDir = filepath.Join(etcDir, "schemas", "protobuf")
+	require.NoError(t, os.MkdirAll(etcDir, os.ModePerm))
+	defer func() {
+		require.NoError(t, os.RemoveAll(etcDir))
+	}()
+	require.NoError(t, InitRegistry())
+
+	v1 := `syntax = "proto3";message Reading {string id = 1;double value = 2;}`
+	v2 := `syntax = "proto3";message Reading {string id = 1;double value = 2;int64 ts = 3;}`
+	require.NoError(t, Register(&Info{Type: "protobuf", Name: "ver", Content: v1, Compatibility: "backward"}))
+	ffs, err := GetSchemaFile("protobuf", "ver")
+	require.NoError(t, err)
+	require.Equal(t, 1, ffs.Version)
+	ffs, err = GetSchemaFile("protobuf", "ver@1")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(etcDir, "ver.proto"), ffs.SchemaFile)
+	_, err = GetSchemaFile("protobuf", "ver@2")
+	require.EqualError(t, err, "schema type protobuf, file ver version 2 not found")
+	_, err = GetSchemaFile("protobuf", "ver@x")
+	require.EqualError(t, err, "invalid schema version x, must be a positive integer or latest")
+
+	// The same content does not create a new version
+	rev := Revision()
+	require.NoError(t, UpdateSchema(&Info{Type: "protobuf", Name: "ver", Content: v1}))
+	ffs, err = GetSchemaFile("protobuf", "ver")
+	require.NoError(t, err)
+	require.Equal(t, 1, ffs.Version)
+	checkFile(etcDir, []string{"ver.proto"}, t)
+
+	// Add a field is backward compatible
+	require.NoError(t, UpdateSchema(&Info{Type: "protobuf", Name: "ver", Content: v2}))
+	require.Greater(t, Revision(), rev)
+	ffs, err = GetSchemaFile("protobuf", "ver@latest")
+	require.NoError(t, err)
+	require.Equal(t, 2, ffs.Version)
+	require.Equal(t, "backward", ffs.Compatibility)
+	ffs, err = GetSchemaFile("protobuf", "ver@1")
+	require.NoError(t, err)
+	content, err := os.ReadFile(ffs.SchemaFile)
+	require.NoError(t, err)
+	require.Equal(t, v1, string(content))
+	versions, err := GetVersions("protobuf", "ver")
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, versions)
+	checkFile(etcDir, []string{"ver.proto", "ver@1.proto"}, t)
+
+	// Incompatible updates are rejected
+	err = UpdateSchema(&Info{Type: "protobuf", Name: "ver", Content: `syntax = "proto3";message Reading {int64 id = 1;double value = 2;}`})
+	require.EqualError(t, err, "schema ver is not backward compatible with version 2: message Reading: field id cannot change type from bytes to varint")
+	err = UpdateSchema(&Info{Type: "protobuf", Name: "ver", Content: `syntax = "proto3";message Sample {string id = 1;}`})
+	require.EqualError(t, err, "schema ver is not backward compatible with version 2: message Reading is removed")
+	err = CreateOrUpdateSchema(&Info{Type: "protobuf", Name: "ver@3", Content: v1})
+	require.EqualError(t, err, "schema name ver@3 cannot contain @ which is reserved for the version")
+	ffs, err = GetSchemaFile("protobuf", "ver")
+	require.NoError(t, err)
+	require.Equal(t, 2, ffs.Version)
+
+	// The versions and the compatibility are loaded after restart
+	require.NoError(t, InitRegistry())
+	info, err := GetSchema("protobuf", "ver")
+	require.NoError(t, err)
+	require.Equal(t, 2, info.Version)
+	require.Equal(t, "backward", info.Compatibility)
+	require.Equal(t, v2, info.Content)
+	info, err = GetSchema("protobuf", "ver@1")
+	require.NoError(t, err)
+	require.Equal(t, v1, info.Content)
+
+	require.NoError(t, DeleteSchema("protobuf", "ver"))
+	checkFile(etcDir, []string{}, t)
+	_, err = GetSchemaFile("protobuf", "ver@1")
+	require.Error(t, err)
+}
+
+func TestSchemaDownloadWithoutLock(t *testing.T) {
+	etcDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	etcDir = filepath.Join(etcDir, "schemas", "protobuf")
+	require.NoError(t, os.MkdirAll(etcDir, os.ModePerm))
+	defer func() {
+		require.NoError(t, os.RemoveAll(etcDir))
+	}()
+	require.NoError(t, InitRegistry())
+	v1 := `syntax = "proto3";message Reading {string id = 1;double value = 2;}`
+	require.NoError(t, Register(&Info{Type: "protobuf", Name: "slow", Content: v1}))
+
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		_, _ = w.Write([]byte(`syntax = "proto3";message Reading {string id = 1;double value = 2;int64 ts = 3;}`))
+	}))
+	defer ts.Close()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- UpdateSchema(&Info{Type: "protobuf", Name: "slow", FilePath: ts.URL + "/slow.proto"})
+	}()
+	<-requested
+	// The registry is readable and other schemas are writable during the download
+	versions, err := GetVersions("protobuf", "slow")
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, versions)
+	require.NoError(t, Register(&Info{Type: "protobuf", Name: "other", Content: v1}))
+	// The concurrent update of the same schema fails the slow one
+	require.NoError(t, UpdateSchema(&Info{Type: "protobuf", Name: "slow", Content: `syntax = "proto3";message Reading {string id = 1;double value = 2;string name = 3;}`}))
+	close(release)
+	select {
+	case err = <-errCh:
+		require.EqualError(t, err, "schema protobuf.slow is updated concurrently, please retry")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "update schema timeout")
+	}
+	ffs, err := GetSchemaFile("protobuf", "slow")
+	require.NoError(t, err)
+	require.Equal(t, 2, ffs.Version)
+	require.NoError(t, DeleteSchema("protobuf", "slow"))
+	require.NoError(t, DeleteSchema("protobuf", "other"))
+}
@@ -18,15 +18,19 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/lf-edge/ekuiper/v2/internal/converter/avro"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema/jsonschema"
 )
 
 type Info struct {
-	Type     def.SchemaType `json:"type" yaml:"type"`
-	Name     string         `json:"name" yaml:"name"`
-	Content  string         `json:"content,omitempty" yaml:"content,omitempty"`
-	FilePath string         `json:"file,omitempty" yaml:"filePath,omitempty"`
-	SoPath   string         `json:"soFile,omitempty" yaml:"soPath,omitempty"`
+	Type          def.SchemaType `json:"type" yaml:"type"`
+	Name          string         `json:"name" yaml:"name"`
+	Content       string         `json:"content,omitempty" yaml:"content,omitempty"`
+	FilePath      string         `json:"file,omitempty" yaml:"filePath,omitempty"`
+	SoPath        string         `json:"soFile,omitempty" yaml:"soPath,omitempty"`
+	Compatibility string         `json:"compatibility,omitempty" yaml:"compatibility,omitempty"`
+	Version       int            `json:"version,omitempty" yaml:"version,omitempty"`
 }
 
 func (i *Info) InstallScript() string {
@@ -44,11 +48,38 @@ func (i *Info) Validate() error {
 	if i.Content != "" && i.FilePath != "" {
 		return fmt.Errorf("cannot specify both content and file")
 	}
+	switch i.Compatibility {
+	case "", CompatibilityNone:
+	case CompatibilityBackward, CompatibilityForward, CompatibilityFull:
+		if _, ok := compatCheckers[i.Type]; !ok {
+			return fmt.Errorf("compatibility check is not supported for %s schema", i.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported compatibility %s, expect one of none, backward, forward and full", i.Compatibility)
+	}
 	switch i.Type {
 	case def.PROTOBUF:
 		if i.Content == "" && i.FilePath == "" {
 			return fmt.Errorf("must specify content or file")
 		}
+	case def.AVRO:
+		if i.Content == "" && i.FilePath == "" {
+			return fmt.Errorf("must specify content or file")
+		}
+		if i.Content != "" {
+			if _, err := avro.Parse(i.Content); err != nil {
+				return err
+			}
+		}
+	case def.JSONSCHEMA:
+		if i.Content == "" && i.FilePath == "" {
+			return fmt.Errorf("must specify content or file")
+		}
+		if i.Content != "" {
+			if _, err := jsonschema.Compile([]byte(i.Content)); err != nil {
+				return err
+			}
+		}
 	case def.CUSTOM:
 		if i.SoPath == "" {
 			return fmt.Errorf("soFile is required")
@@ -60,5 +91,7 @@ func (i *Info) Validate() error {
 }
 
 var schemaExt = map[def.SchemaType]string{
-	def.PROTOBUF: ".proto",
+	def.PROTOBUF:   ".proto",
+	def.AVRO:       ".avsc",
+	def.JSONSCHEMA: ".json",
 }
@@ -78,6 +78,33 @@ func TestSchemaInfo(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			i: &Info{
+				Type:          "protobuf",
+				Name:          "aa",
+				Content:       "bb",
+				Compatibility: "backward",
+			},
+			err: nil,
+		},
+		{
+			i: &Info{
+				Type:          "protobuf",
+				Name:          "aa",
+				Content:       "bb",
+				Compatibility: "transitive",
+			},
+			err: errors.New("unsupported compatibility transitive, expect one of none, backward, forward and full"),
+		},
+		{
+			i: &Info{
+				Type:          "jsonschema",
+				Name:          "aa",
+				Content:       `{"type": "object"}`,
+				Compatibility: "full",
+			},
+			err: errors.New("compatibility check is not supported for jsonschema schema"),
+		},
 		{
 			i: &Info{
 				Type:   "custom",
@@ -94,6 +121,45 @@ func TestSchemaInfo(t *testing.T) {
 			},
 			err: errors.New("soFile is required"),
 		},
+		{
+			i: &Info{
+				Type: "avro",
+				Name: "aa",
+			},
+			err: errors.New("must specify content or file"),
+		},
+		{
+			i: &Info{
+				Type:    "avro",
+				Name:    "aa",
+				Content: `{"type":"record","name":"a","fields":[{"name":"b","type":"long"}]}`,
+			},
+			err: nil,
+		},
+		{
+			i: &Info{
+				Type:    "avro",
+				Name:    "aa",
+				Content: `{"type":"unknown"}`,
+			},
+			err: errors.New("unknown avro type unknown"),
+		},
+		{
+			i: &Info{
+				Type:    "jsonschema",
+				Name:    "aa",
+				Content: `{"type":"object"}`,
+			},
+			err: nil,
+		},
+		{
+			i: &Info{
+				Type:    "jsonschema",
+				Name:    "aa",
+				Content: `{"type":"obj"}`,
+			},
+			err: errors.New("invalid json schema at #/type: unknown type obj"),
+		},
 	}
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
 	for i, tt := range tests {
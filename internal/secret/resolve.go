@@ -0,0 +1,200 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+)
+
+// refReg matches the secret reference like {{secret "name"}}. Other templates such as the
+// data template of sinks are kept untouched.
+var refReg = regexp.MustCompile(`\{\{\s*secret\s+"([^"]+)"\s*}}`)
+
+// dependents records the rules which have resolved a secret. The key is the secret name.
+var (
+	dependents   = make(map[string]map[string]struct{})
+	dependentsMu sync.RWMutex
+)
+
+// ResolveProps returns a copy of the props with all secret references replaced by the values.
+// The props itself is not changed so that the references can be saved and exported safely.
+// If ctx belongs to a rule, the rule is recorded as a dependent of the referred secrets.
+func ResolveProps(ctx api.StreamContext, props map[string]any) (map[string]any, error) {
+	ruleId := ""
+	if ctx != nil {
+		ruleId = ctx.GetRuleId()
+	}
+	return ResolveRuleProps(ruleId, props)
+}
+
+// ResolveRuleProps is the same as ResolveProps but records the dependent by the rule id. It is used when planning
+// the rule because the rule context is not created yet.
+func ResolveRuleProps(ruleId string, props map[string]any) (map[string]any, error) {
+	if !HasRef(props) {
+		return props, nil
+	}
+	names := make(map[string]struct{})
+	r, err := resolveMap(props, names)
+	if err != nil {
+		return nil, err
+	}
+	if ruleId != "" {
+		addDependent(ruleId, names)
+	}
+	return r, nil
+}
+
+// HasRef checks if any value in the props refers a secret
+func HasRef(props map[string]any) bool {
+	return len(Refs(props)) > 0
+}
+
+// Refs returns the sorted secret names referred in the props
+func Refs(props map[string]any) []string {
+	names := make(map[string]struct{})
+	collectRefs(props, names)
+	result := make([]string, 0, len(names))
+	for n := range names {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Dependents returns the ids of rules which have used the secret
+func Dependents(name string) []string {
+	dependentsMu.RLock()
+	defer dependentsMu.RUnlock()
+	result := make([]string, 0, len(dependents[name]))
+	for r := range dependents[name] {
+		result = append(result, r)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// AddDependent records the rule as a dependent of the secrets
+func AddDependent(ruleId string, names []string) {
+	dependentsMu.Lock()
+	defer dependentsMu.Unlock()
+	for _, n := range names {
+		doAddDependent(ruleId, n)
+	}
+}
+
+// RemoveDependent removes the rule from the dependents of all secrets. It is called when the rule is deleted or
+// before the rule is planned again. It returns the sorted names of the secrets which the rule depended on.
+func RemoveDependent(ruleId string) []string {
+	dependentsMu.Lock()
+	defer dependentsMu.Unlock()
+	var result []string
+	for n, rules := range dependents {
+		if _, ok := rules[ruleId]; !ok {
+			continue
+		}
+		result = append(result, n)
+		delete(rules, ruleId)
+		if len(rules) == 0 {
+			delete(dependents, n)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func addDependent(ruleId string, names map[string]struct{}) {
+	dependentsMu.Lock()
+	defer dependentsMu.Unlock()
+	for n := range names {
+		doAddDependent(ruleId, n)
+	}
+}
+
+func doAddDependent(ruleId string, name string) {
+	if _, ok := dependents[name]; !ok {
+		dependents[name] = make(map[string]struct{})
+	}
+	dependents[name][ruleId] = struct{}{}
+}
+
+func collectRefs(v any, names map[string]struct{}) {
+	switch vt := v.(type) {
+	case string:
+		for _, m := range refReg.FindAllStringSubmatch(vt, -1) {
+			names[m[1]] = struct{}{}
+		}
+	case map[string]any:
+		for _, vv := range vt {
+			collectRefs(vv, names)
+		}
+	case []any:
+		for _, vv := range vt {
+			collectRefs(vv, names)
+		}
+	}
+}
+
+func resolveMap(props map[string]any, names map[string]struct{}) (map[string]any, error) {
+	result := make(map[string]any, len(props))
+	for k, v := range props {
+		nv, err := resolveValue(v, names)
+		if err != nil {
+			return nil, fmt.Errorf("fail to resolve secret for %s: %v", k, err)
+		}
+		result[k] = nv
+	}
+	return result, nil
+}
+
+func resolveValue(v any, names map[string]struct{}) (any, error) {
+	switch vt := v.(type) {
+	case string:
+		return resolveString(vt, names)
+	case map[string]any:
+		return resolveMap(vt, names)
+	case []any:
+		result := make([]any, len(vt))
+		for i, vv := range vt {
+			nv, err := resolveValue(vv, names)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = nv
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+func resolveString(s string, names map[string]struct{}) (string, error) {
+	var err error
+	r := refReg.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return m
+		}
+		name := refReg.FindStringSubmatch(m)[1]
+		var val string
+		val, err = Get(name)
+		names[name] = struct{}{}
+		return val
+	})
+	return r, err
+}
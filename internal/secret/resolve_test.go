@@ -0,0 +1,84 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestResolveProps(t *testing.T) {
+	require.NoError(t, InitManager())
+	_ = Delete("pwd")
+	_ = Delete("token")
+	require.NoError(t, Create("pwd", "p1"))
+	require.NoError(t, Create("token", "t1"))
+	defer func() {
+		_ = Delete("pwd")
+		_ = Delete("token")
+	}()
+
+	props := map[string]any{
+		"server":       "tcp://127.0.0.1:1883",
+		"password":     `{{secret "pwd"}}`,
+		"dataTemplate": `{"a":{{.a}}}`,
+		"headers": map[string]any{
+			"Authorization": `Bearer {{ secret "token" }}`,
+		},
+		"list": []any{`{{secret "pwd"}}`, 1},
+		"qos":  1,
+	}
+	require.Equal(t, []string{"pwd", "token"}, Refs(props))
+	ctx := mockContext.NewMockContext("ruleSecret", "op1")
+	r, err := ResolveProps(ctx, props)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"server":       "tcp://127.0.0.1:1883",
+		"password":     "p1",
+		"dataTemplate": `{"a":{{.a}}}`,
+		"headers": map[string]any{
+			"Authorization": "Bearer t1",
+		},
+		"list": []any{"p1", 1},
+		"qos":  1,
+	}, r)
+	// original props keep the references
+	require.Equal(t, `{{secret "pwd"}}`, props["password"])
+	require.Equal(t, []string{"ruleSecret"}, Dependents("pwd"))
+	require.Equal(t, []string{"ruleSecret"}, Dependents("token"))
+	// The dependents are removed when the rule is deleted or planned again
+	ctx2 := mockContext.NewMockContext("ruleSecret2", "op1")
+	_, err = ResolveProps(ctx2, map[string]any{"password": `{{secret "pwd"}}`})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ruleSecret", "ruleSecret2"}, Dependents("pwd"))
+	require.Equal(t, []string{"pwd", "token"}, RemoveDependent("ruleSecret"))
+	require.Equal(t, []string{"ruleSecret2"}, Dependents("pwd"))
+	require.Empty(t, Dependents("token"))
+	AddDependent("ruleSecret", []string{"pwd", "token"})
+	require.Equal(t, []string{"ruleSecret"}, Dependents("token"))
+	require.Equal(t, []string{"pwd"}, RemoveDependent("ruleSecret2"))
+	require.Nil(t, RemoveDependent("ruleSecret2"))
+
+	noRef := map[string]any{"a": "b"}
+	r, err = ResolveProps(ctx, noRef)
+	require.NoError(t, err)
+	require.Equal(t, noRef, r)
+
+	_, err = ResolveProps(ctx, map[string]any{"password": `{{secret "notExist"}}`})
+	require.EqualError(t, err, "fail to resolve secret for password: secret notExist is not found")
+}
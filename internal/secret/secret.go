@@ -0,0 +1,199 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secret manages the sensitive values such as passwords and tokens.
+// The values are encrypted with AES-GCM before saving into the KV store and
+// can only be referenced by name in the configurations like {{secret "name"}}.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+)
+
+const keyFile = "secret.key"
+
+// RotateHandler is called after the value of an existing secret is changed
+type RotateHandler func(name string)
+
+var (
+	secretDb kv.KeyValue
+	gcm      cipher.AEAD
+	mu       sync.RWMutex
+	handlers []RotateHandler
+)
+
+// InitManager prepares the storage and the cipher. The cipher key is the AES key in the config if set.
+// Otherwise, a random key is generated and saved in the data directory.
+func InitManager() error {
+	mu.Lock()
+	defer mu.Unlock()
+	var err error
+	secretDb, err = store.GetKV("secret")
+	if err != nil {
+		return fmt.Errorf("cannot open secret db: %v", err)
+	}
+	key, err := loadKey()
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	gcm, err = cipher.NewGCM(block)
+	return err
+}
+
+func loadKey() ([]byte, error) {
+	if conf.Config != nil && len(conf.Config.AesKey) > 0 {
+		return conf.Config.AesKey, nil
+	}
+	dataDir, err := conf.GetDataLoc()
+	if err != nil {
+		return nil, err
+	}
+	p := filepath.Join(dataDir, keyFile)
+	if b, err := os.ReadFile(p); err == nil {
+		return base64.StdEncoding.DecodeString(string(b))
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p, []byte(base64.StdEncoding.EncodeToString(key)), 0o600); err != nil {
+		return nil, fmt.Errorf("cannot save secret key: %v", err)
+	}
+	return key, nil
+}
+
+// OnRotate registers a handler which will be called when a secret value is updated
+func OnRotate(h RotateHandler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers = append(handlers, h)
+}
+
+// Create saves a new secret. It returns error if the name already exists.
+func Create(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("secret name is required")
+	}
+	ev, err := encrypt(value)
+	if err != nil {
+		return err
+	}
+	return secretDb.Setnx(name, ev)
+}
+
+// Update changes the value of an existing secret and notifies the rotate handlers
+func Update(name, value string) error {
+	old, err := Get(name)
+	if err != nil {
+		return err
+	}
+	ev, err := encrypt(value)
+	if err != nil {
+		return err
+	}
+	if err := secretDb.Set(name, ev); err != nil {
+		return err
+	}
+	if old != value {
+		mu.RLock()
+		hs := handlers
+		mu.RUnlock()
+		for _, h := range hs {
+			h(name)
+		}
+	}
+	return nil
+}
+
+// Delete removes the secret. The references to it will fail to resolve afterward.
+func Delete(name string) error {
+	if _, err := Get(name); err != nil {
+		return err
+	}
+	return secretDb.Delete(name)
+}
+
+// Get returns the decrypted value of the secret
+func Get(name string) (string, error) {
+	if secretDb == nil {
+		return "", fmt.Errorf("secret manager is not initialized")
+	}
+	var ev string
+	ok, err := secretDb.Get(name, &ev)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("secret %s is not found", name))
+	}
+	return decrypt(ev)
+}
+
+// List returns all the secret names in order. The values are never listed.
+func List() ([]string, error) {
+	if secretDb == nil {
+		return nil, fmt.Errorf("secret manager is not initialized")
+	}
+	keys, err := secretDb.Keys()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func encrypt(value string) (string, error) {
+	if gcm == nil {
+		return "", fmt.Errorf("secret manager is not initialized")
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	b := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func decrypt(ev string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(ev)
+	if err != nil {
+		return "", err
+	}
+	ns := gcm.NonceSize()
+	if len(b) < ns {
+		return "", fmt.Errorf("invalid secret data")
+	}
+	r, err := gcm.Open(nil, b[:ns], b[ns:], nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt secret: %v", err)
+	}
+	return string(r), nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+)
+
+func init() {
+	testx.InitEnv("secret")
+}
+
+func TestSecretLifecycle(t *testing.T) {
+	require.NoError(t, InitManager())
+	_ = Delete("mqttPwd")
+	rotated := make([]string, 0)
+	OnRotate(func(name string) {
+		rotated = append(rotated, name)
+	})
+
+	require.NoError(t, Create("mqttPwd", "p@ss"))
+	require.Error(t, Create("mqttPwd", "other"))
+	require.Error(t, Create("", "other"))
+	v, err := Get("mqttPwd")
+	require.NoError(t, err)
+	require.Equal(t, "p@ss", v)
+	// must be encrypted at rest
+	var raw string
+	ok, err := secretDb.Get("mqttPwd", &raw)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, strings.Contains(raw, "p@ss"))
+
+	names, err := List()
+	require.NoError(t, err)
+	require.Contains(t, names, "mqttPwd")
+
+	// same value does not trigger rotation
+	require.NoError(t, Update("mqttPwd", "p@ss"))
+	require.Empty(t, rotated)
+	require.NoError(t, Update("mqttPwd", "newPass"))
+	require.Equal(t, []string{"mqttPwd"}, rotated)
+	v, err = Get("mqttPwd")
+	require.NoError(t, err)
+	require.Equal(t, "newPass", v)
+	require.Error(t, Update("notExist", "a"))
+
+	require.NoError(t, Delete("mqttPwd"))
+	_, err = Get("mqttPwd")
+	require.EqualError(t, err, "secret mqttPwd is not found")
+	require.Error(t, Delete("mqttPwd"))
+}
+
+func TestKeyPersist(t *testing.T) {
+	require.NoError(t, InitManager())
+	_ = Delete("persist")
+	require.NoError(t, Create("persist", "value"))
+	// Reinit will load the same key
+	require.NoError(t, InitManager())
+	v, err := Get("persist")
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+	require.NoError(t, Delete("persist"))
+}
@@ -598,7 +598,7 @@ func importRuleSetPartial(all processor.Ruleset) processor.Ruleset {
 		_, err := ruleProcessor.GetRuleJson(k)
 		if err == nil {
 			// the rule already exist, update
-			err = registry.UpdateRule(k, v)
+			err = registry.UpdateRule(k, v, true)
 			if err != nil {
 				ruleSetRsp.Rules[k] = err.Error()
 				continue
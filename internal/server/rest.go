@@ -177,6 +177,7 @@ func createRestServer(ip string, port int, needToken bool) *http.Server {
 	r.HandleFunc("/streamdetails", streamDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/streams/{name}", streamHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/streams/{name}/schema", streamSchemaHandler).Methods(http.MethodGet)
+	r.HandleFunc("/streams/{name}/infer", streamInferHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/tables", tablesHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/tabledetails", tableDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/tables/{name}", tableHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
@@ -195,6 +196,8 @@ func createRestServer(ip string, port int, needToken bool) *http.Server {
 	r.HandleFunc("/rules/usage/cpu", rulesTopCpuUsageHandler).Methods(http.MethodGet)
 	r.HandleFunc("/rules/validate", validateRuleHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/reset_state", ruleStateHandler).Methods(http.MethodPut)
+	r.HandleFunc("/rules/{name}/savepoints", savepointsHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/rules/{name}/savepoints/{savepoint}", savepointHandler).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
 	r.HandleFunc("/rules/{name}/explain", explainRuleHandler).Methods(http.MethodGet)
 	r.HandleFunc("/ruleset/export", exportHandler).Methods(http.MethodPost)
 	r.HandleFunc("/ruleset/import", importHandler).Methods(http.MethodPost)
@@ -315,6 +318,23 @@ func explainRuleHandler(w http.ResponseWriter, r *http.Request) {
 		handleError(w, errorx.NewWithCode(errorx.NOT_FOUND, "rule not found"), "", logger)
 		return
 	}
+	if r.URL.Query().Get("analyze") == "true" {
+		var duration time.Duration
+		if d := r.URL.Query().Get("duration"); d != "" {
+			duration, err = time.ParseDuration(d)
+			if err != nil {
+				handleError(w, fmt.Errorf("invalid duration %s: %v", d, err), "explain analyze rules error", logger)
+				return
+			}
+		}
+		result, err := registry.AnalyzeRule(rule, duration, r.URL.Query().Get("start") == "true")
+		if err != nil {
+			handleError(w, err, "explain analyze rules error", logger)
+			return
+		}
+		jsonResponse(result, w, logger)
+		return
+	}
 	if rule.Sql == "" {
 		handleError(w, errors.New("only support explain sql now"), "explain rules error", logger)
 		return
@@ -665,6 +685,34 @@ func sourceSchemaHandler(w http.ResponseWriter, r *http.Request, st ast.StreamTy
 	jsonResponse(content, w, logger)
 }
 
+// infer the schema of a stream by sampling or get the last inferred schema
+func streamInferHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	vars := mux.Vars(r)
+	name := vars["name"]
+	switch r.Method {
+	case http.MethodGet:
+		content, err := streamProcessor.GetInference(name)
+		if err != nil {
+			handleError(w, err, "get inferred schema of stream error", logger)
+			return
+		}
+		jsonResponse(content, w, logger)
+	case http.MethodPost:
+		opts := &processor.InferOptions{}
+		if err := json.NewDecoder(r.Body).Decode(opts); err != nil && err != io.EOF {
+			handleError(w, err, "Invalid body: Error decoding json", logger)
+			return
+		}
+		content, err := streamProcessor.InferStream(name, opts)
+		if err != nil {
+			handleError(w, err, "infer schema of stream error", logger)
+			return
+		}
+		jsonResponse(content, w, logger)
+	}
+}
+
 // list or create rules
 func rulesHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
@@ -729,7 +777,15 @@ func ruleHandler(w http.ResponseWriter, r *http.Request) {
 			handleError(w, err, "Invalid body", logger)
 			return
 		}
-		err = registry.UpdateRule(name, string(body))
+		force := false
+		if fs := r.URL.Query().Get("force"); fs != "" {
+			force, err = strconv.ParseBool(fs)
+			if err != nil {
+				handleError(w, err, "Invalid force parameter", logger)
+				return
+			}
+		}
+		err = registry.UpdateRule(name, string(body), force)
 		if err != nil {
 			handleError(w, err, "Update rule error", logger)
 			return
@@ -788,7 +844,12 @@ func startRuleHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	name := vars["name"]
 
-	err := registry.StartRule(name)
+	var err error
+	if sp := r.URL.Query().Get("savepoint"); sp != "" {
+		err = registry.StartRuleFromSavepoint(name, sp)
+	} else {
+		err = registry.StartRule(name)
+	}
 	if err != nil {
 		handleError(w, err, "start rule error", logger)
 		return
@@ -74,6 +74,7 @@ func (suite *RestTestSuite) SetupTest() {
 	r.HandleFunc("/streamdetails", streamDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/streams/{name}", streamHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/streams/{name}/schema", streamSchemaHandler).Methods(http.MethodGet)
+	r.HandleFunc("/streams/{name}/infer", streamInferHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/tables", tablesHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/tabledetails", tableDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/tables/{name}", tableHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
@@ -446,6 +447,20 @@ func (suite *RestTestSuite) Test_rulesManageHandler() {
 	expect = `Rule rule321 was started`
 	assert.Equal(suite.T(), expect, string(returnVal))
 
+	// explain analyze the running rule
+	req1, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/rules/rule321/explain?analyze=true", bytes.NewBufferString("any"))
+	w1 = httptest.NewRecorder()
+	suite.r.ServeHTTP(w1, req1)
+	require.Equal(suite.T(), http.StatusOK, w1.Code)
+	analysis := &ruleAnalysis{}
+	require.NoError(suite.T(), json.NewDecoder(w1.Result().Body).Decode(analysis))
+	assert.NotEmpty(suite.T(), analysis.Plan)
+	require.Len(suite.T(), analysis.Nodes, 6)
+	assert.Equal(suite.T(), "alert", analysis.Nodes[0].Name)
+	assert.Equal(suite.T(), "source", analysis.Nodes[0].Type)
+	assert.Equal(suite.T(), "2_decoder", analysis.Nodes[1].Name)
+	assert.Equal(suite.T(), "sink", analysis.Nodes[5].Type)
+
 	// start non-existence rule
 	req1, _ = http.NewRequest(http.MethodPost, "http://localhost:8080/rules/non-existence-rule/start", bytes.NewBufferString("any"))
 	w1 = httptest.NewRecorder()
@@ -464,6 +479,31 @@ func (suite *RestTestSuite) Test_rulesManageHandler() {
 	expect = `Rule rule321 was stopped.`
 	assert.Equal(suite.T(), expect, string(returnVal))
 
+	// explain analyze the stopped rule
+	req1, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/rules/rule321/explain?analyze=true", bytes.NewBufferString("any"))
+	w1 = httptest.NewRecorder()
+	suite.r.ServeHTTP(w1, req1)
+	assert.Equal(suite.T(), http.StatusBadRequest, w1.Code)
+	// the stopped rule is only started when it is explicitly asked
+	req1, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/rules/rule321/explain?analyze=true&duration=10ms", bytes.NewBufferString("any"))
+	w1 = httptest.NewRecorder()
+	suite.r.ServeHTTP(w1, req1)
+	assert.Equal(suite.T(), http.StatusBadRequest, w1.Code)
+	req1, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/rules/rule321/explain?analyze=true&start=true&duration=2m", bytes.NewBufferString("any"))
+	w1 = httptest.NewRecorder()
+	suite.r.ServeHTTP(w1, req1)
+	assert.Equal(suite.T(), http.StatusBadRequest, w1.Code)
+	req1, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/rules/rule321/explain?analyze=true&start=true&duration=10ms", bytes.NewBufferString("any"))
+	w1 = httptest.NewRecorder()
+	suite.r.ServeHTTP(w1, req1)
+	require.Equal(suite.T(), http.StatusOK, w1.Code)
+	analysis = &ruleAnalysis{}
+	require.NoError(suite.T(), json.NewDecoder(w1.Result().Body).Decode(analysis))
+	assert.Len(suite.T(), analysis.Nodes, 6)
+	st, err := getRuleState("rule321")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), rule.Stopped, st)
+
 	// stop non-existence rule
 	req1, _ = http.NewRequest(http.MethodPost, "http://localhost:8080/rules/non-existence-rule/stop", bytes.NewBufferString("any"))
 	w1 = httptest.NewRecorder()
@@ -1001,3 +1041,29 @@ func (suite *RestTestSuite) TestWaitStopRule() {
 	require.True(suite.T(), end.Sub(now) >= 300*time.Millisecond)
 	waitAllRuleStop()
 }
+
+func (suite *RestTestSuite) Test_streamInferHandler() {
+	buf := bytes.NewBuffer([]byte(`{"sql":"CREATE STREAM inferRest() WITH (DATASOURCE=\"inferRest\", TYPE=\"memory\", FORMAT=\"JSON\")"}`))
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/streams", buf)
+	w := httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	defer streamProcessor.DropStream("inferRest", ast.TypeStream)
+
+	req, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/streams/inferRest/infer", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "http://localhost:8080/streams/inferRest/infer", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "http://localhost:8080/streams/inferRest/infer", bytes.NewBufferString(`{"samples":1,"timeout":100}`))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	body, _ := io.ReadAll(w.Result().Body)
+	require.Contains(suite.T(), string(body), "no message is received from stream inferRest in 100ms")
+}
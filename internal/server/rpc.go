@@ -207,6 +207,73 @@ func (t *Server) RestartRule(name string, reply *string) error {
 	return nil
 }
 
+func (t *Server) StartRuleFromSavepoint(arg *model.SavepointDesc, reply *string) error {
+	if err := registry.StartRuleFromSavepoint(arg.Rule, arg.Name); err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("Rule %s was started from savepoint %s", arg.Rule, arg.Name)
+	return nil
+}
+
+func (t *Server) CreateSavepoint(arg *model.SavepointDesc, reply *string) error {
+	info, err := registry.CreateSavepoint(arg.Rule, arg.Name)
+	if err != nil {
+		return fmt.Errorf("Create savepoint error : %s.", err)
+	}
+	*reply = fmt.Sprintf("Savepoint %s of rule %s was created with %d operator states.", info.Name, info.RuleId, len(info.Operators))
+	return nil
+}
+
+func (t *Server) ShowSavepoints(rule string, reply *string) error {
+	r, err := registry.ListSavepoints(rule)
+	if err != nil {
+		return fmt.Errorf("Show savepoints error : %s.", err)
+	}
+	if len(r) == 0 {
+		*reply = fmt.Sprintf("No savepoints are found for rule %s.", rule)
+		return nil
+	}
+	result, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Show savepoints error : %s.", err)
+	}
+	*reply = string(result)
+	return nil
+}
+
+func (t *Server) DropSavepoint(arg *model.SavepointDesc, reply *string) error {
+	if err := registry.DeleteSavepoint(arg.Rule, arg.Name); err != nil {
+		return fmt.Errorf("Drop savepoint error : %s.", err)
+	}
+	*reply = fmt.Sprintf("Savepoint %s of rule %s is dropped.", arg.Name, arg.Rule)
+	return nil
+}
+
+func (t *Server) ExportSavepoint(arg *model.SavepointDesc, reply *string) error {
+	content, err := registry.ExportSavepoint(arg.Rule, arg.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(arg.FileName, content, 0o644); err != nil {
+		return fmt.Errorf("fail to save to file %s:%v", arg.FileName, err)
+	}
+	*reply = fmt.Sprintf("Savepoint %s of rule %s was exported to %s.", arg.Name, arg.Rule, arg.FileName)
+	return nil
+}
+
+func (t *Server) ImportSavepoint(arg *model.SavepointDesc, reply *string) error {
+	content, err := os.ReadFile(arg.FileName)
+	if err != nil {
+		return fmt.Errorf("fail to read file %s: %v", arg.FileName, err)
+	}
+	info, err := registry.ImportSavepoint(arg.Rule, arg.Name, content)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("Savepoint %s of rule %s was imported.", info.Name, info.RuleId)
+	return nil
+}
+
 func (t *Server) DescRule(name string, reply *string) error {
 	r, err := ruleProcessor.ExecDesc(name)
 	if err != nil {
@@ -20,10 +20,13 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/metric"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/rule"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -160,8 +163,10 @@ func (rr *RuleRegistry) RecoverRule(r *def.Rule) string {
 	return fmt.Sprintf("Rule %s was started.", r.Id)
 }
 
-// UpdateRule validates the new rule, then update the db, then restart the rule
-func (rr *RuleRegistry) UpdateRule(ruleId, ruleJson string) error {
+// UpdateRule validates the new rule, then update the db, then restart the rule.
+// The states of the running rule are migrated to the new rule by the stable ids of the operators.
+// If some states cannot be migrated, the update is rejected unless it is forced to discard them.
+func (rr *RuleRegistry) UpdateRule(ruleId, ruleJson string, force bool) error {
 	ruleJson = replace.ReplaceRuleJson(ruleJson, conf.IsTesting)
 	// Validate the rule json
 	r, err := ruleProcessor.GetRuleByJson(ruleId, ruleJson)
@@ -176,16 +181,35 @@ func (rr *RuleRegistry) UpdateRule(ruleId, ruleJson string) error {
 	// Try plan with the new json. If err, revert to old rule
 	oldRule := rs.Rule
 	rs.Rule = r
+	// The secrets used by the new rule are recorded again when planning
+	secretNames := secret.RemoveDependent(ruleId)
 	// validateRule only check plan is valid, topology shouldn't be changed before ruleState stop
 	newTopo, err := rs.Validate()
 	if err != nil {
 		rs.Rule = oldRule
+		secret.AddDependent(ruleId, secretNames)
 		return err
 	}
+	oldTopo := rs.GetTopo()
+	if oldTopo != nil && !force {
+		if ops := newTopo.IncompatibleOps(oldTopo); len(ops) > 0 {
+			rs.Rule = oldRule
+			newTopo.Cancel()
+			secret.RemoveDependent(ruleId)
+			secret.AddDependent(ruleId, secretNames)
+			return errorx.NewWithCode(errorx.RuleErr, fmt.Sprintf("the states of operators %s cannot be migrated to the updated rule %s, update with force to discard them", strings.Join(ops, ","), ruleId))
+		}
+	}
 	// Validate successful, save to db
 	err1 := rr.update(r.Id, ruleJson)
-	// ReRun the rule
-	rs.Stop()
+	// ReRun the rule. The stop action may be queued, so wait until the old topo is closed before taking its states
+	if err := rs.StopAndWait(savepointTimeout); err != nil {
+		newTopo.Cancel()
+		return errorx.NewWithCode(errorx.RuleErr, fmt.Sprintf("rule %s is saved but fail to restart: %v", ruleId, err))
+	}
+	if oldTopo != nil {
+		newTopo.RestoreFrom(oldTopo.Savepoint())
+	}
 	if r.Triggered {
 		rs.WithTopo(newTopo)
 		err2 := rs.Start()
@@ -207,6 +231,7 @@ func (rr *RuleRegistry) DeleteRule(name string) error {
 			logger.Errorf("delete rule %s error: %v", name, err)
 		}
 		deleteRuleMetrics(name)
+		secret.RemoveDependent(name)
 	}
 	return err
 }
@@ -345,6 +370,94 @@ func (rr *RuleRegistry) GetRuleTopo(name string) (string, error) {
 	}
 }
 
+type ruleAnalysis struct {
+	// Plan is the logical plan, only available for sql rules
+	Plan  string                 `json:"plan,omitempty"`
+	Nodes []*metric.AnalyzeStats `json:"nodes"`
+}
+
+// maxAnalyzeDuration is the longest time to run a stopped rule for analyze because the request blocks during the run
+const maxAnalyzeDuration = time.Minute
+
+// AnalyzeRule attaches to the running rule to get the live statistics of its nodes.
+// If the rule is not running and start is set, it runs the rule for the duration to collect the statistics
+// and then stops it. The run does not change the triggered status of the rule.
+func (rr *RuleRegistry) AnalyzeRule(r *def.Rule, duration time.Duration, start bool) (*ruleAnalysis, error) {
+	rs, ok := registry.load(r.Id)
+	if !ok {
+		return nil, errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("Rule %s is not found in registry, please check if it is created", r.Id))
+	}
+	result := &ruleAnalysis{}
+	if r.Sql != "" {
+		plan, err := planner.GetExplainInfoFromLogicalPlan(r)
+		if err != nil {
+			return nil, err
+		}
+		result.Plan = plan
+	}
+	if rs.GetState() != rule.Running {
+		if !start || duration <= 0 {
+			return nil, errorx.New(fmt.Sprintf("Rule %s is not running, start it or set start=true with the duration to run it for analyze", r.Id))
+		}
+		if duration > maxAnalyzeDuration {
+			return nil, errorx.New(fmt.Sprintf("duration %s to run rule %s for analyze exceeds the max %s", duration, r.Id, maxAnalyzeDuration))
+		}
+		nodes, err := analyzeStoppedRule(r, duration)
+		if err != nil {
+			return nil, err
+		}
+		result.Nodes = nodes
+		return result, nil
+	}
+	nodes, err := rs.GetAnalyzeStats()
+	if err != nil {
+		return nil, err
+	}
+	result.Nodes = nodes
+	return result, nil
+}
+
+// analyzeStoppedRule runs a temporary copy of the stopped rule for the duration to collect the statistics.
+// The copy writes to the nop sink without checkpoint, so the rule itself, its sinks and its states are untouched.
+func analyzeStoppedRule(r *def.Rule, duration time.Duration) ([]*metric.AnalyzeStats, error) {
+	if r.Sql == "" {
+		return nil, errorx.New(fmt.Sprintf("Rule %s is not running, only the sql rule can be run for analyze", r.Id))
+	}
+	ar := *r
+	ar.Id = "$$_analyze_" + r.Id
+	ar.Actions = []map[string]any{
+		{
+			"nop": map[string]any{},
+		},
+	}
+	opt := *r.Options
+	opt.Qos = def.AtMostOnce
+	opt.EnableSaveStateBeforeStop = false
+	ar.Options = &opt
+	// The secrets referred by the source are recorded for the temporary rule during planning
+	defer secret.RemoveDependent(ar.Id)
+	tp, err := planner.Plan(&ar)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tp.Cancel()
+		tp.WaitClose()
+		tp.RemoveMetrics()
+	}()
+	errCh := tp.Open()
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		if errorx.IsUnexpectedErr(err) {
+			return nil, err
+		}
+	case <-timer.C:
+	}
+	return tp.GetAnalyzeStats(), nil
+}
+
 func (rr *RuleRegistry) ValidateRule(name, ruleJson string) ([]string, bool, error) {
 	// Validate the ruleDef json
 	ruleDef, err := ruleProcessor.GetRuleByJson(name, ruleJson)
@@ -367,6 +480,10 @@ func (rr *RuleRegistry) ValidateRule(name, ruleJson string) ([]string, bool, err
 		}
 	} else if ruleDef.Graph != nil {
 		tp, err := planner.PlanByGraph(ruleDef)
+		// The planning records the secret dependents which only make sense for the created rules
+		if _, ok := rr.load(ruleDef.Id); !ok {
+			secret.RemoveDependent(ruleDef.Id)
+		}
 		if err != nil {
 			return nil, false, fmt.Errorf("invalid ruleDef graph: %v", err)
 		}
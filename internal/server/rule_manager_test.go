@@ -15,20 +15,29 @@
 package server
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/rule"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 func TestErrors(t *testing.T) {
 	// update invalid rule
-	err := registry.UpdateRule("test", "selectabc")
+	err := registry.UpdateRule("test", "selectabc", false)
 	assert.EqualError(t, err, "Invalid rule json: Parse rule selectabc error : invalid character 's' looking for beginning of value.")
 	// update rule, no id
-	err = registry.UpdateRule("test", `{"id":"test","sql":"SELECT * FROM demo","actions":[{"log":{}}]}`)
+	err = registry.UpdateRule("test", `{"id":"test","sql":"SELECT * FROM demo","actions":[{"log":{}}]}`, false)
 	assert.EqualError(t, err, "Rule test is not found in registry, please check if it is created")
 	// delete rule, no id
 	err = registry.DeleteRule("test")
@@ -53,3 +62,128 @@ func TestErrors(t *testing.T) {
 	err = registry.StartRule("test")
 	assert.EqualError(t, err, "fail to get stream demo, please check if stream is created")
 }
+
+func TestUpdateRuleMigrate(t *testing.T) {
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM migrateTest() WITH (DATASOURCE="migrate", TYPE="memory", FORMAT="json")`)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = streamProcessor.ExecStreamSql(`DROP STREAM migrateTest`)
+	}()
+	_, err = registry.CreateRule("migrate", `{"id":"migrate","sql":"SELECT count(*) FROM migrateTest GROUP BY TUMBLINGWINDOW(ss, 10)","actions":[{"log":{}}]}`)
+	require.NoError(t, err)
+	defer func() {
+		_ = registry.DeleteRule("migrate")
+	}()
+	// compatible update: add filter and field
+	err = registry.UpdateRule("migrate", `{"id":"migrate","sql":"SELECT count(*), max(a) FROM migrateTest WHERE a > 1 GROUP BY TUMBLINGWINDOW(ss, 10)","actions":[{"log":{}}]}`, false)
+	require.NoError(t, err)
+	// incompatible update is rejected and the rule is not changed
+	err = registry.UpdateRule("migrate", `{"id":"migrate","sql":"SELECT count(*) FROM migrateTest GROUP BY TUMBLINGWINDOW(ss, 20)","actions":[{"log":{}}]}`, false)
+	require.Error(t, err)
+	ec, ok := err.(errorx.ErrorWithCode)
+	require.True(t, ok)
+	assert.Equal(t, errorx.RuleErr, ec.Code())
+	rs, ok := registry.load("migrate")
+	require.True(t, ok)
+	assert.Equal(t, "SELECT count(*), max(a) FROM migrateTest WHERE a > 1 GROUP BY TUMBLINGWINDOW(ss, 10)", rs.Rule.Sql)
+	// forced update discards the states
+	err = registry.UpdateRule("migrate", `{"id":"migrate","sql":"SELECT count(*) FROM migrateTest GROUP BY TUMBLINGWINDOW(ss, 20)","actions":[{"log":{}}]}`, true)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT count(*) FROM migrateTest GROUP BY TUMBLINGWINDOW(ss, 20)", rs.Rule.Sql)
+}
+
+func TestSecretDependents(t *testing.T) {
+	require.NoError(t, secret.InitManager())
+	_ = secret.Delete("depPwd")
+	require.NoError(t, secret.Create("depPwd", "p1"))
+	defer func() {
+		_ = secret.Delete("depPwd")
+	}()
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM secretTest() WITH (DATASOURCE="secret", TYPE="memory", FORMAT="json")`)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = streamProcessor.ExecStreamSql(`DROP STREAM secretTest`)
+	}()
+	_, err = registry.CreateRule("secretDep", `{"id":"secretDep","sql":"SELECT * FROM secretTest","actions":[{"log":{"password":"{{secret \"depPwd\"}}"}}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secretDep"}, secret.Dependents("depPwd"))
+	// invalid update keeps the dependents
+	err = registry.UpdateRule("secretDep", `{"id":"secretDep","sql":"SELECT * FROM notExist","actions":[{"log":{}}]}`, false)
+	require.Error(t, err)
+	assert.Equal(t, []string{"secretDep"}, secret.Dependents("depPwd"))
+	// the secret is not used after update
+	err = registry.UpdateRule("secretDep", `{"id":"secretDep","sql":"SELECT * FROM secretTest","actions":[{"log":{}}]}`, false)
+	require.NoError(t, err)
+	assert.Empty(t, secret.Dependents("depPwd"))
+	err = registry.UpdateRule("secretDep", `{"id":"secretDep","sql":"SELECT * FROM secretTest","actions":[{"log":{"password":"{{secret \"depPwd\"}}"}}]}`, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secretDep"}, secret.Dependents("depPwd"))
+	require.NoError(t, registry.DeleteRule("secretDep"))
+	assert.Empty(t, secret.Dependents("depPwd"))
+	// the sinks of graph rules are resolved too
+	graph := `{"id":"secretGraph","graph":{"nodes":{"src":{"type":"source","nodeType":"memory","props":{"sourceType":"stream","sourceName":"secretTest"}},"log":{"type":"sink","nodeType":"log","props":{"password":"{{secret \"%s\"}}"}}},"topo":{"sources":["src"],"edges":{"src":["log"]}}}}`
+	_, err = registry.CreateRule("secretGraph", fmt.Sprintf(graph, "notExist"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notExist")
+	_, err = registry.CreateRule("secretGraph", fmt.Sprintf(graph, "depPwd"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secretGraph"}, secret.Dependents("depPwd"))
+	require.NoError(t, registry.DeleteRule("secretGraph"))
+	assert.Empty(t, secret.Dependents("depPwd"))
+}
+
+func TestAnalyzeStoppedRule(t *testing.T) {
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM analyzeTest() WITH (DATASOURCE="analyzeIn", TYPE="memory", FORMAT="json")`)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = streamProcessor.ExecStreamSql(`DROP STREAM analyzeTest`)
+	}()
+	_, err = registry.CreateRule("analyzeStopped", `{"id":"analyzeStopped","triggered":false,"sql":"SELECT * FROM analyzeTest","actions":[{"memory":{"topic":"analyzeOut"}}]}`)
+	require.NoError(t, err)
+	defer func() {
+		_ = registry.DeleteRule("analyzeStopped")
+	}()
+	out := pubsub.CreateSub("analyzeOut", nil, "analyzeTestSub", 100)
+	defer pubsub.CloseSourceConsumerChannel("analyzeOut", "analyzeTestSub")
+	r, err := ruleProcessor.GetRuleById("analyzeStopped")
+	require.NoError(t, err)
+
+	ctx := mockContext.NewMockContext("analyzeStopped", "pub")
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				pubsub.Produce(ctx, "analyzeIn", &xsql.Tuple{Message: map[string]any{"a": 1}, Timestamp: timex.GetNow()})
+			}
+		}
+	}()
+	defer close(done)
+	result, err := registry.AnalyzeRule(r, 200*time.Millisecond, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Nodes)
+	assert.Greater(t, result.Nodes[0].RecordsIn, int64(0))
+	// the actions of the rule are not triggered by the analysis
+	select {
+	case v := <-out:
+		assert.Fail(t, "the rule sink received data during the analysis", "%v", v)
+	default:
+	}
+	st, err := getRuleState("analyzeStopped")
+	require.NoError(t, err)
+	assert.Equal(t, rule.Stopped, st)
+
+	// the rule started by the user during the analysis keeps running
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		assert.NoError(t, registry.StartRule("analyzeStopped"))
+	}()
+	_, err = registry.AnalyzeRule(r, 200*time.Millisecond, true)
+	require.NoError(t, err)
+	st, err = getRuleState("analyzeStopped")
+	require.NoError(t, err)
+	assert.Equal(t, rule.Running, st)
+	require.NoError(t, registry.StopRule("analyzeStopped"))
+}
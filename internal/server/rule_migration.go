@@ -32,6 +32,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
 type RuleMigrationProcessor struct {
@@ -112,7 +113,7 @@ func ruleTraverse(rule *def.Rule, de *dependencies) {
 			// get schema id
 			if streamStmt.Options.SCHEMAID != "" {
 				r := strings.Split(streamStmt.Options.SCHEMAID, ".")
-				de.schemas = append(de.schemas, streamStmt.Options.FORMAT+"_"+r[0])
+				de.schemas = append(de.schemas, schemaTypeOfFormat(streamStmt.Options.FORMAT)+"_"+r[0])
 			}
 		}
 		// actions
@@ -177,7 +178,7 @@ func ruleTraverse(rule *def.Rule, de *dependencies) {
 				// get schema id
 				if sourceOption.SCHEMAID != "" {
 					r := strings.Split(sourceOption.SCHEMAID, ".")
-					de.schemas = append(de.schemas, sourceOption.FORMAT+"_"+r[0])
+					de.schemas = append(de.schemas, schemaTypeOfFormat(sourceOption.FORMAT)+"_"+r[0])
 				}
 			case "sink":
 				sinkType := gn.NodeType
@@ -436,6 +437,14 @@ func (p *RuleMigrationProcessor) exportSelected(de *dependencies, config *Config
 	config.Uploads = uploadsExport()
 }
 
+// schemaTypeOfFormat returns the schema type referred by the schemaId of the format
+func schemaTypeOfFormat(format string) string {
+	if strings.EqualFold(format, message.FormatJson) {
+		return string(def.JSONSCHEMA)
+	}
+	return format
+}
+
 func parsePick(props map[string]interface{}) (*ast.SelectStatement, error) {
 	n := &graph.Select{}
 	err := cast.MapToStruct(props, n)
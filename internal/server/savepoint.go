@@ -0,0 +1,347 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store/encoding"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/cache"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/rule"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// SavepointInfo is the metadata of a savepoint
+type SavepointInfo struct {
+	Name      string `json:"name"`
+	RuleId    string `json:"ruleId"`
+	Timestamp int64  `json:"timestamp"`
+	// The stable ids of the operators whose states are saved
+	Operators []string `json:"operators"`
+}
+
+// savepointFile is the portable format of a savepoint. It is gob encoded when exported.
+type savepointFile struct {
+	Info   SavepointInfo
+	States state.Savepoint
+}
+
+const savepointTable = "savepoint"
+
+func savepointKey(ruleId, name string) string {
+	return ruleId + "/" + name
+}
+
+// savepointTimeout is the max time to take the checkpoint or to stop the rule for a savepoint
+const savepointTimeout = 30 * time.Second
+
+// CreateSavepoint takes a named savepoint of the running rule. To get the consistent states including the
+// source offsets, the savepoint is taken by a checkpoint barrier without stopping the rule if the checkpoint is
+// enabled by qos. Otherwise, the rule is stopped to take the snapshot and then started again from the snapshot.
+// The savepoint is saved before restarting, so it is returned along with the restart error if the restart fails.
+func (rr *RuleRegistry) CreateSavepoint(ruleId, name string) (*SavepointInfo, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid savepoint name %q", name)
+	}
+	rs, ok := rr.load(ruleId)
+	if !ok {
+		return nil, errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("Rule %s is not found in registry, please check if it is created", ruleId))
+	}
+	tp := rs.GetTopo()
+	if tp == nil {
+		return nil, errorx.NewWithCode(errorx.RuleErr, fmt.Sprintf("rule %s is not running", ruleId))
+	}
+	sp, err := tp.CheckpointSavepoint(savepointTimeout)
+	stopped := false
+	if err != nil {
+		if !errors.Is(err, topo.ErrNoCheckpoint) {
+			logger.Warnf("take savepoint %s of rule %s by checkpoint error: %v, stop the rule to take it", name, ruleId, err)
+		}
+		if err := rs.StopAndWait(savepointTimeout); err != nil {
+			return nil, err
+		}
+		stopped = true
+		sp = tp.Savepoint()
+	}
+	f := &savepointFile{
+		Info: SavepointInfo{
+			Name:      name,
+			RuleId:    ruleId,
+			Timestamp: timex.GetNowInMilli(),
+			Operators: savepointOperators(sp),
+		},
+		States: sp,
+	}
+	saveErr := saveSavepoint(f)
+	if stopped {
+		if err := restartFrom(rs, sp); err != nil {
+			if saveErr != nil {
+				return nil, fmt.Errorf("%v, and restart rule %s error: %v", saveErr, ruleId, err)
+			}
+			return &f.Info, errorx.NewWithCode(errorx.RuleErr, fmt.Sprintf("savepoint %s is saved but restart rule %s error: %v", name, ruleId, err))
+		}
+	}
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	return &f.Info, nil
+}
+
+// StartRuleFromSavepoint (re)starts the rule with the states restored from the savepoint
+func (rr *RuleRegistry) StartRuleFromSavepoint(ruleId, name string) error {
+	rs, ok := rr.load(ruleId)
+	if !ok {
+		return errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("Rule %s is not found in registry, please check if it is created", ruleId))
+	}
+	f, err := loadSavepoint(ruleId, name)
+	if err != nil {
+		return err
+	}
+	err = rr.updateTrigger(ruleId, true)
+	if err != nil {
+		logger.Warnf("start rule update db status error: %s", err.Error())
+	}
+	rs.Stop()
+	return restartFrom(rs, f.States)
+}
+
+func (rr *RuleRegistry) ListSavepoints(ruleId string) ([]*SavepointInfo, error) {
+	db, err := store.GetKV(savepointTable)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := db.Keys()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*SavepointInfo, 0)
+	for _, k := range keys {
+		if !strings.HasPrefix(k, ruleId+"/") {
+			continue
+		}
+		f := &savepointFile{}
+		if ok, err := db.Get(k, f); err != nil {
+			return nil, err
+		} else if ok {
+			result = append(result, &f.Info)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Timestamp == result[j].Timestamp {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Timestamp < result[j].Timestamp
+	})
+	return result, nil
+}
+
+func (rr *RuleRegistry) DeleteSavepoint(ruleId, name string) error {
+	if _, err := loadSavepoint(ruleId, name); err != nil {
+		return err
+	}
+	db, err := store.GetKV(savepointTable)
+	if err != nil {
+		return err
+	}
+	return db.Delete(savepointKey(ruleId, name))
+}
+
+// ExportSavepoint encodes the savepoint to a portable file content.
+// The spilled window rows are saved in the local disk and only referred by the savepoint, so the savepoint
+// with spilled state is not portable and cannot be exported.
+func (rr *RuleRegistry) ExportSavepoint(ruleId, name string) ([]byte, error) {
+	f, err := loadSavepoint(ruleId, name)
+	if err != nil {
+		return nil, err
+	}
+	if ops := spilledOperators(f.States); len(ops) > 0 {
+		return nil, fmt.Errorf("savepoint %s of rule %s cannot be exported because the operators %v have spilled state in the local disk", name, ruleId, ops)
+	}
+	return encoding.Encode(f)
+}
+
+// ImportSavepoint saves the exported savepoint content as the named savepoint of the rule.
+// The rule may be on another node, so the rule id in the content is replaced.
+func (rr *RuleRegistry) ImportSavepoint(ruleId, name string, content []byte) (*SavepointInfo, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid savepoint name %q", name)
+	}
+	f := &savepointFile{}
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(f); err != nil {
+		return nil, fmt.Errorf("invalid savepoint file: %v", err)
+	}
+	if ops := spilledOperators(f.States); len(ops) > 0 {
+		return nil, fmt.Errorf("invalid savepoint file: the operators %v have spilled state which refers to the local disk of another node", ops)
+	}
+	f.Info.RuleId = ruleId
+	f.Info.Name = name
+	if err := saveSavepoint(f); err != nil {
+		return nil, err
+	}
+	return &f.Info, nil
+}
+
+func restartFrom(rs *rule.State, sp state.Savepoint) error {
+	tp, err := rs.Validate()
+	if err != nil {
+		return err
+	}
+	tp.RestoreFrom(sp)
+	rs.WithTopo(tp)
+	return rs.Start()
+}
+
+func saveSavepoint(f *savepointFile) error {
+	db, err := store.GetKV(savepointTable)
+	if err != nil {
+		return err
+	}
+	if err := db.Set(savepointKey(f.Info.RuleId, f.Info.Name), f); err != nil {
+		return fmt.Errorf("save savepoint %s error: %v", f.Info.Name, err)
+	}
+	return nil
+}
+
+func loadSavepoint(ruleId, name string) (*savepointFile, error) {
+	db, err := store.GetKV(savepointTable)
+	if err != nil {
+		return nil, err
+	}
+	f := &savepointFile{}
+	ok, err := db.Get(savepointKey(ruleId, name), f)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("savepoint %s of rule %s is not found", name, ruleId))
+	}
+	return f, nil
+}
+
+// spilledOperators returns the operators whose states refer to the spilled pages
+func spilledOperators(sp state.Savepoint) []string {
+	var result []string
+	for k, states := range sp {
+		if pages, ok := states[node.WindowSpillKey].([]cache.SpillPage); ok && len(pages) > 0 {
+			result = append(result, k)
+			continue
+		}
+		if tables, ok := states[node.TableSpillKey].(map[string]node.TableSpill); ok {
+			for _, t := range tables {
+				if len(t.Pages) > 0 {
+					result = append(result, k)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func savepointOperators(sp state.Savepoint) []string {
+	result := make([]string, 0, len(sp))
+	for k := range sp {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+type savepointRequest struct {
+	Name string `json:"name"`
+}
+
+// list or create savepoints of a rule
+func savepointsHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	ruleId := mux.Vars(r)["name"]
+	switch r.Method {
+	case http.MethodGet:
+		result, err := registry.ListSavepoints(ruleId)
+		if err != nil {
+			handleError(w, err, "list savepoints error", logger)
+			return
+		}
+		jsonResponse(result, w, logger)
+	case http.MethodPost:
+		req := &savepointRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		info, err := registry.CreateSavepoint(ruleId, req.Name)
+		if err != nil {
+			handleError(w, err, "create savepoint error", logger)
+			return
+		}
+		jsonResponse(info, w, logger)
+	}
+}
+
+// export, import or delete a savepoint
+func savepointHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	vars := mux.Vars(r)
+	ruleId := vars["name"]
+	name := vars["savepoint"]
+	switch r.Method {
+	case http.MethodGet:
+		content, err := registry.ExportSavepoint(ruleId, name)
+		if err != nil {
+			handleError(w, err, "export savepoint error", logger)
+			return
+		}
+		w.Header().Set(ContentType, "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s_%s.savepoint", ruleId, name))
+		_, _ = w.Write(content)
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		info, err := registry.ImportSavepoint(ruleId, name, content)
+		if err != nil {
+			handleError(w, err, "import savepoint error", logger)
+			return
+		}
+		jsonResponse(info, w, logger)
+	case http.MethodDelete:
+		err := registry.DeleteSavepoint(ruleId, name)
+		if err != nil {
+			handleError(w, err, "delete savepoint error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, "Savepoint %s of rule %s is dropped.", name, ruleId)
+	}
+}
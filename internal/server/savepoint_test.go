@@ -0,0 +1,205 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store/encoding"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/cache"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/rule"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+func TestSavepointLC(t *testing.T) {
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM savepointTest() WITH (DATASOURCE="savepoint", TYPE="memory", FORMAT="json")`)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = streamProcessor.ExecStreamSql(`DROP STREAM savepointTest`)
+	}()
+	_, err = registry.CreateRule("sp", `{"id":"sp","sql":"SELECT count(*) FROM savepointTest GROUP BY TUMBLINGWINDOW(ss, 10)","actions":[{"log":{}}],"triggered":false}`)
+	require.NoError(t, err)
+	defer func() {
+		_ = registry.DeleteRule("sp")
+	}()
+	// the rule is not running
+	_, err = registry.CreateSavepoint("sp", "sp1")
+	require.Error(t, err)
+	_, err = registry.CreateSavepoint("notexist", "sp1")
+	assert.Equal(t, errorx.NOT_FOUND, err.(errorx.ErrorWithCode).Code())
+	require.NoError(t, registry.StartRule("sp"))
+	_, err = registry.CreateSavepoint("sp", "a/b")
+	require.EqualError(t, err, `invalid savepoint name "a/b"`)
+	info, err := registry.CreateSavepoint("sp", "sp1")
+	require.NoError(t, err)
+	assert.Equal(t, "sp1", info.Name)
+	assert.Equal(t, "sp", info.RuleId)
+	// the rule keeps running after taking the savepoint
+	rs, ok := registry.load("sp")
+	require.True(t, ok)
+	assert.NotNil(t, rs.GetTopo())
+
+	// import a savepoint exported from another node
+	content, err := encoding.Encode(&savepointFile{
+		Info: SavepointInfo{Name: "other", RuleId: "otherRule", Timestamp: info.Timestamp + 1, Operators: []string{"source:savepointTest"}},
+		States: state.Savepoint{
+			"source:savepointTest": {"$$offset": 10},
+		},
+	})
+	require.NoError(t, err)
+	_, err = registry.ImportSavepoint("sp", "sp2", []byte("invalid"))
+	require.Error(t, err)
+	imported, err := registry.ImportSavepoint("sp", "sp2", content)
+	require.NoError(t, err)
+	assert.Equal(t, &SavepointInfo{Name: "sp2", RuleId: "sp", Timestamp: info.Timestamp + 1, Operators: []string{"source:savepointTest"}}, imported)
+	exported, err := registry.ExportSavepoint("sp", "sp2")
+	require.NoError(t, err)
+	reimported, err := registry.ImportSavepoint("sp", "sp3", exported)
+	require.NoError(t, err)
+	assert.Equal(t, "sp3", reimported.Name)
+	assert.Equal(t, imported.Operators, reimported.Operators)
+	// the spilled state is in the local disk which cannot be exported
+	spilled := &savepointFile{
+		Info: SavepointInfo{Name: "spilled", RuleId: "sp", Operators: []string{"join_aligner", "window:tumbling"}},
+		States: state.Savepoint{
+			"window:tumbling": {node.WindowSpillKey: []cache.SpillPage{{Key: 0, Count: 1, Table: "spill/sp1_window0"}}},
+			"join_aligner":    {node.TableSpillKey: map[string]node.TableSpill{"t1": {Pages: []cache.SpillPage{{Key: 0, Count: 1, Table: "spill/sp1_join_aligner0/t1"}}}}},
+		},
+	}
+	require.NoError(t, saveSavepoint(spilled))
+	_, err = registry.ExportSavepoint("sp", "spilled")
+	require.EqualError(t, err, "savepoint spilled of rule sp cannot be exported because the operators [join_aligner window:tumbling] have spilled state in the local disk")
+	content, err = encoding.Encode(spilled)
+	require.NoError(t, err)
+	_, err = registry.ImportSavepoint("sp", "spilled2", content)
+	require.EqualError(t, err, "invalid savepoint file: the operators [join_aligner window:tumbling] have spilled state which refers to the local disk of another node")
+	require.NoError(t, registry.DeleteSavepoint("sp", "spilled"))
+
+	list, err := registry.ListSavepoints("sp")
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, "sp1", list[0].Name)
+
+	require.NoError(t, registry.StartRuleFromSavepoint("sp", "sp2"))
+	assert.NotNil(t, rs.GetTopo())
+	err = registry.StartRuleFromSavepoint("sp", "notexist")
+	assert.Equal(t, errorx.NOT_FOUND, err.(errorx.ErrorWithCode).Code())
+
+	for _, n := range []string{"sp1", "sp2", "sp3"} {
+		require.NoError(t, registry.DeleteSavepoint("sp", n))
+	}
+	err = registry.DeleteSavepoint("sp", "sp1")
+	assert.Equal(t, errorx.NOT_FOUND, err.(errorx.ErrorWithCode).Code())
+	list, err = registry.ListSavepoints("sp")
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestSavepointCheckpoint(t *testing.T) {
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM savepointCp() WITH (DATASOURCE="savepointCp", TYPE="memory", FORMAT="json")`)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = streamProcessor.ExecStreamSql(`DROP STREAM savepointCp`)
+	}()
+	_, err = registry.CreateRule("spCp", `{"id":"spCp","sql":"SELECT count(*) FROM savepointCp GROUP BY TUMBLINGWINDOW(ss, 10)","actions":[{"log":{}}],"options":{"qos":1}}`)
+	require.NoError(t, err)
+	defer func() {
+		_ = registry.DeleteRule("spCp")
+		_ = registry.DeleteSavepoint("spCp", "cp1")
+	}()
+	rs, ok := registry.load("spCp")
+	require.True(t, ok)
+	var tp *topo.Topo
+	require.Eventually(t, func() bool {
+		tp = rs.GetTopo()
+		return tp != nil && rs.GetState() == rule.Running && tp.GetCoordinator().IsActivated()
+	}, 5*time.Second, 10*time.Millisecond)
+	info, err := registry.CreateSavepoint("spCp", "cp1")
+	require.NoError(t, err)
+	assert.Equal(t, "cp1", info.Name)
+	// taken by the checkpoint barrier without restarting the rule
+	assert.Same(t, tp, rs.GetTopo())
+	assert.Equal(t, rule.Running, rs.GetState())
+	f, err := loadSavepoint("spCp", "cp1")
+	require.NoError(t, err)
+	assert.Equal(t, info.Operators, savepointOperators(f.States))
+}
+
+func TestSavepointRest(t *testing.T) {
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM savepointRest() WITH (DATASOURCE="savepointRest", TYPE="memory", FORMAT="json")`)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = streamProcessor.ExecStreamSql(`DROP STREAM savepointRest`)
+	}()
+	_, err = registry.CreateRule("spRest", `{"id":"spRest","sql":"SELECT * FROM savepointRest","actions":[{"log":{}}]}`)
+	require.NoError(t, err)
+	defer func() {
+		_ = registry.DeleteRule("spRest")
+	}()
+	r := mux.NewRouter()
+	r.HandleFunc("/rules/{name}/start", startRuleHandler).Methods(http.MethodPost)
+	r.HandleFunc("/rules/{name}/savepoints", savepointsHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/rules/{name}/savepoints/{savepoint}", savepointHandler).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
+	do := func(method, url string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/rules/spRest/savepoints", []byte(`{"name":"sp1"}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	info := &SavepointInfo{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), info))
+	assert.Equal(t, "sp1", info.Name)
+
+	w = do(http.MethodGet, "/rules/spRest/savepoints", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []*SavepointInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "sp1", list[0].Name)
+
+	w = do(http.MethodGet, "/rules/spRest/savepoints/sp1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/octet-stream", w.Header().Get(ContentType))
+	content := w.Body.Bytes()
+	w = do(http.MethodPut, "/rules/spRest/savepoints/copied", content)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = do(http.MethodPost, "/rules/spRest/start?savepoint=copied", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = do(http.MethodPost, "/rules/spRest/start?savepoint=notexist", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(http.MethodDelete, "/rules/spRest/savepoints/sp1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = do(http.MethodDelete, "/rules/spRest/savepoints/copied", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = do(http.MethodGet, "/rules/spRest/savepoints/sp1", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -47,6 +47,7 @@ func (sc schemaComp) register() {
 func (sc schemaComp) rest(r *mux.Router) {
 	r.HandleFunc("/schemas/{type}", schemasHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/schemas/{type}/{name}", schemaHandler).Methods(http.MethodPut, http.MethodDelete, http.MethodGet)
+	r.HandleFunc("/schemas/{type}/{name}/versions", schemaVersionsHandler).Methods(http.MethodGet)
 }
 
 func (sc schemaComp) exporter() ConfManager {
@@ -136,7 +137,7 @@ func schemaHandler(w http.ResponseWriter, r *http.Request) {
 			handleError(w, nil, "Invalid body", logger)
 			return
 		}
-		err = schema.CreateOrUpdateSchema(sch)
+		err = schema.UpdateSchema(sch)
 		if err != nil {
 			handleError(w, err, "schema update command error", logger)
 			return
@@ -151,6 +152,17 @@ func schemaHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func schemaVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	vars := mux.Vars(r)
+	l, err := schema.GetVersions(def.SchemaType(vars["type"]), vars["name"])
+	if err != nil {
+		handleError(w, err, "", logger)
+		return
+	}
+	jsonResponse(l, w, logger)
+}
+
 type schemaExporter struct{}
 
 func (e schemaExporter) Import(ctx context.Context, s map[string]string) map[string]string {
@@ -54,6 +54,24 @@ func (suite *SchemaTestSuite) TestSchema() {
 	suite.r.ServeHTTP(w, req)
 	suite.Equal(http.StatusOK, w.Code)
 
+	proto = `{"type": "protobuf", "name": "test", "content": "message ListOfDoubles {repeated double doubles=1;optional string name=2;}"}`
+	req, _ = http.NewRequest(http.MethodPut, "/schemas/protobuf/test", bytes.NewBufferString(proto))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/schemas/protobuf/test/versions", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`[1,2]`, w.Body.String())
+
+	req, _ = http.NewRequest(http.MethodGet, "/schemas/protobuf/test@1", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"version":1`)
+
 	req, _ = http.NewRequest(http.MethodDelete, "/schemas/protobuf/test", bytes.NewBufferString("any"))
 	w = httptest.NewRecorder()
 	suite.r.ServeHTTP(w, req)
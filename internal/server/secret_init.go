@@ -0,0 +1,173 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/rule"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+func init() {
+	components["secret"] = secretComp{}
+}
+
+// secretComp does not implement confExporter on purpose so that the secret values never go to the exported data
+type secretComp struct{}
+
+func (sc secretComp) register() {
+	err := secret.InitManager()
+	if err != nil {
+		panic(err)
+	}
+	secret.OnRotate(restartSecretDependents)
+}
+
+func (sc secretComp) rest(r *mux.Router) {
+	r.HandleFunc("/secrets", secretsHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/secrets/{name}", secretHandler).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
+}
+
+type secretRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type secretResponse struct {
+	Name  string   `json:"name"`
+	Rules []string `json:"rules"`
+}
+
+func secretsHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodGet:
+		l, err := secret.List()
+		if err != nil {
+			handleError(w, err, "secret list command error", logger)
+			return
+		}
+		jsonResponse(l, w, logger)
+	case http.MethodPost:
+		req := &secretRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			handleError(w, err, "Invalid body: Error decoding secret json", logger)
+			return
+		}
+		if err := secret.Create(req.Name, req.Value); err != nil {
+			handleError(w, err, "secret create command error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "secret %s is created", req.Name)
+	}
+}
+
+func secretHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	name := mux.Vars(r)["name"]
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := secret.Get(name); err != nil {
+			handleError(w, err, "describe secret error", logger)
+			return
+		}
+		jsonResponse(&secretResponse{Name: name, Rules: secret.Dependents(name)}, w, logger)
+	case http.MethodPut:
+		req := &secretRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			handleError(w, err, "Invalid body: Error decoding secret json", logger)
+			return
+		}
+		if req.Name != "" && req.Name != name {
+			handleError(w, fmt.Errorf("name %s does not match", req.Name), "Invalid body", logger)
+			return
+		}
+		if err := secret.Update(name, req.Value); err != nil {
+			handleError(w, err, "secret update command error", logger)
+			return
+		}
+		fmt.Fprintf(w, "secret %s is updated", name)
+	case http.MethodDelete:
+		if err := secret.Delete(name); err != nil {
+			handleError(w, err, "delete secret error", logger)
+			return
+		}
+		fmt.Fprintf(w, "secret %s is deleted", name)
+	}
+}
+
+// restartSecretDependents reloads the named connections which refer the rotated secret and
+// restarts the running rules which use the secret directly or through those connections.
+func restartSecretDependents(name string) {
+	ruleIds := secret.Dependents(name)
+	ctx := context.Background()
+	for _, meta := range connection.GetAllConnectionsMeta(false) {
+		if !slices.Contains(secret.Refs(meta.Props), name) {
+			continue
+		}
+		refs, err := connection.ReloadConnection(ctx, meta.ID)
+		if err != nil {
+			logger.Errorf("reload connection %s for secret %s error: %v", meta.ID, name, err)
+			continue
+		}
+		logger.Infof("connection %s is reloaded for secret %s rotation", meta.ID, name)
+		for _, ref := range refs {
+			if id := ruleIdOfRef(ref); id != "" && !slices.Contains(ruleIds, id) {
+				ruleIds = append(ruleIds, id)
+			}
+		}
+	}
+	if registry == nil {
+		return
+	}
+	for _, id := range ruleIds {
+		rs, ok := registry.load(id)
+		if !ok || rs.GetState() != rule.Running {
+			continue
+		}
+		rs.Stop()
+		if err := rs.Start(); err != nil {
+			logger.Errorf("restart rule %s for secret %s rotation error: %v", id, name, err)
+		} else {
+			logger.Infof("rule %s is restarted for secret %s rotation", id, name)
+		}
+	}
+}
+
+// ruleIdOfRef finds the rule id from the connection ref id which is in the format of ruleId_opId_instanceId
+func ruleIdOfRef(ref string) string {
+	if registry == nil {
+		return ""
+	}
+	found := ""
+	registry.RLock()
+	defer registry.RUnlock()
+	for id := range registry.internal {
+		if strings.HasPrefix(ref, id+"_") && len(id) > len(found) {
+			found = id
+		}
+	}
+	return found
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/lf-edge/ekuiper/v2/internal/meta"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type SecretTestSuite struct {
+	suite.Suite
+	sc secretComp
+	r  *mux.Router
+}
+
+func (suite *SecretTestSuite) SetupTest() {
+	suite.sc = secretComp{}
+	suite.r = mux.NewRouter()
+	suite.sc.register()
+	suite.sc.rest(suite.r)
+	meta.InitYamlConfigManager()
+	suite.r.HandleFunc("/data/export", configurationExportHandler).Methods(http.MethodGet, http.MethodPost)
+}
+
+func (suite *SecretTestSuite) TestSecret() {
+	_ = secret.Delete("kafkaPwd")
+	req, _ := http.NewRequest(http.MethodPost, "/secrets", bytes.NewBufferString(`{"name":"kafkaPwd","value":"s3cret"}`))
+	w := httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusCreated, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/secrets", bytes.NewBufferString(`{"name":"kafkaPwd","value":"s3cret"}`))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/secrets", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+	b, _ := io.ReadAll(w.Result().Body)
+	suite.Contains(string(b), `"kafkaPwd"`)
+	suite.NotContains(string(b), "s3cret")
+
+	req, _ = http.NewRequest(http.MethodGet, "/secrets/kafkaPwd", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+	b, _ = io.ReadAll(w.Result().Body)
+	suite.Equal(`{"name":"kafkaPwd","rules":[]}`, string(b))
+
+	req, _ = http.NewRequest(http.MethodPut, "/secrets/kafkaPwd", bytes.NewBufferString(`{"value":"n3w"}`))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+	v, err := secret.Get("kafkaPwd")
+	suite.NoError(err)
+	suite.Equal("n3w", v)
+
+	req, _ = http.NewRequest(http.MethodPut, "/secrets/kafkaPwd", bytes.NewBufferString(`{"name":"other","value":"n3w"}`))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/data/export", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+	b, _ = io.ReadAll(w.Result().Body)
+	suite.False(strings.Contains(string(b), "n3w"))
+
+	req, _ = http.NewRequest(http.MethodDelete, "/secrets/kafkaPwd", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/secrets/kafkaPwd", bytes.NewBufferString("any"))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+func (suite *SecretTestSuite) TestRotateConnection() {
+	suite.NoError(connection.InitConnectionManager4Test())
+	_ = secret.Delete("connPwd")
+	suite.NoError(secret.Create("connPwd", "p1"))
+	ctx := context.Background()
+	_, err := connection.CreateNamedConnection(ctx, "secretConn", "mock", map[string]any{"password": `{{secret "connPwd"}}`})
+	suite.NoError(err)
+	time.Sleep(10 * time.Millisecond)
+	before, err := connection.GetConnectionDetail(ctx, "secretConn")
+	suite.NoError(err)
+	beforeStatus, _ := before.GetStatus()
+	suite.Equal("connected", beforeStatus)
+
+	suite.NoError(secret.Update("connPwd", "p2"))
+	time.Sleep(10 * time.Millisecond)
+	after, err := connection.GetConnectionDetail(ctx, "secretConn")
+	suite.NoError(err)
+	// props keep the reference only
+	suite.Equal(`{{secret "connPwd"}}`, after.Props["password"])
+	afterStatus, _ := after.GetStatus()
+	suite.Equal("connected", afterStatus)
+	suite.NoError(connection.DropNameConnection(ctx, "secretConn"))
+	suite.NoError(secret.Delete("connPwd"))
+}
+
+func TestSecretTestSuite(t *testing.T) {
+	suite.Run(t, new(SecretTestSuite))
+}
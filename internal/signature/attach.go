@@ -0,0 +1,182 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	AttachHeader   = "header"
+	AttachProperty = "property"
+	AttachField    = "field"
+)
+
+// Placeholder is the dynamic prop which is replaced by the signature of each message.
+// Sinks read the header or property value with it just like the data template.
+const Placeholder = "${signature}"
+
+// Conf defines where the signature is put. It is parsed from the same props of the algorithm.
+type Conf struct {
+	// AttachTo is one of header, property or field. Default to header.
+	AttachTo string `json:"attachTo"`
+	// Name is the header name, the property name or the field name of the signature
+	Name string `json:"name"`
+	// PayloadField is the field name of the payload in the wrapper. Only used when attach to field.
+	PayloadField string `json:"payloadField"`
+}
+
+func ParseConf(props map[string]any) (*Conf, error) {
+	c := &Conf{
+		AttachTo:     AttachHeader,
+		Name:         "signature",
+		PayloadField: "payload",
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, fmt.Errorf("read signature properties %v fail with error: %v", props, err)
+	}
+	switch c.AttachTo {
+	case AttachHeader, AttachProperty:
+	case AttachField:
+		if c.Name == c.PayloadField {
+			return nil, fmt.Errorf("signature field and payload field cannot be the same: %s", c.Name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid attachTo %s, must be header, property or field", c.AttachTo)
+	}
+	return c, nil
+}
+
+// SinkPropKey returns the sink property which holds the header or property map
+func (c *Conf) SinkPropKey() string {
+	switch c.AttachTo {
+	case AttachHeader:
+		return "headers"
+	case AttachProperty:
+		return "properties"
+	default:
+		return ""
+	}
+}
+
+// Wrap puts the payload and the base64 encoded signature into a json object.
+// The json object or array payload is embedded as is; other payloads are base64 encoded strings.
+func (c *Conf) Wrap(payload []byte, sig []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(`{`)
+	b.WriteString(strconv.Quote(c.PayloadField))
+	b.WriteString(`:`)
+	if isJsonContainer(payload) {
+		b.Write(payload)
+	} else {
+		b.WriteString(strconv.Quote(base64.StdEncoding.EncodeToString(payload)))
+	}
+	b.WriteString(`,`)
+	b.WriteString(strconv.Quote(c.Name))
+	b.WriteString(`:`)
+	b.WriteString(strconv.Quote(base64.StdEncoding.EncodeToString(sig)))
+	b.WriteString(`}`)
+	return b.Bytes()
+}
+
+// Unwrap is the reverse of Wrap. It returns the original payload and the signature.
+func (c *Conf) Unwrap(data []byte) ([]byte, []byte, error) {
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, fmt.Errorf("invalid signature wrapper: %v", err)
+	}
+	p, ok := m[c.PayloadField]
+	if !ok {
+		return nil, nil, fmt.Errorf("payload field %s not found", c.PayloadField)
+	}
+	s, ok := m[c.Name]
+	if !ok {
+		return nil, nil, fmt.Errorf("signature field %s not found", c.Name)
+	}
+	var ss string
+	if err := json.Unmarshal(s, &ss); err != nil {
+		return nil, nil, fmt.Errorf("signature field %s must be a string", c.Name)
+	}
+	sig, err := base64.StdEncoding.DecodeString(ss)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid signature: %v", err)
+	}
+	if len(p) > 0 && p[0] == '"' {
+		var ps string
+		if err := json.Unmarshal(p, &ps); err != nil {
+			return nil, nil, fmt.Errorf("invalid payload: %v", err)
+		}
+		payload, err := base64.StdEncoding.DecodeString(ps)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid payload: %v", err)
+		}
+		return payload, sig, nil
+	}
+	return p, sig, nil
+}
+
+// FromMeta finds the base64 encoded signature in the metadata. The signature can be a top level metadata
+// or inside the headers or properties (MQTT v5 user properties) metadata.
+func (c *Conf) FromMeta(meta map[string]any) ([]byte, error) {
+	v, ok := meta[c.Name]
+	if !ok {
+		for _, k := range []string{"headers", "properties"} {
+			if v, ok = lookup(meta[k], c.Name); ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("signature %s not found in metadata", c.Name)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("signature must be a base64 string")
+	}
+	sig, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %v", err)
+	}
+	return sig, nil
+}
+
+func lookup(m any, key string) (any, bool) {
+	switch mt := m.(type) {
+	case map[string]string:
+		v, ok := mt[key]
+		return v, ok
+	case map[string]any:
+		v, ok := mt[key]
+		return v, ok
+	default:
+		return nil, false
+	}
+}
+
+// isJsonContainer checks if the payload is a json object or array without surrounding spaces
+// so that the exact bytes are kept after unwrapping.
+func isJsonContainer(p []byte) bool {
+	if len(p) == 0 || (p[0] != '{' && p[0] != '[') || (p[len(p)-1] != '}' && p[len(p)-1] != ']') {
+		return false
+	}
+	return json.Valid(p)
+}
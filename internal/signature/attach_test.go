@@ -0,0 +1,116 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConf(t *testing.T) {
+	c, err := ParseConf(map[string]any{"key": "abc"})
+	require.NoError(t, err)
+	require.Equal(t, &Conf{AttachTo: AttachHeader, Name: "signature", PayloadField: "payload"}, c)
+	require.Equal(t, "headers", c.SinkPropKey())
+	c, err = ParseConf(map[string]any{"attachTo": "property", "name": "sig"})
+	require.NoError(t, err)
+	require.Equal(t, "properties", c.SinkPropKey())
+	c, err = ParseConf(map[string]any{"attachTo": "field"})
+	require.NoError(t, err)
+	require.Equal(t, "", c.SinkPropKey())
+	_, err = ParseConf(map[string]any{"attachTo": "field", "name": "payload"})
+	require.EqualError(t, err, "signature field and payload field cannot be the same: payload")
+	_, err = ParseConf(map[string]any{"attachTo": "body"})
+	require.EqualError(t, err, "invalid attachTo body, must be header, property or field")
+}
+
+func TestWrap(t *testing.T) {
+	c := &Conf{AttachTo: AttachField, Name: "sig", PayloadField: "data"}
+	tests := []struct {
+		name    string
+		payload []byte
+		wrapped string
+	}{
+		{
+			name:    "json object",
+			payload: []byte(`{"a": 1, "b":[1, 2]}`),
+			wrapped: `{"data":{"a": 1, "b":[1, 2]},"sig":"MTIz"}`,
+		},
+		{
+			name:    "json array",
+			payload: []byte(`[{"a":1}]`),
+			wrapped: `{"data":[{"a":1}],"sig":"MTIz"}`,
+		},
+		{
+			name:    "json with spaces",
+			payload: []byte(` {"a":1}`),
+			wrapped: `{"data":"IHsiYSI6MX0=","sig":"MTIz"}`,
+		},
+		{
+			name:    "binary",
+			payload: []byte{0x01, 0x02, 0xff},
+			wrapped: `{"data":"AQL/","sig":"MTIz"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := c.Wrap(tt.payload, []byte("123"))
+			require.Equal(t, tt.wrapped, string(w))
+			p, sig, err := c.Unwrap(w)
+			require.NoError(t, err)
+			require.Equal(t, tt.payload, p)
+			require.Equal(t, []byte("123"), sig)
+		})
+	}
+}
+
+func TestUnwrapErr(t *testing.T) {
+	c := &Conf{AttachTo: AttachField, Name: "sig", PayloadField: "data"}
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{data: `abc`, err: "invalid signature wrapper: invalid character 'a' looking for beginning of value"},
+		{data: `{"sig":"MTIz"}`, err: "payload field data not found"},
+		{data: `{"data":{}}`, err: "signature field sig not found"},
+		{data: `{"data":{},"sig":1}`, err: "signature field sig must be a string"},
+		{data: `{"data":{},"sig":"!"}`, err: "invalid signature: illegal base64 data at input byte 0"},
+		{data: `{"data":"!","sig":"MTIz"}`, err: "invalid payload: illegal base64 data at input byte 0"},
+	}
+	for _, tt := range tests {
+		_, _, err := c.Unwrap([]byte(tt.data))
+		require.EqualError(t, err, tt.err)
+	}
+}
+
+func TestFromMeta(t *testing.T) {
+	c := &Conf{AttachTo: AttachProperty, Name: "sig"}
+	sig, err := c.FromMeta(map[string]any{"sig": "MTIz"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("123"), sig)
+	sig, err = c.FromMeta(map[string]any{"topic": "a", "properties": map[string]string{"sig": "MTIz"}})
+	require.NoError(t, err)
+	require.Equal(t, []byte("123"), sig)
+	sig, err = c.FromMeta(map[string]any{"headers": map[string]any{"sig": "MTIz"}})
+	require.NoError(t, err)
+	require.Equal(t, []byte("123"), sig)
+	_, err = c.FromMeta(map[string]any{"topic": "a"})
+	require.EqualError(t, err, "signature sig not found in metadata")
+	_, err = c.FromMeta(map[string]any{"sig": 1})
+	require.EqualError(t, err, "signature must be a base64 string")
+	_, err = c.FromMeta(map[string]any{"sig": "!"})
+	require.EqualError(t, err, "invalid signature: illegal base64 data at input byte 0")
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// Ed25519Signer signs with the private key which is the base64 encoded 32 bytes seed or 64 bytes private key
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func newEd25519Signer(props map[string]any) (*Ed25519Signer, error) {
+	k, err := decodeKey(props, "privateKey")
+	if err != nil {
+		return nil, err
+	}
+	switch len(k) {
+	case ed25519.SeedSize:
+		return &Ed25519Signer{key: ed25519.NewKeyFromSeed(k)}, nil
+	case ed25519.PrivateKeySize:
+		return &Ed25519Signer{key: k}, nil
+	default:
+		return nil, fmt.Errorf("ed25519 private key must be %d or %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+func (s *Ed25519Signer) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, data), nil
+}
+
+// Ed25519Verifier verifies with the public key which is the base64 encoded 32 bytes
+type Ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+func newEd25519Verifier(props map[string]any) (*Ed25519Verifier, error) {
+	k, err := decodeKey(props, "publicKey")
+	if err != nil {
+		return nil, err
+	}
+	if len(k) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("ed25519 public key must be %d bytes", ed25519.PublicKeySize)
+	}
+	return &Ed25519Verifier{key: k}, nil
+}
+
+func (v *Ed25519Verifier) Verify(data []byte, sig []byte) error {
+	if !ed25519.Verify(v.key, data, sig) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func decodeKey(props map[string]any, name string) ([]byte, error) {
+	k, ok := props[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is required for ed25519", name)
+	}
+	ks, err := cast.ToString(k, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, k)
+	}
+	b, err := base64.StdEncoding.DecodeString(ks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return b, nil
+}
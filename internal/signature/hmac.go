@@ -0,0 +1,55 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// Hmac signs and verifies with HMAC-SHA256. The key is the shared secret string.
+type Hmac struct {
+	key []byte
+}
+
+func newHmac(props map[string]any) (*Hmac, error) {
+	k, ok := props["key"]
+	if !ok {
+		return nil, errors.New("key is required for hmac-sha256")
+	}
+	ks, err := cast.ToString(k, cast.CONVERT_SAMEKIND)
+	if err != nil || ks == "" {
+		return nil, fmt.Errorf("invalid key for hmac-sha256: %v", k)
+	}
+	return &Hmac{key: []byte(ks)}, nil
+}
+
+func (h *Hmac) Sign(data []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (h *Hmac) Verify(data []byte, sig []byte) error {
+	expected, _ := h.Sign(data)
+	if !hmac.Equal(expected, sig) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
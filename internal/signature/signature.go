@@ -0,0 +1,51 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs the payload in sinks and verifies it in sources for tamper evidence.
+package signature
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+const (
+	HMACSHA256 = "hmac-sha256"
+	ED25519    = "ed25519"
+)
+
+// GetSigner returns the signer of the algorithm. The key props are algorithm specific.
+func GetSigner(name string, props map[string]any) (message.Signer, error) {
+	switch name {
+	case HMACSHA256:
+		return newHmac(props)
+	case ED25519:
+		return newEd25519Signer(props)
+	default:
+		return nil, fmt.Errorf("signature algorithm '%s' is not supported", name)
+	}
+}
+
+// GetVerifier returns the verifier of the algorithm. The key props are algorithm specific.
+func GetVerifier(name string, props map[string]any) (message.Verifier, error) {
+	switch name {
+	case HMACSHA256:
+		return newHmac(props)
+	case ED25519:
+		return newEd25519Verifier(props)
+	default:
+		return nil, fmt.Errorf("signature algorithm '%s' is not supported", name)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	tests := []struct {
+		name     string
+		sigProps map[string]any
+		verProps map[string]any
+	}{
+		{
+			name:     HMACSHA256,
+			sigProps: map[string]any{"key": "shared"},
+			verProps: map[string]any{"key": "shared"},
+		},
+		{
+			name:     ED25519,
+			sigProps: map[string]any{"privateKey": base64.StdEncoding.EncodeToString(priv)},
+			verProps: map[string]any{"publicKey": base64.StdEncoding.EncodeToString(pub)},
+		},
+		{
+			name:     ED25519,
+			sigProps: map[string]any{"privateKey": base64.StdEncoding.EncodeToString(priv.Seed())},
+			verProps: map[string]any{"publicKey": base64.StdEncoding.EncodeToString(pub)},
+		},
+	}
+	data := []byte(`{"temperature":23.5}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := GetSigner(tt.name, tt.sigProps)
+			require.NoError(t, err)
+			v, err := GetVerifier(tt.name, tt.verProps)
+			require.NoError(t, err)
+			sig, err := s.Sign(data)
+			require.NoError(t, err)
+			require.NoError(t, v.Verify(data, sig))
+			require.EqualError(t, v.Verify([]byte(`{"temperature":99}`), sig), "signature mismatch")
+		})
+	}
+}
+
+func TestSignerErr(t *testing.T) {
+	tests := []struct {
+		name  string
+		alg   string
+		props map[string]any
+		err   string
+	}{
+		{
+			name: "unknown",
+			alg:  "md5",
+			err:  "signature algorithm 'md5' is not supported",
+		},
+		{
+			name:  "hmac no key",
+			alg:   HMACSHA256,
+			props: map[string]any{},
+			err:   "key is required for hmac-sha256",
+		},
+		{
+			name:  "ed25519 no key",
+			alg:   ED25519,
+			props: map[string]any{},
+			err:   "privateKey is required for ed25519",
+		},
+		{
+			name:  "ed25519 invalid key",
+			alg:   ED25519,
+			props: map[string]any{"privateKey": "!!"},
+			err:   "invalid privateKey: illegal base64 data at input byte 0",
+		},
+		{
+			name:  "ed25519 wrong size",
+			alg:   ED25519,
+			props: map[string]any{"privateKey": "MTIz"},
+			err:   "ed25519 private key must be 32 or 64 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetSigner(tt.alg, tt.props)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+	_, err := GetVerifier(ED25519, map[string]any{"publicKey": "MTIz"})
+	require.EqualError(t, err, "ed25519 public key must be 32 bytes")
+	_, err = GetVerifier("md5", nil)
+	require.EqualError(t, err, "signature algorithm 'md5' is not supported")
+}
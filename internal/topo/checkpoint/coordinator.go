@@ -95,6 +95,7 @@ type Coordinator struct {
 	tasksToTrigger          []Responder
 	tasksToWaitFor          []Responder
 	sinkTasks               []SinkTask
+	listeners               []CheckpointListener
 	pendingCheckpoints      *sync.Map
 	completedCheckpoints    *checkpointStore
 	ruleId                  string
@@ -116,6 +117,7 @@ func NewCoordinator(ruleId string, sources []StreamTask, operators []NonSourceTa
 	logger.Infof("create new coordinator for rule %s", ruleId)
 	signal := make(chan *Signal, 1024)
 	var allResponders, sourceResponders []Responder
+	var listeners []CheckpointListener
 	for _, r := range sources {
 		r.SetQos(qos)
 		re := NewResponderExecutor(signal, r)
@@ -128,6 +130,9 @@ func NewCoordinator(ruleId string, sources []StreamTask, operators []NonSourceTa
 		handler := createBarrierHandler(re, r.GetInputCount(), qos)
 		r.SetBarrierHandler(handler)
 		allResponders = append(allResponders, re)
+		if l, ok := r.(CheckpointListener); ok {
+			listeners = append(listeners, l)
+		}
 	}
 	for _, r := range sinks {
 		r.SetQos(qos)
@@ -144,6 +149,7 @@ func NewCoordinator(ruleId string, sources []StreamTask, operators []NonSourceTa
 		tasksToTrigger:     sourceResponders,
 		tasksToWaitFor:     allResponders,
 		sinkTasks:          sinks,
+		listeners:          listeners,
 		pendingCheckpoints: new(sync.Map),
 		completedCheckpoints: &checkpointStore{
 			maxNum: 3,
@@ -205,7 +211,7 @@ func (c *Coordinator) Activate() error {
 							if checkpoint.isFullyAck() {
 								c.complete(s.CheckpointId)
 								if c.inForceSaveState.Load() {
-									c.FinishForceSaveState()
+									c.FinishForceSaveState(nil)
 								}
 							}
 						} else {
@@ -215,7 +221,7 @@ func (c *Coordinator) Activate() error {
 						logger.Debugf("Receive dec from %s for checkpoint %d, cancel it", s.OpId, s.CheckpointId)
 						c.cancel(s.CheckpointId)
 						if c.inForceSaveState.Load() {
-							c.FinishForceSaveState()
+							c.FinishForceSaveState(fmt.Errorf("checkpoint %d is cancelled by %s", s.CheckpointId, s.OpId))
 						}
 					}
 				case <-c.ctx.Done():
@@ -268,6 +274,8 @@ func (c *Coordinator) Deactivate() error {
 	return nil
 }
 
+// ForceSaveState triggers a checkpoint at once. The returned channel receives nil when the checkpoint completes
+// or the error if it is cancelled.
 func (c *Coordinator) ForceSaveState() (chan any, error) {
 	if c.inForceSaveState.Load() {
 		return nil, fmt.Errorf("duplicated force save state")
@@ -276,9 +284,9 @@ func (c *Coordinator) ForceSaveState() (chan any, error) {
 	return c.forceSaveStateNotify, nil
 }
 
-func (c *Coordinator) FinishForceSaveState() {
+func (c *Coordinator) FinishForceSaveState(err error) {
 	c.inForceSaveState.Store(false)
-	c.forceSaveStateNotify <- struct{}{}
+	c.forceSaveStateNotify <- err
 }
 
 func (c *Coordinator) cancel(checkpointId int64) {
@@ -303,6 +311,9 @@ func (c *Coordinator) complete(checkpointId int64) {
 		}
 		c.completedCheckpoints.add(ccp.(*pendingCheckpoint).finalize())
 		c.pendingCheckpoints.Delete(checkpointId)
+		for _, l := range c.listeners {
+			l.NotifyCheckpointComplete(checkpointId)
+		}
 		// Drop the previous pendingCheckpoints
 		c.pendingCheckpoints.Range(func(a1 interface{}, a2 interface{}) bool {
 			cid := a1.(int64)
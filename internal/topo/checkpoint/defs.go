@@ -38,6 +38,11 @@ type NonSourceTask interface {
 	SetBarrierHandler(BarrierHandler)
 }
 
+// CheckpointListener is notified in the coordinator goroutine after a checkpoint is committed
+type CheckpointListener interface {
+	NotifyCheckpointComplete(checkpointId int64)
+}
+
 type SourceSubTopoTask interface {
 	EnableCheckpoint(sources *[]StreamTask, ops *[]NonSourceTask)
 }
@@ -38,6 +38,8 @@ const (
 	LoggerKey        = "$$logger"
 	RuleStartKey     = "$$ruleStart"
 	RuleWaitGroupKey = "$$ruleWaitGroup"
+	RuleMemoryKey    = "$$ruleMemoryBudget"
+	RuleFlowKey      = "$$ruleFlowControl"
 	TraceStrategyKey = "$$TraceStrategyKey"
 )
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	nodeConf "github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
@@ -71,6 +72,10 @@ func CreateInstance(name string, sourceType string, options *ast.Options) error
 	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
 	props := nodeConf.GetSourceConf(sourceType, options)
 	ctx.GetLogger().Infof("open lookup table with props %v", conf.Printable(props))
+	props, err := secret.ResolveProps(ctx, props)
+	if err != nil {
+		return err
+	}
 	// Create the lookup source according to the source options
 	ns, err := io.LookupSource(sourceType)
 	if err != nil {
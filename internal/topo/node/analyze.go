@@ -0,0 +1,66 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"reflect"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/metric"
+)
+
+type stateHolder interface {
+	GetAllState() map[string]any
+}
+
+func (o *defaultNode) GetAnalyzeStats() *metric.AnalyzeStats {
+	if o.statManager == nil {
+		return nil
+	}
+	stats := o.statManager.GetAnalyzeStats()
+	stats.Name = o.name
+	if sh, ok := o.ctx.(stateHolder); ok {
+		stats.StateSize = stateSize(sh.GetAllState())
+	}
+	return stats
+}
+
+func (o *defaultSinkNode) GetAnalyzeStats() *metric.AnalyzeStats {
+	stats := o.defaultNode.GetAnalyzeStats()
+	if stats == nil {
+		return nil
+	}
+	stats.BufferCapacity = int64(cap(o.input))
+	if stats.BufferCapacity > 0 {
+		stats.BufferOccupancy = float64(len(o.input)) / float64(stats.BufferCapacity)
+	}
+	return stats
+}
+
+// stateSize estimates the state size by entries. The collection state like the window inputs counts by its length.
+func stateSize(states map[string]any) int64 {
+	var size int64
+	for _, s := range states {
+		if s == nil {
+			continue
+		}
+		switch v := reflect.ValueOf(s); v.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Array:
+			size += int64(v.Len())
+		default:
+			size++
+		}
+	}
+	return size
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/metrics"
+)
+
+const (
+	spillThrottle = "throttle"
+	// ThrottleInterval is the sleep step when the memory budget is exceeded
+	ThrottleInterval = 10 * time.Millisecond
+	// MaxThrottle is the maximum time to wait for the memory to be released in one throttle
+	MaxThrottle = time.Second
+)
+
+// MemoryBudget is the shared memory account of a rule. All the window and table join operators of a rule
+// reserve the estimated size of the rows they keep and release them once the rows are dropped.
+// It is thread safe.
+type MemoryBudget struct {
+	RuleID string
+	limit  int64
+	used   atomic.Int64
+}
+
+func NewMemoryBudget(ruleId string, limit int64) *MemoryBudget {
+	return &MemoryBudget{
+		RuleID: ruleId,
+		limit:  limit,
+	}
+}
+
+// GetMemoryBudget returns the memory budget of the rule. Return nil if the rule has no budget
+func GetMemoryBudget(ctx api.StreamContext) *MemoryBudget {
+	if ctx == nil {
+		return nil
+	}
+	b, _ := ctx.Value(topoContext.RuleMemoryKey).(*MemoryBudget)
+	return b
+}
+
+func (b *MemoryBudget) Reserve(size int64) {
+	if b == nil || size == 0 {
+		return
+	}
+	metrics.RuleMemoryGauge.WithLabelValues(b.RuleID).Set(float64(b.used.Add(size)))
+}
+
+func (b *MemoryBudget) Release(size int64) {
+	b.Reserve(-size)
+}
+
+func (b *MemoryBudget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.used.Load()
+}
+
+func (b *MemoryBudget) Limit() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
+
+func (b *MemoryBudget) IsExceeded() bool {
+	if b == nil {
+		return false
+	}
+	return b.used.Load() > b.limit
+}
+
+// Throttle blocks the caller while the budget is exceeded so that the rule slows down instead of
+// allocating more memory. It waits at most MaxThrottle to let the other operators release memory.
+func (b *MemoryBudget) Throttle(ctx api.StreamContext) {
+	if !b.IsExceeded() {
+		return
+	}
+	metrics.SpillCounter.WithLabelValues(spillThrottle, ctx.GetRuleId(), ctx.GetOpId()).Inc()
+	ctx.GetLogger().Debugf("memory budget %d exceeded with %d, throttling", b.limit, b.used.Load())
+	for waited := time.Duration(0); waited < MaxThrottle && b.IsExceeded(); waited += ThrottleInterval {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ThrottleInterval):
+		}
+	}
+}
+
+// SizeOf estimates the memory size of a decoded value
+func SizeOf(v any) int64 {
+	switch vt := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(vt)) + 16
+	case []byte:
+		return int64(len(vt)) + 24
+	case map[string]any:
+		s := int64(48)
+		for k, e := range vt {
+			s += int64(len(k)) + 16 + SizeOf(e)
+		}
+		return s
+	case []any:
+		s := int64(24)
+		for _, e := range vt {
+			s += SizeOf(e)
+		}
+		return s
+	case []map[string]any:
+		s := int64(24)
+		for _, e := range vt {
+			s += SizeOf(e)
+		}
+		return s
+	default:
+		return 16
+	}
+}
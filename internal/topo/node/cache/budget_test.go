@@ -0,0 +1,77 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	var nb *MemoryBudget
+	nb.Reserve(100)
+	assert.False(t, nb.IsExceeded())
+	assert.Equal(t, int64(0), nb.Used())
+
+	b := NewMemoryBudget("rule1", 100)
+	ctx := context.WithValue(context.Background(), context.RuleMemoryKey, b)
+	assert.Equal(t, b, GetMemoryBudget(ctx))
+	assert.Nil(t, GetMemoryBudget(context.Background()))
+	b.Reserve(60)
+	b.Reserve(40)
+	assert.False(t, b.IsExceeded())
+	b.Reserve(1)
+	assert.True(t, b.IsExceeded())
+	assert.Equal(t, int64(101), b.Used())
+	assert.Equal(t, int64(100), b.Limit())
+
+	mc := mockContext.NewMockContext("rule1", "op1")
+	go func() {
+		time.Sleep(3 * ThrottleInterval)
+		b.Release(60)
+	}()
+	start := time.Now()
+	b.Throttle(mc)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 3*ThrottleInterval && elapsed < MaxThrottle, "throttle %v", elapsed)
+	assert.False(t, b.IsExceeded())
+	// no wait if not exceeded
+	start = time.Now()
+	b.Throttle(mc)
+	assert.True(t, time.Since(start) < ThrottleInterval)
+}
+
+func TestSizeOf(t *testing.T) {
+	tests := []struct {
+		v any
+		s int64
+	}{
+		{nil, 0},
+		{"abc", 19},
+		{[]byte("ab"), 26},
+		{10, 16},
+		{[]any{"a", 1}, 24 + 17 + 16},
+		{map[string]any{"a": "b"}, 48 + 1 + 16 + 17},
+		{[]map[string]any{{}}, 24 + 48},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.s, SizeOf(tt.v), "%v", tt.v)
+	}
+}
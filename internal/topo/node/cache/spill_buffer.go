@@ -0,0 +1,328 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/metrics"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+)
+
+const (
+	spillPages = "pages"
+	spillSave  = "save"
+	spillLoad  = "load"
+	spillLost  = "lost"
+)
+
+// SpillPage is the meta of a page saved in disk. The meta is kept in memory and saved along with the operator state
+type SpillPage struct {
+	Key   int
+	Count int
+	// The timestamp range in unix milli of the items, so that the expired pages can be dropped without loading
+	Start int64
+	End   int64
+	// The cache kv table of the page. The operator may be renamed when the states are migrated to the updated rule,
+	// so the table is recorded instead of derived from the operator name.
+	Table string
+}
+
+// SpillBuffer saves the oldest rows of an operator into disk pages when the memory budget is exceeded.
+// The rows are loaded back page by page from the oldest when the operator needs to scan them.
+// Not thread safe!
+type SpillBuffer struct {
+	RuleID string
+	OpID   string
+	table  string
+	pages  []SpillPage
+	next   int
+	count  int
+	// If true, the loaded or dropped pages are kept in disk until a checkpoint taken after that is committed.
+	// Otherwise, the last committed checkpoint may refer to the deleted pages.
+	deferDelete bool
+	released    []int
+	marks       []spillMark
+	// serialize
+	store kv.KeyValue
+}
+
+// spillMark is the first page key which is not released when the checkpoint is taken
+type spillMark struct {
+	checkpointId int64
+	base         int
+}
+
+// SpillTable returns the cache kv table of the spilled pages for the operator instance
+func SpillTable(ctx api.StreamContext) string {
+	return path.Join("spill", ctx.GetRuleId()+ctx.GetOpId()+strconv.Itoa(ctx.GetInstanceId()))
+}
+
+// NewSpillBuffer creates the spill buffer and restores the pages meta saved in the state.
+// The restored pages are read from their recorded table and the new pages are saved in the same table.
+// The stale pages which are not in the restored meta are deleted.
+func NewSpillBuffer(ctx api.StreamContext, pages []SpillPage, deferDelete bool) (*SpillBuffer, error) {
+	return newSpillBuffer(ctx, SpillTable(ctx), pages, deferDelete)
+}
+
+// NewNamedSpillBuffer creates the spill buffer for one of the buffers of the operator instance such as a table of the join
+func NewNamedSpillBuffer(ctx api.StreamContext, name string, pages []SpillPage, deferDelete bool) (*SpillBuffer, error) {
+	return newSpillBuffer(ctx, path.Join(SpillTable(ctx), name), pages, deferDelete)
+}
+
+func newSpillBuffer(ctx api.StreamContext, table string, pages []SpillPage, deferDelete bool) (*SpillBuffer, error) {
+	if len(pages) > 0 && pages[0].Table != "" {
+		if pages[0].Table != table {
+			ctx.GetLogger().Infof("restore spilled pages from table %s", pages[0].Table)
+			_ = store.DropCacheKV(table)
+		}
+		table = pages[0].Table
+	}
+	if len(pages) == 0 {
+		_ = store.DropCacheKV(table)
+	}
+	s, err := store.GetCacheKV(table)
+	if err != nil {
+		return nil, err
+	}
+	b := &SpillBuffer{
+		RuleID:      ctx.GetRuleId(),
+		OpID:        ctx.GetOpId(),
+		table:       table,
+		deferDelete: deferDelete,
+		store:       s,
+	}
+	restored := make(map[string]struct{}, len(pages))
+	for _, p := range pages {
+		b.pages = append(b.pages, p)
+		b.count += p.Count
+		if p.Key >= b.next {
+			b.next = p.Key + 1
+		}
+		restored[strconv.Itoa(p.Key)] = struct{}{}
+	}
+	if len(pages) > 0 {
+		keys, err := s.Keys()
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			if _, ok := restored[k]; !ok {
+				_ = s.Delete(k)
+			}
+		}
+	}
+	return b, nil
+}
+
+// Spill saves the items as a new page after all the saved pages. The start and end are the timestamp range of the items.
+func (b *SpillBuffer) Spill(ctx api.StreamContext, items []any, startTs, endTs int64) error {
+	if len(items) == 0 {
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		metrics.SpillCounter.WithLabelValues(spillSave, b.RuleID, b.OpID).Inc()
+		metrics.SpillGauge.WithLabelValues(spillPages, b.RuleID, b.OpID).Set(float64(len(b.pages)))
+		ctx.GetLogger().Debugf("spilled %d items in %v", len(items), time.Since(start))
+	}()
+	p := newPage(len(items))
+	for _, item := range items {
+		p.append(item)
+	}
+	if err := b.store.Set(strconv.Itoa(b.next), p); err != nil {
+		return fmt.Errorf("fail to spill page %d: %v", b.next, err)
+	}
+	b.pages = append(b.pages, SpillPage{Key: b.next, Count: len(items), Start: startTs, End: endTs, Table: b.table})
+	b.next++
+	b.count += len(items)
+	return nil
+}
+
+// Head returns the meta of the oldest page
+func (b *SpillBuffer) Head() (SpillPage, bool) {
+	if b == nil || len(b.pages) == 0 {
+		return SpillPage{}, false
+	}
+	return b.pages[0], true
+}
+
+// LoadHead reads the oldest page and releases it. If the page is lost, the error is returned.
+func (b *SpillBuffer) LoadHead(ctx api.StreamContext) ([]any, error) {
+	if len(b.pages) == 0 {
+		return nil, nil
+	}
+	sp := b.pages[0]
+	b.pages = b.pages[1:]
+	b.count -= sp.Count
+	defer func() {
+		metrics.SpillCounter.WithLabelValues(spillLoad, b.RuleID, b.OpID).Inc()
+		metrics.SpillGauge.WithLabelValues(spillPages, b.RuleID, b.OpID).Set(float64(len(b.pages)))
+	}()
+	defer b.release(sp.Key)
+	result, err := b.read(sp)
+	if err != nil {
+		metrics.SpillCounter.WithLabelValues(spillLost, b.RuleID, b.OpID).Add(float64(sp.Count))
+		err = fmt.Errorf("fail to load spilled page %d, %d items lost: %v", sp.Key, sp.Count, err)
+		ctx.GetLogger().Warn(err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// Read reads the items of all the pages in order without releasing them. The first skip items of the oldest
+// page are not returned. The unreadable pages are skipped and the last error is returned.
+func (b *SpillBuffer) Read(ctx api.StreamContext, skip int) ([]any, error) {
+	if b == nil || len(b.pages) == 0 {
+		return nil, nil
+	}
+	defer metrics.SpillCounter.WithLabelValues(spillLoad, b.RuleID, b.OpID).Add(float64(len(b.pages)))
+	var lastErr error
+	result := make([]any, 0, b.count-skip)
+	for i, sp := range b.pages {
+		items, err := b.read(sp)
+		if err != nil {
+			lastErr = fmt.Errorf("fail to read spilled page %d: %v", sp.Key, err)
+			continue
+		}
+		if i == 0 && skip > 0 {
+			if skip > len(items) {
+				skip = len(items)
+			}
+			items = items[skip:]
+		}
+		result = append(result, items...)
+	}
+	return result, lastErr
+}
+
+func (b *SpillBuffer) read(sp SpillPage) ([]any, error) {
+	// caution, must create a new page instance
+	p := &page{}
+	ok, err := b.store.Get(strconv.Itoa(sp.Key), p)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("page not found")
+	}
+	result := make([]any, 0, sp.Count)
+	for {
+		item, ok := p.peak()
+		if !ok {
+			break
+		}
+		result = append(result, item)
+		p.delete()
+	}
+	return result, nil
+}
+
+// DropHead releases the oldest page without reading it
+func (b *SpillBuffer) DropHead() {
+	if len(b.pages) == 0 {
+		return
+	}
+	sp := b.pages[0]
+	b.pages = b.pages[1:]
+	b.count -= sp.Count
+	metrics.SpillGauge.WithLabelValues(spillPages, b.RuleID, b.OpID).Set(float64(len(b.pages)))
+	b.release(sp.Key)
+}
+
+func (b *SpillBuffer) release(key int) {
+	if b.deferDelete {
+		b.released = append(b.released, key)
+	} else {
+		_ = b.store.Delete(strconv.Itoa(key))
+	}
+}
+
+// Mark records the pages before the snapshot of the checkpoint is taken.
+// Only the first mark of a checkpoint is kept which is never later than the snapshot.
+func (b *SpillBuffer) Mark(checkpointId int64) {
+	if b == nil || !b.deferDelete {
+		return
+	}
+	if len(b.marks) > 0 && b.marks[len(b.marks)-1].checkpointId >= checkpointId {
+		return
+	}
+	base := b.next
+	if len(b.pages) > 0 {
+		base = b.pages[0].Key
+	}
+	b.marks = append(b.marks, spillMark{checkpointId: checkpointId, base: base})
+}
+
+// Commit deletes the released pages which are not referred by the committed checkpoint
+func (b *SpillBuffer) Commit(checkpointId int64) {
+	if b == nil || len(b.marks) == 0 {
+		return
+	}
+	i := -1
+	for j, m := range b.marks {
+		if m.checkpointId > checkpointId {
+			break
+		}
+		i = j
+	}
+	if i < 0 {
+		return
+	}
+	base := b.marks[i].base
+	b.marks = b.marks[i+1:]
+	n := 0
+	for _, key := range b.released {
+		if key >= base {
+			break
+		}
+		_ = b.store.Delete(strconv.Itoa(key))
+		n++
+	}
+	b.released = b.released[n:]
+}
+
+// Pages returns a copy of the meta of the saved pages to save in the state
+func (b *SpillBuffer) Pages() []SpillPage {
+	if b == nil || len(b.pages) == 0 {
+		return nil
+	}
+	result := make([]SpillPage, len(b.pages))
+	copy(result, b.pages)
+	return result
+}
+
+// Len returns the count of the spilled items
+func (b *SpillBuffer) Len() int {
+	if b == nil {
+		return 0
+	}
+	return b.count
+}
+
+// Drop deletes all the saved pages
+func (b *SpillBuffer) Drop() {
+	b.pages = b.pages[:0]
+	b.count = 0
+	b.released = nil
+	b.marks = nil
+	_ = store.DropCacheKV(b.table)
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	testx.InitEnv("spill")
+	tempStore, err := state.CreateStore("mock", def.AtMostOnce)
+	require.NoError(t, err)
+	contextLogger := conf.Log.WithField("rule", "TestSpill")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger).WithMeta("TestSpill", "op1", tempStore)
+	tuples := make([]any, 5)
+	for i := 0; i < 5; i++ {
+		tuples[i] = &xsql.RawTuple{
+			Emitter:   "test",
+			Timestamp: time.UnixMilli(int64(i)),
+			Rawdata:   []byte("hello"),
+		}
+	}
+	b, err := NewSpillBuffer(ctx, nil, false)
+	require.NoError(t, err)
+	require.NoError(t, b.Spill(ctx, tuples[:2], 0, 1))
+	require.NoError(t, b.Spill(ctx, tuples[2:5], 2, 4))
+	require.NoError(t, b.Spill(ctx, nil, 0, 0))
+	assert.Equal(t, 5, b.Len())
+	pages := b.Pages()
+	table := SpillTable(ctx)
+	assert.Equal(t, []SpillPage{{Key: 0, Count: 2, Start: 0, End: 1, Table: table}, {Key: 1, Count: 3, Start: 2, End: 4, Table: table}}, pages)
+	// restore from the pages meta
+	b, err = NewSpillBuffer(ctx, pages, false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, b.Len())
+	require.NoError(t, b.Spill(ctx, tuples[:1], 0, 0))
+	assert.Equal(t, SpillPage{Key: 2, Count: 1, Table: table}, b.Pages()[2])
+	// load page by page
+	head, ok := b.Head()
+	require.True(t, ok)
+	assert.Equal(t, 0, head.Key)
+	result, err := b.LoadHead(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, tuples[:2], result)
+	assert.Equal(t, 4, b.Len())
+	b.DropHead()
+	assert.Equal(t, 1, b.Len())
+	result, err = b.LoadHead(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, tuples[:1], result)
+	assert.Equal(t, 0, b.Len())
+	assert.Nil(t, b.Pages())
+	_, ok = b.Head()
+	assert.False(t, ok)
+	result, err = b.LoadHead(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	s, err := store.GetCacheKV(SpillTable(ctx))
+	require.NoError(t, err)
+	keys, err := s.Keys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 0)
+	// lost page
+	require.NoError(t, b.Spill(ctx, tuples[:2], 0, 1))
+	require.NoError(t, b.Spill(ctx, tuples[2:3], 2, 2))
+	require.NoError(t, s.Delete("3"))
+	result, err = b.LoadHead(ctx)
+	assert.EqualError(t, err, "fail to load spilled page 3, 2 items lost: page not found")
+	assert.Nil(t, result)
+	result, err = b.LoadHead(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, tuples[2:3], result)
+	// the pages are found by the renamed operator, such as migrated to the updated rule
+	require.NoError(t, b.Spill(ctx, tuples[:2], 0, 1))
+	renamed := context.WithValue(context.Background(), context.LoggerKey, contextLogger).WithMeta("TestSpill", "op2", tempStore)
+	rb, err := NewSpillBuffer(renamed, b.Pages(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rb.Len())
+	result, err = rb.LoadHead(renamed)
+	require.NoError(t, err)
+	assert.Equal(t, tuples[:2], result)
+	require.NoError(t, rb.Spill(renamed, tuples[2:3], 2, 2))
+	assert.Equal(t, []SpillPage{{Key: 6, Count: 1, Start: 2, End: 2, Table: table}}, rb.Pages())
+	// no pages to restore, drop the stale table
+	require.NoError(t, b.Spill(ctx, tuples[:2], 0, 1))
+	_, err = NewSpillBuffer(ctx, nil, false)
+	require.NoError(t, err)
+	s, err = store.GetCacheKV(SpillTable(ctx))
+	require.NoError(t, err)
+	keys, err = s.Keys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 0)
+	b.Drop()
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestSpillBufferDeferDelete(t *testing.T) {
+	testx.InitEnv("spill")
+	tempStore, err := state.CreateStore("mock", def.AtMostOnce)
+	require.NoError(t, err)
+	contextLogger := conf.Log.WithField("rule", "TestSpillDefer")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger).WithMeta("TestSpillDefer", "op1", tempStore)
+	tuples := make([]any, 3)
+	for i := range tuples {
+		tuples[i] = &xsql.RawTuple{
+			Emitter:   "test",
+			Timestamp: time.UnixMilli(int64(i)),
+			Rawdata:   []byte("hello"),
+		}
+	}
+	b, err := NewSpillBuffer(ctx, nil, true)
+	require.NoError(t, err)
+	s, err := store.GetCacheKV(SpillTable(ctx))
+	require.NoError(t, err)
+	for i, tuple := range tuples {
+		require.NoError(t, b.Spill(ctx, []any{tuple}, int64(i), int64(i)))
+	}
+	// checkpoint 1 refers to all pages
+	b.Mark(1)
+	_, err = b.LoadHead(ctx)
+	require.NoError(t, err)
+	// checkpoint 2 refers to page 1 and 2
+	b.Mark(2)
+	b.Mark(2)
+	b.DropHead()
+	keys, err := s.Keys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+	b.Commit(1)
+	keys, err = s.Keys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+	b.Commit(2)
+	keys, err = s.Keys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1", "2"}, keys)
+	// restore from checkpoint 2 deletes the pages which are not referred
+	pages := []SpillPage{{Key: 2, Count: 1, Start: 2, End: 2}}
+	b, err = NewSpillBuffer(ctx, pages, true)
+	require.NoError(t, err)
+	keys, err = s.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, keys)
+	result, err := b.LoadHead(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, tuples[2:], result)
+	b.Drop()
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+const (
+	DefaultColumnBatchSize   = 1024
+	DefaultColumnBatchLinger = 10 * time.Millisecond
+)
+
+// ColumnBatchOp collects the decoded tuples into column batches for the batch execution mode.
+// A batch is sent out when it is full or when the linger interval is reached.
+type ColumnBatchOp struct {
+	*defaultSinkNode
+	batchSize      int
+	lingerInterval time.Duration
+	buffer         *xsql.ColumnBatch
+}
+
+func NewColumnBatchOp(name string, rOpt *def.RuleOption, batchSize int, lingerInterval time.Duration) *ColumnBatchOp {
+	if batchSize < 1 {
+		batchSize = DefaultColumnBatchSize
+	}
+	if lingerInterval < 1 {
+		lingerInterval = DefaultColumnBatchLinger
+	}
+	return &ColumnBatchOp{
+		defaultSinkNode: newDefaultSinkNode(name, rOpt),
+		batchSize:       batchSize,
+		lingerInterval:  lingerInterval,
+	}
+}
+
+func (b *ColumnBatchOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	b.prepareExec(ctx, errCh, "op")
+	ticker := timex.GetTicker(b.lingerInterval)
+	go func() {
+		err := infra.SafeRun(func() error {
+			defer func() {
+				ticker.Stop()
+				b.Close()
+			}()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case d := <-b.input:
+					b.ingest(ctx, d)
+				case <-ticker.C:
+					b.send(ctx)
+				}
+			}
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+func (b *ColumnBatchOp) ingest(ctx api.StreamContext, item any) {
+	// Send out the buffered rows before the control data to keep the order
+	if _, ok := item.(*xsql.Tuple); !ok {
+		b.send(ctx)
+	}
+	data, processed := b.commonIngest(ctx, item)
+	if processed {
+		return
+	}
+	b.onProcessStart(ctx, nil)
+	switch input := data.(type) {
+	case *xsql.Tuple:
+		if b.buffer != nil && b.buffer.Emitter != input.Emitter {
+			b.send(ctx)
+		}
+		if b.buffer == nil {
+			b.buffer = xsql.NewColumnBatch(input.Emitter, b.batchSize)
+		}
+		b.buffer.Append(input)
+		if b.buffer.Len() >= b.batchSize {
+			b.send(ctx)
+		}
+	default:
+		// Other data like collections cannot be batched, just pass them on
+		b.Broadcast(input)
+		b.onSend(ctx, input)
+	}
+	b.onProcessEnd(ctx)
+	b.statManager.SetBufferLength(int64(len(b.input)))
+}
+
+func (b *ColumnBatchOp) send(ctx api.StreamContext) {
+	if b.buffer == nil || b.buffer.Len() == 0 {
+		return
+	}
+	b.Broadcast(b.buffer)
+	b.onSend(ctx, b.buffer)
+	b.buffer = nil
+}
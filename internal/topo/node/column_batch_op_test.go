@@ -0,0 +1,72 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mockclock"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestColumnBatchOp(t *testing.T) {
+	mc := mockclock.GetMockClock()
+	op := NewColumnBatchOp("test", &def.RuleOption{BufferLength: 10, SendError: true}, 3, time.Second)
+	out := make(chan any, 100)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx := mockContext.NewMockContext("test1", "column_batch_test")
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+	send := func(i int) {
+		op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": i}}
+	}
+	// Send out when the batch is full
+	for i := 0; i < 4; i++ {
+		send(i)
+	}
+	r := <-out
+	b, ok := r.(*xsql.ColumnBatch)
+	require.True(t, ok)
+	assert.Equal(t, map[string][]any{"a": {0, 1, 2}}, b.Columns)
+	// Send out when linger interval reaches
+	time.Sleep(10 * time.Millisecond)
+	mc.Add(time.Second)
+	r = <-out
+	b, ok = r.(*xsql.ColumnBatch)
+	require.True(t, ok)
+	assert.Equal(t, map[string][]any{"a": {3}}, b.Columns)
+	// Send out the buffer before the error and EOF
+	send(4)
+	op.input <- errors.New("mock error")
+	send(5)
+	op.input <- xsql.EOFTuple(0)
+	r = <-out
+	b, ok = r.(*xsql.ColumnBatch)
+	require.True(t, ok)
+	assert.Equal(t, map[string][]any{"a": {4}}, b.Columns)
+	assert.Equal(t, errors.New("mock error"), <-out)
+	r = <-out
+	b, ok = r.(*xsql.ColumnBatch)
+	require.True(t, ok)
+	assert.Equal(t, map[string][]any{"a": {5}}, b.Columns)
+	assert.Equal(t, xsql.EOFTuple(0), <-out)
+}
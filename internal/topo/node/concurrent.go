@@ -20,6 +20,7 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
 // WorkerFunc is the function to process the data
@@ -59,21 +60,7 @@ func merge(ctx api.StreamContext, node *defaultSinkNode, sendInterval time.Durat
 		for _, ch := range channels {
 			select {
 			case data := <-ch:
-				for _, d := range data {
-					if derr, ok := d.(error); ok {
-						node.onError(ctx, derr)
-						continue
-					}
-					dd, processed := node.commonIngest(ctx, d)
-					if processed {
-						continue
-					}
-					node.Broadcast(dd)
-					node.onSend(ctx, dd)
-					if sendInterval > 0 {
-						time.Sleep(sendInterval)
-					}
-				}
+				sendResults(ctx, node, sendInterval, data)
 			case <-ctx.Done():
 				ctx.GetLogger().Infof("merge done")
 				return
@@ -82,6 +69,89 @@ func merge(ctx api.StreamContext, node *defaultSinkNode, sendInterval time.Durat
 	}
 }
 
+// runWithPartition runs the workers in parallel like runWithOrder. Instead of round-robin, the items are routed by
+// the hash of the partition keys so that the items of the same keys are always processed by the same worker.
+// Each worker is created by newWorker so that it can hold its own function valuers.
+func runWithPartition(ctx api.StreamContext, node *defaultSinkNode, numWorkers int, keys []ast.Expr, newWorker func(i int) workerFunc) {
+	workerChans := make([]chan any, numWorkers)
+	workerOutChans := make([]chan []any, numWorkers)
+	for i := range workerChans {
+		workerChans[i] = make(chan any)
+		workerOutChans[i] = make(chan []any)
+	}
+	for i := 0; i < numWorkers; i++ {
+		go worker(ctx, node, i, newWorker(i), workerChans[i], workerOutChans[i])
+	}
+	// The worker index of each distributed item in the input order
+	order := make(chan int, cap(node.input)+numWorkers)
+	go mergeByOrder(ctx, node, order, workerOutChans)
+
+	fv, _ := xsql.NewFunctionValuersForOp(ctx)
+	for {
+		node.statManager.SetBufferLength(int64(len(node.input)))
+		select {
+		case <-ctx.Done():
+			ctx.GetLogger().Infof("distribute done")
+			return
+		case item := <-node.input:
+			// Handle the barrier here so that the workers only receive the data
+			data, processed := node.preprocess(ctx, item)
+			if processed {
+				break
+			}
+			i := partitionIndex(keys, data, fv, numWorkers)
+			select {
+			case workerChans[i] <- data:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case order <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// mergeByOrder merges the results of the workers in the order of the input.
+// Each worker must send exactly one result for each input.
+func mergeByOrder(ctx api.StreamContext, node *defaultSinkNode, order chan int, channels []chan []any) {
+	for {
+		select {
+		case i := <-order:
+			select {
+			case data := <-channels[i]:
+				sendResults(ctx, node, 0, data)
+			case <-ctx.Done():
+				ctx.GetLogger().Infof("merge done")
+				return
+			}
+		case <-ctx.Done():
+			ctx.GetLogger().Infof("merge done")
+			return
+		}
+	}
+}
+
+func sendResults(ctx api.StreamContext, node *defaultSinkNode, sendInterval time.Duration, data []any) {
+	for _, d := range data {
+		if derr, ok := d.(error); ok {
+			node.onError(ctx, derr)
+			continue
+		}
+		dd, processed := node.commonIngest(ctx, d)
+		if processed {
+			continue
+		}
+		node.Broadcast(dd)
+		node.onSend(ctx, dd)
+		if sendInterval > 0 {
+			time.Sleep(sendInterval)
+		}
+	}
+}
+
 func distribute(ctx api.StreamContext, node *defaultSinkNode, numWorkers int, workerChans []chan any) {
 	var counter int
 	for {
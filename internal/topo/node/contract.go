@@ -19,6 +19,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/checkpoint"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/metric"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
@@ -47,6 +48,11 @@ type MetricNode interface {
 	RemoveMetrics(ruleId string)
 }
 
+// AnalyzeNode exposes the live statistics of the node for explain analyze
+type AnalyzeNode interface {
+	GetAnalyzeStats() *metric.AnalyzeStats
+}
+
 type OperatorNode interface {
 	DataSinkNode
 	Emitter
@@ -0,0 +1,97 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"math"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// decodeFilter evaluates the predicate pushed down from the WHERE clause by decoding the predicate fields only.
+// It is a pre-filter, the full condition is still evaluated by the filter after the decode.
+type decodeFilter struct {
+	decoder   message.PartialDecoder
+	condition ast.Expr
+	fields    []string
+	schema    map[string]*ast.JsonStreamField
+}
+
+// SetFilter pushes down the predicate on the top level fields to drop the unmatched message before the full decode.
+// It is ignored if the format does not support partial decode.
+func (o *DecodeOp) SetFilter(ctx api.StreamContext, condition ast.Expr, fields []string) {
+	if o.forPayload || condition == nil || len(fields) == 0 {
+		return
+	}
+	pd, ok := o.converter.(message.PartialDecoder)
+	if !ok {
+		ctx.GetLogger().Infof("format %s does not support partial decode, skip the decode filter", o.c.Format)
+		return
+	}
+	o.filter = &decodeFilter{
+		decoder:   pd,
+		condition: condition,
+		fields:    fields,
+		schema:    o.sLayer.GetSchema(),
+	}
+}
+
+// match returns false only if the message surely does not match the condition.
+// Any decode error or the value which may be converted differently by the full decode is deferred to the full decode.
+func (f *decodeFilter) match(ctx api.StreamContext, raw []byte) bool {
+	m := make(map[string]any, len(f.fields))
+	for _, field := range f.fields {
+		v, err := f.decoder.DecodeField(ctx, raw, field)
+		if err != nil || v == nil || !compatible(v, f.schema[field]) {
+			return true
+		}
+		m[field] = v
+	}
+	ve := &xsql.ValuerEval{Valuer: xsql.Message(m)}
+	r, ok := ve.Eval(f.condition).(bool)
+	return !ok || r
+}
+
+// compatible checks if the partially decoded value is the same as the fully decoded value with the schema
+func compatible(v any, field *ast.JsonStreamField) bool {
+	if field == nil {
+		return true
+	}
+	switch field.Type {
+	case "bigint":
+		switch vt := v.(type) {
+		case int64:
+			return true
+		case float64:
+			return vt == math.Trunc(vt)
+		}
+	case "float":
+		switch v.(type) {
+		case int64, float64:
+			return true
+		}
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestDecodeFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		cond   string
+		fields []string
+		schema map[string]*ast.JsonStreamField
+		raw    string
+		match  bool
+	}{
+		{name: "match", cond: "a > 3", fields: []string{"a"}, raw: `{"a":4,"b":{"c":1}}`, match: true},
+		{name: "not match", cond: "a > 3", fields: []string{"a"}, raw: `{"a":3,"b":{"c":1}}`, match: false},
+		{name: "multiple fields", cond: "a > 3 AND b = 'x'", fields: []string{"a", "b"}, raw: `{"a":4,"b":"y"}`, match: false},
+		{name: "missing field", cond: "a > 3", fields: []string{"a"}, raw: `{"b":1}`, match: true},
+		{name: "nested field", cond: "a > 3", fields: []string{"a"}, raw: `{"a":{"c":1}}`, match: true},
+		{name: "invalid payload", cond: "a > 3", fields: []string{"a"}, raw: `{"a":`, match: true},
+		{name: "schema match", cond: "a > 3", fields: []string{"a"}, schema: map[string]*ast.JsonStreamField{"a": {Type: "bigint"}}, raw: `{"a":2}`, match: false},
+		{name: "schema conversion", cond: "a < 3.2", fields: []string{"a"}, schema: map[string]*ast.JsonStreamField{"a": {Type: "bigint"}}, raw: `{"a":3.5}`, match: true},
+		{name: "schema type mismatch", cond: "a = 'true'", fields: []string{"a"}, schema: map[string]*ast.JsonStreamField{"a": {Type: "string"}}, raw: `{"a":true}`, match: true},
+	}
+	ctx := mockContext.NewMockContext("test", "Test")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM demo WHERE " + tt.cond)).Parse()
+			require.NoError(t, err)
+			op, err := NewDecodeOp(ctx, false, "test", "demo", &def.RuleOption{BufferLength: 10, SendError: true}, tt.schema, map[string]any{})
+			require.NoError(t, err)
+			op.SetFilter(ctx, stmt.Condition, tt.fields)
+			require.NotNil(t, op.filter)
+			assert.Equal(t, tt.match, op.filter.match(ctx, []byte(tt.raw)))
+		})
+	}
+}
+
+func TestDecodeWithFilter(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "Test")
+	op, err := NewDecodeOp(ctx, false, "test", "demo", &def.RuleOption{BufferLength: 10, SendError: true}, nil, map[string]any{})
+	require.NoError(t, err)
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM demo WHERE a > 3")).Parse()
+	require.NoError(t, err)
+	op.SetFilter(ctx, stmt.Condition, []string{"a"})
+	out := make(chan any, 100)
+	require.NoError(t, op.AddOutput(out, "test"))
+	errCh := make(chan error)
+	op.Exec(mockContext.NewMockContext("test1", "decode_test"), errCh)
+	for i := 0; i < 6; i++ {
+		op.input <- &xsql.RawTuple{Emitter: "test", Rawdata: []byte(`{"a":` + string(rune('0'+i)) + `}`), Timestamp: time.UnixMilli(111)}
+	}
+	op.input <- xsql.EOFTuple(0)
+	var result []any
+	for r := range out {
+		if _, ok := r.(xsql.EOFTuple); ok {
+			break
+		}
+		result = append(result, r)
+	}
+	assert.Equal(t, []any{
+		&xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 4.0}, Timestamp: time.UnixMilli(111)},
+		&xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 5.0}, Timestamp: time.UnixMilli(111)},
+	}, result)
+}
+
+func TestDecodeFilterNotSupported(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "Test")
+	op, err := NewDecodeOp(ctx, false, "test", "demo", &def.RuleOption{BufferLength: 10, SendError: true}, nil, map[string]any{"format": "delimited"})
+	require.NoError(t, err)
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM demo WHERE a > 3")).Parse()
+	require.NoError(t, err)
+	op.SetFilter(ctx, stmt.Condition, []string{"a"})
+	assert.Nil(t, op.filter)
+}
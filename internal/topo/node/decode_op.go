@@ -41,6 +41,8 @@ type DecodeOp struct {
 	// This is for first level decode, add the payload field to schema to make sure it is decoded
 	forPayload     bool
 	additionSchema string
+	// Only for the first level decode, drop the message by the pushed down predicate before decoding
+	filter *decodeFilter
 }
 
 type dconf struct {
@@ -137,7 +139,26 @@ func (o *DecodeOp) Exec(ctx api.StreamContext, errCh chan<- error) {
 func (o *DecodeOp) Worker(ctx api.StreamContext, item any) []any {
 	switch d := item.(type) {
 	case *xsql.RawTuple:
-		result, err := o.converter.Decode(ctx, d.Raw())
+		if o.filter != nil && !o.filter.match(ctx, d.Raw()) {
+			return nil
+		}
+		var (
+			result any
+			err    error
+		)
+		if md, ok := o.converter.(message.MetaDecoder); ok {
+			// The raw tuple metadata is immutable, decode with a copy which the decoder can enrich
+			meta := make(xsql.Metadata, len(d.Metadata))
+			for k, v := range d.Metadata {
+				meta[k] = v
+			}
+			result, err = md.DecodeWithMeta(ctx, d.Raw(), meta)
+			nd := *d
+			nd.Metadata = meta
+			d = &nd
+		} else {
+			result, err = o.converter.Decode(ctx, d.Raw())
+		}
 		if err != nil {
 			return []any{err}
 		}
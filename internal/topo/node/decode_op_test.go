@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/converter/sparkplugb"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
@@ -249,6 +250,37 @@ func TestValidate(t *testing.T) {
 	assert.EqualError(t, err, "cannot get converter from format test, schemaId : format type test not supported")
 }
 
+func TestMetaDecode(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "Test")
+	op, err := NewDecodeOp(ctx, false, "test", "streamName", &def.RuleOption{BufferLength: 10, SendError: true}, nil, map[string]any{"format": "sparkplugb"})
+	require.NoError(t, err)
+	out := make(chan any, 100)
+	require.NoError(t, op.AddOutput(out, "test"))
+	errCh := make(chan error)
+	op.Exec(mockContext.NewMockContext("test1", "decode_test"), errCh)
+
+	birth, err := sparkplugb.Marshal(&sparkplugb.Payload{Seq: 0, HasSeq: true, Metrics: []*sparkplugb.Metric{{Name: "a", Alias: 1, HasAlias: true, Datatype: sparkplugb.TypeInt32, Value: uint32(1)}}})
+	require.NoError(t, err)
+	data, err := sparkplugb.Marshal(&sparkplugb.Payload{Seq: 1, HasSeq: true, Metrics: []*sparkplugb.Metric{{Alias: 1, HasAlias: true, Value: uint32(2)}}})
+	require.NoError(t, err)
+	rawMeta := map[string]any{"topic": "spBv1.0/g1/NBIRTH/e1", "qos": 1}
+	cases := []*xsql.RawTuple{
+		{Emitter: "test", Rawdata: birth, Timestamp: time.UnixMilli(111), Metadata: rawMeta},
+		{Emitter: "test", Rawdata: data, Timestamp: time.UnixMilli(112), Metadata: map[string]any{"topic": "spBv1.0/g1/NDATA/e1"}},
+	}
+	expects := []*xsql.Tuple{
+		{Emitter: "test", Message: map[string]any{"a": int64(1)}, Timestamp: time.UnixMilli(111), Metadata: map[string]any{"topic": "spBv1.0/g1/NBIRTH/e1", "qos": 1, "groupId": "g1", "edgeNodeId": "e1", "messageType": "NBIRTH", "seq": int64(0)}},
+		{Emitter: "test", Message: map[string]any{"a": int64(2)}, Timestamp: time.UnixMilli(112), Metadata: map[string]any{"topic": "spBv1.0/g1/NDATA/e1", "groupId": "g1", "edgeNodeId": "e1", "messageType": "NDATA", "seq": int64(1)}},
+	}
+	for i, c := range cases {
+		op.input <- c
+		r := <-out
+		require.Equal(t, expects[i], r)
+	}
+	// the raw metadata is not modified
+	require.Equal(t, map[string]any{"topic": "spBv1.0/g1/NBIRTH/e1", "qos": 1}, rawMeta)
+}
+
 func TestPayloadDecodeWithSchema(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,87 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/encryptor"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// DecryptOp decrypts raw bytes. It is the reverse of EncryptNode and runs before decompress and decode.
+// Immutable: false
+// Input: RawTuple
+// Output: RawTuple
+type DecryptOp struct {
+	*defaultSinkNode
+	tool message.Decryptor
+}
+
+func NewDecryptOp(name string, rOpt *def.RuleOption, decryptMethod string, decProps map[string]any) (*DecryptOp, error) {
+	dc, err := encryptor.GetDecryptor(decryptMethod, decProps)
+	if err != nil {
+		return nil, fmt.Errorf("get decryptor %s fail with error: %v", decryptMethod, err)
+	}
+	return &DecryptOp{
+		defaultSinkNode: newDefaultSinkNode(name, rOpt),
+		tool:            dc,
+	}, nil
+}
+
+func (o *DecryptOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	o.prepareExec(ctx, errCh, "op")
+	go func() {
+		defer func() {
+			o.Close()
+		}()
+		err := infra.SafeRun(func() error {
+			runWithOrder(ctx, o.defaultSinkNode, o.concurrency, o.Worker)
+			return nil
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+func (o *DecryptOp) Worker(_ api.StreamContext, item any) []any {
+	switch d := item.(type) {
+	case error:
+		return []any{d}
+	case *xsql.RawTuple:
+		var (
+			r   []byte
+			err error
+		)
+		if md, ok := o.tool.(message.MetaDecryptor); ok {
+			r, err = md.DecryptWithMeta(d.Raw(), d.Metadata)
+		} else {
+			r, err = o.tool.Decrypt(d.Raw())
+		}
+		if err != nil {
+			return []any{err}
+		}
+		d.Rawdata = r
+		return []any{d}
+	default:
+		return []any{fmt.Errorf("unsupported data received: %v", d)}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestNewDecryptOp(t *testing.T) {
+	_, err := NewDecryptOp("test", &def.RuleOption{}, "non", nil)
+	assert.Error(t, err)
+	assert.Equal(t, "get decryptor non fail with error: decryptor 'non' is not supported", err.Error())
+	_, err = NewDecryptOp("test", &def.RuleOption{}, "chacha20poly1305", map[string]any{"key": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	assert.NoError(t, err)
+}
+
+func TestDecryptOp_Exec(t *testing.T) {
+	op, err := NewDecryptOp("test", &def.RuleOption{BufferLength: 10, SendError: true}, "chacha20poly1305", map[string]any{"key": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	assert.NoError(t, err)
+	op.tool = &MockDecryptor{}
+	out := make(chan any, 100)
+	err = op.AddOutput(out, "test")
+	assert.NoError(t, err)
+	ctx := mockContext.NewMockContext("test1", "decrypt_test")
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+
+	cases := []any{
+		&xsql.RawTuple{Emitter: "test", Rawdata: []byte("secret"), Timestamp: time.UnixMilli(111), Metadata: map[string]any{"topic": "demo", "keyId": "k1"}},
+		&xsql.RawTuple{Emitter: "test", Rawdata: []byte("secret"), Timestamp: time.UnixMilli(111), Metadata: map[string]any{"topic": "demo"}},
+		errors.New("go through error"),
+		"invalid",
+	}
+	expects := [][]any{
+		{&xsql.RawTuple{Emitter: "test", Rawdata: []byte("decrypted with k1"), Timestamp: time.UnixMilli(111), Metadata: map[string]any{"topic": "demo", "keyId": "k1"}}},
+		{errors.New("key id not found")},
+		{errors.New("go through error")},
+		{errors.New("unsupported data received: invalid")},
+	}
+
+	for i, c := range cases {
+		op.input <- c
+		for _, e := range expects[i] {
+			r := <-out
+			switch tr := r.(type) {
+			case error:
+				assert.EqualError(t, e.(error), tr.Error())
+			default:
+				assert.Equal(t, e, r)
+			}
+		}
+	}
+}
+
+type MockDecryptor struct{}
+
+func (m *MockDecryptor) Decrypt(_ []byte) ([]byte, error) {
+	return nil, errors.New("should use meta")
+}
+
+func (m *MockDecryptor) DecryptWithMeta(_ []byte, meta map[string]any) ([]byte, error) {
+	k, ok := meta["keyId"]
+	if !ok {
+		return nil, errors.New("key id not found")
+	}
+	return []byte("decrypted with " + k.(string)), nil
+}
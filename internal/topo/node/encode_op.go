@@ -36,7 +36,7 @@ type EncodeOp struct {
 }
 
 func NewEncodeOp(ctx api.StreamContext, name string, rOpt *def.RuleOption, sc *SinkConf) (*EncodeOp, error) {
-	c, err := converter.GetOrCreateConverter(ctx, sc.Format, sc.SchemaId, nil, map[string]any{"delimiter": sc.Delimiter, "hasHeader": sc.HasHeader, "fields": sc.Fields})
+	c, err := converter.GetOrCreateConverter(ctx, sc.Format, sc.SchemaId, nil, map[string]any{"delimiter": sc.Delimiter, "hasHeader": sc.HasHeader, "fields": sc.Fields, "timestampPrecision": sc.TimestampPrecision, "xmlLayout": sc.XmlLayout, "xmlRoot": sc.XmlRoot, "xmlItem": sc.XmlItem, "xpaths": sc.XPaths, "namespaces": sc.Namespaces})
 	if err != nil {
 		return nil, err
 	}
@@ -187,9 +187,10 @@ func (o *WindowOperator) execEventWindow(ctx api.StreamContext, inputs []*xsql.T
 					o.triggerTS = append(o.triggerTS, d.Timestamp)
 				}
 				inputs = append(inputs, d)
+				o.memory.add(d)
 				o.span = nil
 				o.onProcessEnd(ctx)
-				_ = ctx.PutState(WindowInputsKey, inputs)
+				inputs = o.saveInputs(ctx, inputs)
 			default:
 				o.onError(ctx, fmt.Errorf("run Window error: expect xsql.Event type but got %[1]T(%[1]v)", d))
 			}
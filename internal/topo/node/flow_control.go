@@ -0,0 +1,146 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+)
+
+var (
+	// FlowHighWatermark is the buffer usage ratio to pause the sources
+	FlowHighWatermark = 0.8
+	// FlowLowWatermark is the buffer usage ratio to resume the sources
+	FlowLowWatermark = 0.5
+	// FlowCheckInterval is the interval to check the buffer usage when paused
+	FlowCheckInterval = 10 * time.Millisecond
+)
+
+// FlowControl is the credit based backpressure of a rule. The credits are the free slots of the output buffers of
+// all nodes in the rule. When the credits are nearly exhausted, the sources are paused until enough credits are
+// released by the downstream nodes. Meanwhile, the nodes block when sending to a full buffer instead of dropping.
+// It is thread safe.
+type FlowControl struct {
+	RuleID string
+	mu     sync.RWMutex
+	nodes  []*defaultNode
+	// status
+	paused      atomic.Bool
+	pauseCount  atomic.Int64
+	pauseMillis atomic.Int64
+}
+
+func NewFlowControl(ruleId string) *FlowControl {
+	return &FlowControl{RuleID: ruleId}
+}
+
+// GetFlowControl returns the flow control of the rule. Return nil if backpressure is not enabled
+func GetFlowControl(ctx api.StreamContext) *FlowControl {
+	if ctx == nil {
+		return nil
+	}
+	f, _ := ctx.Value(topoContext.RuleFlowKey).(*FlowControl)
+	return f
+}
+
+func (f *FlowControl) register(n *defaultNode) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, o := range f.nodes {
+		if o == n {
+			return
+		}
+	}
+	f.nodes = append(f.nodes, n)
+}
+
+// Usage returns the maximum usage ratio of the output buffers
+func (f *FlowControl) Usage() float64 {
+	if f == nil {
+		return 0
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var r float64
+	for _, n := range f.nodes {
+		n.outputMu.RLock()
+		for _, out := range n.outputs {
+			if c := cap(out); c > 0 {
+				if u := float64(len(out)) / float64(c); u > r {
+					r = u
+				}
+			}
+		}
+		n.outputMu.RUnlock()
+	}
+	return r
+}
+
+// IsPressured returns true if the sources should stop producing
+func (f *FlowControl) IsPressured() bool {
+	if f == nil {
+		return false
+	}
+	return f.paused.Load() || f.Usage() >= FlowHighWatermark
+}
+
+// Acquire blocks the caller source until the buffers have enough credits. Return true if it has waited.
+func (f *FlowControl) Acquire(ctx api.StreamContext) bool {
+	if !f.IsPressured() {
+		return false
+	}
+	if f.paused.CompareAndSwap(false, true) {
+		f.pauseCount.Add(1)
+		ctx.GetLogger().Infof("rule %s buffers are nearly full, pause the sources", f.RuleID)
+	}
+	start := time.Now()
+	defer func() {
+		f.pauseMillis.Add(time.Since(start).Milliseconds())
+	}()
+	for f.Usage() > FlowLowWatermark {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-time.After(FlowCheckInterval):
+		}
+	}
+	if f.paused.CompareAndSwap(true, false) {
+		ctx.GetLogger().Infof("rule %s buffers are released, resume the sources", f.RuleID)
+	}
+	return true
+}
+
+const (
+	BackpressurePausedKey      = "backpressure_paused"
+	BackpressurePauseCountKey  = "backpressure_pause_count"
+	BackpressurePauseMillisKey = "backpressure_pause_ms"
+)
+
+// GetMetrics returns the backpressure status to show in the rule status
+func (f *FlowControl) GetMetrics() (keys []string, values []any) {
+	if f == nil {
+		return nil, nil
+	}
+	return []string{BackpressurePausedKey, BackpressurePauseCountKey, BackpressurePauseMillisKey},
+		[]any{f.paused.Load(), f.pauseCount.Load(), f.pauseMillis.Load()}
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mockclock"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestFlowControlNil(t *testing.T) {
+	var f *FlowControl
+	ctx := mockContext.NewMockContext("rule1", "src1")
+	assert.Nil(t, GetFlowControl(ctx))
+	assert.False(t, f.IsPressured())
+	assert.False(t, f.Acquire(ctx))
+	assert.Equal(t, float64(0), f.Usage())
+	keys, values := f.GetMetrics()
+	assert.Nil(t, keys)
+	assert.Nil(t, values)
+}
+
+func TestFlowControlSubscribe(t *testing.T) {
+	f := NewFlowControl("rule1")
+	ctx, cancel := mockContext.NewMockContext("rule1", "src1").WithCancel()
+	defer cancel()
+	ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleFlowKey, f)
+	sc := &MockSourceConnector{
+		data: [][]byte{[]byte("1"), []byte("2"), []byte("3"), []byte("4"), []byte("5")},
+	}
+	scn, err := NewSourceNode(ctx, "mock_connector", sc, map[string]any{"datasource": "demo"}, &def.RuleOption{
+		BufferLength: 1024,
+		Backpressure: true,
+	})
+	require.NoError(t, err)
+	result := make(chan any, 4)
+	require.NoError(t, scn.AddOutput(result, "testResult"))
+	scn.Open(ctx, make(chan error, 10))
+	time.Sleep(100 * time.Millisecond)
+	// The 5th message is paused instead of dropped
+	assert.Len(t, result, 4)
+	assert.True(t, f.IsPressured())
+	keys, values := f.GetMetrics()
+	assert.Equal(t, []string{BackpressurePausedKey, BackpressurePauseCountKey, BackpressurePauseMillisKey}, keys)
+	assert.Equal(t, true, values[0])
+	assert.Equal(t, int64(1), values[1])
+	// Release to the low watermark to resume
+	<-result
+	<-result
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, result, 3)
+	_, values = f.GetMetrics()
+	assert.Equal(t, false, values[0])
+	assert.True(t, values[2].(int64) > 0)
+	<-result
+	<-result
+	r := <-result
+	assert.Equal(t, []byte("5"), r.(*xsql.RawTuple).Raw())
+}
+
+func TestFlowControlPull(t *testing.T) {
+	mockclock.ResetClock(0)
+	f := NewFlowControl("rule1")
+	ctx, cancel := mockContext.NewMockContext("rule1", "src1").WithCancel()
+	defer cancel()
+	ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleFlowKey, f)
+	sc := &MockPullSource{}
+	scn, err := NewSourceNode(ctx, "mock_connector", sc, map[string]any{"datasource": "demo", "interval": "1s"}, &def.RuleOption{
+		BufferLength: 1024,
+		Backpressure: true,
+	})
+	require.NoError(t, err)
+	result := make(chan any, 1)
+	require.NoError(t, scn.AddOutput(result, "testResult"))
+	scn.Open(ctx, make(chan error, 10))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, sc.pullTimes)
+	// Skip the pull when the buffer is full
+	timex.Add(time.Second)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, sc.pullTimes)
+	<-result
+	timex.Add(time.Second)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 2, sc.pullTimes)
+}
+
+// callbackSource ingests in the callback of the test like a shared connection
+type callbackSource struct {
+	exclusive bool
+	ingest    chan api.BytesIngest
+	// offset is the count of the received messages
+	offset atomic.Int64
+}
+
+func (c *callbackSource) Provision(_ api.StreamContext, _ map[string]any) error { return nil }
+
+func (c *callbackSource) Connect(_ api.StreamContext, _ api.StatusChangeHandler) error { return nil }
+
+func (c *callbackSource) Close(_ api.StreamContext) error { return nil }
+
+func (c *callbackSource) Subscribe(_ api.StreamContext, ingest api.BytesIngest, _ api.ErrorIngest) error {
+	c.ingest <- ingest
+	return nil
+}
+
+func (c *callbackSource) ExclusiveSubscription() bool { return c.exclusive }
+
+func (c *callbackSource) GetOffset() (any, error) { return c.offset.Load(), nil }
+
+func (c *callbackSource) Rewind(_ any) error { return nil }
+
+func (c *callbackSource) ResetOffset(_ map[string]any) error { return nil }
+
+func TestFlowControlSharedCallback(t *testing.T) {
+	for _, exclusive := range []bool{false, true} {
+		t.Run(fmt.Sprintf("exclusive %v", exclusive), func(t *testing.T) {
+			f := NewFlowControl("rule1")
+			ctx, cancel := mockContext.NewMockContext("rule1", "src1").WithCancel()
+			defer cancel()
+			ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleFlowKey, f)
+			sc := &callbackSource{exclusive: exclusive, ingest: make(chan api.BytesIngest, 1)}
+			scn, err := NewSourceNode(ctx, "mock_connector", sc, map[string]any{}, &def.RuleOption{
+				BufferLength: 2,
+				Backpressure: true,
+			})
+			require.NoError(t, err)
+			result := make(chan any, 1)
+			require.NoError(t, scn.AddOutput(result, "testResult"))
+			scn.Open(ctx, make(chan error, 10))
+			ingest := <-sc.ingest
+			done := make(chan struct{})
+			go func() {
+				for i := 0; i < 10; i++ {
+					sc.offset.Add(1)
+					ingest(ctx, []byte(strconv.Itoa(i)), nil, timex.GetNow())
+				}
+				close(done)
+			}()
+			// The callback blocks when the downstream is full whether it is shared or not
+			select {
+			case <-done:
+				require.Fail(t, "the callback should block")
+			case <-time.After(200 * time.Millisecond):
+			}
+			// Only the offset of the data sent out is saved
+			offset, err := ctx.GetState(OffsetKey)
+			require.NoError(t, err)
+			require.NotNil(t, offset)
+			assert.Less(t, offset.(int64), sc.offset.Load())
+			// No data is dropped
+			for i := 0; i < 10; i++ {
+				r := <-result
+				assert.Equal(t, []byte(strconv.Itoa(i)), r.(*xsql.RawTuple).Raw())
+			}
+			<-done
+			assert.Eventually(t, func() bool {
+				offset, _ := ctx.GetState(OffsetKey)
+				return offset == int64(10)
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
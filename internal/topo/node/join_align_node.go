@@ -16,6 +16,7 @@ package node
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -31,6 +32,10 @@ type JoinAlignNode struct {
 	// table states
 	batch map[string][]*xsql.Tuple
 	size  map[string]int
+	// memory accounting and spill of the table rows, nil if no memory budget
+	memory *tableMemory
+	// the latest committed checkpoint id, set by the coordinator
+	committedCheckpoint atomic.Int64
 }
 
 const BatchKey = "$$batchInputs"
@@ -79,12 +84,19 @@ func (n *JoinAlignNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 			if n.batch == nil {
 				n.batch = make(map[string][]*xsql.Tuple)
 			}
+			m, err := newTableMemory(ctx, n.batch, n.qos >= def.AtLeastOnce)
+			if err != nil {
+				return err
+			}
+			n.memory = m
+			defer n.memory.close()
 
 			for {
 				log.Debugf("JoinAlignNode %s is looping", n.name)
 				select {
 				// process incoming item from both streams(transformed) and tables
 				case item := <-n.input:
+					n.memory.mark(item)
 					data, processed := n.commonIngest(ctx, item)
 					if processed {
 						break
@@ -94,13 +106,16 @@ func (n *JoinAlignNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 					case *xsql.Tuple:
 						log.Debugf("JoinAlignNode receive tuple input %v", d)
 						if b, ok := n.batch[d.Emitter]; ok {
-							s := n.size[d.Emitter]
-							if len(b) >= s {
-								b = b[s-len(b)+1:]
-							}
+							b = n.memory.evict(d.Emitter, b, n.size[d.Emitter])
 							b = append(b, d)
+							n.memory.add(d.Emitter, d)
+							n.memory.commit(n.committedCheckpoint.Load())
+							b = n.memory.sync(ctx, d.Emitter, b)
 							n.batch[d.Emitter] = b
 							_ = ctx.PutState(BatchKey, n.batch)
+							if n.memory != nil {
+								_ = ctx.PutState(TableSpillKey, n.memory.state())
+							}
 						} else {
 							n.alignBatch(ctx, d)
 						}
@@ -123,6 +138,11 @@ func (n *JoinAlignNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 	}()
 }
 
+// NotifyCheckpointComplete is called by the checkpoint coordinator so that the released spill pages can be deleted
+func (n *JoinAlignNode) NotifyCheckpointComplete(checkpointId int64) {
+	n.committedCheckpoint.Store(checkpointId)
+}
+
 func (n *JoinAlignNode) alignBatch(ctx api.StreamContext, input any) {
 	var w *xsql.WindowTuples
 	switch t := input.(type) {
@@ -134,7 +154,10 @@ func (n *JoinAlignNode) alignBatch(ctx api.StreamContext, input any) {
 	case *xsql.WindowTuples:
 		w = t
 	}
-	for _, contents := range n.batch {
+	for emitter, contents := range n.batch {
+		for _, v := range n.memory.rows(ctx, emitter) {
+			w = w.AddTuple(v)
+		}
 		if contents != nil {
 			for _, v := range contents {
 				w = w.AddTuple(v)
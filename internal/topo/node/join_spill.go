@@ -0,0 +1,234 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/checkpoint"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/cache"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+const TableSpillKey = "$$tableSpill"
+
+// TableSpill is the spill state of a table in the join
+type TableSpill struct {
+	Pages []cache.SpillPage
+	// The count of the rows of the oldest page which are out of the retain size
+	Skip int
+}
+
+func init() {
+	gob.Register(map[string]TableSpill{})
+}
+
+// tableMemory accounts the memory of the table rows in the join in the rule memory budget.
+// If the budget is exceeded, the oldest rows of the tables are saved to disk. The table rows are
+// joined with every stream row, so the spilled rows are read without releasing for each join.
+// The spilled rows of a table are always older than its rows in memory.
+// All methods are no-op for a nil receiver which means no memory budget is set.
+type tableMemory struct {
+	budget *cache.MemoryBudget
+	spills map[string]*cache.SpillBuffer
+	skips  map[string]int
+	// the estimated sizes of the rows in memory of each table, aligned with the rows
+	sizes map[string][]int64
+	total int64
+}
+
+// newTableMemory creates the memory accounting of the tables and accounts the restored rows.
+// If deferDelete is true, the released spill pages are kept in disk until the next checkpoint is committed.
+func newTableMemory(ctx api.StreamContext, batch map[string][]*xsql.Tuple, deferDelete bool) (*tableMemory, error) {
+	budget := cache.GetMemoryBudget(ctx)
+	if budget == nil {
+		return nil, nil
+	}
+	var restored map[string]TableSpill
+	if s, err := ctx.GetState(TableSpillKey); err == nil && s != nil {
+		if st, ok := s.(map[string]TableSpill); ok {
+			restored = st
+		} else {
+			return nil, fmt.Errorf("restore join state `spill` %v error, invalid type", s)
+		}
+	}
+	m := &tableMemory{
+		budget: budget,
+		spills: make(map[string]*cache.SpillBuffer, len(batch)),
+		skips:  make(map[string]int, len(batch)),
+		sizes:  make(map[string][]int64, len(batch)),
+	}
+	for emitter, rows := range batch {
+		sb, err := cache.NewNamedSpillBuffer(ctx, emitter, restored[emitter].Pages, deferDelete)
+		if err != nil {
+			return nil, err
+		}
+		m.spills[emitter] = sb
+		m.skips[emitter] = restored[emitter].Skip
+		for _, t := range rows {
+			m.add(emitter, t)
+		}
+	}
+	return m, nil
+}
+
+// add accounts the new row appended to the table
+func (m *tableMemory) add(emitter string, t *xsql.Tuple) {
+	if m == nil {
+		return
+	}
+	s := tupleSize(t)
+	m.sizes[emitter] = append(m.sizes[emitter], s)
+	m.total += s
+	m.budget.Reserve(s)
+}
+
+// spilled returns the count of the spilled rows of the table which are still in the retain size
+func (m *tableMemory) spilled(emitter string) int {
+	if m == nil {
+		return 0
+	}
+	return m.spills[emitter].Len() - m.skips[emitter]
+}
+
+// evict drops the oldest rows of the table, spilled or not, so that a new row can be added within the retain size
+func (m *tableMemory) evict(emitter string, rows []*xsql.Tuple, size int) []*xsql.Tuple {
+	for m.spilled(emitter)+len(rows) >= size {
+		if m.spilled(emitter) > 0 {
+			m.skips[emitter]++
+			if p, ok := m.spills[emitter].Head(); ok && m.skips[emitter] >= p.Count {
+				m.spills[emitter].DropHead()
+				m.skips[emitter] = 0
+			}
+			continue
+		}
+		if len(rows) == 0 {
+			break
+		}
+		if m != nil {
+			m.release(emitter, 1)
+		}
+		rows = rows[1:]
+	}
+	return rows
+}
+
+// rows returns the spilled rows of the table in order
+func (m *tableMemory) rows(ctx api.StreamContext, emitter string) []*xsql.Tuple {
+	if m.spilled(emitter) <= 0 {
+		return nil
+	}
+	items, err := m.spills[emitter].Read(ctx, m.skips[emitter])
+	if err != nil {
+		ctx.GetLogger().Error(err)
+	}
+	result := make([]*xsql.Tuple, 0, len(items))
+	for _, item := range items {
+		t, ok := item.(*xsql.Tuple)
+		if !ok {
+			ctx.GetLogger().Errorf("invalid spilled tuple %v", item)
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// sync spills the oldest rows of the table to disk if the budget is exceeded and returns the rest rows in memory.
+// It also throttles the operator if the budget is still exceeded.
+func (m *tableMemory) sync(ctx api.StreamContext, emitter string, rows []*xsql.Tuple) []*xsql.Tuple {
+	if m == nil {
+		return rows
+	}
+	spilled := 0
+	for m.budget.IsExceeded() && len(rows)-spilled >= SpillPageSize {
+		items := make([]any, SpillPageSize)
+		for i := 0; i < SpillPageSize; i++ {
+			items[i] = rows[spilled+i]
+		}
+		start, end := rows[spilled].Timestamp.UnixMilli(), rows[spilled+SpillPageSize-1].Timestamp.UnixMilli()
+		if err := m.spills[emitter].Spill(ctx, items, start, end); err != nil {
+			ctx.GetLogger().Error(err)
+			break
+		}
+		m.release(emitter, SpillPageSize)
+		spilled += SpillPageSize
+	}
+	if spilled > 0 {
+		// copy to a new slice so that the spilled rows can be garbage collected
+		rows = append(make([]*xsql.Tuple, 0, len(rows)-spilled), rows[spilled:]...)
+	}
+	m.budget.Throttle(ctx)
+	return rows
+}
+
+func (m *tableMemory) release(emitter string, n int) {
+	var s int64
+	for _, size := range m.sizes[emitter][:n] {
+		s += size
+	}
+	m.sizes[emitter] = m.sizes[emitter][n:]
+	m.total -= s
+	m.budget.Release(s)
+}
+
+// mark records the spilled pages when receiving the checkpoint barrier
+func (m *tableMemory) mark(item any) {
+	if m == nil {
+		return
+	}
+	if b, ok := item.(*checkpoint.BufferOrEvent); ok {
+		if barrier, ok := b.Data.(*checkpoint.Barrier); ok {
+			for _, sb := range m.spills {
+				sb.Mark(barrier.CheckpointId)
+			}
+		}
+	}
+}
+
+// commit deletes the released spill pages which are not referred by the committed checkpoint
+func (m *tableMemory) commit(checkpointId int64) {
+	if m == nil || checkpointId <= 0 {
+		return
+	}
+	for _, sb := range m.spills {
+		sb.Commit(checkpointId)
+	}
+}
+
+// state returns the spill state of the tables to save along with the rows in memory
+func (m *tableMemory) state() map[string]TableSpill {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]TableSpill, len(m.spills))
+	for emitter, sb := range m.spills {
+		result[emitter] = TableSpill{Pages: sb.Pages(), Skip: m.skips[emitter]}
+	}
+	return result
+}
+
+// close releases all the memory accounted by the tables
+func (m *tableMemory) close() {
+	if m == nil {
+		return
+	}
+	m.budget.Release(m.total)
+	m.sizes = nil
+	m.total = 0
+}
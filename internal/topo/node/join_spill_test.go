@@ -0,0 +1,127 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/cache"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestTableMemory(t *testing.T) {
+	testx.InitEnv("join_spill")
+	old := SpillPageSize
+	SpillPageSize = 2
+	defer func() { SpillPageSize = old }()
+	ctx := mockContext.NewMockContext("test1", "table_memory")
+	m, err := newTableMemory(ctx, map[string][]*xsql.Tuple{"table1": nil}, false)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	tuples := make([]*xsql.Tuple, 7)
+	for i := range tuples {
+		tuples[i] = &xsql.Tuple{Emitter: "table1", Message: map[string]any{"a": int64(i)}, Timestamp: time.UnixMilli(int64(i))}
+	}
+	size := tupleSize(tuples[0])
+	budget := cache.NewMemoryBudget("test1", 3*size)
+	ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleMemoryKey, budget)
+	m, err = newTableMemory(ctx, map[string][]*xsql.Tuple{"table1": nil}, false)
+	require.NoError(t, err)
+	var rows []*xsql.Tuple
+	// retain 5 rows of the table
+	appendRow := func(tuple *xsql.Tuple) {
+		rows = m.evict("table1", rows, 5)
+		rows = append(rows, tuple)
+		m.add("table1", tuple)
+		rows = m.sync(ctx, "table1", rows)
+	}
+	for _, tuple := range tuples[:4] {
+		appendRow(tuple)
+	}
+	// Spill a page to keep the budget
+	assert.Equal(t, tuples[2:4], rows)
+	assert.Equal(t, 2*size, budget.Used())
+	assert.Equal(t, tuples[:2], m.rows(ctx, "table1"))
+	// The first spilled row is out of the retain size
+	appendRow(tuples[4])
+	appendRow(tuples[5])
+	assert.Equal(t, tuples[4:6], rows)
+	assert.Equal(t, tuples[1:4], m.rows(ctx, "table1"))
+	table := path.Join(cache.SpillTable(ctx), "table1")
+	assert.Equal(t, map[string]TableSpill{
+		"table1": {
+			Pages: []cache.SpillPage{{Key: 0, Count: 2, Start: 0, End: 1, Table: table}, {Key: 1, Count: 2, Start: 2, End: 3, Table: table}},
+			Skip:  1,
+		},
+	}, m.state())
+	// The page is dropped once all its rows are out of the retain size
+	appendRow(tuples[6])
+	assert.Equal(t, tuples[4:7], rows)
+	assert.Equal(t, tuples[2:4], m.rows(ctx, "table1"))
+	assert.Equal(t, map[string]TableSpill{
+		"table1": {
+			Pages: []cache.SpillPage{{Key: 1, Count: 2, Start: 2, End: 3, Table: table}},
+		},
+	}, m.state())
+	assert.Equal(t, 3*size, budget.Used())
+	m.close()
+	assert.Equal(t, int64(0), budget.Used())
+}
+
+func TestJoinAlignSpill(t *testing.T) {
+	testx.InitEnv("join_spill")
+	old := SpillPageSize
+	SpillPageSize = 2
+	defer func() { SpillPageSize = old }()
+	op, err := NewJoinAlignNode("test", []string{"table1"}, []int{4}, &def.RuleOption{BufferLength: 10})
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("test2", "join_spill").WithCancel()
+	defer cancel()
+	// Keep 3 table rows in memory
+	budget := cache.NewMemoryBudget("test2", 3*tupleSize(&xsql.Tuple{Message: map[string]any{"a": int64(0)}}))
+	ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleMemoryKey, budget)
+	op.Exec(ctx, make(chan error, 10))
+	for i := 0; i < 6; i++ {
+		op.input <- &xsql.Tuple{Emitter: "table1", Message: map[string]any{"a": int64(i)}}
+	}
+	op.input <- &xsql.Tuple{Emitter: "stream1", Message: map[string]any{"b": int64(0)}}
+	r := <-out
+	w, ok := r.(*xsql.WindowTuples)
+	require.True(t, ok)
+	// The spilled rows are joined along with the rows in memory
+	require.Len(t, w.Content, 5)
+	for i, row := range w.Content[1:] {
+		a, _ := row.Value("a", "")
+		assert.Equal(t, int64(i+2), a)
+	}
+	v, err := ctx.GetState(TableSpillKey)
+	require.NoError(t, err)
+	assert.Len(t, v.(map[string]TableSpill)["table1"].Pages, 1)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(0), budget.Used())
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"slices"
+	"sync"
+)
+
+// latencySampleSize is the count of the latest latencies kept to estimate the percentiles
+const latencySampleSize = 512
+
+// AnalyzeStats is the live statistics of a node reported by explain analyze
+type AnalyzeStats struct {
+	Name string `json:"name"`
+	// Type is one of source, op and sink
+	Type       string `json:"type"`
+	RecordsIn  int64  `json:"recordsIn"`
+	RecordsOut int64  `json:"recordsOut"`
+	// Selectivity is the ratio of records out to records in
+	Selectivity  float64 `json:"selectivity"`
+	Exceptions   int64   `json:"exceptions"`
+	AvgLatencyUs int64   `json:"avgLatencyUs"`
+	P99LatencyUs int64   `json:"p99LatencyUs"`
+	// CostUs is the total processing time of the node
+	CostUs int64 `json:"costUs"`
+	// CostRatio is the share of the node cost in the whole rule
+	CostRatio float64 `json:"costRatio"`
+	// StateSize is the count of the state entries. Collections count by their length
+	StateSize       int64   `json:"stateSize"`
+	BufferLength    int64   `json:"bufferLength"`
+	BufferCapacity  int64   `json:"bufferCapacity,omitempty"`
+	BufferOccupancy float64 `json:"bufferOccupancy,omitempty"`
+}
+
+// latencyStats accumulates the process latencies. The latest latencies are kept in a ring to calculate the percentile.
+// It is added by the node goroutine and read by the rest api, so it is guarded by the mutex.
+type latencyStats struct {
+	mu      sync.Mutex
+	total   int64
+	count   int64
+	samples []int64
+	next    int
+}
+
+func (l *latencyStats) add(latency int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total += latency
+	l.count++
+	if len(l.samples) < latencySampleSize {
+		l.samples = append(l.samples, latency)
+		return
+	}
+	l.samples[l.next] = latency
+	l.next = (l.next + 1) % latencySampleSize
+}
+
+func (l *latencyStats) avg() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.count == 0 {
+		return 0
+	}
+	return l.total / l.count
+}
+
+// percentile returns the p (0-100) percentile of the sampled latencies with the nearest rank method
+func (l *latencyStats) percentile(p int) int64 {
+	l.mu.Lock()
+	sorted := slices.Clone(l.samples)
+	l.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0
+	}
+	slices.Sort(sorted)
+	rank := (len(sorted)*p + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func (l *latencyStats) cost() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.total
+}
+
+func (sm *DefaultStatManager) GetAnalyzeStats() *AnalyzeStats {
+	stats := &AnalyzeStats{
+		Type:         sm.opType,
+		RecordsIn:    sm.totalRecordsIn,
+		RecordsOut:   sm.totalRecordsOut,
+		Exceptions:   sm.totalExceptions,
+		AvgLatencyUs: sm.latencies.avg(),
+		P99LatencyUs: sm.latencies.percentile(99),
+		CostUs:       sm.latencies.cost(),
+		BufferLength: sm.bufferLength,
+	}
+	if stats.RecordsIn > 0 {
+		stats.Selectivity = float64(stats.RecordsOut) / float64(stats.RecordsIn)
+	}
+	return stats
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestLatencyStats(t *testing.T) {
+	l := &latencyStats{}
+	assert.Equal(t, int64(0), l.avg())
+	assert.Equal(t, int64(0), l.percentile(99))
+	for i := 1; i <= 100; i++ {
+		l.add(int64(i))
+	}
+	assert.Equal(t, int64(50), l.avg())
+	assert.Equal(t, int64(99), l.percentile(99))
+	assert.Equal(t, int64(50), l.percentile(50))
+	assert.Equal(t, int64(100), l.percentile(100))
+	// Only the latest samples count for the percentile
+	for i := 0; i < latencySampleSize; i++ {
+		l.add(1)
+	}
+	assert.Len(t, l.samples, latencySampleSize)
+	assert.Equal(t, int64(1), l.percentile(99))
+	assert.Equal(t, int64(100+latencySampleSize), l.count)
+}
+
+func TestLatencyStatsConcurrent(t *testing.T) {
+	l := &latencyStats{}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2*latencySampleSize; i++ {
+			l.add(int64(i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = l.percentile(99)
+			_ = l.avg()
+			_ = l.cost()
+		}
+	}()
+	wg.Wait()
+	assert.Equal(t, int64(2*latencySampleSize), l.count)
+}
+
+func TestGetAnalyzeStats(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	sm := NewStatManager(ctx, "op")
+	for i := 0; i < 4; i++ {
+		sm.IncTotalRecordsIn()
+		sm.ProcessTimeStart()
+		sm.ProcessTimeEnd()
+	}
+	sm.IncTotalRecordsOut()
+	sm.SetBufferLength(3)
+	stats := sm.GetAnalyzeStats()
+	assert.Equal(t, "op", stats.Type)
+	assert.Equal(t, int64(4), stats.RecordsIn)
+	assert.Equal(t, int64(1), stats.RecordsOut)
+	assert.Equal(t, 0.25, stats.Selectivity)
+	assert.Equal(t, int64(3), stats.BufferLength)
+	assert.GreaterOrEqual(t, stats.P99LatencyUs, stats.AvgLatencyUs)
+}
@@ -52,6 +52,8 @@ type StatManager interface {
 	// 0 is connecting, 1 is connected, -1 is disconnected
 	SetConnectionState(state string, message string)
 	GetMetrics() []any
+	// GetAnalyzeStats returns the statistics for explain analyze
+	GetAnalyzeStats() *AnalyzeStats
 	// Clean remove all metrics history
 	Clean(ruleId string)
 }
@@ -69,6 +71,7 @@ type DefaultStatManager struct {
 	totalExceptions   int64
 	lastException     string
 	lastExceptionTime time.Time
+	latencies         *latencyStats
 
 	connectionState *ConnectionStatManager
 	// configs
@@ -106,6 +109,7 @@ func NewStatManager(ctx api.StreamContext, opType string) StatManager {
 			connectionState: &ConnectionStatManager{},
 		}
 	}
+	ds.latencies = &latencyStats{}
 	sm, err := getStatManager(ctx, ds)
 	if err != nil {
 		ctx.GetLogger().Warnf("Fail to create extra stat manager for %s %s: %v", opType, ctx.GetOpId(), err)
@@ -147,6 +151,7 @@ func (sm *DefaultStatManager) ProcessTimeStart() {
 func (sm *DefaultStatManager) ProcessTimeEnd() {
 	if !sm.processTimeStart.IsZero() {
 		sm.processLatency = int64(time.Since(sm.processTimeStart) / time.Microsecond)
+		sm.latencies.add(sm.processLatency)
 	}
 }
 
@@ -98,6 +98,7 @@ func (sm *PrometheusStatManager) IncTotalExceptions(err string) {
 func (sm *PrometheusStatManager) ProcessTimeEnd() {
 	if !sm.processTimeStart.IsZero() {
 		sm.processLatency = int64(time.Since(sm.processTimeStart) / time.Microsecond)
+		sm.latencies.add(sm.processLatency)
 		sm.pProcessLatency.Set(float64(sm.processLatency))
 		sm.pProcessLatencyHist.Observe(float64(sm.processLatency))
 	}
@@ -51,6 +51,8 @@ type defaultNode struct {
 	spanCtx                  api.StreamContext
 	disableBufferFullDiscard bool
 	isStatManagerHostBySink  bool
+	// backpressure of the rule, nil if not enabled
+	flow *FlowControl
 }
 
 func newDefaultNode(name string, options *def.RuleOption) *defaultNode {
@@ -152,7 +154,7 @@ func (o *defaultNode) doBroadcast(val any) {
 			vt.SetTracerCtx(o.spanCtx)
 		}
 		// wait buffer consume if buffer full
-		if o.disableBufferFullDiscard {
+		if o.disableBufferFullDiscard || o.flow != nil {
 			select {
 			case out <- val:
 				continue
@@ -225,6 +227,8 @@ func (o *defaultNode) prepareExec(ctx api.StreamContext, errCh chan<- error, opT
 		o.opsWg.Add(1)
 	}
 	o.ctrlCh = errCh
+	o.flow = GetFlowControl(ctx)
+	o.flow.register(o)
 }
 
 func (o *defaultNode) finishExec() {
@@ -22,6 +22,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 )
 
@@ -42,6 +43,8 @@ type UnaryOperator struct {
 	*defaultSinkNode
 	op        UnOperation
 	cancelled bool
+	// If set, run the operation by concurrency instances partitioned by the keys
+	partitionKeys []ast.Expr
 }
 
 // New NewUnary creates *UnaryOperator value
@@ -56,6 +59,12 @@ func (o *UnaryOperator) SetOperation(op UnOperation) {
 	o.op = op
 }
 
+// SetPartition sets the partition keys. The stateful operation whose states are separated by the keys
+// can run in parallel by the rule concurrency.
+func (o *UnaryOperator) SetPartition(keys []ast.Expr) {
+	o.partitionKeys = keys
+}
+
 // Exec is the entry point for the executor
 func (o *UnaryOperator) Exec(ctx api.StreamContext, errCh chan<- error) {
 	o.prepareExec(ctx, errCh, "op")
@@ -71,7 +80,11 @@ func (o *UnaryOperator) Exec(ctx api.StreamContext, errCh chan<- error) {
 			o.Close()
 		}()
 		err := infra.SafeRun(func() error {
-			o.doOp(ctx.WithInstance(0), errCh)
+			if len(o.partitionKeys) > 0 && o.concurrency > 1 {
+				o.doPartitionedOp(ctx)
+			} else {
+				o.doOp(ctx.WithInstance(0), errCh)
+			}
 			return nil
 		})
 		if err != nil {
@@ -129,3 +142,27 @@ func (o *UnaryOperator) doOp(ctx api.StreamContext, errCh chan<- error) {
 		}
 	}
 }
+
+func (o *UnaryOperator) doPartitionedOp(ctx api.StreamContext) {
+	if o.op == nil {
+		ctx.GetLogger().Info("Unary operator missing operation")
+		return
+	}
+	runWithPartition(ctx, o.defaultSinkNode, o.concurrency, o.partitionKeys, func(_ int) workerFunc {
+		fv, afv := xsql.NewFunctionValuersForOp(ctx)
+		return func(ctx api.StreamContext, item any) []any {
+			switch val := o.op.Apply(ctx, item, fv, afv).(type) {
+			case nil:
+				return nil
+			case []xsql.Row:
+				result := make([]any, 0, len(val))
+				for _, v := range val {
+					result = append(result, v)
+				}
+				return result
+			default:
+				return []any{val}
+			}
+		}
+	})
+}
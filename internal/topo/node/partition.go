@@ -0,0 +1,67 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// partitionContext is the context of a partition instance of a stateful operator.
+// The states of all partitions are kept in the state of the operator so that they are saved
+// to the store by the checkpoint of the operator. Each partition has its own key space.
+type partitionContext struct {
+	api.StreamContext
+	prefix string
+}
+
+func newPartitionContext(ctx api.StreamContext, index int) api.StreamContext {
+	return &partitionContext{
+		StreamContext: ctx.WithInstance(index + 1),
+		prefix:        fmt.Sprintf("$$partition%d_", index),
+	}
+}
+
+func (c *partitionContext) PutState(key string, value interface{}) error {
+	return c.StreamContext.PutState(c.prefix+key, value)
+}
+
+func (c *partitionContext) GetState(key string) (interface{}, error) {
+	return c.StreamContext.GetState(c.prefix + key)
+}
+
+func (c *partitionContext) DeleteState(key string) error {
+	return c.StreamContext.DeleteState(c.prefix + key)
+}
+
+// partitionIndex returns the partition of the row by the hash of the values of the keys.
+// Items other than row like control messages always go to the first partition.
+func partitionIndex(keys []ast.Expr, item any, fv *xsql.FunctionValuer, n int) int {
+	row, ok := item.(xsql.Row)
+	if !ok || n <= 1 {
+		return 0
+	}
+	ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(row, fv)}
+	h := fnv.New32a()
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(h, "%v,", ve.Eval(k))
+	}
+	return int(h.Sum32() % uint32(n))
+}
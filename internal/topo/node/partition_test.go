@@ -0,0 +1,139 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestPartitionContext(t *testing.T) {
+	ctx := mockContext.NewMockContext("test1", "partition_test")
+	p0 := newPartitionContext(ctx, 0)
+	p1 := newPartitionContext(ctx, 1)
+	assert.Equal(t, 1, p0.GetInstanceId())
+	assert.Equal(t, 2, p1.GetInstanceId())
+	require.NoError(t, p0.PutState("k", 0))
+	require.NoError(t, p1.PutState("k", 1))
+	v, err := p0.GetState("k")
+	require.NoError(t, err)
+	assert.Equal(t, 0, v)
+	v, err = p1.GetState("k")
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	// The partition states are saved in the operator state
+	v, err = ctx.GetState("$$partition1_k")
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	require.NoError(t, p1.DeleteState("k"))
+	v, err = ctx.GetState("$$partition1_k")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestPartitionIndex(t *testing.T) {
+	ctx := mockContext.NewMockContext("test1", "partition_test")
+	fv, _ := xsql.NewFunctionValuersForOp(ctx)
+	keys := []ast.Expr{&ast.FieldRef{Name: "a", StreamName: ast.DefaultStream}}
+	row := func(a any) *xsql.Tuple {
+		return &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": a, "b": a}}
+	}
+	used := make(map[int]struct{})
+	for i := 0; i < 20; i++ {
+		p := partitionIndex(keys, row(i), fv, 4)
+		assert.Equal(t, p, partitionIndex(keys, row(i), fv, 4))
+		assert.True(t, p >= 0 && p < 4)
+		used[p] = struct{}{}
+	}
+	assert.True(t, len(used) > 1)
+	assert.Equal(t, 0, partitionIndex(keys, row(3), fv, 1))
+	assert.Equal(t, 0, partitionIndex(keys, xsql.EOFTuple(0), fv, 4))
+}
+
+// keyRecordOp records the function valuer of each key and sleeps for the key 0
+type keyRecordOp struct {
+	sync.Mutex
+	valuers map[any]map[*xsql.FunctionValuer]struct{}
+}
+
+func (k *keyRecordOp) Apply(_ api.StreamContext, data any, fv *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) any {
+	tuple := data.(*xsql.Tuple)
+	a := tuple.Message["a"]
+	k.Lock()
+	if _, ok := k.valuers[a]; !ok {
+		k.valuers[a] = make(map[*xsql.FunctionValuer]struct{})
+	}
+	k.valuers[a][fv] = struct{}{}
+	k.Unlock()
+	if a == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if a == 2 {
+		return fmt.Errorf("key %v", a)
+	}
+	return tuple
+}
+
+func TestPartitionedUnaryOp(t *testing.T) {
+	op := New("test", &def.RuleOption{BufferLength: 10, Concurrency: 3, SendError: true})
+	rop := &keyRecordOp{valuers: make(map[any]map[*xsql.FunctionValuer]struct{})}
+	op.SetOperation(rop)
+	op.SetPartition([]ast.Expr{&ast.FieldRef{Name: "a", StreamName: ast.DefaultStream}})
+	out := make(chan any, 100)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("test1", "partition_test").WithCancel()
+	defer cancel()
+	op.Exec(ctx, make(chan error, 10))
+	go func() {
+		for i := 0; i < 30; i++ {
+			op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": i % 5, "seq": i}}
+		}
+		op.input <- xsql.EOFTuple(0)
+	}()
+	// The results keep the input order even if the key 0 is slow
+	for i := 0; i < 30; i++ {
+		r := <-out
+		if i%5 == 2 {
+			assert.Equal(t, fmt.Errorf("key 2"), r)
+			continue
+		}
+		tuple, ok := r.(*xsql.Tuple)
+		require.True(t, ok)
+		assert.Equal(t, i, tuple.Message["seq"])
+	}
+	assert.Equal(t, xsql.EOFTuple(0), <-out)
+	// Each key is always processed by the same instance
+	rop.Lock()
+	defer rop.Unlock()
+	all := make(map[*xsql.FunctionValuer]struct{})
+	for _, fvs := range rop.valuers {
+		assert.Len(t, fvs, 1)
+		for fv := range fvs {
+			all[fv] = struct{}{}
+		}
+	}
+	assert.True(t, len(all) > 1)
+}
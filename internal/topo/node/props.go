@@ -24,22 +24,30 @@ import (
 )
 
 type SinkConf struct {
-	Concurrency    int               `json:"concurrency"`
-	Omitempty      bool              `json:"omitIfEmpty"`
-	SendSingle     bool              `json:"sendSingle"`
-	DataTemplate   string            `json:"dataTemplate"`
-	Format         string            `json:"format"`
-	SchemaId       string            `json:"schemaId"`
-	Delimiter      string            `json:"delimiter"`
-	BufferLength   int               `json:"bufferLength"`
-	Fields         []string          `json:"fields"`
-	DataField      string            `json:"dataField"`
-	BatchSize      int               `json:"batchSize"`
-	LingerInterval cast.DurationConf `json:"lingerInterval"`
-	Compression    string            `json:"compression"`
-	Encryption     string            `json:"encryption"`
-	EncProps       map[string]any    `json:"encProps"`
-	HasHeader      bool              `json:"hasHeader"`
+	Concurrency        int               `json:"concurrency"`
+	Omitempty          bool              `json:"omitIfEmpty"`
+	SendSingle         bool              `json:"sendSingle"`
+	DataTemplate       string            `json:"dataTemplate"`
+	Format             string            `json:"format"`
+	SchemaId           string            `json:"schemaId"`
+	Delimiter          string            `json:"delimiter"`
+	BufferLength       int               `json:"bufferLength"`
+	Fields             []string          `json:"fields"`
+	DataField          string            `json:"dataField"`
+	BatchSize          int               `json:"batchSize"`
+	LingerInterval     cast.DurationConf `json:"lingerInterval"`
+	Compression        string            `json:"compression"`
+	Encryption         string            `json:"encryption"`
+	EncProps           map[string]any    `json:"encProps"`
+	Signature          string            `json:"signature"`
+	SigProps           map[string]any    `json:"sigProps"`
+	HasHeader          bool              `json:"hasHeader"`
+	TimestampPrecision string            `json:"timestampPrecision"`
+	XmlLayout          string            `json:"xmlLayout"`
+	XmlRoot            string            `json:"xmlRoot"`
+	XmlItem            string            `json:"xmlItem"`
+	XPaths             []map[string]any  `json:"xpaths"`
+	Namespaces         map[string]string `json:"namespaces"`
 	conf.SinkConf
 }
 
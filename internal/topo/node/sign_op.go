@@ -0,0 +1,95 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/signature"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// SignOp signs the final payload. The signature is set as the dynamic prop of the placeholder to be
+// sent as header or property, or wrapped with the payload in a json object.
+// Immutable: false
+// Input: RawTuple
+// Output: RawTuple
+type SignOp struct {
+	*defaultSinkNode
+	tool message.Signer
+	conf *signature.Conf
+}
+
+func NewSignOp(name string, rOpt *def.RuleOption, sigMethod string, sigProps map[string]any) (*SignOp, error) {
+	s, err := signature.GetSigner(sigMethod, sigProps)
+	if err != nil {
+		return nil, fmt.Errorf("get signer %s fail with error: %v", sigMethod, err)
+	}
+	c, err := signature.ParseConf(sigProps)
+	if err != nil {
+		return nil, err
+	}
+	return &SignOp{
+		defaultSinkNode: newDefaultSinkNode(name, rOpt),
+		tool:            s,
+		conf:            c,
+	}, nil
+}
+
+func (o *SignOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	o.prepareExec(ctx, errCh, "op")
+	go func() {
+		defer func() {
+			o.Close()
+		}()
+		err := infra.SafeRun(func() error {
+			runWithOrder(ctx, o.defaultSinkNode, o.concurrency, o.Worker)
+			return nil
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+func (o *SignOp) Worker(_ api.StreamContext, item any) []any {
+	switch d := item.(type) {
+	case *xsql.RawTuple:
+		sig, err := o.tool.Sign(d.Raw())
+		if err != nil {
+			return []any{err}
+		}
+		if o.conf.AttachTo == signature.AttachField {
+			d.Replace(o.conf.Wrap(d.Raw(), sig))
+			return []any{d}
+		}
+		// props may be shared with other tuples, always copy
+		props := make(map[string]string, len(d.Props)+1)
+		for k, v := range d.Props {
+			props[k] = v
+		}
+		props[signature.Placeholder] = base64.StdEncoding.EncodeToString(sig)
+		d.Props = props
+		return []any{d}
+	default:
+		return []any{fmt.Errorf("unsupported data received: %v", d)}
+	}
+}
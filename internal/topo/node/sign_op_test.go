@@ -0,0 +1,101 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/signature"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestNewSignOp(t *testing.T) {
+	_, err := NewSignOp("test", &def.RuleOption{}, "non", nil)
+	assert.EqualError(t, err, "get signer non fail with error: signature algorithm 'non' is not supported")
+	_, err = NewSignOp("test", &def.RuleOption{}, "hmac-sha256", map[string]any{"key": "k", "attachTo": "body"})
+	assert.EqualError(t, err, "invalid attachTo body, must be header, property or field")
+	_, err = NewSignOp("test", &def.RuleOption{}, "hmac-sha256", map[string]any{"key": "k"})
+	assert.NoError(t, err)
+}
+
+func TestSignOp_Exec(t *testing.T) {
+	s, err := signature.GetSigner("hmac-sha256", map[string]any{"key": "k"})
+	require.NoError(t, err)
+	sig, err := s.Sign([]byte(`{"a":1}`))
+	require.NoError(t, err)
+	sigStr := base64.StdEncoding.EncodeToString(sig)
+
+	tests := []struct {
+		name    string
+		props   map[string]any
+		cases   []any
+		expects []any
+	}{
+		{
+			name:  "header",
+			props: map[string]any{"key": "k"},
+			cases: []any{
+				&xsql.RawTuple{Rawdata: []byte(`{"a":1}`)},
+				&xsql.RawTuple{Rawdata: []byte(`{"a":1}`), Props: map[string]string{"{{.a}}": "1"}},
+				errors.New("go through error"),
+				"invalid",
+			},
+			expects: []any{
+				&xsql.RawTuple{Rawdata: []byte(`{"a":1}`), Props: map[string]string{signature.Placeholder: sigStr}},
+				&xsql.RawTuple{Rawdata: []byte(`{"a":1}`), Props: map[string]string{"{{.a}}": "1", signature.Placeholder: sigStr}},
+				errors.New("go through error"),
+				errors.New("unsupported data received: invalid"),
+			},
+		},
+		{
+			name:  "field",
+			props: map[string]any{"key": "k", "attachTo": "field"},
+			cases: []any{
+				&xsql.RawTuple{Rawdata: []byte(`{"a":1}`)},
+			},
+			expects: []any{
+				&xsql.RawTuple{Rawdata: []byte(`{"payload":{"a":1},"signature":"` + sigStr + `"}`)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := NewSignOp("test", &def.RuleOption{BufferLength: 10, SendError: true}, "hmac-sha256", tt.props)
+			require.NoError(t, err)
+			out := make(chan any, 100)
+			require.NoError(t, op.AddOutput(out, "test"))
+			ctx := mockContext.NewMockContext("test1", "sign_test")
+			errCh := make(chan error)
+			op.Exec(ctx, errCh)
+			for i, c := range tt.cases {
+				op.input <- c
+				r := <-out
+				switch tr := r.(type) {
+				case error:
+					assert.EqualError(t, tt.expects[i].(error), tr.Error())
+				default:
+					assert.Equal(t, tt.expects[i], r)
+				}
+			}
+		})
+	}
+}
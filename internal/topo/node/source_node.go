@@ -43,6 +43,15 @@ type SourceNode struct {
 	s         api.Source
 	interval  time.Duration
 	notifySub bool
+	// The ingestion queue of the subscription when backpressure is enabled
+	pending      chan func()
+	bufferLength int
+}
+
+// exclusiveSubscriber is implemented by the subscription sources whose ingestion runs in a goroutine of their own
+// subscription. With backpressure, their ingestion blocks directly so that the pressure propagates to the upstream.
+type exclusiveSubscriber interface {
+	ExclusiveSubscription() bool
 }
 
 type sourceConf struct {
@@ -65,10 +74,11 @@ func NewSourceNode(ctx api.StreamContext, name string, ss api.Source, props map[
 		return nil, err
 	}
 	m := &SourceNode{
-		defaultNode: newDefaultNode(name, rOpt),
-		s:           ss,
-		interval:    time.Duration(cc.Interval),
-		notifySub:   rOpt.NotifySub,
+		defaultNode:  newDefaultNode(name, rOpt),
+		s:            ss,
+		interval:     time.Duration(cc.Interval),
+		notifySub:    rOpt.NotifySub,
+		bufferLength: rOpt.BufferLength,
 	}
 	switch st := ss.(type) {
 	case api.Bounded:
@@ -83,8 +93,44 @@ func (m *SourceNode) Open(ctx api.StreamContext, ctrlCh chan<- error) {
 	go m.Run(ctx, ctrlCh)
 }
 
+// ingest runs the ingestion with backpressure. The subscription callback may be shared by the rules of the same
+// connection, such as MQTT, so its data is queued and processed by the goroutine of this subscription which waits
+// for the credits. The queue absorbs the short bursts of this rule. Once it is full, the callback blocks so that
+// the client pauses the consumption instead of dropping the data.
+func (m *SourceNode) ingest(ctx api.StreamContext, f func()) {
+	if m.pending == nil {
+		// pause the consumption until the downstream buffers have credits
+		m.flow.Acquire(ctx)
+		f()
+		return
+	}
+	select {
+	case m.pending <- f:
+	case <-ctx.Done():
+	}
+}
+
+func (m *SourceNode) runPending(ctx api.StreamContext) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case f := <-m.pending:
+			m.flow.Acquire(ctx)
+			f()
+		}
+	}
+}
+
 func (m *SourceNode) ingestBytes(ctx api.StreamContext, data []byte, meta map[string]any, ts time.Time) {
 	ctx.GetLogger().Debugf("source connector %s receive data %+v", m.name, data)
+	offset := m.offset(ctx)
+	m.ingest(ctx, func() {
+		m.doIngestBytes(ctx, data, meta, ts, offset)
+	})
+}
+
+func (m *SourceNode) doIngestBytes(ctx api.StreamContext, data []byte, meta map[string]any, ts time.Time, offset any) {
 	m.onProcessStart(ctx, nil)
 	if meta == nil {
 		meta = make(map[string]any)
@@ -94,7 +140,7 @@ func (m *SourceNode) ingestBytes(ctx api.StreamContext, data []byte, meta map[st
 	m.Broadcast(tuple)
 	m.onSend(ctx, tuple)
 	m.onProcessEnd(ctx)
-	_ = m.updateState(ctx)
+	_ = m.updateState(ctx, offset)
 }
 
 func (m *SourceNode) traceStart(ctx api.StreamContext, meta map[string]any, tuple xsql.HasTracerCtx) {
@@ -139,6 +185,13 @@ func (m *SourceNode) traceStart(ctx api.StreamContext, meta map[string]any, tupl
 
 func (m *SourceNode) ingestAnyTuple(ctx api.StreamContext, data any, meta map[string]any, ts time.Time) {
 	ctx.GetLogger().Debugf("source connector %s receive data %+v", m.name, data)
+	offset := m.offset(ctx)
+	m.ingest(ctx, func() {
+		m.doIngestAnyTuple(ctx, data, meta, ts, offset)
+	})
+}
+
+func (m *SourceNode) doIngestAnyTuple(ctx api.StreamContext, data any, meta map[string]any, ts time.Time, offset any) {
 	m.onProcessStart(ctx, nil)
 	if meta == nil {
 		meta = make(map[string]any)
@@ -175,7 +228,7 @@ func (m *SourceNode) ingestAnyTuple(ctx api.StreamContext, data any, meta map[st
 		panic(fmt.Sprintf("receive wrong data %v", data))
 	}
 	m.onProcessEnd(ctx)
-	_ = m.updateState(ctx)
+	_ = m.updateState(ctx, offset)
 }
 
 func (m *SourceNode) connectionStatusChange(status string, message string) {
@@ -213,6 +266,13 @@ func (m *SourceNode) ingestError(ctx api.StreamContext, err error) {
 
 func (m *SourceNode) ingestEof(ctx api.StreamContext) {
 	ctx.GetLogger().Infof("send out EOF")
+	// keep the order with the queued data
+	if m.pending != nil {
+		m.pending <- func() {
+			m.Broadcast(xsql.EOFTuple(0))
+		}
+		return
+	}
 	m.Broadcast(xsql.EOFTuple(0))
 }
 
@@ -241,18 +301,27 @@ func (m *SourceNode) Rewind(ctx api.StreamContext) error {
 	return nil
 }
 
-func (m *SourceNode) updateState(ctx api.StreamContext) error {
-	s := m.s
-	if rw, ok := s.(api.Rewindable); ok {
+// offset reads the offset of the rewindable source when the data is received. The queued data may be behind the
+// source, so the offset is kept with the data and saved by updateState after the data is sent out.
+func (m *SourceNode) offset(ctx api.StreamContext) any {
+	if rw, ok := m.s.(api.Rewindable); ok {
 		state, err := rw.GetOffset()
 		if err != nil {
-			return err
+			ctx.GetLogger().Warnf("source %s get offset error: %v", m.name, err)
+			return nil
 		}
-		return ctx.PutState(OffsetKey, state)
+		return state
 	}
 	return nil
 }
 
+func (m *SourceNode) updateState(ctx api.StreamContext, offset any) error {
+	if offset == nil {
+		return nil
+	}
+	return ctx.PutState(OffsetKey, offset)
+}
+
 // Run Subscribe could be a long-running function
 func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 	defer func() {
@@ -271,6 +340,7 @@ func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 		if err := m.Rewind(ctx); err != nil {
 			return err
 		}
+		m.preparePending(ctx)
 		switch ss := m.s.(type) {
 		case api.BytesSource:
 			err = ss.Subscribe(ctx, m.ingestBytes, m.ingestError)
@@ -293,6 +363,28 @@ func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 	<-ctx.Done()
 }
 
+// preparePending queues the subscription data with backpressure unless the subscription is exclusive
+func (m *SourceNode) preparePending(ctx api.StreamContext) {
+	if m.flow == nil {
+		return
+	}
+	switch m.s.(type) {
+	case api.BytesSource, api.TupleSource:
+	default:
+		// pull sources ingest in the goroutine of this node
+		return
+	}
+	if es, ok := m.s.(exclusiveSubscriber); ok && es.ExclusiveSubscription() {
+		return
+	}
+	l := m.bufferLength
+	if l <= 0 {
+		l = 1024
+	}
+	m.pending = make(chan func(), l)
+	go m.runPending(ctx)
+}
+
 func (m *SourceNode) runPull(ctx api.StreamContext) error {
 	err := m.doPull(ctx, timex.GetNow())
 	if err != nil {
@@ -305,6 +397,11 @@ func (m *SourceNode) runPull(ctx api.StreamContext) error {
 			for {
 				select {
 				case tc := <-ticker.C:
+					// skip the pull to slow down the interval when the downstream is busy
+					if m.flow.IsPressured() {
+						ctx.GetLogger().Debugf("source skip pull at %v due to backpressure", tc.UnixMilli())
+						continue
+					}
 					ctx.GetLogger().Debugf("source pull at %v", tc.UnixMilli())
 					e := m.doPull(ctx, tc)
 					if e != nil {
@@ -0,0 +1,98 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/signature"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// VerifyOp verifies the signature of the raw bytes. It runs before decryption and decompression so that
+// the tampered messages are dropped as errors before any further processing.
+// Immutable: false
+// Input: RawTuple
+// Output: RawTuple
+type VerifyOp struct {
+	*defaultSinkNode
+	tool message.Verifier
+	conf *signature.Conf
+}
+
+func NewVerifyOp(name string, rOpt *def.RuleOption, verMethod string, verProps map[string]any) (*VerifyOp, error) {
+	v, err := signature.GetVerifier(verMethod, verProps)
+	if err != nil {
+		return nil, fmt.Errorf("get verifier %s fail with error: %v", verMethod, err)
+	}
+	c, err := signature.ParseConf(verProps)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyOp{
+		defaultSinkNode: newDefaultSinkNode(name, rOpt),
+		tool:            v,
+		conf:            c,
+	}, nil
+}
+
+func (o *VerifyOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	o.prepareExec(ctx, errCh, "op")
+	go func() {
+		defer func() {
+			o.Close()
+		}()
+		err := infra.SafeRun(func() error {
+			runWithOrder(ctx, o.defaultSinkNode, o.concurrency, o.Worker)
+			return nil
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+func (o *VerifyOp) Worker(_ api.StreamContext, item any) []any {
+	switch d := item.(type) {
+	case error:
+		return []any{d}
+	case *xsql.RawTuple:
+		var (
+			payload = d.Raw()
+			sig     []byte
+			err     error
+		)
+		if o.conf.AttachTo == signature.AttachField {
+			payload, sig, err = o.conf.Unwrap(payload)
+		} else {
+			sig, err = o.conf.FromMeta(d.Metadata)
+		}
+		if err != nil {
+			return []any{fmt.Errorf("verify signature error: %v", err)}
+		}
+		if err = o.tool.Verify(payload, sig); err != nil {
+			return []any{fmt.Errorf("verify signature error: %v", err)}
+		}
+		d.Rawdata = payload
+		return []any{d}
+	default:
+		return []any{fmt.Errorf("unsupported data received: %v", d)}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"math"
+	"sync/atomic"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -72,6 +73,10 @@ type WindowOperator struct {
 	nextSpanCtx  context.Context
 	nextSpan     trace.Span
 	tupleSpanMap map[*xsql.Tuple]trace.Span
+	// memory accounting and spill of the inputs, nil if no memory budget
+	memory *windowMemory
+	// the latest committed checkpoint id, set by the coordinator
+	committedCheckpoint atomic.Int64
 }
 
 const (
@@ -116,6 +121,7 @@ func NewWindowOp(name string, w WindowConfig, options *def.RuleOption) (*WindowO
 }
 
 func (o *WindowOperator) Close() {
+	o.memory.close()
 	o.defaultNode.Close()
 }
 
@@ -140,6 +146,16 @@ func (o *WindowOperator) Exec(ctx api.StreamContext, errCh chan<- error) {
 	} else {
 		log.Warnf("Restore window state fails: %s", err)
 	}
+	// Event time window scans all inputs for each watermark, so it only accounts the memory without spilling
+	if m, err := newWindowMemory(ctx, !o.isEventTime, o.qos >= def.AtLeastOnce); err != nil {
+		infra.DrainError(ctx, err, errCh)
+		return
+	} else {
+		o.memory = m
+		for _, t := range inputs {
+			o.memory.add(t)
+		}
+	}
 	if !o.isEventTime {
 		o.triggerTime = timex.GetNow()
 	}
@@ -274,11 +290,12 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 					}
 					log.Debugf("triggered by restore inputs")
 					inputs = o.scan(inputs, next, ctx)
-					_ = ctx.PutState(WindowInputsKey, inputs)
+					inputs = o.saveInputs(ctx, inputs)
 					_ = ctx.PutState(TriggerTimeKey, o.triggerTime)
 				}
 			case ast.SESSION_WINDOW:
 				timeout, duration := o.window.Interval, o.window.Length
+				inputs = o.memory.load(ctx, inputs, time.Time{})
 				for {
 					et := inputs[0].Timestamp
 					d := time.Duration(et.UnixMilli()%duration.Milliseconds()) * time.Millisecond
@@ -311,7 +328,7 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 					}
 					log.Debugf("triggered by restore inputs")
 					inputs = o.scan(inputs, next, ctx)
-					_ = ctx.PutState(WindowInputsKey, inputs)
+					inputs = o.saveInputs(ctx, inputs)
 					_ = ctx.PutState(TriggerTimeKey, o.triggerTime)
 				}
 			}
@@ -324,10 +341,11 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 			o.statManager.ProcessTimeStart()
 			inputs = o.scan(inputs, delayTS, ctx)
 			o.statManager.ProcessTimeEnd()
-			_ = ctx.PutState(WindowInputsKey, inputs)
+			inputs = o.saveInputs(ctx, inputs)
 			_ = ctx.PutState(MsgCountKey, o.msgCount)
 		// process incoming item
 		case item := <-o.input:
+			o.memory.mark(item)
 			data, processed := o.commonIngest(ctx, item)
 			if processed {
 				break
@@ -338,6 +356,7 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 				log.Debugf("Event window receive tuple %s", d.Message)
 				o.handleTraceIngestTuple(ctx, d)
 				inputs = append(inputs, d)
+				o.memory.add(d)
 				switch o.window.Type {
 				case ast.NOT_WINDOW:
 					inputs = o.scan(inputs, d.Timestamp, ctx)
@@ -379,7 +398,7 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 						continue
 					}
 					o.msgCount = 0
-
+					inputs = o.memory.load(ctx, inputs, time.Time{})
 					if tl, er := NewTupleList(inputs, o.window.CountLength); er != nil {
 						log.Error(fmt.Sprintf("Found error when trying to "))
 						infra.DrainError(ctx, er, errCh)
@@ -401,7 +420,7 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 						inputs = tl.getRestTuples()
 					}
 				}
-				_ = ctx.PutState(WindowInputsKey, inputs)
+				inputs = o.saveInputs(ctx, inputs)
 				_ = ctx.PutState(MsgCountKey, o.msgCount)
 			default:
 				o.onError(ctx, fmt.Errorf("run Window error: expect xsql.Tuple type but got %[1]T(%[1]v)", d))
@@ -438,7 +457,7 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 				// expire all inputs, so that when timer scans there is no item
 				inputs = make([]*xsql.Tuple, 0)
 				o.statManager.ProcessTimeEnd()
-				_ = ctx.PutState(WindowInputsKey, inputs)
+				inputs = o.saveInputs(ctx, inputs)
 				_ = ctx.PutState(TriggerTimeKey, o.triggerTime)
 				timeoutTicker = nil
 			}
@@ -468,9 +487,10 @@ func (o *WindowOperator) setupTicker() {
 func (o *WindowOperator) tick(ctx api.StreamContext, inputs []*xsql.Tuple, n time.Time, log api.Logger) []*xsql.Tuple {
 	if o.window.Type == ast.SESSION_WINDOW {
 		log.Debugf("session window update trigger time %d with %d inputs", n.UnixMilli(), len(inputs))
-		if len(inputs) == 0 || n.Sub(inputs[0].Timestamp) < o.window.Length {
-			if len(inputs) > 0 {
-				log.Debugf("session window last trigger time %d < first tuple %d", n.Add(-o.window.Length).UnixMilli(), inputs[0].Timestamp.UnixMilli())
+		first, ok := o.memory.head(inputs)
+		if !ok || n.Sub(first) < o.window.Length {
+			if ok {
+				log.Debugf("session window last trigger time %d < first tuple %d", n.Add(-o.window.Length).UnixMilli(), first.UnixMilli())
 			}
 			return inputs
 		}
@@ -479,11 +499,28 @@ func (o *WindowOperator) tick(ctx api.StreamContext, inputs []*xsql.Tuple, n tim
 	log.Debugf("triggered by ticker at %d", n.UnixMilli())
 	inputs = o.scan(inputs, n, ctx)
 	o.statManager.ProcessTimeEnd()
-	_ = ctx.PutState(WindowInputsKey, inputs)
+	inputs = o.saveInputs(ctx, inputs)
 	_ = ctx.PutState(TriggerTimeKey, o.triggerTime)
 	return inputs
 }
 
+// saveInputs syncs the inputs with the memory budget and saves them into the state.
+// The returned inputs may be fewer than the given ones if the oldest are spilled.
+func (o *WindowOperator) saveInputs(ctx api.StreamContext, inputs []*xsql.Tuple) []*xsql.Tuple {
+	o.memory.commit(o.committedCheckpoint.Load())
+	inputs = o.memory.sync(ctx, inputs)
+	_ = ctx.PutState(WindowInputsKey, inputs)
+	if o.memory != nil && o.memory.spill != nil {
+		_ = ctx.PutState(WindowSpillKey, o.memory.pages())
+	}
+	return inputs
+}
+
+// NotifyCheckpointComplete is called by the checkpoint coordinator so that the released spill pages can be deleted
+func (o *WindowOperator) NotifyCheckpointComplete(checkpointId int64) {
+	o.committedCheckpoint.Store(checkpointId)
+}
+
 type TupleList struct {
 	tuples []*xsql.Tuple
 	index  int // Current index
@@ -566,9 +603,15 @@ func (o *WindowOperator) handleInputs(ctx api.StreamContext, inputs []*xsql.Tupl
 	if o.window.Type == ast.HOPPING_WINDOW || o.window.Type == ast.SLIDING_WINDOW {
 		delta = o.calDelta(right, log)
 	}
-	content := make([]xsql.Row, 0, len(inputs))
 	// Sync table
 	left := right.Add(-length).Add(-delta)
+	// Only the overlap window discards the expired tuples, other windows need all the spilled tuples
+	var expired time.Time
+	if o.isOverlapWindow {
+		expired = left
+	}
+	inputs = o.memory.load(ctx, inputs, expired)
+	content := make([]xsql.Row, 0, len(inputs))
 	log.Debugf("triggerTime: %d, length: %d, delta: %d, leftmost: %d", right.UnixMilli(), length, delta, left.UnixMilli())
 	nextleft := -1
 	// Assume the inputs are sorted by timestamp
@@ -609,6 +652,10 @@ func (o *WindowOperator) handleInputs(ctx api.StreamContext, inputs []*xsql.Tupl
 
 func (o *WindowOperator) gcInputs(inputs []*xsql.Tuple, triggerTime time.Time, ctx api.StreamContext) []*xsql.Tuple {
 	length := o.window.Length + o.window.Delay
+	// The inputs in memory are newer than the spilled ones which are not all expired
+	if o.memory.expire(triggerTime.Add(-length)) {
+		return inputs
+	}
 	gcIndex := -1
 	for i, tuple := range inputs {
 		if tuple.Timestamp.Add(length).Compare(triggerTime) >= 0 {
@@ -0,0 +1,216 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+)
+
+// PartitionedWindowOp runs a processing time window by the rule concurrency instances.
+// The rows are routed to the instances by the hash of the group by keys, so that each group
+// is windowed and grouped by a single instance. The merger combines the groups of all instances
+// for the same window and emits them in the order of the window end.
+// Input: *xsql.Tuple
+// Output: *xsql.GroupedTuplesSet
+type PartitionedWindowOp struct {
+	*defaultSinkNode
+	keys      []ast.Expr
+	aggregate UnOperation
+	windows   []*WindowOperator
+}
+
+// partitionWindow is the grouped result of a window of a partition
+type partitionWindow struct {
+	index  int
+	end    int64
+	groups []*xsql.GroupedTuples
+	err    error
+}
+
+func NewPartitionedWindowOp(name string, w WindowConfig, keys []ast.Expr, aggregate UnOperation, options *def.RuleOption) (*PartitionedWindowOp, error) {
+	if w.Type != ast.TUMBLING_WINDOW && w.Type != ast.HOPPING_WINDOW {
+		return nil, fmt.Errorf("window type %s cannot be partitioned", w.Type)
+	}
+	if options.IsEventTime {
+		return nil, fmt.Errorf("event time window cannot be partitioned")
+	}
+	o := &PartitionedWindowOp{
+		defaultSinkNode: newDefaultSinkNode(name, options),
+		keys:            keys,
+		aggregate:       aggregate,
+	}
+	// The instances send to the merger only, so never discard
+	opt := *options
+	opt.DisableBufferFullDiscard = true
+	o.windows = make([]*WindowOperator, o.concurrency)
+	for i := range o.windows {
+		win, err := NewWindowOp(fmt.Sprintf("%s_%d", name, i), w, &opt)
+		if err != nil {
+			return nil, err
+		}
+		o.windows[i] = win
+	}
+	return o, nil
+}
+
+func (o *PartitionedWindowOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	o.prepareExec(ctx, errCh, "op")
+	results := make(chan *partitionWindow, len(o.windows))
+	for i, win := range o.windows {
+		out := make(chan any, cap(o.input))
+		_ = win.AddOutput(out, o.name)
+		pctx := newPartitionContext(ctx, i)
+		win.Exec(pctx, errCh)
+		go func(i int) {
+			err := infra.SafeRun(func() error {
+				o.groupWindow(pctx, i, out, results)
+				return nil
+			})
+			if err != nil {
+				infra.DrainError(ctx, err, errCh)
+			}
+		}(i)
+	}
+	go func() {
+		err := infra.SafeRun(func() error {
+			o.merge(ctx, results)
+			return nil
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+	go func() {
+		defer o.Close()
+		err := infra.SafeRun(func() error {
+			o.distribute(ctx)
+			return nil
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+func (o *PartitionedWindowOp) distribute(ctx api.StreamContext) {
+	fv, _ := xsql.NewFunctionValuersForOp(ctx)
+	for {
+		select {
+		case item := <-o.input:
+			data, processed := o.commonIngest(ctx, item)
+			if processed {
+				break
+			}
+			o.onProcessStart(ctx, data)
+			input, _ := o.windows[partitionIndex(o.keys, data, fv, len(o.windows))].GetInput()
+			select {
+			case input <- data:
+			case <-ctx.Done():
+				return
+			}
+			o.onProcessEnd(ctx)
+			o.statManager.SetBufferLength(int64(len(o.input)))
+		case <-ctx.Done():
+			ctx.GetLogger().Infof("partitioned window %s cancelling....", o.name)
+			return
+		}
+	}
+}
+
+// groupWindow groups the window of a partition. All rows of a group are in the same partition,
+// so the groups of the partitions never overlap.
+func (o *PartitionedWindowOp) groupWindow(ctx api.StreamContext, index int, input chan any, results chan *partitionWindow) {
+	fv, afv := xsql.NewFunctionValuersForOp(ctx)
+	for {
+		select {
+		case item := <-input:
+			r := &partitionWindow{index: index}
+			switch d := item.(type) {
+			case *xsql.WindowTuples:
+				end, _ := d.FuncValue("window_end")
+				r.end, _ = end.(int64)
+				switch g := o.aggregate.Apply(ctx, d, fv, afv).(type) {
+				case *xsql.GroupedTuplesSet:
+					r.groups = g.Groups
+				case error:
+					r.err = g
+				}
+			case error:
+				r.err = d
+			default:
+				continue
+			}
+			select {
+			case results <- r:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// merge combines the groups of the same window. A window is complete once all partitions have emitted it
+// or a later window. Each partition of a processing time window emits every window even if it is empty.
+func (o *PartitionedWindowOp) merge(ctx api.StreamContext, results chan *partitionWindow) {
+	lastEnds := make([]int64, len(o.windows))
+	pending := make(map[int64][]*xsql.GroupedTuples)
+	for {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				o.onError(ctx, r.err)
+				continue
+			}
+			pending[r.end] = append(pending[r.end], r.groups...)
+			lastEnds[r.index] = r.end
+			var complete int64 = math.MaxInt64
+			for _, e := range lastEnds {
+				if e < complete {
+					complete = e
+				}
+			}
+			ends := make([]int64, 0, len(pending))
+			for e := range pending {
+				if e <= complete {
+					ends = append(ends, e)
+				}
+			}
+			sort.Slice(ends, func(i, j int) bool { return ends[i] < ends[j] })
+			for _, e := range ends {
+				groups := pending[e]
+				delete(pending, e)
+				if len(groups) == 0 {
+					continue
+				}
+				result := &xsql.GroupedTuplesSet{Groups: groups}
+				o.Broadcast(result)
+				o.onSend(ctx, result)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
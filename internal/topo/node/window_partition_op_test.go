@@ -0,0 +1,87 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mockclock"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestPartitionedWindowOp(t *testing.T) {
+	mc := mockclock.GetMockClock()
+	mc.Set(time.UnixMilli(1000500))
+	key := &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream}
+	dims := ast.Dimensions{{Expr: key}}
+	op, err := NewPartitionedWindowOp("test", WindowConfig{
+		Type:        ast.TUMBLING_WINDOW,
+		Length:      time.Second,
+		RawInterval: 1,
+		TimeUnit:    ast.SS,
+	}, []ast.Expr{key}, &operator.AggregateOp{Dimensions: dims}, &def.RuleOption{BufferLength: 10, Concurrency: 3})
+	require.NoError(t, err)
+	require.Len(t, op.windows, 3)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("test1", "window_partition_test").WithCancel()
+	defer cancel()
+	op.Exec(ctx, make(chan error, 10))
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 6; i++ {
+		op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": int64(i % 3), "b": int64(i)}, Timestamp: mc.Now()}
+	}
+	time.Sleep(50 * time.Millisecond)
+	mc.Add(600 * time.Millisecond)
+	// The groups of all partitions are merged into one window
+	r := <-out
+	gs, ok := r.(*xsql.GroupedTuplesSet)
+	require.True(t, ok)
+	counts := make(map[any]int)
+	for _, g := range gs.Groups {
+		a, _ := g.Content[0].Value("a", "")
+		counts[a] = len(g.Content)
+		end, _ := g.WindowRange.FuncValue("window_end")
+		assert.Equal(t, int64(1001000), end)
+	}
+	assert.Equal(t, map[any]int{int64(0): 2, int64(1): 2, int64(2): 2}, counts)
+	// Each partition keeps its state in the operator state
+	for i := 0; i < 3; i++ {
+		v, err := ctx.GetState(fmt.Sprintf("$$partition%d_%s", i, TriggerTimeKey))
+		require.NoError(t, err)
+		assert.Equal(t, time.UnixMilli(1001000).UnixMilli(), v.(time.Time).UnixMilli())
+	}
+	// The empty window is not sent
+	mc.Add(time.Second)
+	time.Sleep(50 * time.Millisecond)
+	op.input <- xsql.EOFTuple(0)
+	assert.Equal(t, xsql.EOFTuple(0), <-out)
+}
+
+func TestPartitionedWindowOpInvalid(t *testing.T) {
+	_, err := NewPartitionedWindowOp("test", WindowConfig{Type: ast.COUNT_WINDOW, CountLength: 3}, nil, &operator.AggregateOp{}, &def.RuleOption{Concurrency: 2})
+	assert.EqualError(t, err, "window type COUNT_WINDOW cannot be partitioned")
+	_, err = NewPartitionedWindowOp("test", WindowConfig{Type: ast.TUMBLING_WINDOW, Length: time.Second}, nil, &operator.AggregateOp{}, &def.RuleOption{Concurrency: 2, IsEventTime: true})
+	assert.EqualError(t, err, "event time window cannot be partitioned")
+}
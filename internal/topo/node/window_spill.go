@@ -0,0 +1,235 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/checkpoint"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/cache"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+const WindowSpillKey = "$$windowSpill"
+
+// SpillPageSize is the count of the tuples in a spilled page
+var SpillPageSize = 256
+
+func init() {
+	gob.Register([]cache.SpillPage{})
+}
+
+// windowMemory accounts the memory of the window inputs in the rule memory budget.
+// If spill is enabled, the oldest inputs are saved to disk when the budget is exceeded.
+// The spilled inputs are always older than the inputs in memory.
+// All methods are no-op for a nil receiver which means no memory budget is set.
+type windowMemory struct {
+	budget *cache.MemoryBudget
+	// nil if spill is not supported
+	spill *cache.SpillBuffer
+	// the estimated sizes of the inputs in memory, aligned with the inputs
+	sizes []int64
+	total int64
+}
+
+// newWindowMemory creates the memory accounting of the window. If deferDelete is true, the loaded spill pages are
+// kept in disk until the next checkpoint is committed.
+func newWindowMemory(ctx api.StreamContext, canSpill bool, deferDelete bool) (*windowMemory, error) {
+	budget := cache.GetMemoryBudget(ctx)
+	if budget == nil {
+		return nil, nil
+	}
+	m := &windowMemory{budget: budget}
+	if canSpill {
+		var pages []cache.SpillPage
+		if s, err := ctx.GetState(WindowSpillKey); err == nil && s != nil {
+			if ps, ok := s.([]cache.SpillPage); ok {
+				pages = ps
+			} else {
+				return nil, fmt.Errorf("restore window state `spill` %v error, invalid type", s)
+			}
+		}
+		sb, err := cache.NewSpillBuffer(ctx, pages, deferDelete)
+		if err != nil {
+			return nil, err
+		}
+		m.spill = sb
+	}
+	return m, nil
+}
+
+func tupleSize(t *xsql.Tuple) int64 {
+	return cache.SizeOf(map[string]any(t.Message)) + 64
+}
+
+// add accounts the new tuple appended to the inputs
+func (m *windowMemory) add(t *xsql.Tuple) {
+	if m == nil {
+		return
+	}
+	s := tupleSize(t)
+	m.sizes = append(m.sizes, s)
+	m.total += s
+	m.budget.Reserve(s)
+}
+
+// head returns the timestamp of the oldest input including the spilled ones
+func (m *windowMemory) head(inputs []*xsql.Tuple) (time.Time, bool) {
+	if m != nil {
+		if p, ok := m.spill.Head(); ok {
+			return time.UnixMilli(p.Start), true
+		}
+	}
+	if len(inputs) == 0 {
+		return time.Time{}, false
+	}
+	return inputs[0].Timestamp, true
+}
+
+// expire drops the spilled pages whose tuples are all before the given time without loading them.
+// Return true if there are still spilled tuples, so that the inputs in memory are not expired.
+func (m *windowMemory) expire(before time.Time) bool {
+	if m == nil {
+		return false
+	}
+	for {
+		p, ok := m.spill.Head()
+		if !ok {
+			return false
+		}
+		if before.IsZero() || p.End >= before.UnixMilli() {
+			return true
+		}
+		m.spill.DropHead()
+	}
+}
+
+// load drops the expired spilled pages which are before the given time and then loads the rest pages
+// in order before the inputs. A zero time means all the spilled tuples are needed.
+func (m *windowMemory) load(ctx api.StreamContext, inputs []*xsql.Tuple, before time.Time) []*xsql.Tuple {
+	if !m.expire(before) {
+		return inputs
+	}
+	var (
+		loaded []*xsql.Tuple
+		sizes  []int64
+	)
+	for m.spill.Len() > 0 {
+		items, err := m.spill.LoadHead(ctx)
+		if err != nil {
+			ctx.GetLogger().Error(err)
+		}
+		for _, item := range items {
+			t, ok := item.(*xsql.Tuple)
+			if !ok {
+				ctx.GetLogger().Errorf("invalid spilled tuple %v", item)
+				continue
+			}
+			s := tupleSize(t)
+			loaded = append(loaded, t)
+			sizes = append(sizes, s)
+			m.total += s
+			m.budget.Reserve(s)
+		}
+	}
+	ctx.GetLogger().Debugf("load %d spilled tuples", len(loaded))
+	m.sizes = append(sizes, m.sizes...)
+	return append(loaded, inputs...)
+}
+
+// mark records the spilled pages when receiving the checkpoint barrier
+func (m *windowMemory) mark(item any) {
+	if m == nil {
+		return
+	}
+	if b, ok := item.(*checkpoint.BufferOrEvent); ok {
+		if barrier, ok := b.Data.(*checkpoint.Barrier); ok {
+			m.spill.Mark(barrier.CheckpointId)
+		}
+	}
+}
+
+// commit deletes the released spill pages which are not referred by the committed checkpoint
+func (m *windowMemory) commit(checkpointId int64) {
+	if m == nil || checkpointId <= 0 {
+		return
+	}
+	m.spill.Commit(checkpointId)
+}
+
+// sync releases the tuples which are dropped from the head of the inputs. If the budget is exceeded,
+// spill the oldest tuples to disk and return the remaining inputs in memory.
+// It also throttles the operator if the budget is still exceeded.
+func (m *windowMemory) sync(ctx api.StreamContext, inputs []*xsql.Tuple) []*xsql.Tuple {
+	if m == nil {
+		return inputs
+	}
+	// The window always drops the tuples from the head
+	if dropped := len(m.sizes) - len(inputs); dropped > 0 {
+		m.release(dropped)
+	}
+	// Keep at least one page in memory so that the latest tuples and the checks of inputs[0] are not affected
+	spilled := 0
+	for m.spill != nil && m.budget.IsExceeded() && len(inputs)-spilled >= 2*SpillPageSize {
+		items := make([]any, SpillPageSize)
+		for i := 0; i < SpillPageSize; i++ {
+			items[i] = inputs[spilled+i]
+		}
+		start, end := inputs[spilled].Timestamp.UnixMilli(), inputs[spilled+SpillPageSize-1].Timestamp.UnixMilli()
+		if err := m.spill.Spill(ctx, items, start, end); err != nil {
+			ctx.GetLogger().Error(err)
+			break
+		}
+		m.release(SpillPageSize)
+		spilled += SpillPageSize
+	}
+	if spilled > 0 {
+		// copy to a new slice so that the spilled tuples can be garbage collected
+		inputs = append(make([]*xsql.Tuple, 0, len(inputs)-spilled), inputs[spilled:]...)
+	}
+	m.budget.Throttle(ctx)
+	return inputs
+}
+
+func (m *windowMemory) release(n int) {
+	var s int64
+	for _, size := range m.sizes[:n] {
+		s += size
+	}
+	m.sizes = m.sizes[n:]
+	m.total -= s
+	m.budget.Release(s)
+}
+
+func (m *windowMemory) pages() []cache.SpillPage {
+	if m == nil {
+		return nil
+	}
+	return m.spill.Pages()
+}
+
+// close releases all the memory accounted by the window
+func (m *windowMemory) close() {
+	if m == nil {
+		return
+	}
+	m.budget.Release(m.total)
+	m.sizes = nil
+	m.total = 0
+}
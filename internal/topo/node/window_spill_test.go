@@ -0,0 +1,187 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/checkpoint"
+	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/cache"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mockclock"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestWindowMemory(t *testing.T) {
+	testx.InitEnv("window_spill")
+	old := SpillPageSize
+	SpillPageSize = 2
+	defer func() { SpillPageSize = old }()
+	ctx := mockContext.NewMockContext("test1", "window_memory")
+	m, err := newWindowMemory(ctx, true, false)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	tuples := make([]*xsql.Tuple, 6)
+	for i := range tuples {
+		tuples[i] = &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": int64(i)}, Timestamp: time.UnixMilli(int64(i))}
+	}
+	size := tupleSize(tuples[0])
+	budget := cache.NewMemoryBudget("test1", 3*size)
+	ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleMemoryKey, budget)
+	m, err = newWindowMemory(ctx, true, false)
+	require.NoError(t, err)
+	var inputs []*xsql.Tuple
+	for _, tuple := range tuples {
+		inputs = append(inputs, tuple)
+		m.add(tuple)
+	}
+	assert.Equal(t, 6*size, budget.Used())
+	// Spill 2 pages to keep the budget
+	inputs = m.sync(ctx, inputs)
+	assert.Equal(t, tuples[4:], inputs)
+	assert.Equal(t, 2*size, budget.Used())
+	table := cache.SpillTable(ctx)
+	assert.Equal(t, []cache.SpillPage{{Key: 0, Count: 2, Start: 0, End: 1, Table: table}, {Key: 1, Count: 2, Start: 2, End: 3, Table: table}}, m.pages())
+	first, ok := m.head(inputs)
+	require.True(t, ok)
+	assert.Equal(t, time.UnixMilli(0), first)
+	// The spilled pages are not all expired
+	assert.True(t, m.expire(time.UnixMilli(1)))
+	assert.Len(t, m.pages(), 2)
+	// Drop the expired page without loading and load the rest in order
+	inputs = m.load(ctx, inputs, time.UnixMilli(2))
+	assert.Equal(t, tuples[2:], inputs)
+	assert.Equal(t, 4*size, budget.Used())
+	assert.Nil(t, m.pages())
+	assert.False(t, m.expire(time.UnixMilli(2)))
+	inputs = m.sync(ctx, inputs)
+	assert.Equal(t, tuples[4:], inputs)
+	// Load all back in order
+	inputs = m.load(ctx, inputs, time.Time{})
+	assert.Equal(t, tuples[2:], inputs)
+	assert.Equal(t, 4*size, budget.Used())
+	// Release the dropped tuples
+	inputs = m.sync(ctx, inputs[3:])
+	assert.Equal(t, tuples[5:], inputs)
+	assert.Equal(t, size, budget.Used())
+	m.close()
+	assert.Equal(t, int64(0), budget.Used())
+	// No spill, only accounting
+	m, err = newWindowMemory(ctx, false, false)
+	require.NoError(t, err)
+	for _, tuple := range tuples {
+		m.add(tuple)
+	}
+	inputs = m.sync(ctx, tuples)
+	assert.Equal(t, tuples, inputs)
+	assert.Equal(t, 6*size, budget.Used())
+	m.close()
+}
+
+func TestWindowMemoryCheckpoint(t *testing.T) {
+	testx.InitEnv("window_spill")
+	old := SpillPageSize
+	SpillPageSize = 1
+	defer func() { SpillPageSize = old }()
+	ctx := mockContext.NewMockContext("test2", "window_memory")
+	tuples := make([]*xsql.Tuple, 3)
+	for i := range tuples {
+		tuples[i] = &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": int64(i)}, Timestamp: time.UnixMilli(int64(i))}
+	}
+	budget := cache.NewMemoryBudget("test2", tupleSize(tuples[0]))
+	ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleMemoryKey, budget)
+	m, err := newWindowMemory(ctx, true, true)
+	require.NoError(t, err)
+	for _, tuple := range tuples {
+		m.add(tuple)
+	}
+	inputs := m.sync(ctx, tuples)
+	assert.Equal(t, tuples[2:], inputs)
+	s, err := store.GetCacheKV(cache.SpillTable(ctx))
+	require.NoError(t, err)
+	// The checkpoint refers to the spilled pages
+	m.mark(&checkpoint.BufferOrEvent{Data: &checkpoint.Barrier{CheckpointId: 1}})
+	inputs = m.load(ctx, inputs, time.Time{})
+	assert.Equal(t, tuples, inputs)
+	m.mark(&checkpoint.BufferOrEvent{Data: &checkpoint.Barrier{CheckpointId: 2}})
+	m.commit(1)
+	keys, err := s.Keys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+	// The pages are deleted after a checkpoint taken after loading is committed
+	m.commit(2)
+	keys, err = s.Keys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 0)
+	m.close()
+}
+
+func TestWindowSpill(t *testing.T) {
+	testx.InitEnv("window_spill")
+	old := SpillPageSize
+	SpillPageSize = 2
+	defer func() { SpillPageSize = old }()
+	mc := mockclock.GetMockClock()
+	mc.Set(time.UnixMilli(2000500))
+	op, err := NewWindowOp("test", WindowConfig{
+		Type:        ast.TUMBLING_WINDOW,
+		Length:      time.Second,
+		RawInterval: 1,
+		TimeUnit:    ast.SS,
+	}, &def.RuleOption{BufferLength: 10})
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("test1", "window_spill").WithCancel()
+	defer cancel()
+	// Keep 3 tuples in memory
+	budget := cache.NewMemoryBudget("test1", 3*tupleSize(&xsql.Tuple{Message: map[string]any{"a": int64(0)}}))
+	ctx = topoContext.WithValue(ctx.(*topoContext.DefaultContext), topoContext.RuleMemoryKey, budget)
+	op.Exec(ctx, make(chan error, 10))
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": int64(i)}, Timestamp: mc.Now()}
+	}
+	time.Sleep(100 * time.Millisecond)
+	v, err := ctx.GetState(WindowSpillKey)
+	require.NoError(t, err)
+	ts := mc.Now().UnixMilli()
+	assert.Equal(t, []cache.SpillPage{{Key: 0, Count: 2, Start: ts, End: ts, Table: cache.SpillTable(ctx)}}, v)
+	v, err = ctx.GetState(WindowInputsKey)
+	require.NoError(t, err)
+	assert.Len(t, v, 3)
+	mc.Add(600 * time.Millisecond)
+	// The spilled tuples are loaded back when the window triggers
+	r := <-out
+	w, ok := r.(*xsql.WindowTuples)
+	require.True(t, ok)
+	require.Len(t, w.Content, 5)
+	for i, row := range w.Content {
+		a, _ := row.Value("a", "")
+		assert.Equal(t, int64(i), a)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(0), budget.Used())
+}
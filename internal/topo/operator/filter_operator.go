@@ -26,6 +26,8 @@ import (
 type FilterOp struct {
 	Condition  ast.Expr
 	StateFuncs []*ast.Call
+	// Compiled is the condition compiled by the schema. It is nil if the condition cannot be compiled.
+	Compiled xsql.CompiledExpr
 }
 
 // Apply the filter operator to each message in the stream
@@ -41,7 +43,7 @@ func (p *FilterOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Funct
 		return input
 	case xsql.Row:
 		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(input, fv)}
-		result := ve.Eval(p.Condition)
+		result := p.eval(ve)
 		switch r := result.(type) {
 		case error:
 			return fmt.Errorf("run Where error: %s", r)
@@ -61,7 +63,7 @@ func (p *FilterOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Funct
 		var sel []int
 		err := input.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
 			ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, fv)}
-			result := ve.Eval(p.Condition)
+			result := p.eval(ve)
 			switch val := result.(type) {
 			case error:
 				return false, fmt.Errorf("run Where error: %s", val)
@@ -84,8 +86,42 @@ func (p *FilterOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Funct
 		if r.Len() > 0 {
 			return r
 		}
+	case *xsql.ColumnBatch:
+		sel := make([]int, 0, input.Len())
+		err := input.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
+			ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, fv)}
+			switch val := p.eval(ve).(type) {
+			case error:
+				return false, fmt.Errorf("run Where error: %s", val)
+			case bool:
+				if val {
+					for _, f := range p.StateFuncs {
+						_ = ve.Eval(f)
+					}
+					sel = append(sel, i)
+				}
+			case nil:
+				break
+			default:
+				return false, fmt.Errorf("run Where error: invalid condition that returns non-bool value %[1]T(%[1]v)", val)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(sel) > 0 {
+			return input.Filter(sel)
+		}
 	default:
 		return fmt.Errorf("run Where error: invalid input %[1]T(%[1]v)", input)
 	}
 	return nil
 }
+
+func (p *FilterOp) eval(ve *xsql.ValuerEval) any {
+	if p.Compiled != nil {
+		return p.Compiled(ve)
+	}
+	return ve.Eval(p.Condition)
+}
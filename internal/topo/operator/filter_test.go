@@ -754,3 +754,60 @@ func TestFilterPlanError(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterPlan_Batch(t *testing.T) {
+	tests := []struct {
+		sql    string
+		result []map[string]any
+		err    string
+	}{
+		{
+			sql: "SELECT a FROM src WHERE a > 1 AND b = \"x\"",
+			result: []map[string]any{
+				{"a": int64(2), "b": "x"},
+				{"a": int64(4), "b": "x"},
+			},
+		},
+		{
+			sql: "SELECT a FROM src WHERE abs(a) > 3",
+			result: []map[string]any{
+				{"a": int64(4), "b": "x"},
+			},
+		},
+		{
+			sql: "SELECT a FROM src WHERE a > 10",
+		},
+		{
+			sql: "SELECT a FROM src WHERE a + b > 10",
+			err: "run Where error: invalid operation int64(1) + string(y)",
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			assert.NoError(t, err)
+			b := xsql.NewColumnBatch("src", 4)
+			b.Append(&xsql.Tuple{Emitter: "src", Message: xsql.Message{"a": int64(1), "b": "y"}})
+			b.Append(&xsql.Tuple{Emitter: "src", Message: xsql.Message{"a": int64(2), "b": "x"}})
+			b.Append(&xsql.Tuple{Emitter: "src", Message: xsql.Message{"a": int64(3)}})
+			b.Append(&xsql.Tuple{Emitter: "src", Message: xsql.Message{"a": int64(4), "b": "x"}})
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			pp := &FilterOp{Condition: stmt.Condition}
+			result := pp.Apply(ctx, b, fv, afv)
+			if tt.err != "" {
+				assert.EqualError(t, result.(error), tt.err)
+				return
+			}
+			if tt.result == nil {
+				assert.Nil(t, result)
+				return
+			}
+			var actual []map[string]any
+			for _, r := range result.(*xsql.ColumnBatch).ToRows() {
+				actual = append(actual, r.ToMap())
+			}
+			assert.Equal(t, tt.result, actual)
+		})
+	}
+}
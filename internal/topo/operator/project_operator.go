@@ -16,6 +16,7 @@ package operator
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -60,18 +61,8 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 	case error:
 		return input
 	case xsql.Row:
-		ve := pp.getRowVE(input, nil, fv, afv)
-		if err := pp.project(input, ve); err != nil {
-			return fmt.Errorf("run Select error: %s", err)
-		} else {
-			if pp.SendMeta {
-				if md, ok := input.(xsql.MetaData); ok {
-					metadata := md.MetaData()
-					if metadata != nil {
-						input.Set(message.MetaKey, md.MetaData())
-					}
-				}
-			}
+		if err := pp.projectRow(input, fv, afv); err != nil {
+			return err
 		}
 	case xsql.Collection:
 		var err error
@@ -106,12 +97,132 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 		if err != nil {
 			return err
 		}
+	case *xsql.ColumnBatch:
+		if pp.IsAggregate || pp.SendMeta {
+			rows := input.ToRows()
+			for _, row := range rows {
+				if err := pp.projectRow(row, fv, afv); err != nil {
+					return err
+				}
+			}
+			return rows
+		}
+		r, err := pp.projectBatch(input, fv)
+		if err != nil {
+			return fmt.Errorf("run Select error: %s", err)
+		}
+		return r
 	default:
 		return fmt.Errorf("run Select error: invalid input %[1]T(%[1]v)", input)
 	}
 	return data
 }
 
+func (pp *ProjectOp) projectRow(input xsql.Row, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) error {
+	ve := pp.getRowVE(input, nil, fv, afv)
+	if err := pp.project(input, ve); err != nil {
+		return fmt.Errorf("run Select error: %s", err)
+	}
+	if pp.SendMeta {
+		if md, ok := input.(xsql.MetaData); ok {
+			metadata := md.MetaData()
+			if metadata != nil {
+				input.Set(message.MetaKey, md.MetaData())
+			}
+		}
+	}
+	return nil
+}
+
+// projectBatch picks the columns and calculates the expressions for all rows in the batch to produce a new batch
+func (pp *ProjectOp) projectBatch(input *xsql.ColumnBatch, fv *xsql.FunctionValuer) (*xsql.ColumnBatch, error) {
+	n := input.Len()
+	columns := make(map[string][]any)
+	// Like the row path, the picked columns keep the present nil values and the absent explicit columns are kept as nil by sendNil
+	nulls := make(map[string][]bool)
+	keepNil := make(map[string]bool)
+	if pp.AllWildcard || pp.WildcardEmitters[input.Emitter] {
+		for k := range input.Columns {
+			if !slices.Contains(pp.ExceptNames, k) {
+				columns[k], _ = input.Column(k)
+				if flags := input.Nulls(k); flags != nil {
+					nulls[k] = flags
+				}
+			}
+		}
+	} else {
+		for _, colTab := range pp.ColNames {
+			if colTab[1] != "" && colTab[1] != string(ast.DefaultStream) && colTab[1] != input.Emitter {
+				continue
+			}
+			if col, ok := input.Column(colTab[0]); ok {
+				columns[colTab[0]] = col
+				if flags := input.Nulls(colTab[0]); flags != nil {
+					nulls[colTab[0]] = flags
+				}
+			} else if pp.SendNil {
+				columns[colTab[0]] = make([]any, n)
+			}
+			keepNil[colTab[0]] = pp.SendNil
+		}
+	}
+	// The picked columns may share the memory with the input, copy them before changing
+	owned := make(map[string]bool)
+	set := func(k string, i int, v any) {
+		if !owned[k] {
+			col := make([]any, n)
+			copy(col, columns[k])
+			columns[k] = col
+			owned[k] = true
+		}
+		columns[k][i] = v
+		if flags, ok := nulls[k]; ok || v == nil {
+			if !ok {
+				flags = make([]bool, n)
+				nulls[k] = flags
+			}
+			flags[i] = v == nil
+		}
+	}
+	err := input.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, fv, &xsql.WildcardValuer{Data: r})}
+		for _, f := range pp.ExprFields {
+			vi := ve.Eval(f.Expr)
+			if e, ok := vi.(error); ok {
+				return false, fmt.Errorf("expr: %s meet error, err:%v", f.Expr.String(), e)
+			}
+			switch vt := vi.(type) {
+			case nil:
+			case function.ResultCols:
+				for k, v := range vt {
+					set(k, i, v)
+				}
+			default:
+				set(f.Name, i, vi)
+			}
+		}
+		for _, f := range pp.AliasFields {
+			vi := ve.Eval(f.Expr)
+			if e, ok := vi.(error); ok {
+				if ref, ok := f.Expr.(*ast.FieldRef); ok {
+					s := ref.AliasRef.Expression.String()
+					return false, fmt.Errorf("alias: %v expr: %v meet error, err:%v", f.AName, s, e)
+				}
+				return false, fmt.Errorf("alias: %v expr: %v meet error, err:%v", f.AName, f.Expr.String(), e)
+			}
+			if vi != nil || pp.SendNil {
+				set(f.AName, i, vi)
+				keepNil[f.AName] = pp.SendNil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return input.Derive(columns, nulls, keepNil), nil
+}
+
 func (pp *ProjectOp) getVE(tuple xsql.RawRow, agg xsql.AggregateData, wr *xsql.WindowRange, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) *xsql.ValuerEval {
 	afv.SetData(agg)
 	if pp.IsAggregate {
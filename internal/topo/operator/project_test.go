@@ -28,6 +28,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
 func parseStmt(p *ProjectOp, fields ast.Fields) {
@@ -3139,3 +3140,63 @@ func TestProjectPlan_SendNil(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectPlan_Batch(t *testing.T) {
+	sqls := []string{
+		"SELECT a FROM test",
+		"SELECT A, b FROM test",
+		"SELECT * FROM test",
+		"SELECT * EXCEPT(b) FROM test",
+		"SELECT *, upper(d) AS e FROM test",
+		"SELECT a, b * 2 AS c, upper(d) AS d FROM test",
+		"SELECT a, c FROM test",
+		"SELECT abs(b) + 1 AS x, abs(b) AS y FROM test",
+		"SELECT a, meta(topic) AS topic FROM test",
+		"SELECT a, changed_cols(\"n_\", true, b, d) FROM test",
+		"SELECT a, b FROM test WHERE a = \"v2\"",
+	}
+	newTuples := func() []*xsql.Tuple {
+		return []*xsql.Tuple{
+			{Emitter: "test", Message: xsql.Message{"a": "v1", "b": int64(-3), "d": "x"}, Metadata: xsql.Metadata{"topic": "t1"}},
+			{Emitter: "test", Message: xsql.Message{"a": "v2", "b": int64(4)}, Metadata: xsql.Metadata{"topic": "t2"}},
+			{Emitter: "test", Message: xsql.Message{"b": int64(5), "d": "y"}},
+			{Emitter: "test", Message: xsql.Message{"a": nil, "b": int64(6), "d": nil}},
+		}
+	}
+	for i, sql := range sqls {
+		for _, sendNil := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s_%v", sql, sendNil), func(t *testing.T) {
+				stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+				require.NoError(t, err)
+				// Use different rules to isolate the function states
+				ctx := mockContext.NewMockContext(fmt.Sprintf("batchRow%d", i), "project")
+				fv, afv := xsql.NewFunctionValuersForOp(ctx)
+				// Project row by row as the expected result
+				pp := &ProjectOp{SendNil: sendNil}
+				parseStmt(pp, stmt.Fields)
+				var expected []map[string]any
+				for _, tuple := range newTuples() {
+					r := pp.Apply(ctx, tuple, fv, afv)
+					require.IsType(t, &xsql.Tuple{}, r)
+					expected = append(expected, r.(*xsql.Tuple).ToMap())
+				}
+				// Project the batch
+				ctx = mockContext.NewMockContext(fmt.Sprintf("batchCol%d", i), "project")
+				fv, afv = xsql.NewFunctionValuersForOp(ctx)
+				pb := &ProjectOp{SendNil: sendNil}
+				parseStmt(pb, stmt.Fields)
+				b := xsql.NewColumnBatch("test", 4)
+				for _, tuple := range newTuples() {
+					b.Append(tuple)
+				}
+				r := pb.Apply(ctx, b, fv, afv)
+				require.IsType(t, &xsql.ColumnBatch{}, r)
+				var actual []map[string]any
+				for _, row := range r.(*xsql.ColumnBatch).ToRows() {
+					actual = append(actual, row.ToMap())
+				}
+				require.Equal(t, expected, actual)
+			})
+		}
+	}
+}
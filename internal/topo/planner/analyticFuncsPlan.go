@@ -22,6 +22,8 @@ type AnalyticFuncsPlan struct {
 	baseLogicalPlan
 	funcs      []*ast.Call
 	fieldFuncs []*ast.Call
+	// If set, run the functions in parallel partitioned by the keys
+	partitionKeys []ast.Expr
 }
 
 func (p AnalyticFuncsPlan) Init() *AnalyticFuncsPlan {
@@ -52,6 +54,16 @@ func (p *AnalyticFuncsPlan) BuildExplainInfo() {
 		}
 		info += " ]"
 	}
+	if len(p.partitionKeys) != 0 {
+		info += ", partitionBy:[ "
+		for i, k := range p.partitionKeys {
+			info += k.String()
+			if i != len(p.partitionKeys)-1 {
+				info += ", "
+			}
+		}
+		info += " ]"
+	}
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
 
@@ -50,6 +50,9 @@ type DataSourcePlan struct {
 	pruneFields []string
 	// inRuleTest means whether in the rule test mode
 	inRuleTest bool
+	// the predicate pushed down to the decoder and its fields
+	decodeFilter       ast.Expr
+	decodeFilterFields []string
 }
 
 func (p DataSourcePlan) Init() *DataSourcePlan {
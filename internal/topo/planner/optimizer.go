@@ -17,10 +17,12 @@ package planner
 import "github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 
 var optRuleList = []logicalOptRule{
+	&constantFolder{},
 	&columnPruner{},
 	&predicatePushDown{},
 	&pushProjectionPlan{},
 	&pushAliasDecode{},
+	&commonSubexprEliminator{},
 }
 
 func optimize(p LogicalPlan, options *def.RuleOption) (LogicalPlan, error) {
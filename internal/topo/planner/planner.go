@@ -43,6 +43,20 @@ func Plan(rule *def.Rule) (*topo.Topo, error) {
 
 // PlanSQLWithSourcesAndSinks For test only
 func PlanSQLWithSourcesAndSinks(rule *def.Rule, mockSourcesProp map[string]map[string]any) (*topo.Topo, error) {
+	store, err := store2.GetKV("stream")
+	if err != nil {
+		return nil, err
+	}
+	return planSQL(rule, mockSourcesProp, store)
+}
+
+// PlanSQLWithStore plans the rule with the stream definitions read from the given store instead of the stream db.
+// It is used to run temporary rules against overridden stream definitions such as the schema inference.
+func PlanSQLWithStore(rule *def.Rule, store kv.KeyValue) (*topo.Topo, error) {
+	return planSQL(rule, nil, store)
+}
+
+func planSQL(rule *def.Rule, mockSourcesProp map[string]map[string]any, store kv.KeyValue) (*topo.Topo, error) {
 	sql := rule.Sql
 	if rule.Actions == nil {
 		rule.Actions = []map[string]any{
@@ -68,10 +82,6 @@ func PlanSQLWithSourcesAndSinks(rule *def.Rule, mockSourcesProp map[string]map[s
 	if rule.Options.SendMetaToSink && (len(streamsFromStmt) > 1 || stmt.Dimensions != nil) {
 		return nil, fmt.Errorf("Invalid option sendMetaToSink, it can not be applied to window")
 	}
-	store, err := store2.GetKV("stream")
-	if err != nil {
-		return nil, err
-	}
 	// Create the logical plan and optimize. Logical plans are a linked list
 	lp, err := createLogicalPlan(stmt, rule.Options, store)
 	if err != nil {
@@ -112,10 +122,16 @@ func createTopo(rule *def.Rule, lp LogicalPlan, mockSourcesProp map[string]map[s
 	}
 	tp.SetStreams(streamsFromStmt)
 
-	input, _, err := buildOps(lp, tp, rule.Options, mockSourcesProp, streamsFromStmt, 0)
+	planDecodeFilter(lp, rule.Options)
+	planPartition(lp, rule.Options)
+	input, index, err := buildOps(lp, tp, rule.Options, mockSourcesProp, streamsFromStmt, 0)
 	if err != nil {
 		return nil, err
 	}
+	// Sinks always receive rows
+	if outputsBatch(lp, rule.Options) {
+		input = planUnbatch(tp, input, index+1, rule.Options)
+	}
 	inputs := []node.Emitter{input}
 	// Add actions
 	err = buildActions(tp, rule, inputs, len(streamsFromStmt))
@@ -198,9 +214,38 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			return nil, 0, err
 		}
 		newIndex = ni
+		if outputsBatch(c, options) && !acceptsBatch(lp) {
+			newIndex++
+			input = planUnbatch(tp, input, newIndex, options)
+		}
 		inputs = append(inputs, input)
 	}
 	newIndex++
+	// Extract the state functions first, the plans with state functions cannot be shared
+	switch t := lp.(type) {
+	case *WindowPlan:
+		t.ExtractStateFunc()
+	case *FilterPlan:
+		t.ExtractStateFunc()
+	case *HavingPlan:
+		t.ExtractStateFunc()
+	}
+	// The operator upon shared stream may be shared by rules with the same prefix
+	sp, existed, isShared := sharedPrefix(lp, inputs, options)
+	if isShared && existed {
+		tp.ReplaceSrc(inputs[0].(node.DataSourceNode), sp)
+		return sp, newIndex, nil
+	}
+	addOperator := func(inputs []node.Emitter, op node.OperatorNode) {
+		tp.AddOperator(inputs, op)
+	}
+	var parent node.DataSourceNode
+	if isShared {
+		parent = inputs[0].(node.DataSourceNode)
+		addOperator = func(inputs []node.Emitter, op node.OperatorNode) {
+			sp.AddOperator(inputs, op)
+		}
+	}
 	var (
 		op  node.Emitter
 		err error
@@ -212,6 +257,7 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			return nil, 0, err
 		}
 		tp.AddSrc(srcNode)
+		tp.AddStateSignature(srcNode.GetName(), "source:"+string(t.name))
 		inputs = []node.Emitter{srcNode}
 		op = srcNode
 		if len(emitters) > 0 {
@@ -229,11 +275,15 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	case *WatermarkPlan:
 		op = node.NewWatermarkOp(fmt.Sprintf("%d_watermark", newIndex), t.SendWatermark, t.Emitters, options)
 	case *AnalyticFuncsPlan:
-		op = Transform(&operator.AnalyticFuncsOp{Funcs: t.funcs, FieldFuncs: t.fieldFuncs}, fmt.Sprintf("%d_analytic", newIndex), options)
+		aop := Transform(&operator.AnalyticFuncsOp{Funcs: t.funcs, FieldFuncs: t.fieldFuncs}, fmt.Sprintf("%d_analytic", newIndex), options)
+		if len(t.partitionKeys) > 0 {
+			aop.SetPartition(t.partitionKeys)
+		}
+		op = aop
 	case *IncWindowPlan:
 		if t.Condition != nil {
-			wfilterOp := Transform(&operator.FilterOp{Condition: t.Condition}, fmt.Sprintf("%d_windowFilter", newIndex), options)
-			tp.AddOperator(inputs, wfilterOp)
+			wfilterOp := Transform(&operator.FilterOp{Condition: t.Condition, Compiled: compileCondition(lp, t.Condition)}, fmt.Sprintf("%d_windowFilter", newIndex), options)
+			addOperator(inputs, wfilterOp)
 			inputs = []node.Emitter{wfilterOp}
 		}
 		l, i, d := convertFromDuration(t.TimeUnit, t.Length, t.Interval, t.Delay)
@@ -259,8 +309,8 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 		}
 	case *WindowPlan:
 		if t.condition != nil {
-			wfilterOp := Transform(&operator.FilterOp{Condition: t.condition}, fmt.Sprintf("%d_windowFilter", newIndex), options)
-			tp.AddOperator(inputs, wfilterOp)
+			wfilterOp := Transform(&operator.FilterOp{Condition: t.condition, Compiled: compileCondition(lp, t.condition)}, fmt.Sprintf("%d_windowFilter", newIndex), options)
+			addOperator(inputs, wfilterOp)
 			inputs = []node.Emitter{wfilterOp}
 		}
 		l, i, d := convertFromDuration(t.timeUnit, t.length, t.interval, t.delay)
@@ -271,8 +321,7 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 		case ast.HOPPING_WINDOW:
 			rawInterval = t.interval
 		}
-		t.ExtractStateFunc()
-		op, err = node.NewWindowOp(fmt.Sprintf("%d_window", newIndex), node.WindowConfig{
+		wc := node.WindowConfig{
 			Type:             t.wtype,
 			Delay:            d,
 			Length:           l,
@@ -283,9 +332,11 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			TimeUnit:         t.timeUnit,
 			TriggerCondition: t.triggerCondition,
 			StateFuncs:       t.stateFuncs,
-		}, options)
-		if err != nil {
-			return nil, 0, err
+		}
+		if len(t.partitionDims) > 0 {
+			op, err = node.NewPartitionedWindowOp(fmt.Sprintf("%d_window", newIndex), wc, dimensionExprs(t.partitionDims), &operator.AggregateOp{Dimensions: t.partitionDims}, options)
+		} else {
+			op, err = node.NewWindowOp(fmt.Sprintf("%d_window", newIndex), wc, options)
 		}
 	case *DedupTriggerPlan:
 		op = node.NewDedupTriggerNode(fmt.Sprintf("%d_dedup_trigger", newIndex), options, t.aliasName, t.startField.Name, t.endField.Name, t.nowField.Name, t.expire)
@@ -296,12 +347,10 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	case *JoinPlan:
 		op = Transform(&operator.JoinOp{Joins: t.joins, From: t.from}, fmt.Sprintf("%d_join", newIndex), options)
 	case *FilterPlan:
-		t.ExtractStateFunc()
-		op = Transform(&operator.FilterOp{Condition: t.condition, StateFuncs: t.stateFuncs}, fmt.Sprintf("%d_filter", newIndex), options)
+		op = Transform(&operator.FilterOp{Condition: t.condition, StateFuncs: t.stateFuncs, Compiled: compileCondition(lp, t.condition)}, fmt.Sprintf("%d_filter", newIndex), options)
 	case *AggregatePlan:
 		op = Transform(&operator.AggregateOp{Dimensions: t.dimensions}, fmt.Sprintf("%d_aggregate", newIndex), options)
 	case *HavingPlan:
-		t.ExtractStateFunc()
 		op = Transform((&operator.HavingOp{Condition: t.condition, StateFuncs: t.stateFuncs, IsIncAgg: t.IsIncAgg}), fmt.Sprintf("%d_having", newIndex), options)
 	case *OrderPlan:
 		op = Transform(&operator.OrderOp{SortFields: t.SortFields}, fmt.Sprintf("%d_order", newIndex), options)
@@ -315,14 +364,56 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 		err = fmt.Errorf("unknown logical plan %v", t)
 	}
 	if err != nil {
+		if isShared {
+			topo.RemoveSubTopo(sp.GetName())
+		}
 		return nil, 0, err
 	}
 	if onode, ok := op.(node.OperatorNode); ok {
-		tp.AddOperator(inputs, onode)
+		addOperator(inputs, onode)
+		if sig, ok := stateSignature(lp, options); ok && !isShared {
+			tp.AddStateSignature(onode.GetName(), sig)
+		}
+	}
+	if _, ok := lp.(*DataSourcePlan); ok && outputsBatch(lp, options) {
+		newIndex++
+		op = planColumnBatch(tp, op, newIndex, options)
+	}
+	if isShared {
+		tp.ReplaceSrc(parent, sp)
+		return sp, newIndex, nil
 	}
 	return op, newIndex, nil
 }
 
+// compileCondition compiles the condition with the schema of the only data source of the plan.
+// It returns nil for schemaless stream, join, multiple sources or uncompilable condition.
+func compileCondition(lp LogicalPlan, cond ast.Expr) xsql.CompiledExpr {
+	var ds *DataSourcePlan
+	var walk func(p LogicalPlan) bool
+	walk = func(p LogicalPlan) bool {
+		switch d := p.(type) {
+		case *DataSourcePlan:
+			if ds != nil {
+				return false
+			}
+			ds = d
+		case *LookupPlan, *JoinPlan:
+			return false
+		}
+		for _, c := range p.Children() {
+			if !walk(c) {
+				return false
+			}
+		}
+		return true
+	}
+	if !walk(lp) || ds == nil || ds.isSchemaless || len(ds.colAliasMapping) > 0 {
+		return nil
+	}
+	return xsql.CompileExpr(cond, ds.streamFields)
+}
+
 func convertFromDuration(timeUnit ast.Token, length, interval int, delay int64) (time.Duration, time.Duration, time.Duration) {
 	var unit time.Duration
 	switch timeUnit {
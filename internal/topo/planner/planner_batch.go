@@ -0,0 +1,76 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// isBatchExecution checks if the rule runs in the column batch mode. The batch is not aligned with the
+// checkpoint barrier and watermark, so it only works for processing time rules with qos 0.
+func isBatchExecution(options *def.RuleOption) bool {
+	return options != nil && options.BatchExecution && options.Qos == def.AtMostOnce && !options.IsEventTime
+}
+
+// acceptsBatch returns whether the operator of the plan can process column batches.
+// The incremental window aggregates row by row, so it receives rows like the other operators.
+func acceptsBatch(lp LogicalPlan) bool {
+	switch lp.(type) {
+	case *FilterPlan, *ProjectPlan:
+		return true
+	default:
+		return false
+	}
+}
+
+// outputsBatch returns whether the operator of the plan sends out column batches
+func outputsBatch(lp LogicalPlan, options *def.RuleOption) bool {
+	if !isBatchExecution(options) {
+		return false
+	}
+	switch t := lp.(type) {
+	case *DataSourcePlan:
+		return t.streamStmt != nil && t.streamStmt.StreamType == ast.TypeStream
+	case *FilterPlan, *ProjectPlan:
+		for _, c := range lp.Children() {
+			if !outputsBatch(c, options) {
+				return false
+			}
+		}
+		return len(lp.Children()) > 0
+	default:
+		return false
+	}
+}
+
+// planUnbatch adds the operator to convert the column batches back to rows
+func planUnbatch(tp *topo.Topo, input node.Emitter, index int, options *def.RuleOption) node.Emitter {
+	op := Transform(&operator.UnbatchOp{}, fmt.Sprintf("%d_unbatch", index), options)
+	tp.AddOperator([]node.Emitter{input}, op)
+	return op
+}
+
+// planColumnBatch adds the operator to collect the source rows into column batches
+func planColumnBatch(tp *topo.Topo, input node.Emitter, index int, options *def.RuleOption) node.Emitter {
+	op := node.NewColumnBatchOp(fmt.Sprintf("%d_column_batch", index), options, node.DefaultColumnBatchSize, node.DefaultColumnBatchLinger)
+	tp.AddOperator([]node.Emitter{input}, op)
+	return op
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestPlanBatchExecution(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM batchsrc (a BIGINT, b STRING) WITH (DATASOURCE="batch", FORMAT="json", TYPE="mqtt");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("batchsrc", string(s)))
+
+	testcases := []struct {
+		sql   string
+		qos   def.Qos
+		edges map[string][]any
+	}{
+		{
+			sql: `SELECT a FROM batchsrc WHERE a > 1`,
+			edges: map[string][]any{
+				"source_batchsrc":              {"op_2_decoder"},
+				"op_2_decoder":                 {"op_3_column_batch"},
+				"op_3_column_batch":            {"op_4_filter"},
+				"op_4_filter":                  {"op_5_project"},
+				"op_5_project":                 {"op_6_unbatch"},
+				"op_6_unbatch":                 {"op_logToMemory_0_0_transform"},
+				"op_logToMemory_0_0_transform": {"op_logToMemory_0_1_encode"},
+				"op_logToMemory_0_1_encode":    {"sink_logToMemory_0"},
+			},
+		},
+		{
+			sql: `SELECT count(*) FROM batchsrc WHERE a > 1 GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			edges: map[string][]any{
+				"source_batchsrc":              {"op_2_decoder"},
+				"op_2_decoder":                 {"op_3_column_batch"},
+				"op_3_column_batch":            {"op_4_unbatch"},
+				"op_4_unbatch":                 {"op_5_inc_agg_window"},
+				"op_5_inc_agg_window":          {"op_6_filter"},
+				"op_6_filter":                  {"op_7_project"},
+				"op_7_project":                 {"op_logToMemory_0_0_transform"},
+				"op_logToMemory_0_0_transform": {"op_logToMemory_0_1_encode"},
+				"op_logToMemory_0_1_encode":    {"sink_logToMemory_0"},
+			},
+		},
+		{
+			sql: `SELECT a, row_number() FROM batchsrc`,
+			edges: map[string][]any{
+				"source_batchsrc":              {"op_2_decoder"},
+				"op_2_decoder":                 {"op_3_column_batch"},
+				"op_3_column_batch":            {"op_4_unbatch"},
+				"op_4_unbatch":                 {"op_5_windowFunc"},
+				"op_5_windowFunc":              {"op_6_project"},
+				"op_6_project":                 {"op_logToMemory_0_0_transform"},
+				"op_logToMemory_0_0_transform": {"op_logToMemory_0_1_encode"},
+				"op_logToMemory_0_1_encode":    {"sink_logToMemory_0"},
+			},
+		},
+		{
+			sql: `SELECT a FROM batchsrc WHERE a > 1`,
+			qos: def.AtLeastOnce,
+			edges: map[string][]any{
+				"source_batchsrc":              {"op_2_decoder"},
+				"op_2_decoder":                 {"op_3_filter"},
+				"op_3_filter":                  {"op_4_project"},
+				"op_4_project":                 {"op_logToMemory_0_0_transform"},
+				"op_logToMemory_0_0_transform": {"op_logToMemory_0_1_encode"},
+				"op_logToMemory_0_1_encode":    {"sink_logToMemory_0"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		r := def.GetDefaultRule("batch", tc.sql)
+		r.Options.BatchExecution = true
+		r.Options.PlanOptimizeStrategy.EnableIncrementalWindow = true
+		r.Options.Qos = tc.qos
+		tp, err := PlanSQLWithSourcesAndSinks(r, nil)
+		require.NoError(t, err, tc.sql)
+		assert.Equal(t, tc.edges, tp.GetTopo().Edges, tc.sql)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// planDecodeFilter pushes the simple predicates of the WHERE clause right upon a stream into its decoder.
+// The decoder decodes the predicate fields partially to drop the unmatched messages before the full decode.
+// The predicates are kept in the original filter, so the decode filter only needs to be a superset of the result.
+// The event time rules are not pushed down because the dropped messages still advance the watermark.
+func planDecodeFilter(lp LogicalPlan, options *def.RuleOption) {
+	if options.IsEventTime {
+		return
+	}
+	var cond ast.Expr
+	switch t := lp.(type) {
+	case *FilterPlan:
+		cond = t.condition
+	case *WindowPlan:
+		cond = t.condition
+	case *IncWindowPlan:
+		cond = t.Condition
+	}
+	if cond != nil && len(lp.Children()) == 1 {
+		if ds, ok := lp.Children()[0].(*DataSourcePlan); ok {
+			ds.decodeFilter, ds.decodeFilterFields = extractDecodeFilter(cond, ds)
+		}
+	}
+	for _, c := range lp.Children() {
+		planDecodeFilter(c, options)
+	}
+}
+
+// extractDecodeFilter extracts the conjuncts which compare a top level field with a literal.
+// The stateful or volatile conditions are not pushed because dropping messages changes their result.
+func extractDecodeFilter(cond ast.Expr, ds *DataSourcePlan) (ast.Expr, []string) {
+	if ds.streamStmt.StreamType != ast.TypeStream || ds.streamStmt.Options.SHARED || len(ds.colAliasMapping) > 0 {
+		return nil, nil
+	}
+	if !isDeterministicExpr(cond) {
+		return nil, nil
+	}
+	var (
+		result ast.Expr
+		fields []string
+	)
+	for _, c := range splitConjuncts(cond) {
+		f, ok := simplePredicateField(c, ds.name)
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = c
+		} else {
+			result = &ast.BinaryExpr{OP: ast.AND, LHS: result, RHS: c}
+		}
+		found := false
+		for _, ff := range fields {
+			if ff == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fields = append(fields, f)
+		}
+	}
+	return result, fields
+}
+
+func splitConjuncts(expr ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return splitConjuncts(e.Expr)
+	case *ast.BinaryExpr:
+		if e.OP == ast.AND {
+			return append(splitConjuncts(e.LHS), splitConjuncts(e.RHS)...)
+		}
+	}
+	return []ast.Expr{expr}
+}
+
+// simplePredicateField returns the field name if the expression compares a column of the stream with a literal
+func simplePredicateField(expr ast.Expr, stream ast.StreamName) (string, bool) {
+	be, ok := expr.(*ast.BinaryExpr)
+	if !ok {
+		return "", false
+	}
+	switch be.OP {
+	case ast.EQ, ast.NEQ, ast.LT, ast.LTE, ast.GT, ast.GTE:
+	default:
+		return "", false
+	}
+	fr, ok := be.LHS.(*ast.FieldRef)
+	lit := be.RHS
+	if !ok {
+		fr, ok = be.RHS.(*ast.FieldRef)
+		lit = be.LHS
+	}
+	if !ok || !fr.IsColumn() || (fr.StreamName != ast.DefaultStream && fr.StreamName != stream) {
+		return "", false
+	}
+	switch lit.(type) {
+	case *ast.IntegerLiteral, *ast.NumberLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return fr.Name, true
+	}
+	return "", false
+}
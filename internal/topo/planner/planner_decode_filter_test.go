@@ -0,0 +1,121 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestPlanDecodeFilter(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	streamSqls := map[string]string{
+		"dfsrc":    `CREATE STREAM dfsrc (a BIGINT, b STRING, c STRUCT(d BIGINT)) WITH (DATASOURCE="df", FORMAT="json", TYPE="mqtt");`,
+		"dfless":   `CREATE STREAM dfless () WITH (DATASOURCE="df", FORMAT="json", TYPE="mqtt");`,
+		"dfshared": `CREATE STREAM dfshared (a BIGINT) WITH (DATASOURCE="df", FORMAT="json", TYPE="mqtt", SHARED="true");`,
+		"dfevent":  `CREATE STREAM dfevent (a BIGINT, b STRING) WITH (DATASOURCE="df", FORMAT="json", TYPE="mqtt", TIMESTAMP="a");`,
+	}
+	for name, sql := range streamSqls {
+		s, err := json.Marshal(&xsql.StreamInfo{
+			StreamType: ast.TypeStream,
+			Statement:  sql,
+		})
+		require.NoError(t, err)
+		require.NoError(t, kv.Set(name, string(s)))
+	}
+	testcases := []struct {
+		sql       string
+		eventTime bool
+		filter    string
+		fields    []string
+	}{
+		{
+			sql:    `SELECT a FROM dfsrc WHERE a > 1`,
+			filter: "binaryExpr:{ dfsrc.a > 1 }",
+			fields: []string{"a"},
+		},
+		{
+			sql:    `SELECT a FROM dfsrc WHERE (a > 1 AND 'x' = b) AND a < 10 AND abs(a) > 2 AND c->d = 1`,
+			filter: "binaryExpr:{ binaryExpr:{ binaryExpr:{ dfsrc.a > 1 } AND binaryExpr:{ x = dfsrc.b } } AND binaryExpr:{ dfsrc.a < 10 } }",
+			fields: []string{"a", "b"},
+		},
+		{
+			sql:    `SELECT a FROM dfsrc WHERE a > 1 OR b = 'x'`,
+			filter: "",
+		},
+		{
+			sql:    `SELECT a FROM dfsrc WHERE a > 1 AND changed_col(true, b) = 'x'`,
+			filter: "",
+		},
+		{
+			sql:    `SELECT count(*) FROM dfsrc WHERE b != 'x' GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			filter: "binaryExpr:{ dfsrc.b != x }",
+			fields: []string{"b"},
+		},
+		{
+			sql:    `SELECT * FROM dfless WHERE name = 'x'`,
+			filter: "binaryExpr:{ dfless.name = x }",
+			fields: []string{"name"},
+		},
+		{
+			sql:    `SELECT a FROM dfshared WHERE a > 1`,
+			filter: "",
+		},
+		{
+			sql:       `SELECT count(*) FROM dfevent WHERE b != 'x' GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			eventTime: true,
+			filter:    "",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := xsql.GetStatementFromSql(tc.sql)
+			require.NoError(t, err)
+			options := def.GetDefaultRule("dfrule", tc.sql).Options
+			options.IsEventTime = tc.eventTime
+			lp, err := createLogicalPlan(stmt, options, kv)
+			require.NoError(t, err)
+			planDecodeFilter(lp, options)
+			var ds *DataSourcePlan
+			var find func(p LogicalPlan)
+			find = func(p LogicalPlan) {
+				if d, ok := p.(*DataSourcePlan); ok {
+					ds = d
+				}
+				for _, c := range p.Children() {
+					find(c)
+				}
+			}
+			find(lp)
+			require.NotNil(t, ds)
+			if tc.filter == "" {
+				assert.Nil(t, ds.decodeFilter)
+				return
+			}
+			require.NotNil(t, ds.decodeFilter)
+			assert.Equal(t, tc.filter, ds.decodeFilter.String())
+			assert.Equal(t, tc.fields, ds.decodeFilterFields)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// stateSignature returns the stable id of the stateful operator built from the logical plan.
+// The id only covers the settings which decide the layout of the operator states, so that the states
+// can be migrated to the operator with the same id when the rule is updated. For example, changing the
+// window filter or the projected fields keeps the id of the window. Stateless plans return false.
+func stateSignature(lp LogicalPlan, options *def.RuleOption) (string, bool) {
+	switch t := lp.(type) {
+	case *WindowPlan:
+		sig := fmt.Sprintf("window:%s,%d,%d,%d,%s,%t,%s,%s", t.wtype, t.length, t.interval, t.delay, t.timeUnit, t.isEventTime, exprString(t.triggerCondition), callsSignature(t.stateFuncs))
+		if len(t.partitionDims) > 0 {
+			sig += fmt.Sprintf(",partition:%s,%d", exprsString(dimensionExprs(t.partitionDims)), options.Concurrency)
+		}
+		return sig, true
+	case *IncWindowPlan:
+		funcs := make([]string, 0, len(t.IncAggFuncs))
+		for _, f := range t.IncAggFuncs {
+			funcs = append(funcs, fmt.Sprintf("%s AS %s", exprString(f.Expr), f.Name))
+		}
+		return fmt.Sprintf("inc_window:%s,%d,%d,%d,%s,%s,%s,%s", t.WType, t.Length, t.Interval, t.Delay, t.TimeUnit, exprString(t.TriggerCondition), exprsString(dimensionExprs(t.Dimensions)), strings.Join(funcs, ",")), true
+	case *AnalyticFuncsPlan:
+		sig := "analytic:" + callsSignature(t.funcs) + "|" + callsSignature(t.fieldFuncs)
+		if len(t.partitionKeys) > 0 {
+			sig += fmt.Sprintf(",partition:%s,%d", exprsString(t.partitionKeys), options.Concurrency)
+		}
+		return sig, true
+	case *WatermarkPlan:
+		return "watermark:" + strings.Join(t.Emitters, ","), true
+	case *DedupTriggerPlan:
+		return fmt.Sprintf("dedup_trigger:%s,%s,%s,%s,%d", t.aliasName, t.startField.Name, t.endField.Name, t.nowField.Name, t.expire), true
+	case *JoinAlignPlan:
+		return fmt.Sprintf("join_aligner:%s,%v", strings.Join(t.Emitters, ","), t.Sizes), true
+	case *FilterPlan:
+		if len(t.stateFuncs) == 0 {
+			return "", false
+		}
+		return "filter:" + callsSignature(t.stateFuncs), true
+	case *HavingPlan:
+		if len(t.stateFuncs) == 0 {
+			return "", false
+		}
+		return "having:" + callsSignature(t.stateFuncs), true
+	default:
+		return "", false
+	}
+}
+
+// callsSignature identifies the stateful function calls. The states of the functions are keyed by the
+// function id, so the id is part of the signature.
+func callsSignature(calls []*ast.Call) string {
+	sigs := make([]string, 0, len(calls))
+	for _, c := range calls {
+		sigs = append(sigs, fmt.Sprintf("%s#%d", c.String(), c.FuncId))
+	}
+	return strings.Join(sigs, ",")
+}
+
+func exprsString(exprs []ast.Expr) string {
+	result := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		result = append(result, exprString(e))
+	}
+	return strings.Join(result, ",")
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestPlanMigration(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM migratesrc () WITH (DATASOURCE="migrate", FORMAT="json", TYPE="mqtt");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("migratesrc", string(s)))
+
+	plan := func(sql string) *topo.Topo {
+		tp, err := PlanSQLWithSourcesAndSinks(def.GetDefaultRule("migrate", sql), nil)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = tp.Cancel()
+		})
+		return tp
+	}
+	tests := []struct {
+		name  string
+		old   string
+		new   string
+		incom []string
+	}{
+		{
+			name: "add window filter and field",
+			old:  `SELECT count(*) FROM migratesrc GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			new:  `SELECT count(*), max(b) FROM migratesrc WHERE b > 1 GROUP BY TUMBLINGWINDOW(ss, 10)`,
+		},
+		{
+			name:  "change window length",
+			old:   `SELECT count(*) FROM migratesrc GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			new:   `SELECT count(*) FROM migratesrc GROUP BY TUMBLINGWINDOW(ss, 20)`,
+			incom: []string{"3_window"},
+		},
+		{
+			name: "change filter and add field after analytic function",
+			old:  `SELECT a, lag(a) AS la FROM migratesrc WHERE a > 1`,
+			new:  `SELECT a, lag(a) AS la, b FROM migratesrc WHERE a > 2`,
+		},
+		{
+			name:  "change analytic function",
+			old:   `SELECT a, lag(a) AS la FROM migratesrc WHERE a > 1`,
+			new:   `SELECT a, lag(b) AS la FROM migratesrc WHERE a > 1`,
+			incom: []string{"3_analytic"},
+		},
+		{
+			name:  "add stateful filter",
+			old:   `SELECT a FROM migratesrc WHERE a > 1`,
+			new:   `SELECT a FROM migratesrc WHERE a > lag(a)`,
+			incom: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldTp := plan(tt.old)
+			newTp := plan(tt.new)
+			assert.Equal(t, tt.incom, newTp.IncompatibleOps(oldTp))
+		})
+	}
+}
+
+func TestStateSignature(t *testing.T) {
+	options := def.GetDefaultRule("sig", "").Options
+	sig, ok := stateSignature(&FilterPlan{condition: &ast.BinaryExpr{OP: ast.GT, LHS: &ast.FieldRef{Name: "a"}, RHS: &ast.IntegerLiteral{Val: 1}}}, options)
+	assert.False(t, ok)
+	assert.Equal(t, "", sig)
+	sig, ok = stateSignature(&FilterPlan{stateFuncs: []*ast.Call{{Name: "lag", FuncId: 1, Args: []ast.Expr{&ast.FieldRef{Name: "a"}}}}}, options)
+	assert.True(t, ok)
+	assert.Equal(t, "filter:Call:{ name:lag, args:[a] }#1", sig)
+	options.Concurrency = 2
+	sig, ok = stateSignature(&WindowPlan{wtype: ast.TUMBLING_WINDOW, length: 10, timeUnit: ast.SS, partitionDims: ast.Dimensions{{Expr: &ast.FieldRef{Name: "a"}}}}, options)
+	assert.True(t, ok)
+	assert.Equal(t, "window:TUMBLING_WINDOW,10,0,0,SS,false,,,partition:a,2", sig)
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// planPartition partitions the stateful operators by their keys to run them in parallel by the rule concurrency.
+// A processing time tumbling or hopping window with GROUP BY is partitioned by the group keys and the aggregate
+// right upon it is merged into the window to group per partition. Analytic functions are partitioned if they
+// share the same PARTITION BY.
+func planPartition(lp LogicalPlan, options *def.RuleOption) {
+	if options.Concurrency < 2 {
+		return
+	}
+	if ap, ok := lp.(*AnalyticFuncsPlan); ok {
+		ap.partitionKeys = analyticPartitionKeys(ap)
+	}
+	children := lp.Children()
+	for i, c := range children {
+		if ap, ok := c.(*AggregatePlan); ok && len(ap.Children()) == 1 {
+			if wp, ok := ap.Children()[0].(*WindowPlan); ok && canPartitionWindow(wp, ap.dimensions, options) {
+				wp.partitionDims = ap.dimensions
+				children[i] = wp
+			}
+		}
+		planPartition(children[i], options)
+	}
+	lp.SetChildren(children)
+}
+
+// canPartitionWindow checks if the window triggers by time only so that all partitions trigger together.
+// Count, sliding and session windows depend on the rows of other keys. The checkpoint barrier is not aligned
+// across the partitions, so the rows queued in the partitions would be lost on restore if the qos is at least once.
+func canPartitionWindow(wp *WindowPlan, dims ast.Dimensions, options *def.RuleOption) bool {
+	if wp.isEventTime || wp.triggerCondition != nil || len(wp.Children()) != 1 || len(dims) == 0 {
+		return false
+	}
+	if options.Qos >= def.AtLeastOnce {
+		return false
+	}
+	if wp.wtype != ast.TUMBLING_WINDOW && wp.wtype != ast.HOPPING_WINDOW {
+		return false
+	}
+	for _, d := range dims {
+		if !isDeterministicExpr(d.Expr) {
+			return false
+		}
+	}
+	return true
+}
+
+// analyticPartitionKeys returns the PARTITION BY shared by all analytic functions.
+// The functions without partition keep the state of all rows so that they cannot be partitioned.
+func analyticPartitionKeys(ap *AnalyticFuncsPlan) []ast.Expr {
+	var (
+		keys []ast.Expr
+		fp   string
+	)
+	for _, f := range append(append([]*ast.Call{}, ap.funcs...), ap.fieldFuncs...) {
+		if f.Partition == nil || len(f.Partition.Exprs) == 0 {
+			return nil
+		}
+		for _, e := range f.Partition.Exprs {
+			if !isDeterministicExpr(e) {
+				return nil
+			}
+		}
+		if keys == nil {
+			keys = f.Partition.Exprs
+			fp = f.Partition.String()
+		} else if f.Partition.String() != fp {
+			return nil
+		}
+	}
+	return keys
+}
+
+func dimensionExprs(dims ast.Dimensions) []ast.Expr {
+	exprs := make([]ast.Expr, 0, len(dims))
+	for _, d := range dims {
+		exprs = append(exprs, d.Expr)
+	}
+	return exprs
+}
+
+func isDeterministicExpr(expr ast.Expr) bool {
+	deterministic := true
+	ast.WalkFunc(expr, func(n ast.Node) bool {
+		if c, ok := n.(*ast.Call); ok && !function.IsDeterministicFunc(c.Name) {
+			deterministic = false
+		}
+		return deterministic
+	})
+	return deterministic
+}
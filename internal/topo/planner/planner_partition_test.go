@@ -0,0 +1,161 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestPlanPartition(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM ptsrc (a BIGINT, b STRING, c BIGINT) WITH (DATASOURCE="pt", FORMAT="json", TYPE="mqtt");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("ptsrc", string(s)))
+	testcases := []struct {
+		sql         string
+		concurrency int
+		qos         def.Qos
+		window      []string
+		analytic    []string
+	}{
+		{
+			sql:         `SELECT b, count(*) FROM ptsrc GROUP BY b, TUMBLINGWINDOW(ss, 10)`,
+			concurrency: 4,
+			window:      []string{"ptsrc.b"},
+		},
+		{
+			sql:         `SELECT b, c, count(*) FROM ptsrc WHERE a > 1 GROUP BY b, c, HOPPINGWINDOW(ss, 10, 5) HAVING count(*) > 1`,
+			concurrency: 2,
+			window:      []string{"ptsrc.b", "ptsrc.c"},
+		},
+		{
+			sql:         `SELECT b, count(*) FROM ptsrc GROUP BY b, TUMBLINGWINDOW(ss, 10)`,
+			concurrency: 1,
+		},
+		{
+			sql:         `SELECT b, count(*) FROM ptsrc GROUP BY b, TUMBLINGWINDOW(ss, 10)`,
+			concurrency: 4,
+			qos:         def.AtLeastOnce,
+		},
+		{
+			sql:         `SELECT b, count(*) FROM ptsrc GROUP BY b, COUNTWINDOW(10)`,
+			concurrency: 4,
+		},
+		{
+			sql:         `SELECT b, count(*) FROM ptsrc GROUP BY b, SESSIONWINDOW(ss, 10, 5)`,
+			concurrency: 4,
+		},
+		{
+			sql:         `SELECT count(*) FROM ptsrc GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			concurrency: 4,
+		},
+		{
+			sql:         `SELECT lag(a) OVER (PARTITION BY b) AS la, changed_col(true, c) OVER (PARTITION BY b) FROM ptsrc`,
+			concurrency: 4,
+			analytic:    []string{"ptsrc.b"},
+		},
+		{
+			sql:         `SELECT lag(a) OVER (PARTITION BY b) AS la, lag(c) FROM ptsrc`,
+			concurrency: 4,
+		},
+		{
+			sql:         `SELECT lag(a) OVER (PARTITION BY b) AS la, lag(c) OVER (PARTITION BY c) FROM ptsrc`,
+			concurrency: 4,
+		},
+		{
+			sql:         `SELECT lag(a) OVER (PARTITION BY b) AS la FROM ptsrc`,
+			concurrency: 1,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := xsql.GetStatementFromSql(tc.sql)
+			require.NoError(t, err)
+			options := def.GetDefaultRule("ptrule", tc.sql).Options
+			options.Concurrency = tc.concurrency
+			options.Qos = tc.qos
+			lp, err := createLogicalPlan(stmt, options, kv)
+			require.NoError(t, err)
+			planPartition(lp, options)
+			var (
+				window    []string
+				analytic  []string
+				aggregate bool
+			)
+			var find func(p LogicalPlan)
+			find = func(p LogicalPlan) {
+				switch t := p.(type) {
+				case *WindowPlan:
+					for _, d := range t.partitionDims {
+						window = append(window, d.Expr.String())
+					}
+				case *AnalyticFuncsPlan:
+					for _, k := range t.partitionKeys {
+						analytic = append(analytic, k.String())
+					}
+				case *AggregatePlan:
+					aggregate = true
+				}
+				for _, c := range p.Children() {
+					find(c)
+				}
+			}
+			find(lp)
+			assert.Equal(t, tc.window, window)
+			assert.Equal(t, tc.analytic, analytic)
+			// The aggregate is merged into the partitioned window
+			if len(tc.window) > 0 {
+				assert.False(t, aggregate)
+			}
+		})
+	}
+}
+
+func TestPlanPartitionTopo(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM ptsrc (a BIGINT, b STRING, c BIGINT) WITH (DATASOURCE="pt", FORMAT="json", TYPE="mqtt");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("ptsrc", string(s)))
+	r := def.GetDefaultRule("pttopo", `SELECT b, count(*) FROM ptsrc GROUP BY b, TUMBLINGWINDOW(ss, 10) HAVING count(*) > 1`)
+	r.Options.Concurrency = 2
+	tp, err := PlanSQLWithSourcesAndSinks(r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]any{
+		"source_ptsrc":                 {"op_2_decoder"},
+		"op_2_decoder":                 {"op_3_window"},
+		"op_3_window":                  {"op_4_having"},
+		"op_4_having":                  {"op_5_project"},
+		"op_5_project":                 {"op_logToMemory_0_0_transform"},
+		"op_logToMemory_0_0_transform": {"op_logToMemory_0_1_encode"},
+		"op_logToMemory_0_1_encode":    {"sink_logToMemory_0"},
+	}, tp.GetTopo().Edges)
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// sharedPrefix finds or creates the sub topo to share the operator of the logical plan with other rules.
+// Only the stateless filter, window, aggregate and project right above a shared stream or another shared
+// operator can be shared. The sub topo is identified by the fingerprint of the operator and its input.
+// If the sub topo exists, the operator has been created by another rule and the existed flag is true.
+func sharedPrefix(lp LogicalPlan, inputs []node.Emitter, options *def.RuleOption) (*topo.SrcSubTopo, bool, bool) {
+	if !options.PlanOptimizeStrategy.IsSharedPrefixEnable() || options.Qos > def.AtMostOnce || len(inputs) != 1 {
+		return nil, false, false
+	}
+	parent, ok := inputs[0].(*topo.SrcSubTopo)
+	if !ok {
+		return nil, false, false
+	}
+	kind, fp, ok := fingerprint(lp)
+	if !ok {
+		return nil, false, false
+	}
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s|%s|%d|%t|%t|%t", parent.GetName(), fp, options.BufferLength, options.SendError, options.DisableBufferFullDiscard, options.Backpressure)
+	sp, existed := topo.GetOrCreateSubTopo(fmt.Sprintf("%s_%016x", kind, h.Sum64()))
+	if !existed {
+		sp.AddSrc(parent)
+	}
+	return sp, existed, true
+}
+
+// fingerprint returns the canonical text of the plan which decides the output of the operator.
+// Plans with states which depend on the rule lifecycle, such as the extracted state functions, are not sharable.
+func fingerprint(lp LogicalPlan) (string, string, bool) {
+	switch t := lp.(type) {
+	case *FilterPlan:
+		if len(t.stateFuncs) > 0 || hasStateFunc(t.condition) {
+			return "", "", false
+		}
+		return "filter", fmt.Sprintf("filter:%s,%s", exprString(t.condition), callsSignature(t.stateFuncs)), true
+	case *WindowPlan:
+		if t.isEventTime || len(t.partitionDims) > 0 || len(t.stateFuncs) > 0 || hasStateFunc(t.condition, t.triggerCondition) {
+			return "", "", false
+		}
+		return "window", fmt.Sprintf("window:%s,%d,%d,%d,%s,%d,%s,%s,%s", t.wtype, t.length, t.interval, t.delay, t.timeUnit, t.limit, exprString(t.condition), exprString(t.triggerCondition), callsSignature(t.stateFuncs)), true
+	case *AggregatePlan:
+		dims := make([]string, 0, len(t.dimensions))
+		for _, d := range t.dimensions {
+			dims = append(dims, exprString(d.Expr))
+		}
+		return "aggregate", "aggregate:" + strings.Join(dims, ","), true
+	case *ProjectPlan:
+		fields := make([]string, 0, len(t.fields))
+		exprs := make([]ast.Expr, 0, len(t.fields))
+		for _, f := range t.fields {
+			fields = append(fields, fmt.Sprintf("%s AS %s", exprString(f.Expr), f.AName))
+			exprs = append(exprs, f.Expr)
+		}
+		if hasStateFunc(exprs...) {
+			return "", "", false
+		}
+		return "project", fmt.Sprintf("project:%s,%v,%v,%t,%t,%t,%t,%d", strings.Join(fields, ","), t.exceptNames, t.aliasNames, t.isAggregate, t.sendMeta, t.sendNil, t.enableLimit, t.limitCount), true
+	default:
+		return "", "", false
+	}
+}
+
+// hasStateFunc checks the expressions and the expressions of the referred aliases recursively
+func hasStateFunc(exprs ...ast.Expr) bool {
+	found := false
+	visited := make(map[*ast.AliasRef]bool)
+	var walk func(expr ast.Expr)
+	walk = func(expr ast.Expr) {
+		ast.WalkFunc(expr, func(n ast.Node) bool {
+			switch f := n.(type) {
+			case *ast.Call:
+				if xsql.ImplicitStateFuncs[f.Name] || function.IsAnalyticFunc(f.Name) {
+					found = true
+				}
+			case *ast.FieldRef:
+				if f.AliasRef != nil && !visited[f.AliasRef] {
+					visited[f.AliasRef] = true
+					walk(f.AliasRef.Expression)
+				}
+			}
+			return !found
+		})
+	}
+	for _, expr := range exprs {
+		if found {
+			break
+		}
+		walk(expr)
+	}
+	return found
+}
+
+func exprString(expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	return expr.String()
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestPlanSharedPrefix(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM prefixsrc () WITH (DATASOURCE="prefix", FORMAT="json", TYPE="mqtt", SHARED="true");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("prefixsrc", string(s)))
+
+	plan := func(id, sql string) *topo.Topo {
+		r := def.GetDefaultRule(id, sql)
+		r.Options.PlanOptimizeStrategy.EnableSharedPrefix = true
+		tp, err := PlanSQLWithSourcesAndSinks(r, nil)
+		require.NoError(t, err)
+		return tp
+	}
+	tp1 := plan("prefix1", `SELECT count(*) FROM prefixsrc WHERE deviceType = "pump" GROUP BY TUMBLINGWINDOW(ss, 10)`)
+	tp2 := plan("prefix2", `SELECT avg(temperature) FROM prefixsrc WHERE deviceType = "pump" GROUP BY TUMBLINGWINDOW(ss, 10)`)
+	tp3 := plan("prefix3", `SELECT count(*) FROM prefixsrc WHERE deviceType = "fan" GROUP BY TUMBLINGWINDOW(ss, 10)`)
+	defer func() {
+		for _, tp := range []*topo.Topo{tp1, tp2, tp3} {
+			ss, ok := tp.GetSourceNodes()[0].(*topo.SrcSubTopo)
+			for ok {
+				topo.RemoveSubTopo(ss.GetName())
+				ss, ok = ss.GetSource().(*topo.SrcSubTopo)
+			}
+		}
+	}()
+	// The filter and window are shared, the project is different
+	sp1, ok := tp1.GetSourceNodes()[0].(*topo.SrcSubTopo)
+	require.True(t, ok)
+	sp2, ok := tp2.GetSourceNodes()[0].(*topo.SrcSubTopo)
+	require.True(t, ok)
+	assert.NotSame(t, sp1, sp2)
+	assert.Same(t, sp1.GetSource(), sp2.GetSource())
+	window := sp1.GetSource().(*topo.SrcSubTopo)
+	assert.Equal(t, 1, window.OpsCount())
+	filter := window.GetSource().(*topo.SrcSubTopo)
+	assert.Equal(t, 1, filter.OpsCount())
+	// The shared nodes are shown in the rule topo
+	edges := tp2.GetTopo().Edges
+	assert.Equal(t, []any{"op_" + window.GetName() + "_4_window"}, edges["op_"+filter.GetName()+"_3_filter"])
+	assert.Equal(t, []any{"op_" + sp2.GetName() + "_5_project"}, edges["op_"+window.GetName()+"_4_window"])
+	assert.Equal(t, []string{"source_prefixsrc"}, tp2.GetTopo().Sources)
+	// Different filter is not shared
+	sp3 := tp3.GetSourceNodes()[0].(*topo.SrcSubTopo)
+	filter3 := sp3.GetSource().(*topo.SrcSubTopo).GetSource().(*topo.SrcSubTopo)
+	assert.NotSame(t, filter, filter3)
+	assert.Same(t, filter.GetSource(), filter3.GetSource())
+	// Not shared without the option
+	tp4, err := PlanSQLWithSourcesAndSinks(def.GetDefaultRule("prefix4", `SELECT count(*) FROM prefixsrc WHERE deviceType = "pump" GROUP BY TUMBLINGWINDOW(ss, 10)`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prefixsrc", tp4.GetSourceNodes()[0].GetName())
+	// The state functions, even referred by alias, are not shared
+	for i, sql := range []string{
+		`SELECT last_hit_count() AS c FROM prefixsrc WHERE c > 5`,
+		`SELECT last_hit_count() AS c FROM prefixsrc WHERE c > 5`,
+		`SELECT count(*) FROM prefixsrc GROUP BY SlidingWindow(ss, 2) OVER (WHEN ts - last_hit_time() > 1000)`,
+	} {
+		tp := plan(fmt.Sprintf("prefixState%d", i), sql)
+		assert.Equal(t, "prefixsrc", tp.GetSourceNodes()[0].GetName(), sql)
+	}
+}
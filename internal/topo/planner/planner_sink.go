@@ -23,6 +23,8 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
+	"github.com/lf-edge/ekuiper/v2/internal/signature"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
@@ -43,6 +45,10 @@ func buildActions(tp *topo.Topo, rule *def.Rule, inputs []node.Emitter, streamCo
 			if err != nil {
 				return err
 			}
+			props, err = secret.ResolveRuleProps(rule.Id, props)
+			if err != nil {
+				return err
+			}
 			sinkName := fmt.Sprintf("%s_%d", name, i)
 			cn, err := SinkToComp(tp, name, sinkName, props, rule, streamCount)
 			if err != nil {
@@ -85,6 +91,11 @@ func SinkToComp(tp *topo.Topo, sinkType string, sinkName string, props map[strin
 		return nil, fmt.Errorf("fail to parse sink configuration: %v", err)
 	}
 	templates := findTemplateProps(props)
+	if commonConf.Signature != "" {
+		if err = attachSignatureProp(props, commonConf.SigProps); err != nil {
+			return nil, err
+		}
+	}
 	// Split sink node
 	sinkOps, err := splitSink(tp, s, sinkName, rule.Options, commonConf, templates)
 	if err != nil {
@@ -166,6 +177,41 @@ func findTemplateProps(props map[string]any) []string {
 	return result
 }
 
+// attachSignatureProp adds the signature placeholder to the headers or properties of the sink props.
+// The sign op will set the signature as the dynamic prop of the placeholder for each message.
+func attachSignatureProp(props map[string]any, sigProps map[string]any) error {
+	sc, err := signature.ParseConf(sigProps)
+	if err != nil {
+		return err
+	}
+	key := sc.SinkPropKey()
+	if key == "" {
+		return nil
+	}
+	var m map[string]any
+	switch pt := props[key].(type) {
+	case nil:
+		m = make(map[string]any, 1)
+	case map[string]any:
+		m = make(map[string]any, len(pt)+1)
+		for k, v := range pt {
+			m[k] = v
+		}
+	case map[string]string:
+		m = make(map[string]any, len(pt)+1)
+		for k, v := range pt {
+			m[k] = v
+		}
+	default:
+		return fmt.Errorf("signature %s requires the %s property to be a map", sc.AttachTo, key)
+	}
+	if _, ok := m[sc.Name]; !ok {
+		m[sc.Name] = signature.Placeholder
+	}
+	props[key] = m
+	return nil
+}
+
 // Split sink node according to the sink configuration. Return the new input emitters.
 func splitSink(tp *topo.Topo, s api.Sink, sinkName string, options *def.RuleOption, sc *node.SinkConf, templates []string) ([]node.TopNode, error) {
 	index := 0
@@ -219,6 +265,16 @@ func splitSink(tp *topo.Topo, s api.Sink, sinkName string, options *def.RuleOpti
 			index++
 			result = append(result, encryptOp)
 		}
+
+		// Sign the final payload so that the receiver can verify it before decryption
+		if !isStreamWriter && sc.Signature != "" {
+			signOp, err := node.NewSignOp(fmt.Sprintf("%s_%d_sign", sinkName, index), options, sc.Signature, sc.SigProps)
+			if err != nil {
+				return nil, err
+			}
+			index++
+			result = append(result, signOp)
+		}
 	}
 	// Caching
 	if sc.EnableCache && !sc.ResendAlterQueue {
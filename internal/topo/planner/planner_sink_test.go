@@ -159,6 +159,41 @@ func TestSinkPlan(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "encrypt and sign sink plan",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"encryption": "aes",
+							"signature":  "hmac-sha256",
+							"sigProps":   map[string]any{"key": "k"},
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			topo: &def.PrintableTopo{
+				Sources: []string{"source_src1"},
+				Edges: map[string][]any{
+					"source_src1": {
+						"op_log_0_0_transform",
+					},
+					"op_log_0_0_transform": {
+						"op_log_0_1_encode",
+					},
+					"op_log_0_1_encode": {
+						"op_log_0_2_encrypt",
+					},
+					"op_log_0_2_encrypt": {
+						"op_log_0_3_sign",
+					},
+					"op_log_0_3_sign": {
+						"sink_log_0",
+					},
+				},
+			},
+		},
 		{
 			name: "encrypt and compress with stream writer",
 			rule: &def.Rule{
@@ -337,6 +372,37 @@ func TestSinkPlanError(t *testing.T) {
 			},
 			err: "template: sink:1: unexpected <.> in operand",
 		},
+		{
+			name: "invalid signature algorithm",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"signature": "md5",
+							"sigProps":  map[string]any{"key": "k"},
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			err: "get signer md5 fail with error: signature algorithm 'md5' is not supported",
+		},
+		{
+			name: "invalid signature header",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"headers":   "{{.headers}}",
+							"signature": "hmac-sha256",
+							"sigProps":  map[string]any{"key": "k"},
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			err: "signature header requires the headers property to be a map",
+		},
 	}
 	for _, c := range tc {
 		t.Run(c.name, func(t *testing.T) {
@@ -399,3 +465,26 @@ func TestFindTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachSignatureProp(t *testing.T) {
+	props := map[string]any{
+		"headers": map[string]any{"Content-Type": "application/json"},
+	}
+	err := attachSignatureProp(props, map[string]any{"key": "k", "name": "X-Signature"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"headers": map[string]any{"Content-Type": "application/json", "X-Signature": "${signature}"},
+	}, props)
+	props = map[string]any{}
+	err = attachSignatureProp(props, map[string]any{"key": "k", "attachTo": "property"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"properties": map[string]any{"signature": "${signature}"},
+	}, props)
+	props = map[string]any{}
+	err = attachSignatureProp(props, map[string]any{"key": "k", "attachTo": "field"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{}, props)
+	err = attachSignatureProp(props, map[string]any{"attachTo": "body"})
+	assert.EqualError(t, err, "invalid attachTo body, must be header, property or field")
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	nodeConf "github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
@@ -73,12 +74,13 @@ func splitSource(ctx api.StreamContext, t *DataSourcePlan, ss api.Source, option
 			props[k] = v
 		}
 	}
+	props, err := secret.ResolveRuleProps(ruleId, props)
+	if err != nil {
+		return nil, nil, 0, err
+	}
 	_ = cast.MapToStruct(props, sp)
 	// Create the connector node as source node
-	var (
-		err         error
-		srcConnNode node.DataSourceNode
-	)
+	var srcConnNode node.DataSourceNode
 	// Some connection only allow one subscription. The source should implement UniqueSub to provide a subId to avoid multiple connection.
 	us, hasSubId := ss.(model.UniqueSub)
 	conId := sp.SelId
@@ -141,6 +143,24 @@ func splitSource(ctx api.StreamContext, t *DataSourcePlan, ss api.Source, option
 		ops = append(ops, rlOp)
 	}
 
+	if featureSet.needVerification {
+		vo, err := node.NewVerifyOp(fmt.Sprintf("%d_verify", index), options, sp.Verification, sp.VerProps)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		index++
+		ops = append(ops, vo)
+	}
+
+	if featureSet.needDecryption {
+		dro, err := node.NewDecryptOp(fmt.Sprintf("%d_decrypt", index), options, sp.Decryption, sp.DecProps)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		index++
+		ops = append(ops, dro)
+	}
+
 	if featureSet.needCompression {
 		dco, err := node.NewDecompressOp(fmt.Sprintf("%d_decompress", index), options, sp.Decompression)
 		if err != nil {
@@ -160,6 +180,10 @@ func splitSource(ctx api.StreamContext, t *DataSourcePlan, ss api.Source, option
 		if err != nil {
 			return nil, nil, 0, err
 		}
+		// The predicates may refer to the payload fields or apply to the merged messages, so do not filter ahead
+		if t.decodeFilter != nil && !featureSet.needPayloadDecode && !featureSet.needRatelimitMerge {
+			decodeNode.SetFilter(ctx, t.decodeFilter, t.decodeFilterFields)
+		}
 		index++
 		ops = append(ops, decodeNode)
 	}
@@ -213,6 +237,10 @@ func splitSource(ctx api.StreamContext, t *DataSourcePlan, ss api.Source, option
 
 type SourcePropsForSplit struct {
 	Decompression string            `json:"decompression"`
+	Decryption    string            `json:"decryption"`
+	DecProps      map[string]any    `json:"decProps"`
+	Verification  string            `json:"verification"`
+	VerProps      map[string]any    `json:"verProps"`
 	SelId         string            `json:"connectionSelector"`
 	PayloadFormat string            `json:"payloadFormat"`
 	Interval      cast.DurationConf `json:"interval"`
@@ -225,6 +253,8 @@ type SourcePropsForSplit struct {
 type traits struct {
 	needConnection    bool
 	needCompression   bool
+	needDecryption    bool
+	needVerification  bool
 	needDecode        bool
 	needPayloadDecode bool
 	// rate limit will plan right after source read
@@ -253,9 +283,17 @@ func checkFeatures(ss api.Source, sp *SourcePropsForSplit, props map[string]any)
 	if info.HasInterval {
 		delete(props, "sendInterval")
 	}
+	if sp.Decryption != "" && !info.NeedDecode {
+		return traits{}, fmt.Errorf("decryption is only supported by bytes source")
+	}
+	if sp.Verification != "" && !info.NeedDecode {
+		return traits{}, fmt.Errorf("signature verification is only supported by bytes source")
+	}
 	r := traits{
 		needConnection:    sp.SelId != "",
 		needCompression:   sp.Decompression != "" && (!info.HasCompress || info.NeedBatchDecode),
+		needDecryption:    sp.Decryption != "",
+		needVerification:  sp.Verification != "",
 		needDecode:        info.NeedDecode,
 		needPayloadDecode: sp.PayloadFormat != "",
 	}
@@ -320,7 +358,10 @@ func planLookupSource(ctx api.StreamContext, t *LookupPlan, ruleOption *def.Rule
 	if si == nil {
 		return nil, fmt.Errorf("lookup source type %s not found", t.options.TYPE)
 	}
-	props := nodeConf.GetSourceConf(t.options.TYPE, t.options)
+	props, err := secret.ResolveProps(ctx, nodeConf.GetSourceConf(t.options.TYPE, t.options))
+	if err != nil {
+		return nil, err
+	}
 	switch si.(type) {
 	case api.LookupSource:
 		return node.NewLookupNode(ctx, t.joinExpr.Name, false, t.fields, t.keys, t.joinExpr.JoinType, t.valvars, t.options, ruleOption, props)
@@ -58,6 +58,7 @@ func TestPlanTopo(t *testing.T) {
 		"filesrc2": `CREATE STREAM fs2 () WITH (FORMAT="delimited", TYPE="file",CONF_KEY="csv");`,
 		"filesrc3": `CREATE STREAM fs3 () WITH (FORMAT="json",TYPE="file",CONF_KEY="json");`,
 		"neuron1":  `CREATE STREAM neuron1 () WITH (FORMAT="json", TYPE="neuron",CONF_KEY="tcp");`,
+		"src6":     `CREATE STREAM src6 () WITH (DATASOURCE="topic1", FORMAT="json", TYPE="mqtt", CONF_KEY="testDecrypt");`,
 	}
 	for name, sql := range streamSqls {
 		s, err := json.Marshal(&xsql.StreamInfo{
@@ -132,6 +133,17 @@ func TestPlanTopo(t *testing.T) {
 			p: "neuron",
 			k: "tcp",
 		},
+		{
+			conf: map[string]any{
+				"verification":  "hmac-sha256",
+				"verProps":      map[string]any{"key": "k", "attachTo": "property"},
+				"decryption":    "chacha20poly1305",
+				"decProps":      map[string]any{"key": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+				"decompression": "gzip",
+			},
+			p: "mqtt",
+			k: "testDecrypt",
+		},
 	}
 	meta.InitYamlConfigManager()
 	dataDir, _ := conf.GetDataLoc()
@@ -396,6 +408,39 @@ func TestPlanTopo(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "test verify and decrypt",
+			sql:  `SELECT * FROM src6`,
+			topo: &def.PrintableTopo{
+				Sources: []string{"source_src6"},
+				Edges: map[string][]any{
+					"source_src6": {
+						"op_2_verify",
+					},
+					"op_2_verify": {
+						"op_3_decrypt",
+					},
+					"op_3_decrypt": {
+						"op_4_decompress",
+					},
+					"op_4_decompress": {
+						"op_5_decoder",
+					},
+					"op_5_decoder": {
+						"op_6_project",
+					},
+					"op_6_project": {
+						"op_logToMemory_0_0_transform",
+					},
+					"op_logToMemory_0_0_transform": {
+						"op_logToMemory_0_1_encode",
+					},
+					"op_logToMemory_0_1_encode": {
+						"sink_logToMemory_0",
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -23,7 +23,11 @@ type pushProjectionPlan struct{}
 
 // pushProjectionPlan inject Projection Plan between the shared Datasource and its father only if the Plan have windowPlan
 // We use Projection to remove the unused column before windowPlan in order to reduce memory consuming
-func (pp *pushProjectionPlan) optimize(plan LogicalPlan, _ *def.RuleOption) (LogicalPlan, error) {
+// If the shared prefix is enabled, the projection is skipped because it differs by rules and prevents sharing the window
+func (pp *pushProjectionPlan) optimize(plan LogicalPlan, opt *def.RuleOption) (LogicalPlan, error) {
+	if opt != nil && opt.PlanOptimizeStrategy.IsSharedPrefixEnable() {
+		return plan, nil
+	}
 	if pp.searchJoinPlan(plan) {
 		return plan, nil
 	}
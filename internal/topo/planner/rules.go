@@ -14,7 +14,15 @@
 
 package planner
 
-import "github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
 
 type logicalOptRule interface {
 	optimize(LogicalPlan, *def.RuleOption) (LogicalPlan, error)
@@ -42,3 +50,262 @@ func (r *columnPruner) optimize(lp LogicalPlan, _ *def.RuleOption) (LogicalPlan,
 func (r *columnPruner) name() string {
 	return "columnPruner"
 }
+
+// constantFolder evaluates the operations on literals like 1 + 2 in the plan once to save the per tuple evaluation
+type constantFolder struct{}
+
+func (r *constantFolder) optimize(lp LogicalPlan, _ *def.RuleOption) (LogicalPlan, error) {
+	walkPlan(lp, func(p LogicalPlan) {
+		for _, slot := range exprSlots(p) {
+			*slot = foldExpr(*slot)
+		}
+	})
+	return lp, nil
+}
+
+func (r *constantFolder) name() string {
+	return "constantFolder"
+}
+
+var foldableOps = map[ast.Token]bool{
+	ast.ADD: true, ast.SUB: true, ast.MUL: true, ast.MOD: true,
+	ast.BITWISE_AND: true, ast.BITWISE_OR: true, ast.BITWISE_XOR: true,
+	ast.AND: true, ast.OR: true,
+	ast.EQ: true, ast.NEQ: true, ast.LT: true, ast.LTE: true, ast.GT: true, ast.GTE: true,
+}
+
+func foldExpr(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		e.Expr = foldExpr(e.Expr)
+		if isLiteral(e.Expr) {
+			return e.Expr
+		}
+	case *ast.BinaryExpr:
+		e.LHS = foldExpr(e.LHS)
+		e.RHS = foldExpr(e.RHS)
+		if foldableOps[e.OP] && isLiteral(e.LHS) && isLiteral(e.RHS) {
+			// Errors like divided by zero are kept to report in runtime
+			if l, ok := toLiteral((&xsql.ValuerEval{Valuer: xsql.MultiValuer()}).Eval(e)); ok {
+				return l
+			}
+		}
+	case *ast.FieldRef:
+		if e.IsAlias() && e.AliasRef != nil {
+			e.AliasRef.Expression = foldExpr(e.AliasRef.Expression)
+		}
+	case *ast.Call:
+		for i, arg := range e.Args {
+			e.Args[i] = foldExpr(arg)
+		}
+	case *ast.CaseExpr:
+		e.Value = foldExpr(e.Value)
+		for _, w := range e.WhenClauses {
+			w.Expr = foldExpr(w.Expr)
+			w.Result = foldExpr(w.Result)
+		}
+		e.ElseClause = foldExpr(e.ElseClause)
+	}
+	return expr
+}
+
+func isLiteral(expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.IntegerLiteral, *ast.NumberLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return true
+	default:
+		return false
+	}
+}
+
+func toLiteral(v any) (ast.Expr, bool) {
+	switch vt := v.(type) {
+	case int64:
+		return &ast.IntegerLiteral{Val: vt}, true
+	case float64:
+		return &ast.NumberLiteral{Val: vt}, true
+	case string:
+		return &ast.StringLiteral{Val: vt}, true
+	case bool:
+		return &ast.BooleanLiteral{Val: vt}, true
+	default:
+		return nil, false
+	}
+}
+
+// commonSubexprEliminator replaces the function calls which appear more than once in the rule with an internal alias.
+// The alias value is calculated once and cached in the tuple, so that the following evaluations in the same or later
+// operators can reuse it. Only deterministic scalar functions are eliminated.
+type commonSubexprEliminator struct{}
+
+func (r *commonSubexprEliminator) optimize(lp LogicalPlan, _ *def.RuleOption) (LogicalPlan, error) {
+	counts := make(map[string]int)
+	walkPlan(lp, func(p LogicalPlan) {
+		for _, slot := range cseSlots(p) {
+			visitCSE(slot, func(e *ast.Expr) bool {
+				counts[(*e).String()]++
+				return false
+			})
+		}
+	})
+	refs := make(map[string]*ast.FieldRef)
+	var err error
+	walkPlan(lp, func(p LogicalPlan) {
+		for _, slot := range cseSlots(p) {
+			visitCSE(slot, func(e *ast.Expr) bool {
+				key := (*e).String()
+				if counts[key] < 2 || err != nil {
+					return false
+				}
+				ref, ok := refs[key]
+				if !ok {
+					var ar *ast.AliasRef
+					ar, err = ast.NewAliasRef(*e)
+					if err != nil {
+						return false
+					}
+					h := fnv.New64a()
+					_, _ = h.Write([]byte(key))
+					ref = &ast.FieldRef{StreamName: ast.AliasStream, Name: fmt.Sprintf("$$cse_%016x", h.Sum64()), AliasRef: ar}
+					refs[key] = ref
+				}
+				*e = ref
+				return true
+			})
+		}
+	})
+	return lp, err
+}
+
+func (r *commonSubexprEliminator) name() string {
+	return "commonSubexprEliminator"
+}
+
+// cseSlots returns the expressions to search common sub expressions. The root of the project fields are not
+// included because they are evaluated once as the output. Only their sub expressions are searched. The alias
+// expressions are included as they may be referred and evaluated by other expressions.
+func cseSlots(lp LogicalPlan) []*ast.Expr {
+	switch lp.(type) {
+	case *FilterPlan, *HavingPlan:
+		return exprSlots(lp)
+	case *ProjectPlan:
+		var result []*ast.Expr
+		visited := make(map[*ast.AliasRef]bool)
+		for _, slot := range exprSlots(lp) {
+			if f, ok := (*slot).(*ast.FieldRef); ok && f.IsAlias() && f.AliasRef != nil {
+				if !visited[f.AliasRef] {
+					visited[f.AliasRef] = true
+					result = append(result, &f.AliasRef.Expression)
+				}
+				continue
+			}
+			result = append(result, childSlots(*slot)...)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// visitCSE visits the candidate sub expressions in pre-order. If fn returns true, the children are not visited.
+func visitCSE(slot *ast.Expr, fn func(e *ast.Expr) bool) {
+	if isCSECandidate(*slot) && fn(slot) {
+		return
+	}
+	for _, c := range childSlots(*slot) {
+		visitCSE(c, fn)
+	}
+}
+
+func childSlots(expr ast.Expr) []*ast.Expr {
+	var result []*ast.Expr
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		result = append(result, &e.Expr)
+	case *ast.BinaryExpr:
+		result = append(result, &e.LHS, &e.RHS)
+	case *ast.Call:
+		// Do not look into the aggregate functions which run on the whole group
+		if e.FuncType == ast.FuncTypeScalar && !e.Cached {
+			for i := range e.Args {
+				result = append(result, &e.Args[i])
+			}
+		}
+	case *ast.CaseExpr:
+		if e.Value != nil {
+			result = append(result, &e.Value)
+		}
+		for _, w := range e.WhenClauses {
+			result = append(result, &w.Expr, &w.Result)
+		}
+		if e.ElseClause != nil {
+			result = append(result, &e.ElseClause)
+		}
+	}
+	return result
+}
+
+func isCSECandidate(expr ast.Expr) bool {
+	c, ok := expr.(*ast.Call)
+	if !ok || c.FuncType != ast.FuncTypeScalar || c.Cached || !function.IsDeterministicFunc(c.Name) {
+		return false
+	}
+	result := true
+	ast.WalkFunc(c, func(n ast.Node) bool {
+		switch nt := n.(type) {
+		case *ast.Call:
+			if nt.FuncType != ast.FuncTypeScalar || nt.Cached || xsql.ImplicitStateFuncs[nt.Name] || function.IsAnalyticFunc(nt.Name) {
+				result = false
+			}
+		case *ast.Wildcard:
+			result = false
+		case *ast.FieldRef:
+			if nt.IsAlias() {
+				result = false
+			}
+		}
+		return result
+	})
+	return result
+}
+
+// exprSlots returns the pointers to the non-nil root expressions of the plan so that they can be rewritten
+func exprSlots(lp LogicalPlan) []*ast.Expr {
+	var slots []*ast.Expr
+	switch p := lp.(type) {
+	case *FilterPlan:
+		slots = append(slots, &p.condition)
+	case *HavingPlan:
+		slots = append(slots, &p.condition)
+	case *WindowPlan:
+		slots = append(slots, &p.condition, &p.triggerCondition)
+	case *JoinPlan:
+		for i := range p.joins {
+			slots = append(slots, &p.joins[i].Expr)
+		}
+	case *ProjectPlan:
+		for i := range p.fields {
+			slots = append(slots, &p.fields[i].Expr)
+		}
+		for i := range p.aliasFields {
+			slots = append(slots, &p.aliasFields[i].Expr)
+		}
+		for i := range p.exprFields {
+			slots = append(slots, &p.exprFields[i].Expr)
+		}
+	}
+	result := slots[:0]
+	for _, s := range slots {
+		if *s != nil {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func walkPlan(lp LogicalPlan, fn func(p LogicalPlan)) {
+	fn(lp)
+	for _, c := range lp.Children() {
+		walkPlan(c, fn)
+	}
+}
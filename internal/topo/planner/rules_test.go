@@ -0,0 +1,114 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestExprRewriteRules(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql     string
+		explain string
+	}{
+		{
+			sql: `select a + 1 * 2 as c from stream where b > 10 - 5 and 1 = 1`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ $$alias.c,aliasRef:binaryExpr:{ stream.a + 2 } ]"}
+	{"op":"FilterPlan_1","info":"Condition:{ binaryExpr:{ binaryExpr:{ stream.b > 5 } AND true } }, "}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+		{
+			sql: `select abs(a) + 1 as c, abs(a) as d from stream where abs(a) > 2`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ $$alias.c,aliasRef:binaryExpr:{ $$alias.$$cse_b0f045b8a6bdb270,aliasRef:Call:{ name:abs, args:[stream.a] } + 1 }, $$alias.d,aliasRef:$$alias.$$cse_b0f045b8a6bdb270,aliasRef:Call:{ name:abs, args:[stream.a] } ]"}
+	{"op":"FilterPlan_1","info":"Condition:{ binaryExpr:{ $$alias.$$cse_b0f045b8a6bdb270,aliasRef:Call:{ name:abs, args:[stream.a] } > 2 } }, "}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+		{
+			sql: `select rand() as r, rand() as s from stream`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ $$alias.r,aliasRef:Call:{ name:rand }, $$alias.s,aliasRef:Call:{ name:rand } ]"}
+	{"op":"DataSourcePlan_1","info":"StreamName: stream"}`,
+		},
+		{
+			sql: `select a / 2 from stream where 3 / 2 > 1`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ binaryExpr:{ stream.a / 2 } ]"}
+	{"op":"FilterPlan_1","info":"Condition:{ binaryExpr:{ binaryExpr:{ 3 / 2 } > 1 } }, "}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := createLogicalPlan(stmt, &def.RuleOption{Qos: 0}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
+func TestCompileCondition(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql      string
+		compiled bool
+	}{
+		{sql: `select a from stream where b > 10 - 5`, compiled: true},
+		{sql: `select a from stream where abs(b) > 5`, compiled: false},
+		{sql: `select a from stream where b > 1 group by countwindow(2)`, compiled: true},
+		{sql: `select stream.a from stream inner join sharedStream on stream.a = sharedStream.a where stream.b > 1 group by countwindow(2)`, compiled: false},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := createLogicalPlan(stmt, &def.RuleOption{Qos: 0}, kv)
+		require.NoError(t, err)
+		var (
+			cp   LogicalPlan
+			cond ast.Expr
+		)
+		walkPlan(p, func(lp LogicalPlan) {
+			if cp != nil {
+				return
+			}
+			switch pt := lp.(type) {
+			case *JoinPlan:
+				cp, cond = pt, pt.joins[0].Expr
+			case *FilterPlan:
+				cp, cond = pt, pt.condition
+			case *WindowPlan:
+				if pt.condition != nil {
+					cp, cond = pt, pt.condition
+				}
+			}
+		})
+		require.NotNil(t, cp, tc.sql)
+		require.Equal(t, tc.compiled, compileCondition(cp, cond) != nil, tc.sql)
+	}
+}
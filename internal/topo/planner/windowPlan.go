@@ -32,6 +32,8 @@ type WindowPlan struct {
 	timeUnit         ast.Token
 	limit            int // If limit is not positive, there will be no limit
 	isEventTime      bool
+	// If set, the window is partitioned by the group keys and groups the rows per partition
+	partitionDims ast.Dimensions
 
 	stateFuncs []*ast.Call
 }
@@ -56,6 +58,16 @@ func (p *WindowPlan) BuildExplainInfo() {
 		}
 		info += " ]"
 	}
+	if len(p.partitionDims) != 0 {
+		info += ", partitionBy:[ "
+		for i, d := range p.partitionDims {
+			info += d.Expr.String()
+			if i != len(p.partitionDims)-1 {
+				info += ", "
+			}
+		}
+		info += " ]"
+	}
 	info += ", limit: " + strconv.Itoa(p.limit) + " }"
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
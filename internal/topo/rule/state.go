@@ -34,6 +34,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/schedule"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/metric"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
@@ -117,6 +118,13 @@ func (s *State) HasTopo() bool {
 	return s.topology != nil
 }
 
+// GetTopo returns the running topo. It is nil if the rule is not running
+func (s *State) GetTopo() *topo.Topo {
+	s.RLock()
+	defer s.RUnlock()
+	return s.topology
+}
+
 // Validate tries to plan and return the planned topo and any errors
 // Need to cancel the topo if it is of no use because the input/output channels are set
 // Otherwise, the shared source may send to these channels and hang
@@ -392,6 +400,23 @@ func (s *State) Stop() {
 	return
 }
 
+// StopAndWait stops the rule and waits until it is stopped. Stop returns at once if another action is in progress
+// and the stop action is queued, so the topo may be still running when Stop returns.
+func (s *State) StopAndWait(timeout time.Duration) error {
+	s.Stop()
+	deadline := time.Now().Add(timeout)
+	for {
+		switch s.GetState() {
+		case Stopped, StoppedByErr, ScheduledStop:
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout to wait rule %s to stop", s.Rule.Id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func (s *State) ScheduleStop() {
 	defer s.nextAction()
 	s.logger.Debug("scheduled stop RunState")
@@ -604,6 +629,15 @@ func (s *State) GetMetrics() ([]string, []any) {
 	return nil, nil
 }
 
+func (s *State) GetAnalyzeStats() ([]*metric.AnalyzeStats, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.topology != nil {
+		return s.topology.GetAnalyzeStats(), nil
+	}
+	return nil, fmt.Errorf("rule %s is not running", s.Rule.Id)
+}
+
 func (s *State) GetStreams() []string {
 	s.RLock()
 	defer s.RUnlock()
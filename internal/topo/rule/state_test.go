@@ -21,11 +21,16 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/schedule"
 	"github.com/lf-edge/ekuiper/v2/internal/processor"
 	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/metric"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -142,6 +147,42 @@ func TestAPIs(t *testing.T) {
 	assert.NoError(t, e)
 }
 
+func TestGetAnalyzeStats(t *testing.T) {
+	sp := processor.NewStreamProcessor()
+	_, err := sp.ExecStmt(`CREATE STREAM demoAnalyze () WITH (FORMAT="JSON", TYPE="memory", DATASOURCE="testAnalyze")`)
+	require.NoError(t, err)
+	defer sp.ExecStmt(`DROP STREAM demoAnalyze`)
+	st := NewState(def.GetDefaultRule("testAnalyze", "select a from demoAnalyze where a > 3"))
+	_, err = st.GetAnalyzeStats()
+	require.EqualError(t, err, "rule testAnalyze is not running")
+	require.NoError(t, st.Start())
+	defer st.Delete()
+	time.Sleep(100 * time.Millisecond)
+	ctx := mockContext.NewMockContext("testAnalyze", "pub")
+	for i := 0; i < 10; i++ {
+		pubsub.Produce(ctx, "testAnalyze", &xsql.Tuple{Message: map[string]any{"a": i}, Timestamp: timex.GetNow()})
+	}
+	var stats []*metric.AnalyzeStats
+	assert.Eventually(t, func() bool {
+		stats, err = st.GetAnalyzeStats()
+		return err == nil && len(stats) == 6 && stats[5].RecordsIn == 6
+	}, time.Second, 10*time.Millisecond)
+	names := make([]string, 0, len(stats))
+	var ratio float64
+	for _, s := range stats {
+		names = append(names, s.Name)
+		ratio += s.CostRatio
+	}
+	assert.Equal(t, []string{"demoAnalyze", "2_filter", "3_project", "logToMemory_0_0_transform", "logToMemory_0_1_encode", "logToMemory_0"}, names)
+	filter := stats[1]
+	assert.Equal(t, "op", filter.Type)
+	assert.Equal(t, int64(10), filter.RecordsIn)
+	assert.Equal(t, int64(6), filter.RecordsOut)
+	assert.Equal(t, 0.6, filter.Selectivity)
+	assert.Equal(t, int64(1024), filter.BufferCapacity)
+	assert.InDelta(t, 1, ratio, 0.0001)
+}
+
 func TestStateTransit(t *testing.T) {
 	sp := processor.NewStreamProcessor()
 	_, err := sp.ExecStmt(`CREATE STREAM demo () WITH (FORMAT="JSON", TYPE="memory", DATASOURCE="test")`)
@@ -0,0 +1,168 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// ErrNoCheckpoint means the topo has no checkpoint to take the savepoint while running
+var ErrNoCheckpoint = errors.New("checkpoint is not enabled")
+
+type stateHolder interface {
+	GetAllState() map[string]any
+}
+
+type streamTask interface {
+	GetStreamContext() api.StreamContext
+}
+
+// AddStateSignature records the stable id of a stateful node. The id is decided by the settings which
+// define the layout of the node states instead of the node name which changes when the rule sql changes.
+// Duplicate ids are made unique by the occurrence order.
+func (s *Topo) AddStateSignature(name string, sig string) {
+	id := sig
+	for i := 2; s.hasStateSignature(id); i++ {
+		id = fmt.Sprintf("%s#%d", sig, i)
+	}
+	s.stateSigs[name] = id
+}
+
+func (s *Topo) hasStateSignature(sig string) bool {
+	for _, v := range s.stateSigs {
+		if v == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// IncompatibleOps returns the names of the stateful operators of the old topo whose states cannot be
+// migrated to this topo. An empty result means the states can be fully migrated.
+func (s *Topo) IncompatibleOps(old *Topo) []string {
+	var result []string
+	for _, op := range old.ops {
+		sig, ok := old.stateSigs[op.GetName()]
+		if ok && !s.hasStateSignature(sig) {
+			result = append(result, op.GetName())
+		}
+	}
+	return result
+}
+
+// Savepoint collects the states of the stateful nodes by their stable ids.
+// It must be called after the topo is stopped so that the states are not changing.
+func (s *Topo) Savepoint() state.Savepoint {
+	sp := make(state.Savepoint)
+	collect := func(name string, n any) {
+		sig, ok := s.stateSigs[name]
+		if !ok {
+			return
+		}
+		st, ok := n.(streamTask)
+		if !ok || st.GetStreamContext() == nil {
+			return
+		}
+		if sh, ok := st.GetStreamContext().(stateHolder); ok {
+			if states := sh.GetAllState(); len(states) > 0 {
+				sp[sig] = states
+			}
+		}
+	}
+	for _, src := range s.sources {
+		collect(src.GetName(), src)
+	}
+	for _, op := range s.ops {
+		collect(op.GetName(), op)
+	}
+	return sp
+}
+
+// CheckpointSavepoint takes the savepoint by a checkpoint barrier so that the rule keeps running. The states are
+// consistent with the source offsets as they are read from the completed checkpoint. It requires the checkpoint
+// which is enabled by qos, otherwise ErrNoCheckpoint is returned.
+func (s *Topo) CheckpointSavepoint(timeout time.Duration) (state.Savepoint, error) {
+	s.mu.Lock()
+	c, st := s.coordinator, s.store
+	s.mu.Unlock()
+	if !c.IsActivated() || st == nil {
+		return nil, ErrNoCheckpoint
+	}
+	notify, err := c.ForceSaveState()
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case r := <-notify:
+		if e, ok := r.(error); ok && e != nil {
+			return nil, e
+		}
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("timeout to take the checkpoint of rule %s", s.name)
+	}
+	// The completed checkpoint is the latest one in the store until the next checkpoint interval
+	sp := make(state.Savepoint)
+	collect := func(name string) error {
+		sig, ok := s.stateSigs[name]
+		if !ok {
+			return nil
+		}
+		m, err := st.GetOpState(name)
+		if err != nil {
+			return err
+		}
+		if states := cast.SyncMapToMap(m); len(states) > 0 {
+			sp[sig] = states
+		}
+		return nil
+	}
+	for _, src := range s.sources {
+		if err := collect(src.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	for _, op := range s.ops {
+		if err := collect(op.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	return sp, nil
+}
+
+// RestoreFrom sets the savepoint to restore the node states in the next open.
+func (s *Topo) RestoreFrom(sp state.Savepoint) {
+	if len(sp) == 0 {
+		return
+	}
+	s.savepoint = sp
+}
+
+// restoredStates maps the savepoint to the node names of this topo
+func (s *Topo) restoredStates() map[string]map[string]any {
+	result := make(map[string]map[string]any, len(s.savepoint))
+	for name, sig := range s.stateSigs {
+		if states, ok := s.savepoint[sig]; ok {
+			result[name] = states
+		}
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+)
+
+func TestStateSignature(t *testing.T) {
+	conf.InitConf()
+	tp, err := NewWithNameAndOptions("sigRule", def.GetDefaultRule("sigRule", "select * from demo").Options)
+	require.NoError(t, err)
+	tp.AddStateSignature("3_window", "window:a")
+	tp.AddStateSignature("5_window", "window:a")
+	tp.AddStateSignature("6_analytic", "analytic:b")
+	assert.Equal(t, map[string]string{
+		"3_window":   "window:a",
+		"5_window":   "window:a#2",
+		"6_analytic": "analytic:b",
+	}, tp.stateSigs)
+	tp.RestoreFrom(state.Savepoint{
+		"window:a#2": {"inputs": 1},
+		"analytic:c": {"lag": 2},
+	})
+	assert.Equal(t, map[string]map[string]any{
+		"5_window": {"inputs": 1},
+	}, tp.restoredStates())
+	// empty savepoint is ignored
+	tp.savepoint = nil
+	tp.RestoreFrom(state.Savepoint{})
+	assert.Nil(t, tp.savepoint)
+}
+
+func TestSavepointMigrate(t *testing.T) {
+	conf.InitConf()
+	options := def.GetDefaultRule("migrateRule", "select * from demo").Options
+	oldTp, err := NewWithNameAndOptions("migrateRule", options)
+	require.NoError(t, err)
+	oldOp := node.NewWatermarkOp("2_watermark", false, nil, options)
+	oldTp.AddOperator(nil, oldOp)
+	oldTp.AddStateSignature(oldOp.GetName(), "watermark:")
+	oldTp.AddOperator(nil, node.NewWatermarkOp("3_watermark", false, nil, options))
+	oldTp.AddStateSignature("3_watermark", "watermark:demo")
+	oldTp.Open()
+	require.NoError(t, oldOp.GetStreamContext().PutState("wm", int64(100)))
+	require.NoError(t, oldTp.Cancel())
+	oldTp.WaitClose()
+
+	newTp, err := NewWithNameAndOptions("migrateRule", options)
+	require.NoError(t, err)
+	newOp := node.NewWatermarkOp("3_watermark", false, nil, options)
+	newTp.AddOperator(nil, newOp)
+	newTp.AddStateSignature(newOp.GetName(), "watermark:")
+	// the op with signature watermark:demo is missing
+	assert.Equal(t, []string{"3_watermark"}, newTp.IncompatibleOps(oldTp))
+
+	sp := oldTp.Savepoint()
+	assert.Equal(t, state.Savepoint{"watermark:": {"wm": int64(100)}}, sp)
+	newTp.RestoreFrom(sp)
+	newTp.Open()
+	defer func() {
+		_ = newTp.Cancel()
+		newTp.WaitClose()
+	}()
+	v, err := newOp.GetStreamContext().GetState("wm")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), v)
+	assert.Nil(t, newTp.savepoint)
+}
+
+type rewindSource struct {
+	offset int
+}
+
+func (m *rewindSource) GetOffset() (any, error) {
+	return m.offset, nil
+}
+
+func (m *rewindSource) Rewind(offset any) error {
+	m.offset = offset.(int)
+	return nil
+}
+
+func (m *rewindSource) ResetOffset(_ map[string]any) error {
+	return nil
+}
+
+func (m *rewindSource) Provision(_ api.StreamContext, _ map[string]any) error {
+	return nil
+}
+
+func (m *rewindSource) Close(_ api.StreamContext) error {
+	return nil
+}
+
+func (m *rewindSource) Connect(_ api.StreamContext, _ api.StatusChangeHandler) error {
+	return nil
+}
+
+func (m *rewindSource) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, _ api.ErrorIngest) error {
+	m.offset++
+	ingest(ctx, map[string]any{"offset": m.offset}, nil, time.Now())
+	return nil
+}
+
+func TestSavepointSourceOffset(t *testing.T) {
+	conf.InitConf()
+	options := def.GetDefaultRule("offsetRule", "select * from demo").Options
+	newTopo := func(src *rewindSource) *Topo {
+		tp, err := NewWithNameAndOptions("offsetRule", options)
+		require.NoError(t, err)
+		sn, err := node.NewSourceNode(tp.GetContext(), "demo", src, map[string]any{"datasource": "demo"}, options)
+		require.NoError(t, err)
+		tp.AddSrc(sn)
+		tp.AddStateSignature(sn.GetName(), "source:demo")
+		return tp
+	}
+	src := &rewindSource{offset: 5}
+	oldTp := newTopo(src)
+	oldTp.Open()
+	require.Eventually(t, func() bool {
+		v, _ := oldTp.sources[0].(*node.SourceNode).GetStreamContext().GetState(node.OffsetKey)
+		return v == 6
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, oldTp.Cancel())
+	oldTp.WaitClose()
+	sp := oldTp.Savepoint()
+	assert.Equal(t, state.Savepoint{"source:demo": {node.OffsetKey: 6}}, sp)
+
+	// the new source rewinds to the saved offset and resumes from there
+	src2 := &rewindSource{}
+	newTp := newTopo(src2)
+	newTp.RestoreFrom(sp)
+	newTp.Open()
+	defer func() {
+		_ = newTp.Cancel()
+		newTp.WaitClose()
+	}()
+	require.Eventually(t, func() bool {
+		v, _ := newTp.sources[0].(*node.SourceNode).GetStreamContext().GetState(node.OffsetKey)
+		return v == 7
+	}, time.Second, 10*time.Millisecond)
+}
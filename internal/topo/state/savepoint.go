@@ -0,0 +1,50 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"sync"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+)
+
+// Savepoint is the snapshot of the operator states of a stopped rule.
+// It is keyed by the stable id of the operators so that the states can be restored into a new topo
+// whose operator names may change.
+type Savepoint map[string]map[string]any
+
+// savepointStore restores the op states from the savepoint instead of the last checkpoint.
+// The checkpoint is saved by the operator names of the old topo, so it must not be restored to the new topo.
+type savepointStore struct {
+	api.Store
+	states map[string]map[string]any
+}
+
+// WithSavepoint wraps the store to restore the op states by the op names.
+// The ops which are not in the states start with empty states.
+func WithSavepoint(store api.Store, states map[string]map[string]any) api.Store {
+	return &savepointStore{
+		Store:  store,
+		states: states,
+	}
+}
+
+func (s *savepointStore) GetOpState(opId string) (*sync.Map, error) {
+	m := &sync.Map{}
+	for k, v := range s.states[opId] {
+		m.Store(k, v)
+	}
+	return m, nil
+}
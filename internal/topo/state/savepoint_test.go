@@ -0,0 +1,39 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+func TestSavepointStore(t *testing.T) {
+	s := WithSavepoint(newMemoryStore(), map[string]map[string]any{
+		"4_window": {"inputs": []int{1, 2}},
+	})
+	m, err := s.GetOpState("4_window")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"inputs": []int{1, 2}}, cast.SyncMapToMap(m))
+	m, err = s.GetOpState("3_window")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, cast.SyncMapToMap(m))
+	// the checkpoint saving passes through
+	require.NoError(t, s.SaveState(1, "4_window", map[string]any{}))
+	require.NoError(t, s.SaveCheckpoint(1))
+}
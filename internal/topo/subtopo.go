@@ -76,16 +76,7 @@ func (s *SrcSubTopo) Open(ctx api.StreamContext, parentErrCh chan<- error) {
 		s.refCount.Add(1)
 		ctx.GetLogger().Infof("Sub topo %s opened by rule %s with %d ref", s.name, ctx.GetRuleId(), s.refCount.Load())
 	}
-	// Attach schemas
-	for _, op := range s.ops {
-		if so, ok := op.(node.SchemaNode); ok {
-			si, hasSchema := s.schemaReg[ctx.GetRuleId()]
-			if hasSchema {
-				ctx.GetLogger().Infof("attach schema to op %s", op.GetName())
-				so.AttachSchema(ctx, si.datasource, si.schema, si.isWildcard)
-			}
-		}
-	}
+	s.attachSchema(ctx)
 	// If not opened yet, open it. It may be opened before, but failed to open. In this case, try to open it again.
 	if s.opened.CompareAndSwap(false, true) {
 		poe := infra.SafeRun(func() error {
@@ -125,6 +116,36 @@ func (s *SrcSubTopo) Open(ctx api.StreamContext, parentErrCh chan<- error) {
 	}
 }
 
+// attachSchema attaches the schema of the rule to the ops. For the shared operator prefix, the schema nodes
+// are in the nested sub topo of the stream, so attach it recursively.
+func (s *SrcSubTopo) attachSchema(ctx api.StreamContext) {
+	for _, op := range s.ops {
+		if so, ok := op.(node.SchemaNode); ok {
+			si, hasSchema := s.schemaReg[ctx.GetRuleId()]
+			if hasSchema {
+				ctx.GetLogger().Infof("attach schema to op %s", op.GetName())
+				so.AttachSchema(ctx, si.datasource, si.schema, si.isWildcard)
+			}
+		}
+	}
+	if ss, ok := s.source.(*SrcSubTopo); ok {
+		ss.attachSchema(ctx)
+	}
+}
+
+func (s *SrcSubTopo) detachSchema(ctx api.StreamContext, ruleId string) {
+	if _, hasSchema := s.schemaReg[ruleId]; hasSchema {
+		for _, op := range s.ops {
+			if so, ok := op.(node.SchemaNode); ok {
+				so.DetachSchema(ctx, ruleId)
+			}
+		}
+	}
+	if ss, ok := s.source.(*SrcSubTopo); ok {
+		ss.detachSchema(ctx, ruleId)
+	}
+}
+
 func (s *SrcSubTopo) notifyError(poe error) {
 	// Notify error to all ref rules
 	s.refRules.Range(func(k, v interface{}) bool {
@@ -143,9 +164,13 @@ func (s *SrcSubTopo) GetName() string {
 }
 
 func (s *SrcSubTopo) SubMetrics() (keys []string, values []any) {
-	for i, v := range s.source.GetMetrics() {
-		keys = append(keys, fmt.Sprintf("source_%s_0_%s", s.source.GetName(), metric.MetricNames[i]))
-		values = append(values, v)
+	if ss, ok := s.source.(node.MergeableTopo); ok {
+		keys, values = ss.SubMetrics()
+	} else {
+		for i, v := range s.source.GetMetrics() {
+			keys = append(keys, fmt.Sprintf("source_%s_0_%s", s.source.GetName(), metric.MetricNames[i]))
+			values = append(values, v)
+		}
 	}
 	for _, so := range s.ops {
 		for i, v := range so.GetMetrics() {
@@ -184,15 +209,11 @@ func (s *SrcSubTopo) Close(ctx api.StreamContext, ruleId string, runId int) {
 				s.cancel()
 			}
 			if ss, ok := s.source.(*SrcSubTopo); ok {
-				ss.Close(ctx, "$$subtopo_"+s.name, runId)
+				ss.Close(ctx, "$$subtopo_"+s.name, 0)
 			}
 			RemoveSubTopo(s.name)
 		}
-		for _, op := range s.ops {
-			if so, ok := op.(node.SchemaNode); ok {
-				so.DetachSchema(ctx, ruleId)
-			}
-		}
+		s.detachSchema(ctx, ruleId)
 	}
 	_ = s.RemoveOutput(fmt.Sprintf("%s.%d", ruleId, runId))
 }
@@ -208,7 +229,12 @@ func (s *SrcSubTopo) RemoveMetrics(ruleId string) {
 }
 
 func (s *SrcSubTopo) EnableCheckpoint(sources *[]checkpoint.StreamTask, ops *[]checkpoint.NonSourceTask) {
-	*sources = append(*sources, s.source.(checkpoint.StreamTask))
+	switch st := s.source.(type) {
+	case checkpoint.SourceSubTopoTask:
+		st.EnableCheckpoint(sources, ops)
+	case checkpoint.StreamTask:
+		*sources = append(*sources, st)
+	}
 	for _, op := range s.ops {
 		*ops = append(*ops, op)
 	}
@@ -16,6 +16,7 @@ package topo
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
@@ -56,8 +57,14 @@ func (s *SrcSubTopo) AddSrc(src node.DataSourceNode) *SrcSubTopo {
 
 // AddOperator adds an internal operator to the subtopo.
 func (s *SrcSubTopo) AddOperator(inputs []node.Emitter, operator node.OperatorNode) *SrcSubTopo {
+	ch, opName := operator.GetInput()
 	for _, input := range inputs {
-		input.AddOutput(operator.GetInput())
+		outputName := opName
+		if _, ok := input.(node.MergeableTopo); ok {
+			// Name it like a rule output so that it is removed when this sub topo closes the nested one
+			outputName = fmt.Sprintf("$$subtopo_%s.0_%s", s.name, opName)
+		}
+		_ = input.AddOutput(ch, outputName)
 		operator.AddInputCount()
 		switch rt := input.(type) {
 		case node.MergeableTopo:
@@ -88,18 +95,31 @@ func (s *SrcSubTopo) addEdge(from node.TopNode, to node.TopNode, toType string)
 }
 
 func (s *SrcSubTopo) MergeSrc(parentTopo *def.PrintableTopo) {
-	parentTopo.Sources = append(parentTopo.Sources, s.topo.Sources...)
+	for _, src := range s.topo.Sources {
+		if !slices.Contains(parentTopo.Sources, src) {
+			parentTopo.Sources = append(parentTopo.Sources, src)
+		}
+	}
 	for k, v := range s.topo.Edges {
 		parentTopo.Edges[k] = v
 	}
 }
 
 func (s *SrcSubTopo) LinkTopo(parentTopo *def.PrintableTopo, parentJointName string) {
+	parentTopo.Edges[s.tailName()] = []any{fmt.Sprintf("op_%s", parentJointName)}
+}
+
+// linkSink adds the printable link from the tail to a sink of the parent topo. Several sinks may link to the same tail.
+func (s *SrcSubTopo) linkSink(parentTopo *def.PrintableTopo, sinkName string) {
+	f := s.tailName()
+	parentTopo.Edges[f] = append(parentTopo.Edges[f], fmt.Sprintf("sink_%s", sinkName))
+}
+
+func (s *SrcSubTopo) tailName() string {
 	if _, ok := s.tail.(node.DataSourceNode); ok {
-		parentTopo.Edges[fmt.Sprintf("source_%s", s.tail.(node.TopNode).GetName())] = []any{fmt.Sprintf("op_%s", parentJointName)}
-	} else {
-		parentTopo.Edges[fmt.Sprintf("op_%s_%s", s.name, s.tail.(node.TopNode).GetName())] = []any{fmt.Sprintf("op_%s", parentJointName)}
+		return fmt.Sprintf("source_%s", s.tail.(node.TopNode).GetName())
 	}
+	return fmt.Sprintf("op_%s_%s", s.name, s.tail.(node.TopNode).GetName())
 }
 
 var _ node.MergeableTopo = &SrcSubTopo{}
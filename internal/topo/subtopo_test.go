@@ -118,6 +118,77 @@ func TestSubtopoLC(t *testing.T) {
 	assert.Equal(t, 0, opNode.schemaCount)
 }
 
+func TestSubtopoSharedPrefix(t *testing.T) {
+	assert.Equal(t, 0, mlen(&subTopoPool))
+	subTopo, _ := GetOrCreateSubTopo("shared")
+	srcNode := &mockSrc{name: "shared"}
+	opNode := &mockOp{name: "op1", ch: make(chan any)}
+	subTopo.AddSrc(srcNode)
+	subTopo.AddOperator([]node.Emitter{srcNode}, opNode)
+	subTopo.StoreSchema("rule1", "shared", map[string]*ast.JsonStreamField{
+		"field1": {Type: "string"},
+	}, false)
+	subTopo.StoreSchema("rule2", "shared", map[string]*ast.JsonStreamField{
+		"field2": {Type: "string"},
+	}, false)
+	// The prefix reads the shared stream
+	prefix, existed := GetOrCreateSubTopo("filter_1")
+	assert.False(t, existed)
+	filterNode := &mockOp{name: "filter", ch: make(chan any)}
+	prefix.AddSrc(subTopo)
+	prefix.AddOperator([]node.Emitter{subTopo}, filterNode)
+	assert.Equal(t, 1, len(opNode.outputs))
+	assert.Equal(t, &def.PrintableTopo{
+		Sources: []string{"source_shared"},
+		Edges: map[string][]any{
+			"source_shared": {"op_shared_op1"},
+			"op_shared_op1": {"op_filter_1_filter"},
+		},
+	}, prefix.topo)
+	ptopo := &def.PrintableTopo{
+		Sources: []string{"source_shared"},
+		Edges:   map[string][]any{},
+	}
+	prefix.MergeSrc(ptopo)
+	prefix.linkSink(ptopo, "log_0")
+	prefix.linkSink(ptopo, "log_1")
+	assert.Equal(t, &def.PrintableTopo{
+		Sources: []string{"source_shared"},
+		Edges: map[string][]any{
+			"source_shared":      {"op_shared_op1"},
+			"op_shared_op1":      {"op_filter_1_filter"},
+			"op_filter_1_filter": {"sink_log_0", "sink_log_1"},
+		},
+	}, ptopo)
+	// Run by two rules, the stream is referred by the prefix only
+	ctx1 := mockContext.NewMockContext("rule1", "abc")
+	prefix.Open(ctx1, make(chan error))
+	ctx2 := mockContext.NewMockContext("rule2", "abc")
+	prefix.Open(ctx2, make(chan error))
+	assert.Equal(t, int32(2), prefix.refCount.Load())
+	assert.Equal(t, int32(1), subTopo.refCount.Load())
+	assert.Equal(t, 2, opNode.schemaCount)
+	keys, _ := prefix.SubMetrics()
+	assert.Equal(t, 27, len(keys))
+	assert.Equal(t, "op_filter_1_filter_0_records_in_total", keys[18])
+	var (
+		sources []checkpoint.StreamTask
+		ops     []checkpoint.NonSourceTask
+	)
+	prefix.EnableCheckpoint(&sources, &ops)
+	assert.Equal(t, []checkpoint.StreamTask{srcNode}, sources)
+	assert.Equal(t, []checkpoint.NonSourceTask{opNode, filterNode}, ops)
+	// Stop
+	prefix.Close(ctx1, "rule1", 1)
+	assert.Equal(t, 2, mlen(&subTopoPool))
+	assert.Equal(t, 1, opNode.schemaCount)
+	prefix.Close(ctx2, "rule2", 2)
+	assert.Equal(t, int32(0), subTopo.refCount.Load())
+	assert.Equal(t, 0, mlen(&subTopoPool))
+	assert.Equal(t, 0, opNode.schemaCount)
+	assert.Equal(t, 0, len(opNode.outputs))
+}
+
 // Test when connection fails
 func TestSubtopoRunError(t *testing.T) {
 	assert.Equal(t, 0, mlen(&subTopoPool))
@@ -33,6 +33,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/topo/checkpoint"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/cache"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node/metric"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
@@ -59,6 +60,10 @@ type Topo struct {
 	topo        *def.PrintableTopo
 	mu          sync.Mutex
 	hasOpened   atomic.Bool
+	// stable ids of the stateful nodes by node name, used to migrate states when updating the rule
+	stateSigs map[string]string
+	// the states to restore when opening. It is only used once
+	savepoint state.Savepoint
 
 	opsWg *sync.WaitGroup
 }
@@ -73,7 +78,8 @@ func NewWithNameAndOptions(name string, options *def.RuleOption) (*Topo, error)
 			Sources: make([]string, 0),
 			Edges:   make(map[string][]interface{}),
 		},
-		opsWg: &sync.WaitGroup{},
+		opsWg:     &sync.WaitGroup{},
+		stateSigs: make(map[string]string),
 	}
 	tp.prepareContext() // ensure context is set
 	return tp, nil
@@ -145,8 +151,30 @@ func (s *Topo) AddSrc(src node.DataSourceNode) *Topo {
 	return s
 }
 
+// ReplaceSrc replaces the source with the sub topo which wraps it such as the shared operators upon a shared stream
+func (s *Topo) ReplaceSrc(old node.DataSourceNode, src node.DataSourceNode) *Topo {
+	for i, o := range s.sources {
+		if o == old {
+			s.sources[i] = src
+			if rt, ok := src.(node.MergeableTopo); ok {
+				rt.MergeSrc(s.topo)
+			}
+			break
+		}
+	}
+	return s
+}
+
 func (s *Topo) AddSink(inputs []node.Emitter, snk node.DataSinkNode) *Topo {
 	for _, input := range inputs {
+		if ss, ok := input.(*SrcSubTopo); ok {
+			// The sink reads the shared operators directly, add rule id so that it can be removed
+			ch, name := snk.GetInput()
+			_ = ss.AddOutput(ch, fmt.Sprintf("%s.%d_%s", s.name, s.runId, name))
+			snk.AddInputCount()
+			ss.linkSink(s.topo, snk.GetName())
+			continue
+		}
 		err := input.AddOutput(snk.GetInput())
 		if err != nil {
 			s.ctx.GetLogger().Error(err)
@@ -241,6 +269,12 @@ func (s *Topo) prepareContext() {
 		ctx := kctx.WithValue(kctx.RuleBackground(s.name), kctx.LoggerKey, contextLogger)
 		ctx = kctx.WithValue(ctx, kctx.RuleStartKey, timex.GetNowInMilli())
 		ctx = kctx.WithValue(ctx, kctx.RuleWaitGroupKey, s.opsWg)
+		if s.options != nil && s.options.MemoryBudget > 0 {
+			ctx = kctx.WithValue(ctx, kctx.RuleMemoryKey, cache.NewMemoryBudget(s.name, s.options.MemoryBudget))
+		}
+		if s.options != nil && s.options.Backpressure {
+			ctx = kctx.WithValue(ctx, kctx.RuleFlowKey, node.NewFlowControl(s.name))
+		}
 		s.ctx, s.cancel = ctx.WithCancel()
 	}
 }
@@ -277,6 +311,10 @@ func (s *Topo) Open() <-chan error {
 			return err
 		}
 		topoStore := s.store
+		if s.savepoint != nil {
+			topoStore = state.WithSavepoint(s.store, s.restoredStates())
+			s.savepoint = nil
+		}
 		// open stream sink, after log sink is ready.
 		for _, snk := range s.sinks {
 			snk.Exec(s.ctx.WithMeta(s.name, snk.GetName(), topoStore), s.drain)
@@ -405,9 +443,54 @@ func (s *Topo) GetMetrics() (keys []string, values []any) {
 			values = append(values, v)
 		}
 	}
+	if s.ctx != nil {
+		fkeys, fvalues := node.GetFlowControl(s.ctx).GetMetrics()
+		keys = append(keys, fkeys...)
+		values = append(values, fvalues...)
+	}
 	return
 }
 
+// GetAnalyzeStats returns the live statistics of all the nodes for explain analyze.
+// The cost ratio of each node is its share of the total processing time of the rule.
+func (s *Topo) GetAnalyzeStats() []*metric.AnalyzeStats {
+	var result []*metric.AnalyzeStats
+	for _, sn := range s.sources {
+		result = appendAnalyzeStats(result, sn)
+	}
+	for _, so := range s.ops {
+		result = appendAnalyzeStats(result, so)
+	}
+	for _, sn := range s.sinks {
+		result = appendAnalyzeStats(result, sn)
+	}
+	var total int64
+	for _, st := range result {
+		total += st.CostUs
+	}
+	if total > 0 {
+		for _, st := range result {
+			st.CostRatio = float64(st.CostUs) / float64(total)
+		}
+	}
+	return result
+}
+
+func appendAnalyzeStats(result []*metric.AnalyzeStats, n node.TopNode) []*metric.AnalyzeStats {
+	switch nt := n.(type) {
+	case *SrcSubTopo:
+		result = appendAnalyzeStats(result, nt.source)
+		for _, op := range nt.ops {
+			result = appendAnalyzeStats(result, op)
+		}
+	case node.AnalyzeNode:
+		if st := nt.GetAnalyzeStats(); st != nil {
+			result = append(result, st)
+		}
+	}
+	return result
+}
+
 func (s *Topo) RemoveMetrics() {
 	conf.Log.Infof("start removing %v metrics", s.name)
 	for _, sn := range s.sources {
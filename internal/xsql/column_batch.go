@@ -0,0 +1,309 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+)
+
+// ColumnBatch is a micro batch of rows from the same emitter which is stored by columns.
+// It is used in the batch execution mode to exchange many rows between operators in one hop.
+// The columns are immutable after the batch is sent out. Operators like filter only change the
+// selection vector and operators like project produce a new batch.
+type ColumnBatch struct {
+	Ctx     api.StreamContext
+	Emitter string
+	// Columns are the values of each column. All columns have the same length as the total rows.
+	// A nil value means the column is absent or null in that row unless it is marked in nulls.
+	Columns    map[string][]any
+	Timestamps []time.Time
+	Metadata   []Metadata
+	// KeepNil is the columns to keep the nil values when converting to rows
+	KeepNil map[string]bool
+	// nulls marks the rows whose column is present with a nil value. Only the columns having nil values are recorded.
+	nulls map[string][]bool
+	// sel is the selection vector of the row indexes. Nil means all rows are selected.
+	sel  []int
+	size int
+}
+
+// NewColumnBatch creates an empty batch with the capacity of rows
+func NewColumnBatch(emitter string, capacity int) *ColumnBatch {
+	return &ColumnBatch{
+		Emitter:    emitter,
+		Columns:    make(map[string][]any),
+		Timestamps: make([]time.Time, 0, capacity),
+		Metadata:   make([]Metadata, 0, capacity),
+	}
+}
+
+// Append adds a tuple as a new row. It should only be called when building the batch.
+func (b *ColumnBatch) Append(t *Tuple) {
+	for k, v := range t.Message {
+		col, ok := b.Columns[k]
+		if !ok {
+			col = make([]any, b.size, cap(b.Timestamps))
+		}
+		b.Columns[k] = append(col, v)
+		if v == nil {
+			b.markNull(k, b.size)
+		}
+	}
+	b.size++
+	for k, col := range b.Columns {
+		if len(col) < b.size {
+			b.Columns[k] = append(col, nil)
+		}
+	}
+	if b.Ctx == nil {
+		b.Ctx = t.Ctx
+	}
+	b.Timestamps = append(b.Timestamps, t.Timestamp)
+	b.Metadata = append(b.Metadata, t.Metadata)
+}
+
+func (b *ColumnBatch) markNull(k string, idx int) {
+	if b.nulls == nil {
+		b.nulls = make(map[string][]bool)
+	}
+	flags, ok := b.nulls[k]
+	if !ok {
+		flags = make([]bool, cap(b.Timestamps))
+	}
+	if idx >= len(flags) {
+		flags = append(flags, make([]bool, idx-len(flags)+1)...)
+	}
+	flags[idx] = true
+	b.nulls[k] = flags
+}
+
+func (b *ColumnBatch) isNull(k string, idx int) bool {
+	flags := b.nulls[k]
+	return idx < len(flags) && flags[idx]
+}
+
+// Len returns the selected rows count
+func (b *ColumnBatch) Len() int {
+	if b.sel != nil {
+		return len(b.sel)
+	}
+	return b.size
+}
+
+// index returns the physical row index of the ith selected row
+func (b *ColumnBatch) index(i int) int {
+	if b.sel != nil {
+		return b.sel[i]
+	}
+	return i
+}
+
+// Filter returns a new batch sharing the columns which only selects the rows of the indexes.
+// The indexes are positions of the selected rows of this batch.
+func (b *ColumnBatch) Filter(indexes []int) *ColumnBatch {
+	nb := *b
+	nb.sel = make([]int, len(indexes))
+	for i, idx := range indexes {
+		nb.sel[i] = b.index(idx)
+	}
+	return &nb
+}
+
+// Derive returns a new batch of the selected rows with the new columns. Each column must have the length of the selected rows.
+// The nulls mark the selected rows whose column is present with a nil value, see Nulls.
+func (b *ColumnBatch) Derive(columns map[string][]any, nulls map[string][]bool, keepNil map[string]bool) *ColumnBatch {
+	nb := &ColumnBatch{
+		Ctx:        b.Ctx,
+		Emitter:    b.Emitter,
+		Columns:    columns,
+		Timestamps: b.Timestamps,
+		Metadata:   b.Metadata,
+		KeepNil:    keepNil,
+		nulls:      nulls,
+		size:       b.Len(),
+	}
+	if b.sel != nil {
+		nb.Timestamps = make([]time.Time, len(b.sel))
+		nb.Metadata = make([]Metadata, len(b.sel))
+		for i, idx := range b.sel {
+			nb.Timestamps[i] = b.Timestamps[idx]
+			nb.Metadata[i] = b.Metadata[idx]
+		}
+	}
+	return nb
+}
+
+// columnName finds the column name in the batch which may be in different case
+func (b *ColumnBatch) columnName(name string) (string, bool) {
+	if _, ok := b.Columns[name]; ok {
+		return name, true
+	}
+	if conf.Config == nil || conf.Config.Basic.IgnoreCase {
+		for k := range b.Columns {
+			if strings.EqualFold(k, name) {
+				return k, true
+			}
+		}
+	}
+	return name, false
+}
+
+// Column returns the values of the selected rows for the column
+func (b *ColumnBatch) Column(name string) ([]any, bool) {
+	name, ok := b.columnName(name)
+	if !ok {
+		return nil, false
+	}
+	col := b.Columns[name]
+	if b.sel == nil {
+		return col, true
+	}
+	result := make([]any, len(b.sel))
+	for i, idx := range b.sel {
+		result[i] = col[idx]
+	}
+	return result, true
+}
+
+// Nulls returns the flags of the selected rows whose column is present with a nil value.
+// It returns nil if the column has no nil value.
+func (b *ColumnBatch) Nulls(name string) []bool {
+	name, _ = b.columnName(name)
+	flags, ok := b.nulls[name]
+	if !ok {
+		return nil
+	}
+	result := make([]bool, b.Len())
+	for i := range result {
+		if idx := b.index(i); idx < len(flags) {
+			result[i] = flags[idx]
+		}
+	}
+	return result
+}
+
+// Range iterates the selected rows. The row passed to the function is reused and only valid in the call.
+func (b *ColumnBatch) Range(f func(i int, r ReadonlyRow) (bool, error)) error {
+	r := &BatchRow{batch: b}
+	for i := 0; i < b.Len(); i++ {
+		r.reset(b.index(i))
+		if next, err := f(i, r); err != nil || !next {
+			return err
+		}
+	}
+	return nil
+}
+
+// Row converts the ith selected row to a tuple
+func (b *ColumnBatch) Row(i int) *Tuple {
+	idx := b.index(i)
+	return &Tuple{
+		Ctx:       b.Ctx,
+		Emitter:   b.Emitter,
+		Message:   b.message(idx),
+		Timestamp: b.Timestamps[idx],
+		Metadata:  b.Metadata[idx],
+	}
+}
+
+func (b *ColumnBatch) message(idx int) Message {
+	m := make(Message, len(b.Columns))
+	for k, col := range b.Columns {
+		if v := col[idx]; v != nil || b.KeepNil[k] || b.isNull(k, idx) {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// ToRows converts the selected rows to tuples
+func (b *ColumnBatch) ToRows() []Row {
+	result := make([]Row, b.Len())
+	for i := range result {
+		result[i] = b.Row(i)
+	}
+	return result
+}
+
+// BatchRow is a read only view of a row in the batch. It avoids to convert the columns to a map for evaluation.
+type BatchRow struct {
+	batch *ColumnBatch
+	idx   int
+	alias map[string]any
+}
+
+func (r *BatchRow) reset(idx int) {
+	r.idx = idx
+	if len(r.alias) > 0 {
+		clear(r.alias)
+	}
+}
+
+func (r *BatchRow) Value(key, _ string) (any, bool) {
+	if v, ok := r.alias[key]; ok {
+		return v, ok
+	}
+	if col, ok := r.batch.Columns[key]; ok {
+		v := col[r.idx]
+		return v, v != nil || r.batch.isNull(key, r.idx)
+	}
+	if conf.Config == nil || conf.Config.Basic.IgnoreCase {
+		for k, col := range r.batch.Columns {
+			if strings.EqualFold(k, key) {
+				v := col[r.idx]
+				return v, v != nil || r.batch.isNull(k, r.idx)
+			}
+		}
+	}
+	return nil, false
+}
+
+func (r *BatchRow) Meta(key, table string) (any, bool) {
+	md := r.batch.Metadata[r.idx]
+	if key == "*" {
+		return map[string]any(md), true
+	}
+	return md.Value(key, table)
+}
+
+func (r *BatchRow) AliasValue(name string) (any, bool) {
+	v, ok := r.alias[name]
+	return v, ok
+}
+
+func (r *BatchRow) AppendAlias(key string, value any) bool {
+	if r.alias == nil {
+		r.alias = make(map[string]any)
+	}
+	r.alias[key] = value
+	return true
+}
+
+func (r *BatchRow) All(_ string) (map[string]any, bool) {
+	return r.batch.message(r.idx), true
+}
+
+func (r *BatchRow) GetTracerCtx() api.StreamContext {
+	return nil
+}
+
+func (r *BatchRow) SetTracerCtx(_ api.StreamContext) {}
+
+var _ ReadonlyRow = &BatchRow{}
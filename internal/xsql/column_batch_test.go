@@ -0,0 +1,97 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnBatch(t *testing.T) {
+	ts := time.UnixMilli(1000)
+	b := NewColumnBatch("src", 4)
+	b.Append(&Tuple{Emitter: "src", Message: Message{"a": int64(1), "b": "x"}, Timestamp: ts, Metadata: Metadata{"topic": "t1"}})
+	b.Append(&Tuple{Emitter: "src", Message: Message{"a": int64(2)}, Timestamp: ts.Add(time.Second), Metadata: Metadata{"topic": "t2"}})
+	b.Append(&Tuple{Emitter: "src", Message: Message{"a": int64(3), "c": true}, Timestamp: ts.Add(2 * time.Second)})
+	require.Equal(t, 3, b.Len())
+	assert.Equal(t, map[string][]any{
+		"a": {int64(1), int64(2), int64(3)},
+		"b": {"x", nil, nil},
+		"c": {nil, nil, true},
+	}, b.Columns)
+
+	// Filter keeps the selected rows only
+	fb := b.Filter([]int{0, 2})
+	require.Equal(t, 2, fb.Len())
+	col, ok := fb.Column("a")
+	require.True(t, ok)
+	assert.Equal(t, []any{int64(1), int64(3)}, col)
+	_, ok = fb.Column("d")
+	assert.False(t, ok)
+	fb2 := fb.Filter([]int{1})
+	assert.Equal(t, &Tuple{Emitter: "src", Message: Message{"a": int64(3), "c": true}, Timestamp: ts.Add(2 * time.Second)}, fb2.Row(0))
+	assert.Equal(t, 3, b.Len())
+
+	// Range with the row view
+	var values []any
+	err := fb.Range(func(i int, r ReadonlyRow) (bool, error) {
+		v, _ := r.Value("a", "")
+		values = append(values, v)
+		_, ok := r.Value("b", "")
+		assert.Equal(t, i == 0, ok)
+		m, _ := r.Meta("topic", "")
+		assert.Equal(t, i == 0, m == "t1")
+		_, ok = r.AliasValue("x")
+		assert.False(t, ok)
+		r.AppendAlias("x", i)
+		v, _ = r.Value("x", "")
+		assert.Equal(t, i, v)
+		all, _ := r.All("")
+		_, ok = all["c"]
+		assert.Equal(t, i == 1, ok)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(3)}, values)
+
+	// Derive a new batch with the selected rows
+	db := fb.Derive(map[string][]any{"d": {nil, "y"}, "e": {nil, nil}}, nil, map[string]bool{"d": true})
+	assert.Equal(t, []Row{
+		&Tuple{Emitter: "src", Message: Message{"d": nil}, Timestamp: ts, Metadata: Metadata{"topic": "t1"}},
+		&Tuple{Emitter: "src", Message: Message{"d": "y"}, Timestamp: ts.Add(2 * time.Second)},
+	}, db.ToRows())
+	// The present nil values are kept while the absent ones are not
+	nb := NewColumnBatch("src", 2)
+	nb.Append(&Tuple{Emitter: "src", Message: Message{"a": nil}, Timestamp: ts})
+	nb.Append(&Tuple{Emitter: "src", Message: Message{"b": int64(1)}, Timestamp: ts})
+	assert.Equal(t, []bool{true, false}, nb.Nulls("a"))
+	assert.Nil(t, nb.Nulls("b"))
+	err = nb.Range(func(i int, r ReadonlyRow) (bool, error) {
+		_, ok := r.Value("a", "")
+		assert.Equal(t, i == 0, ok)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Row{
+		&Tuple{Emitter: "src", Message: Message{"a": nil}, Timestamp: ts},
+		&Tuple{Emitter: "src", Message: Message{"b": int64(1)}, Timestamp: ts},
+	}, nb.ToRows())
+	assert.Equal(t, []Row{
+		&Tuple{Emitter: "src", Message: Message{"b": int64(1)}, Timestamp: ts},
+	}, nb.Filter([]int{1}).ToRows())
+}
@@ -0,0 +1,291 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// CompiledExpr is the expression compiled into a closure. It returns the same result as ValuerEval.Eval.
+type CompiledExpr func(ve *ValuerEval) any
+
+type valueKind int
+
+const (
+	kindUnknown valueKind = iota
+	kindInt
+	kindFloat
+	kindString
+	kindBool
+)
+
+// CompileExpr compiles the expression into typed closures by the stream schema to save the type switches in the
+// evaluation. It only supports the comparison, arithmetic and logical operations on the fields and literals. If any
+// part of the expression is not supported or the type of a field is unknown, nil is returned and the expression
+// should be evaluated by ValuerEval. In runtime, if the value type does not match the schema, the compiled closure
+// falls back to ValuerEval too.
+func CompileExpr(expr ast.Expr, schema map[string]*ast.JsonStreamField) CompiledExpr {
+	if expr == nil || len(schema) == 0 {
+		return nil
+	}
+	c, _, ok := compileExpr(expr, schema)
+	if !ok {
+		return nil
+	}
+	return c
+}
+
+func compileExpr(expr ast.Expr, schema map[string]*ast.JsonStreamField) (CompiledExpr, valueKind, bool) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		v := e.Val
+		return func(_ *ValuerEval) any { return v }, kindInt, true
+	case *ast.NumberLiteral:
+		v := e.Val
+		return func(_ *ValuerEval) any { return v }, kindFloat, true
+	case *ast.StringLiteral:
+		v := e.Val
+		return func(_ *ValuerEval) any { return v }, kindString, true
+	case *ast.BooleanLiteral:
+		v := e.Val
+		return func(_ *ValuerEval) any { return v }, kindBool, true
+	case *ast.ParenExpr:
+		return compileExpr(e.Expr, schema)
+	case *ast.FieldRef:
+		return compileField(e, schema)
+	case *ast.BinaryExpr:
+		return compileBinary(e, schema)
+	default:
+		return nil, kindUnknown, false
+	}
+}
+
+func compileField(f *ast.FieldRef, schema map[string]*ast.JsonStreamField) (CompiledExpr, valueKind, bool) {
+	if f.IsAlias() || f.Name == "" {
+		return nil, kindUnknown, false
+	}
+	sf, ok := schema[f.Name]
+	if !ok || sf == nil {
+		return nil, kindUnknown, false
+	}
+	var k valueKind
+	switch sf.Type {
+	case "bigint":
+		k = kindInt
+	case "float":
+		k = kindFloat
+	case "string":
+		k = kindString
+	case "boolean":
+		k = kindBool
+	default:
+		return nil, kindUnknown, false
+	}
+	n := f.Name
+	t := ""
+	if f.StreamName != ast.DefaultStream {
+		t = string(f.StreamName)
+	}
+	return func(ve *ValuerEval) any {
+		v, _ := ve.Valuer.Value(n, t)
+		return v
+	}, k, true
+}
+
+func compileBinary(e *ast.BinaryExpr, schema map[string]*ast.JsonStreamField) (CompiledExpr, valueKind, bool) {
+	l, lk, ok := compileExpr(e.LHS, schema)
+	if !ok {
+		return nil, kindUnknown, false
+	}
+	r, rk, ok := compileExpr(e.RHS, schema)
+	if !ok {
+		return nil, kindUnknown, false
+	}
+	isNum := func(k valueKind) bool { return k == kindInt || k == kindFloat }
+	op := e.OP
+	switch op {
+	case ast.AND, ast.OR:
+		if lk != kindBool || rk != kindBool {
+			return nil, kindUnknown, false
+		}
+		return compileLogical(e, l, r), kindBool, true
+	case ast.EQ, ast.NEQ, ast.LT, ast.LTE, ast.GT, ast.GTE:
+		switch {
+		case isNum(lk) && isNum(rk):
+			return compileNumeric(e, l, r), kindBool, true
+		case lk == kindString && rk == kindString:
+			return compileString(e, l, r), kindBool, true
+		case lk == kindBool && rk == kindBool && (op == ast.EQ || op == ast.NEQ):
+			return compileBoolCompare(e, l, r), kindBool, true
+		}
+	case ast.ADD, ast.SUB, ast.MUL:
+		if isNum(lk) && isNum(rk) {
+			k := kindFloat
+			if lk == kindInt && rk == kindInt {
+				k = kindInt
+			}
+			return compileNumeric(e, l, r), k, true
+		}
+	}
+	return nil, kindUnknown, false
+}
+
+func compileLogical(e *ast.BinaryExpr, l, r CompiledExpr) CompiledExpr {
+	isAnd := e.OP == ast.AND
+	return func(ve *ValuerEval) any {
+		lb, ok := l(ve).(bool)
+		if !ok {
+			return ve.Eval(e)
+		}
+		if isAnd && !lb {
+			return false
+		}
+		if !isAnd && lb {
+			return true
+		}
+		if rb, ok := r(ve).(bool); ok {
+			return rb
+		}
+		return ve.Eval(e)
+	}
+}
+
+func compileNumeric(e *ast.BinaryExpr, l, r CompiledExpr) CompiledExpr {
+	op := e.OP
+	return func(ve *ValuerEval) any {
+		lv, rv := l(ve), r(ve)
+		switch lt := lv.(type) {
+		case int64:
+			switch rt := rv.(type) {
+			case int64:
+				return intOp(op, lt, rt)
+			case float64:
+				return floatOp(op, float64(lt), rt)
+			}
+		case float64:
+			switch rt := rv.(type) {
+			case int64:
+				return floatOp(op, lt, float64(rt))
+			case float64:
+				return floatOp(op, lt, rt)
+			}
+		}
+		if lv == nil || rv == nil {
+			return nilResult(op)
+		}
+		return ve.Eval(e)
+	}
+}
+
+func compileString(e *ast.BinaryExpr, l, r CompiledExpr) CompiledExpr {
+	op := e.OP
+	return func(ve *ValuerEval) any {
+		lv, rv := l(ve), r(ve)
+		ls, lok := lv.(string)
+		rs, rok := rv.(string)
+		if lok && rok {
+			switch op {
+			case ast.EQ:
+				return ls == rs
+			case ast.NEQ:
+				return ls != rs
+			case ast.LT:
+				return ls < rs
+			case ast.LTE:
+				return ls <= rs
+			case ast.GT:
+				return ls > rs
+			case ast.GTE:
+				return ls >= rs
+			}
+		}
+		if lv == nil || rv == nil {
+			return nilResult(op)
+		}
+		return ve.Eval(e)
+	}
+}
+
+func compileBoolCompare(e *ast.BinaryExpr, l, r CompiledExpr) CompiledExpr {
+	isEq := e.OP == ast.EQ
+	return func(ve *ValuerEval) any {
+		lv, rv := l(ve), r(ve)
+		lb, lok := lv.(bool)
+		rb, rok := rv.(bool)
+		if lok && rok {
+			return (lb == rb) == isEq
+		}
+		if lv == nil || rv == nil {
+			return false
+		}
+		return ve.Eval(e)
+	}
+}
+
+// nilResult is the same as ValuerEval for nil operands: false for comparison and nil for arithmetic
+func nilResult(op ast.Token) any {
+	switch op {
+	case ast.EQ, ast.NEQ, ast.LT, ast.LTE, ast.GT, ast.GTE:
+		return false
+	default:
+		return nil
+	}
+}
+
+func intOp(op ast.Token, l, r int64) any {
+	switch op {
+	case ast.EQ:
+		return l == r
+	case ast.NEQ:
+		return l != r
+	case ast.LT:
+		return l < r
+	case ast.LTE:
+		return l <= r
+	case ast.GT:
+		return l > r
+	case ast.GTE:
+		return l >= r
+	case ast.ADD:
+		return l + r
+	case ast.SUB:
+		return l - r
+	default: // ast.MUL
+		return l * r
+	}
+}
+
+func floatOp(op ast.Token, l, r float64) any {
+	switch op {
+	case ast.EQ:
+		return l == r
+	case ast.NEQ:
+		return l != r
+	case ast.LT:
+		return l < r
+	case ast.LTE:
+		return l <= r
+	case ast.GT:
+		return l > r
+	case ast.GTE:
+		return l >= r
+	case ast.ADD:
+		return l + r
+	case ast.SUB:
+		return l - r
+	default: // ast.MUL
+		return l * r
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const benchCondition = "SELECT * FROM src WHERE a > 10 AND b * 2 < 100.5 AND c = \"hello\""
+
+func BenchmarkInterpretedFilter(b *testing.B) {
+	ve, cond := prepareBenchFilter(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ve.Eval(cond)
+	}
+}
+
+func BenchmarkCompiledFilter(b *testing.B) {
+	ve, cond := prepareBenchFilter(b)
+	c := CompileExpr(cond, compileSchema)
+	if c == nil {
+		b.Fatal("condition is not compiled")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = c(ve)
+	}
+}
+
+func prepareBenchFilter(b *testing.B) (*ValuerEval, ast.Expr) {
+	stmt, err := NewParser(strings.NewReader(benchCondition)).Parse()
+	if err != nil {
+		b.Fatal(err)
+	}
+	tuple := &Tuple{Emitter: "src", Message: Message{"a": int64(20), "b": 30.5, "c": "hello", "d": true}}
+	return &ValuerEval{Valuer: MultiValuer(tuple)}, stmt.Condition
+}
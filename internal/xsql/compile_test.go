@@ -0,0 +1,87 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+var compileSchema = map[string]*ast.JsonStreamField{
+	"a": {Type: "bigint"},
+	"b": {Type: "float"},
+	"c": {Type: "string"},
+	"d": {Type: "boolean"},
+	"e": {Type: "array", Items: &ast.JsonStreamField{Type: "bigint"}},
+}
+
+func TestCompileExpr(t *testing.T) {
+	tests := []struct {
+		sql      string
+		compiled bool
+	}{
+		{sql: "a > 10", compiled: true},
+		{sql: "a + 2 * b >= 30.5", compiled: true},
+		{sql: "a - 1 = 9 AND c = \"hello\"", compiled: true},
+		{sql: "c < \"z\" OR d", compiled: true},
+		{sql: "d = true", compiled: true},
+		{sql: "d != (a > 3)", compiled: true},
+		{sql: "b <= a", compiled: true},
+		{sql: "a / 2 > 1", compiled: false},
+		{sql: "c + \"a\" = \"b\"", compiled: false},
+		{sql: "d > true", compiled: false},
+		{sql: "abs(a) > 1", compiled: false},
+		{sql: "e[0] > 1", compiled: false},
+		{sql: "f > 1", compiled: false},
+		{sql: "a > 1 AND c", compiled: false},
+	}
+	rows := []Message{
+		{"a": int64(10), "b": 20.5, "c": "hello", "d": true},
+		{"a": int64(11), "b": float64(1), "c": "world", "d": false},
+		{"a": 3.5, "b": int64(30), "c": "z", "d": true},
+		{"a": nil, "c": nil},
+		{},
+		// type mismatch with the schema falls back to the interpreter
+		{"a": "10", "b": true, "c": 1, "d": "true"},
+		{"a": 11, "b": float32(2), "c": "a", "d": 1},
+	}
+	for _, tt := range tests {
+		stmt, err := NewParser(strings.NewReader("SELECT * FROM src WHERE " + tt.sql)).Parse()
+		require.NoError(t, err, tt.sql)
+		c := CompileExpr(stmt.Condition, compileSchema)
+		if !tt.compiled {
+			assert.Nil(t, c, tt.sql)
+			continue
+		}
+		require.NotNil(t, c, tt.sql)
+		for i, m := range rows {
+			tuple := &Tuple{Emitter: "src", Message: m}
+			ve := &ValuerEval{Valuer: MultiValuer(tuple)}
+			assert.Equal(t, ve.Eval(stmt.Condition), c(ve), "%s with row %d", tt.sql, i)
+		}
+	}
+}
+
+func TestCompileExprNoSchema(t *testing.T) {
+	stmt, err := NewParser(strings.NewReader("SELECT * FROM src WHERE a > 1")).Parse()
+	require.NoError(t, err)
+	assert.Nil(t, CompileExpr(stmt.Condition, nil))
+	assert.Nil(t, CompileExpr(nil, compileSchema))
+}
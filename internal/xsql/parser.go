@@ -1542,6 +1542,12 @@ func (p *Parser) parseStreamOptions() (*ast.Options, error) {
 							} else {
 								opts.RETAIN_SIZE = val
 							}
+						case ast.INFER_SAMPLES:
+							if val, err := strconv.Atoi(lit3); err != nil || val <= 0 {
+								return nil, fmt.Errorf("found %q, expect positive number value in %s option.", lit3, lit1)
+							} else {
+								opts.INFER_SAMPLES = val
+							}
 						case ast.SHARED:
 							if val := strings.ToUpper(lit3); (val != "TRUE") && (val != "FALSE") {
 								return nil, fmt.Errorf("found %q, expect TRUE/FALSE value in %s option.", lit3, lit1)
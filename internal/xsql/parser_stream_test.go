@@ -448,6 +448,23 @@ func TestParser_ParseCreateStream(t *testing.T) {
 				},
 			},
 		},
+		{
+			s: `CREATE STREAM demo () WITH (DATASOURCE="users", FORMAT="JSON", INFER_SAMPLES="20");`,
+			stmt: &ast.StreamStmt{
+				Name:         ast.StreamName("demo"),
+				StreamFields: nil,
+				Options: &ast.Options{
+					DATASOURCE:    "users",
+					FORMAT:        "JSON",
+					INFER_SAMPLES: 20,
+				},
+			},
+		},
+		{
+			s:    `CREATE STREAM demo () WITH (DATASOURCE="users", FORMAT="JSON", INFER_SAMPLES="0");`,
+			stmt: nil,
+			err:  `found "0", expect positive number value in INFER_SAMPLES option.`,
+		},
 	}
 
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
@@ -460,3 +477,60 @@ func TestParser_ParseCreateStream(t *testing.T) {
 		}
 	}
 }
+
+func TestReplaceStreamFields(t *testing.T) {
+	fields := ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "device name", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "tags", FieldType: &ast.ArrayType{Type: ast.STRINGS}},
+		{Name: "points", FieldType: &ast.ArrayType{Type: ast.ARRAY, FieldType: &ast.ArrayType{Type: ast.FLOAT}}},
+		{Name: "meta", FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+			{Name: "ok", FieldType: &ast.BasicType{Type: ast.BOOLEAN}},
+			{Name: "items", FieldType: &ast.ArrayType{Type: ast.STRUCT, FieldType: &ast.RecType{StreamFields: ast.StreamFields{
+				{Name: "ts", FieldType: &ast.BasicType{Type: ast.DATETIME}},
+			}}}},
+		}}},
+	}
+	tests := []struct {
+		s      string
+		fields ast.StreamFields
+		exp    string
+		err    string
+	}{
+		{
+			s:      `CREATE STREAM demo () WITH (DATASOURCE="a(b)", FORMAT="JSON")`,
+			fields: fields,
+			exp:    "CREATE STREAM demo (`id` BIGINT, `device name` STRING, `tags` ARRAY(STRING), `points` ARRAY(ARRAY(FLOAT)), `meta` STRUCT(`ok` BOOLEAN, `items` ARRAY(STRUCT(`ts` DATETIME)))) WITH (DATASOURCE=\"a(b)\", FORMAT=\"JSON\")",
+		},
+		{
+			s:   "CREATE STREAM `de(mo` (a STRUCT(b BIGINT), c FLOAT) WITH (DATASOURCE=\"demo\")",
+			exp: "CREATE STREAM `de(mo` () WITH (DATASOURCE=\"demo\")",
+		},
+		{
+			s:   "SHOW STREAMS",
+			err: "cannot find the field definitions in statement SHOW STREAMS",
+		},
+	}
+	for i, tt := range tests {
+		got, err := ReplaceStreamFields(tt.s, tt.fields)
+		if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
+			t.Errorf("%d. error mismatch:\n  exp=%s\n  got=%s", i, tt.err, err)
+			continue
+		}
+		if tt.err != "" {
+			continue
+		}
+		if got != tt.exp {
+			t.Errorf("%d. statement mismatch:\n  exp=%s\n  got=%s", i, tt.exp, got)
+			continue
+		}
+		stmt, err := NewParser(strings.NewReader(got)).ParseCreateStmt()
+		if err != nil {
+			t.Errorf("%d. parse %s error: %v", i, got, err)
+			continue
+		}
+		if fields := stmt.(*ast.StreamStmt).StreamFields; !reflect.DeepEqual(tt.fields, fields) {
+			t.Errorf("%d. fields mismatch:\n  exp=%#v\n  got=%#v", i, tt.fields, fields)
+		}
+	}
+}
@@ -194,7 +194,9 @@ func (d *AffiliateRow) MergeMap(cachedMap map[string]interface{}) {
 		}
 	}
 	for k, v := range d.AliasMap {
-		cachedMap[k] = v
+		if !strings.HasPrefix(k, "$$") {
+			cachedMap[k] = v
+		}
 	}
 }
 
@@ -94,3 +94,77 @@ func GetDataSource(m kv.KeyValue, name string) (stmt *ast.StreamStmt, err error)
 	}
 	return
 }
+
+// ReplaceStreamFields rewrites the field definitions of a create stream/table statement.
+// The other parts of the statement such as the options are kept as is.
+func ReplaceStreamFields(statement string, fields ast.StreamFields) (string, error) {
+	start, end := -1, -1
+	depth := 0
+	var quote rune
+	escaped := false
+	for i, ch := range statement {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+			} else if ch == '\\' {
+				escaped = true
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = ch
+		case '(':
+			if start < 0 {
+				start = i
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && start >= 0 {
+				end = i + 1
+			}
+		}
+		if end > 0 {
+			break
+		}
+	}
+	if start < 0 || end < 0 {
+		return "", fmt.Errorf("cannot find the field definitions in statement %s", statement)
+	}
+	return statement[:start] + "(" + PrintStreamFields(fields) + ")" + statement[end:], nil
+}
+
+// PrintStreamFields prints the stream fields in the syntax of the create stream statement
+func PrintStreamFields(fields ast.StreamFields) string {
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("`" + f.Name + "` ")
+		printStreamFieldType(&b, f.FieldType)
+	}
+	return b.String()
+}
+
+func printStreamFieldType(b *strings.Builder, ft ast.FieldType) {
+	switch t := ft.(type) {
+	case *ast.BasicType:
+		b.WriteString(strings.ToUpper(t.Type.String()))
+	case *ast.ArrayType:
+		b.WriteString("ARRAY(")
+		if t.FieldType != nil {
+			printStreamFieldType(b, t.FieldType)
+		} else {
+			b.WriteString(strings.ToUpper(t.Type.String()))
+		}
+		b.WriteString(")")
+	case *ast.RecType:
+		b.WriteString("STRUCT(")
+		b.WriteString(PrintStreamFields(t.StreamFields))
+		b.WriteString(")")
+	}
+}
@@ -63,6 +63,7 @@ var (
 
 func init() {
 	RegisterSyncCache()
+	RegisterSpill()
 	prometheus.MustRegister(RuleStatusCountGauge)
 	prometheus.MustRegister(RuleStatusGauge)
 	prometheus.MustRegister(RuleCPUUsageGauge)
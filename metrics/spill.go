@@ -0,0 +1,46 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	SpillCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kuiper",
+		Subsystem: "spill",
+		Name:      "counter",
+		Help:      "counter of spill",
+	}, []string{LblType, LblRuleIDType, LblOpIDType})
+
+	SpillGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kuiper",
+		Subsystem: "spill",
+		Name:      "gauge",
+		Help:      "gauge of spill",
+	}, []string{LblType, LblRuleIDType, LblOpIDType})
+
+	RuleMemoryGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kuiper",
+		Subsystem: "rule",
+		Name:      "memory_bytes",
+		Help:      "gauge of the estimated memory of the rows kept by the rule",
+	}, []string{LblRuleIDType})
+)
+
+func RegisterSpill() {
+	prometheus.MustRegister(SpillCounter)
+	prometheus.MustRegister(SpillGauge)
+	prometheus.MustRegister(RuleMemoryGauge)
+}
@@ -240,6 +240,8 @@ type Options struct {
 	KIND string `json:"kind,omitempty"`
 	// for delimited format only
 	DELIMITER string `json:"delimiter,omitempty"`
+	// the default number of messages to sample when inferring the schema
+	INFER_SAMPLES int `json:"inferSamples,omitempty"`
 
 	RuleID       string                      `json:"-"`
 	Schema       map[string]*JsonStreamField `json:"-"`
@@ -232,6 +232,7 @@ const (
 	SCHEMAID          = "SCHEMAID"
 	KIND              = "KIND"
 	DELIMITER         = "DELIMITER"
+	INFER_SAMPLES     = "INFER_SAMPLES"
 
 	XBIGINT   = "BIGINT"
 	XFLOAT    = "FLOAT"
@@ -257,6 +258,7 @@ var StreamTokens = map[string]struct{}{
 	SCHEMAID:          {},
 	KIND:              {},
 	DELIMITER:         {},
+	INFER_SAMPLES:     {},
 }
 
 var StreamDataTypes = map[string]DataType{
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/failpoint"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/secret"
 	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	"github.com/lf-edge/ekuiper/v2/pkg/modules"
@@ -255,6 +256,26 @@ func UpdateConnection(ctx api.StreamContext, id, typ string, props map[string]an
 	return createNamedConnection(ctx, id, typ, props)
 }
 
+// ReloadConnection closes the current connection instance and dials a new one with the same props.
+// It is used when the props content changes without changing the definition such as secret rotation.
+// The ref names are returned so that the callers can restart the referred rules.
+func ReloadConnection(ctx api.StreamContext, id string) ([]string, error) {
+	globalConnectionManager.Lock()
+	defer globalConnectionManager.Unlock()
+	meta, ok := globalConnectionManager.connectionPool[id]
+	if !ok {
+		return nil, fmt.Errorf("connection %s not existed", id)
+	}
+	if meta.cw.IsInitialized() {
+		conn, err := meta.cw.Wait(ctx)
+		if conn != nil && err == nil {
+			conn.Close(ctx)
+		}
+	}
+	meta.cw = newConnWrapper(ctx, meta)
+	return meta.GetRefNames(), nil
+}
+
 func isInternalConnection(id string) (bool, error) {
 	meta, ok := globalConnectionManager.connectionPool[id]
 	if !ok {
@@ -341,7 +362,12 @@ func createConnection(connCtx api.StreamContext, meta *Meta) (modules.Connection
 	}
 	conn = connRegister(connCtx)
 	sc, isStateful := conn.(modules.StatefulDialer)
-	err = conn.Provision(connCtx, meta.ID, meta.Props)
+	// resolve secrets to a copy so that the meta props only keep the references
+	props, err := secret.ResolveProps(connCtx, meta.Props)
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Provision(connCtx, meta.ID, props)
 	if err != nil {
 		return nil, err
 	}
@@ -223,3 +223,25 @@ func TestFetchConnectionNotExist(t *testing.T) {
 	_, err := FetchConnection(ctx, "2222", "mock", map[string]interface{}{"connectionSelector": "id2"}, nil)
 	require.Error(t, err)
 }
+
+func TestReloadConnection(t *testing.T) {
+	require.NoError(t, InitConnectionManager4Test())
+	ctx := context.Background()
+	cw, err := CreateNamedConnection(ctx, "reload1", "mock", nil)
+	require.NoError(t, err)
+	_, err = cw.Wait(ctx)
+	require.NoError(t, err)
+	_, err = attachConnection("reload1", "rule1_op1_0", nil)
+	require.NoError(t, err)
+	refs, err := ReloadConnection(ctx, "reload1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"rule1_op1_0"}, refs)
+	meta, err := GetConnectionDetail(ctx, "reload1")
+	require.NoError(t, err)
+	require.NotEqual(t, cw, meta.cw)
+	conn, err := meta.cw.Wait(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, conn)
+	_, err = ReloadConnection(ctx, "nonexist")
+	require.Error(t, err)
+}
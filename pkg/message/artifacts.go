@@ -21,13 +21,19 @@ import (
 )
 
 const (
-	FormatBinary     = "binary"
-	FormatJson       = "json"
-	FormatProtobuf   = "protobuf"
-	FormatDelimited  = "delimited"
-	FormatUrlEncoded = "urlencoded"
-	FormatXML        = "xml"
-	FormatCustom     = "custom"
+	FormatBinary       = "binary"
+	FormatJson         = "json"
+	FormatProtobuf     = "protobuf"
+	FormatDelimited    = "delimited"
+	FormatUrlEncoded   = "urlencoded"
+	FormatXML          = "xml"
+	FormatCustom       = "custom"
+	FormatAvro         = "avro"
+	FormatMsgpack      = "msgpack"
+	FormatCbor         = "cbor"
+	FormatSparkplugB   = "sparkplugb"
+	FormatPrometheus   = "prometheus"
+	FormatLineProtocol = "lineprotocol"
 
 	DefaultField = "self"
 	MetaKey      = "__meta"
@@ -44,6 +50,12 @@ type PartialDecoder interface {
 	DecodeField(ctx api.StreamContext, b []byte, f string) (any, error)
 }
 
+// MetaDecoder decodes bytes with the help of the message metadata such as the topic.
+// The decoder may also add extracted information to the metadata.
+type MetaDecoder interface {
+	DecodeWithMeta(ctx api.StreamContext, b []byte, meta map[string]any) (any, error)
+}
+
 type SchemaResetAbleConverter interface {
 	ResetSchema(schema map[string]*ast.JsonStreamField)
 }
@@ -69,3 +81,23 @@ type Decompressor interface {
 type Encryptor interface {
 	Encrypt([]byte) ([]byte, error)
 }
+
+// Decryptor decrypts bytes
+type Decryptor interface {
+	Decrypt([]byte) ([]byte, error)
+}
+
+// MetaDecryptor decrypts bytes with the help of the message metadata such as the key id
+type MetaDecryptor interface {
+	DecryptWithMeta(data []byte, meta map[string]any) ([]byte, error)
+}
+
+// Signer computes the signature of bytes
+type Signer interface {
+	Sign([]byte) ([]byte, error)
+}
+
+// Verifier checks if the signature matches the bytes
+type Verifier interface {
+	Verify(data []byte, sig []byte) error
+}
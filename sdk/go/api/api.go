@@ -51,6 +51,26 @@ type Source interface {
 	Closable
 }
 
+// Rewindable is an optional feature of the source to take part in the checkpoint of the rule.
+// The offset is saved with the rule checkpoint and sent back to rewind when the rule restarts.
+type Rewindable interface {
+	// GetOffset is called after each tuple is sent out to get the offset of it
+	GetOffset() (interface{}, error)
+	// Rewind is called before Open with the offset saved in the last checkpoint
+	Rewind(offset interface{}) error
+}
+
+// LookupSource is the source to query the external system on demand
+type LookupSource interface {
+	// Configure Called during initialization. Configure the source with the data source(e.g. table name) and the properties read from the yaml
+	Configure(datasource string, props map[string]interface{}) error
+	// Open is called once before lookup
+	Open(ctx StreamContext) error
+	// Lookup receive lookup values to construct the query and return query results
+	Lookup(ctx StreamContext, fields []string, keys []string, values []interface{}) ([]map[string]interface{}, error)
+	Closable
+}
+
 type Function interface {
 	// The argument is a list of xsql.Expr
 	Validate(args []interface{}) error
@@ -99,6 +119,17 @@ type StreamContext interface {
 	WithMeta(ruleId string, opId string) StreamContext
 	WithInstance(instanceId int) StreamContext
 	WithCancel() (StreamContext, context.CancelFunc)
+	// PutState State handling. The state is saved along with the rule checkpoint. Only available for sources and sinks.
+	PutState(key string, value interface{}) error
+	GetState(key string) (interface{}, error)
+	DeleteState(key string) error
+}
+
+// Store is the state storage of the rule
+type Store interface {
+	PutState(key string, value interface{}) error
+	GetState(key string) (interface{}, error)
+	DeleteState(key string) error
 }
 
 type FunctionContext interface {
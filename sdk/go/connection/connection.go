@@ -16,6 +16,7 @@ package connection
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"go.nanomsg.org/mangos/v3"
@@ -65,6 +66,30 @@ type DataInOutChannel interface {
 	Closable
 }
 
+type DataReqChannel interface {
+	Req([]byte) ([]byte, error)
+	Closable
+}
+
+// NanomsgReqChannel sends the request and waits for the reply. It is safe to be used concurrently.
+type NanomsgReqChannel struct {
+	sync.Mutex
+	sock mangos.Socket
+}
+
+func (r *NanomsgReqChannel) Req(arg []byte) ([]byte, error) {
+	r.Lock()
+	defer r.Unlock()
+	if err := r.sock.Send(arg); err != nil {
+		return nil, fmt.Errorf("can't send request: %s", err.Error())
+	}
+	return r.sock.Recv()
+}
+
+func (r *NanomsgReqChannel) Close() error {
+	return r.sock.Close()
+}
+
 type NanomsgRepChannel struct {
 	sock mangos.Socket
 }
@@ -148,6 +173,47 @@ func CreateFuncChannel(symbolName string) (DataInOutChannel, error) {
 	return &NanomsgRepChannel{sock: sock}, nil
 }
 
+// CreateLookupChannel connects to the rule to receive lookup requests
+func CreateLookupChannel(ctx api.StreamContext) (DataInOutChannel, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = req.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new req socket: %s", err)
+	}
+	// The recv should not have timeout because it is event driven
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionSendDeadline: 1000 * time.Millisecond,
+		mangos.OptionRetryTime:    0,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_lookup.ipc", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if err = sock.DialOptions(url, dialOptions); err != nil {
+		return nil, fmt.Errorf("can't dial on req socket: %s", err.Error())
+	}
+	return &NanomsgRepChannel{sock: sock}, nil
+}
+
+// CreateStateChannel connects to the rule to access the rule state
+func CreateStateChannel(ctx api.StreamContext) (DataReqChannel, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = req.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new req socket: %s", err)
+	}
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionSendDeadline: 1000 * time.Millisecond,
+		mangos.OptionRecvDeadline: 5000 * time.Millisecond,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_state.ipc", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if err = sock.DialOptions(url, dialOptions); err != nil {
+		return nil, fmt.Errorf("can't dial on req socket: %s", err.Error())
+	}
+	return &NanomsgReqChannel{sock: sock}, nil
+}
+
 func CreateSinkChannel(ctx api.StreamContext) (DataInChannel, error) {
 	var (
 		sock mangos.Socket
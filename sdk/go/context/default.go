@@ -16,6 +16,7 @@ package context
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -32,6 +33,8 @@ type DefaultContext struct {
 	ctx        context.Context
 	// Only initialized after withMeta set
 	logger api.Logger
+	// Only set for source and sink
+	store api.Store
 }
 
 func Background() *DefaultContext {
@@ -46,6 +49,21 @@ func WithValue(parent *DefaultContext, key, val interface{}) *DefaultContext {
 	return parent
 }
 
+// WithStore binds the rule state store to the context
+func WithStore(parent api.StreamContext, store api.Store) api.StreamContext {
+	c, ok := parent.(*DefaultContext)
+	if !ok {
+		return parent
+	}
+	return &DefaultContext{
+		ruleId:     c.ruleId,
+		opId:       c.opId,
+		instanceId: c.instanceId,
+		ctx:        c.ctx,
+		store:      store,
+	}
+}
+
 // Deadline Implement context interface
 func (c *DefaultContext) Deadline() (deadline time.Time, ok bool) {
 	return c.ctx.Deadline()
@@ -103,6 +121,7 @@ func (c *DefaultContext) WithInstance(instanceId int) api.StreamContext {
 		ruleId:     c.ruleId,
 		opId:       c.opId,
 		ctx:        c.ctx,
+		store:      c.store,
 	}
 }
 
@@ -113,5 +132,29 @@ func (c *DefaultContext) WithCancel() (api.StreamContext, context.CancelFunc) {
 		opId:       c.opId,
 		instanceId: c.instanceId,
 		ctx:        ctx,
+		store:      c.store,
 	}, cancel
 }
+
+var errNoState = errors.New("state is not available in this context")
+
+func (c *DefaultContext) PutState(key string, value interface{}) error {
+	if c.store == nil {
+		return errNoState
+	}
+	return c.store.PutState(key, value)
+}
+
+func (c *DefaultContext) GetState(key string) (interface{}, error) {
+	if c.store == nil {
+		return nil, errNoState
+	}
+	return c.store.GetState(key)
+}
+
+func (c *DefaultContext) DeleteState(key string) error {
+	if c.store == nil {
+		return errNoState
+	}
+	return c.store.DeleteState(key)
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/sdk/go/api"
+)
+
+func TestLookup(r api.LookupSource, fields []string, keys []string, values []interface{}, exp []map[string]interface{}, t *testing.T) {
+	ctx := newMockContext("rule1", "op1")
+	err := r.Open(ctx)
+	if err != nil {
+		t.Errorf("open error: %v", err)
+		return
+	}
+	result, err := r.Lookup(ctx, fields, keys, values)
+	if err != nil {
+		t.Errorf("lookup error: %v", err)
+		return
+	}
+	err = r.Close(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(exp, result) {
+		t.Errorf("result mismatch:\n  exp=%v\n  got=%v\n\n", exp, result)
+	}
+}
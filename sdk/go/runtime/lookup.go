@@ -0,0 +1,99 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	context2 "context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/sdk/go/api"
+	"github.com/lf-edge/ekuiper/sdk/go/connection"
+)
+
+// lookupRuntime answers the lookup requests of the rule. Its lifecycle is controlled by the plugin.
+type lookupRuntime struct {
+	s      api.LookupSource
+	ch     connection.DataInOutChannel
+	ctx    api.StreamContext
+	cancel context2.CancelFunc
+	key    string
+}
+
+func setupLookupRuntime(con *Control, s api.LookupSource) (*lookupRuntime, error) {
+	ctx, err := parseContext(con)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Configure(con.DataSource, con.Config)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := connection.CreateLookupChannel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx.GetLogger().Info("Setup lookup channel")
+	ctx, cancel := ctx.WithCancel()
+	return &lookupRuntime{
+		s:      s,
+		ch:     ch,
+		ctx:    ctx,
+		cancel: cancel,
+		key:    fmt.Sprintf("%s_%s_%d_%s", con.Meta.RuleId, con.Meta.OpId, con.Meta.InstanceId, con.SymbolName),
+	}, nil
+}
+
+func (s *lookupRuntime) run() {
+	err := s.s.Open(s.ctx)
+	if err != nil {
+		s.ctx.GetLogger().Errorf("open lookup source error: %v", err)
+		_ = s.stop()
+		return
+	}
+	err = s.ch.Run(func(req []byte) []byte {
+		d := &LookupData{}
+		err := json.Unmarshal(req, d)
+		if err != nil {
+			return encodeReply(false, err.Error())
+		}
+		s.ctx.GetLogger().Debugf("running lookup with %+v", d)
+		r, err := s.s.Lookup(s.ctx, d.Fields, d.Keys, d.Values)
+		if err != nil {
+			return encodeReply(false, err.Error())
+		}
+		return encodeReply(true, r)
+	})
+	if s.isRunning() {
+		s.ctx.GetLogger().Error(err)
+		_ = s.stop()
+	}
+}
+
+func (s *lookupRuntime) stop() error {
+	s.cancel()
+	_ = s.s.Close(s.ctx)
+	err := s.ch.Close()
+	if err != nil {
+		s.ctx.GetLogger().Info(err)
+	}
+	s.ctx.GetLogger().Info("closed lookup channel")
+	reg.Delete(s.key)
+	return nil
+}
+
+func (s *lookupRuntime) isRunning() bool {
+	return s.ctx.Err() == nil
+}
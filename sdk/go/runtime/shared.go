@@ -18,6 +18,7 @@ const (
 	TYPE_SOURCE = "source"
 	TYPE_SINK   = "sink"
 	TYPE_FUNC   = "func"
+	TYPE_LOOKUP = "lookup"
 )
 
 type Meta struct {
@@ -37,6 +38,8 @@ type Control struct {
 	PluginType string                 `json:"pluginType"`
 	DataSource string                 `json:"dataSource,omitempty"`
 	Config     map[string]interface{} `json:"config,omitempty"`
+	// Offset is the source offset saved in the last checkpoint to rewind from
+	Offset interface{} `json:"offset,omitempty"`
 }
 
 type Command struct {
@@ -66,3 +69,23 @@ type FuncReply struct {
 	State  bool        `json:"state"`
 	Result interface{} `json:"result"`
 }
+
+const (
+	STATE_GET    = "get"
+	STATE_PUT    = "put"
+	STATE_DELETE = "delete"
+)
+
+// StateData is the state request sent to the rule. The reply is a FuncReply.
+type StateData struct {
+	Cmd   string      `json:"cmd"`
+	Key   string      `json:"key"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// LookupData is the lookup request from the rule. The reply is a FuncReply.
+type LookupData struct {
+	Fields []string      `json:"fields"`
+	Keys   []string      `json:"keys"`
+	Values []interface{} `json:"values"`
+}
@@ -26,12 +26,13 @@ import (
 )
 
 type sinkRuntime struct {
-	s      api.Sink
-	ch     connection.DataInChannel
-	ackCh  connection.DataOutChannel
-	ctx    api.StreamContext
-	cancel context2.CancelFunc
-	key    string
+	s       api.Sink
+	ch      connection.DataInChannel
+	ackCh   connection.DataOutChannel
+	stateCh connection.DataReqChannel
+	ctx     api.StreamContext
+	cancel  context2.CancelFunc
+	key     string
 }
 
 func setupSinkRuntime(con *Control, s api.Sink) (*sinkRuntime, error) {
@@ -43,23 +44,31 @@ func setupSinkRuntime(con *Control, s api.Sink) (*sinkRuntime, error) {
 	if err != nil {
 		return nil, err
 	}
+	ctx, stateCh, err := setupState(ctx)
+	if err != nil {
+		return nil, err
+	}
 	ch, err := connection.CreateSinkChannel(ctx)
 	if err != nil {
+		_ = stateCh.Close()
 		return nil, err
 	}
 	ackCh, err := connection.CreateSinkAckChannel(ctx)
 	if err != nil {
+		_ = stateCh.Close()
+		_ = ch.Close()
 		return nil, err
 	}
 	ctx.GetLogger().Info("Setup message pipeline, start listening")
 	ctx, cancel := ctx.WithCancel()
 	return &sinkRuntime{
-		s:      s,
-		ch:     ch,
-		ackCh:  ackCh,
-		ctx:    ctx,
-		cancel: cancel,
-		key:    fmt.Sprintf("%s_%s_%d_%s", con.Meta.RuleId, con.Meta.OpId, con.Meta.InstanceId, con.SymbolName),
+		s:       s,
+		ch:      ch,
+		ackCh:   ackCh,
+		stateCh: stateCh,
+		ctx:     ctx,
+		cancel:  cancel,
+		key:     fmt.Sprintf("%s_%s_%d_%s", con.Meta.RuleId, con.Meta.OpId, con.Meta.InstanceId, con.SymbolName),
 	}, nil
 }
 
@@ -118,6 +127,10 @@ func (s *sinkRuntime) stop() error {
 	if err != nil {
 		s.ctx.GetLogger().Info(err)
 	}
+	err = s.stateCh.Close()
+	if err != nil {
+		s.ctx.GetLogger().Info(err)
+	}
 	s.ctx.GetLogger().Info("closed sink data channel")
 	reg.Delete(s.key)
 	return nil
@@ -26,11 +26,19 @@ import (
 // if stop by error, inform plugin
 
 type sourceRuntime struct {
-	s      api.Source
-	ch     connection.DataOutChannel
-	ctx    api.StreamContext
-	cancel context2.CancelFunc
-	key    string
+	s       api.Source
+	ch      connection.DataOutChannel
+	stateCh connection.DataReqChannel
+	ctx     api.StreamContext
+	cancel  context2.CancelFunc
+	key     string
+}
+
+// sourceMessage is the message with the offset for rewindable source
+type sourceMessage struct {
+	Message map[string]interface{} `json:"message"`
+	Meta    map[string]interface{} `json:"meta"`
+	Offset  interface{}            `json:"offset,omitempty"`
 }
 
 func setupSourceRuntime(con *Control, s api.Source) (*sourceRuntime, error) {
@@ -45,19 +53,35 @@ func setupSourceRuntime(con *Control, s api.Source) (*sourceRuntime, error) {
 	if err != nil {
 		return nil, err
 	}
+	if con.Offset != nil {
+		rw, ok := s.(api.Rewindable)
+		if !ok {
+			return nil, fmt.Errorf("source %s is not rewindable", con.SymbolName)
+		}
+		err = rw.Rewind(con.Offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx, stateCh, err := setupState(ctx)
+	if err != nil {
+		return nil, err
+	}
 	// connect to mq server
 	ch, err := connection.CreateSourceChannel(ctx)
 	if err != nil {
+		_ = stateCh.Close()
 		return nil, err
 	}
 	ctx.GetLogger().Info("Setup message pipeline, start sending")
 	ctx, cancel := ctx.WithCancel()
 	return &sourceRuntime{
-		s:      s,
-		ch:     ch,
-		ctx:    ctx,
-		cancel: cancel,
-		key:    fmt.Sprintf("%s_%s_%d_%s", con.Meta.RuleId, con.Meta.OpId, con.Meta.InstanceId, con.SymbolName),
+		s:       s,
+		ch:      ch,
+		stateCh: stateCh,
+		ctx:     ctx,
+		cancel:  cancel,
+		key:     fmt.Sprintf("%s_%s_%d_%s", con.Meta.RuleId, con.Meta.OpId, con.Meta.InstanceId, con.SymbolName),
 	}, nil
 }
 
@@ -73,7 +97,15 @@ func (s *sourceRuntime) run() {
 			s.stop()
 		case data := <-consumer:
 			s.ctx.GetLogger().Debugf("broadcast data %v", data)
-			broadcast(s.ctx, s.ch, data)
+			if rw, ok := s.s.(api.Rewindable); ok {
+				offset, err := rw.GetOffset()
+				if err != nil {
+					s.ctx.GetLogger().Errorf("get offset error: %v", err)
+				}
+				broadcast(s.ctx, s.ch, &sourceMessage{Message: data.Message(), Meta: data.Meta(), Offset: offset})
+			} else {
+				broadcast(s.ctx, s.ch, data)
+			}
 		case <-s.ctx.Done():
 			s.s.Close(s.ctx)
 			return
@@ -87,6 +119,10 @@ func (s *sourceRuntime) stop() error {
 	if err != nil {
 		s.ctx.GetLogger().Info(err)
 	}
+	err = s.stateCh.Close()
+	if err != nil {
+		s.ctx.GetLogger().Info(err)
+	}
 	s.ctx.GetLogger().Info("closed source data channel")
 	reg.Delete(s.key)
 	return nil
@@ -0,0 +1,77 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/sdk/go/api"
+	"github.com/lf-edge/ekuiper/sdk/go/connection"
+	"github.com/lf-edge/ekuiper/sdk/go/context"
+)
+
+// stateStore accesses the rule state through the state channel. The values are transferred in json,
+// so numbers are got back as float64.
+type stateStore struct {
+	ch connection.DataReqChannel
+}
+
+// setupState connects to the state service of the rule and binds the state store to the context
+func setupState(ctx api.StreamContext) (api.StreamContext, connection.DataReqChannel, error) {
+	ch, err := connection.CreateStateChannel(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return context.WithStore(ctx, &stateStore{ch: ch}), ch, nil
+}
+
+func (s *stateStore) PutState(key string, value interface{}) error {
+	_, err := s.req(STATE_PUT, key, value)
+	return err
+}
+
+func (s *stateStore) GetState(key string) (interface{}, error) {
+	return s.req(STATE_GET, key, nil)
+}
+
+func (s *stateStore) DeleteState(key string) error {
+	_, err := s.req(STATE_DELETE, key, nil)
+	return err
+}
+
+func (s *stateStore) req(cmd string, key string, value interface{}) (interface{}, error) {
+	arg, err := json.Marshal(&StateData{
+		Cmd:   cmd,
+		Key:   key,
+		Value: value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.ch.Req(arg)
+	if err != nil {
+		return nil, err
+	}
+	r := &FuncReply{}
+	err = json.Unmarshal(res, r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state reply %s: %v", string(res), err)
+	}
+	if !r.State {
+		return nil, fmt.Errorf("%s state %s error: %v", cmd, key, r.Result)
+	}
+	return r.Result, nil
+}
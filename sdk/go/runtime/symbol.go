@@ -64,6 +64,6 @@ func parseContext(con *Control) (api.StreamContext, error) {
 		return nil, errors.New(err)
 	}
 	contextLogger := context.LogEntry("rule", con.Meta.RuleId)
-	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger).WithMeta(con.Meta.RuleId, con.Meta.OpId)
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger).WithMeta(con.Meta.RuleId, con.Meta.OpId).WithInstance(con.Meta.InstanceId)
 	return ctx, nil
 }
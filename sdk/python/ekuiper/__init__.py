@@ -17,10 +17,10 @@ from ekuiper.runtime import plugin
 from ekuiper.runtime.context import Context
 from ekuiper.runtime.plugin import PluginConfig
 from ekuiper.sink import Sink
-from ekuiper.source import Source
+from ekuiper.source import Source, Rewindable, LookupSource
 
 __all__ = [
-    'plugin', 'PluginConfig', 'Source', 'Sink', 'Function', 'Context'
+    'plugin', 'PluginConfig', 'Source', 'Rewindable', 'LookupSource', 'Sink', 'Function', 'Context'
 ]
 
 name = "ekuiper"
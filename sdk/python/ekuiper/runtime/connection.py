@@ -16,6 +16,8 @@ import logging
 import time
 from typing import Callable
 
+import threading
+
 from pynng import Req0, Push0, Pull0, Timeout
 
 
@@ -53,6 +55,38 @@ class PairChannel:
         self.sock.close()
 
 
+class LookupChannel(PairChannel):
+
+    # noinspection PyMissingConstructor
+    def __init__(self, meta: dict):
+        s = Req0(resend_time=0)
+        url = "ipc:///tmp/{}_{}_{}_lookup.ipc".format(meta['ruleId'], meta['opId'],
+                                                      meta['instanceId'])
+        logging.info("dialing {}".format(url))
+        dial_with_retry(s, url)
+        self.sock = s
+
+
+class StateChannel:
+
+    def __init__(self, meta: dict):
+        s = Req0(send_timeout=1000, recv_timeout=5000)
+        url = "ipc:///tmp/{}_{}_{}_state.ipc".format(meta['ruleId'], meta['opId'],
+                                                     meta['instanceId'])
+        logging.info(url)
+        dial_with_retry(s, url)
+        self.sock = s
+        self.lock = threading.Lock()
+
+    def req(self, data: bytes) -> bytes:
+        with self.lock:
+            self.sock.send(data)
+            return self.sock.recv()
+
+    def close(self):
+        self.sock.close()
+
+
 class SourceChannel:
 
     def __init__(self, meta: dict):
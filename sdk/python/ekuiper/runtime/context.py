@@ -61,3 +61,18 @@ class Context(object):
     def ack_error(self, error: str):
         """Emit the response ack error to the sink"""
         pass
+
+    @abstractmethod
+    def put_state(self, key: str, value):
+        """Save the state of the rule, it is saved along with the rule checkpoint"""
+        pass
+
+    @abstractmethod
+    def get_state(self, key: str):
+        """Return the state of the rule or None if not found"""
+        pass
+
+    @abstractmethod
+    def delete_state(self, key: str):
+        """Delete the state of the rule"""
+        pass
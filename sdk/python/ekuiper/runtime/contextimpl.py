@@ -16,7 +16,8 @@ import json
 import logging
 import sys
 
-from .connection import SourceChannel, SinkAckChannel
+from . import shared
+from .connection import SourceChannel, SinkAckChannel, StateChannel
 from .context import Context
 
 
@@ -27,6 +28,8 @@ class ContextImpl(Context):
         self.opId = meta['opId']
         self.instanceId = meta['instanceId']
         self.emitter = None
+        self.state_ch = None
+        self.offset_func = None
 
     def set_emitter(self, emitter: SourceChannel):
         self.emitter = emitter
@@ -34,6 +37,13 @@ class ContextImpl(Context):
     def set_ack_emitter(self, emitter: SinkAckChannel):
         self.ack_emitter = emitter
 
+    def set_state_channel(self, ch: StateChannel):
+        self.state_ch = ch
+
+    def set_offset_func(self, f):
+        """f returns the offset of the emitted tuple of a rewindable source"""
+        self.offset_func = f
+
     def get_rule_id(self) -> str:
         return self.ruleId
 
@@ -48,6 +58,8 @@ class ContextImpl(Context):
 
     def emit(self, message: dict, meta: dict):
         data = {'message': message, 'meta': meta}
+        if self.offset_func is not None:
+            data['offset'] = self.offset_func()
         json_str = json.dumps(data)
         return self.emitter.send(str.encode(json_str))
 
@@ -63,4 +75,24 @@ class ContextImpl(Context):
     def ack_error(self, error: str):
         data = {'error': error}
         json_str = json.dumps(data)
-        return self.ack_emitter.send(str.encode(json_str))
\ No newline at end of file
+        return self.ack_emitter.send(str.encode(json_str))
+
+    def put_state(self, key: str, value):
+        self.state_req(shared.STATE_PUT, key, value)
+
+    def get_state(self, key: str):
+        return self.state_req(shared.STATE_GET, key)
+
+    def delete_state(self, key: str):
+        self.state_req(shared.STATE_DELETE, key)
+
+    def state_req(self, cmd: str, key: str, value=None):
+        if self.state_ch is None:
+            raise RuntimeError('state is not available in this context')
+        data = {'cmd': cmd, 'key': key}
+        if value is not None:
+            data['value'] = value
+        reply = json.loads(self.state_ch.req(str.encode(json.dumps(data))))
+        if not reply['state']:
+            raise RuntimeError('{} state {} error: {}'.format(cmd, key, reply['result']))
+        return reply['result']
@@ -0,0 +1,83 @@
+#  Copyright 2025 EMQ Technologies Co., Ltd.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+import json
+import logging
+import traceback
+
+from . import reg
+from .connection import LookupChannel
+from .function import encode_reply
+from .symbol import parse_context, SymbolRuntime
+from ..source import LookupSource
+
+
+class LookupRuntime(SymbolRuntime):
+
+    def __init__(self, ctrl: dict, s: LookupSource):
+        ctx = parse_context(ctrl)
+        ds = ""
+        config = {}
+        if 'dataSource' in ctrl:
+            ds = ctrl['dataSource']
+        if 'config' in ctrl:
+            config = ctrl['config']
+        s.configure(ds, config)
+        ch = LookupChannel(ctrl['meta'])
+        self.s = s
+        self.ctx = ctx
+        self.ch = ch
+        self.running = False
+        self.key = f"{ctrl['meta']['ruleId']}_{ctrl['meta']['opId']}" \
+                   f"_{ctrl['meta']['instanceId']}_{ctrl['symbolName']}"
+
+    def run(self):
+        logging.info('start running lookup source')
+        self.running = True
+        reg.setr(self.key, self)
+        # noinspection PyBroadException
+        try:
+            self.s.open(self.ctx)
+            self.ch.run(self.do_lookup)
+        except Exception:
+            """two occasions: normal stop will close socket to raise an error OR\
+             stopped by unexpected error"""
+            if self.running:
+                logging.error(traceback.format_exc())
+        finally:
+            if self.running:
+                self.stop()
+
+    def do_lookup(self, req: bytes):
+        # noinspection PyBroadException
+        try:
+            c = json.loads(req)
+            logging.debug("running lookup with {}".format(c))
+            r = self.s.lookup(self.ctx, c['fields'], c['keys'], c['values'])
+            return encode_reply(True, r)
+        except Exception:
+            return encode_reply(False, traceback.format_exc())
+
+    def stop(self):
+        self.running = False
+        # noinspection PyBroadException
+        try:
+            self.s.close(self.ctx)
+            self.ch.close()
+            reg.delete(self.key)
+        except Exception:
+            logging.error(traceback.format_exc())
+
+    def is_running(self) -> bool:
+        return self.running
@@ -22,22 +22,25 @@ from typing import Dict, Callable
 from . import reg, shared
 from .connection import PairChannel
 from .function import FunctionRuntime
+from .lookup import LookupRuntime
 from .sink import SinkRuntime
 from .source import SourceRuntime
 from ..function import Function
 from ..sink import Sink
-from ..source import Source
+from ..source import Source, LookupSource
 
 
 class PluginConfig:
 
     def __init__(self, name: str, sources: Dict[str, Callable[[], Source]],
                  sinks: Dict[str, Callable[[], Sink]],
-                 functions: Dict[str, Callable[[], Function]]):
+                 functions: Dict[str, Callable[[], Function]],
+                 lookup_sources: Dict[str, Callable[[], LookupSource]] = None):
         self.name = name
         self.sources = sources
         self.sinks = sinks
         self.functions = functions
+        self.lookup_sources = lookup_sources or {}
 
     def get(self, plugin_type: str, symbol_name: str):
         if plugin_type == shared.TYPE_SOURCE:
@@ -46,6 +49,8 @@ class PluginConfig:
             return self.sinks[symbol_name]
         elif plugin_type == shared.TYPE_FUNC:
             return self.functions[symbol_name]
+        elif plugin_type == shared.TYPE_LOOKUP:
+            return self.lookup_sources.get(symbol_name)
         else:
             return None
 
@@ -105,6 +110,11 @@ def command_reply(req: bytes) -> bytes:
                 runtime = SinkRuntime(ctrl, s)
                 x = threading.Thread(target=runtime.run, daemon=True)
                 x.start()
+            elif ctrl['pluginType'] == shared.TYPE_LOOKUP:
+                logging.info("running lookup source {}".format(ctrl['symbolName']))
+                runtime = LookupRuntime(ctrl, s)
+                x = threading.Thread(target=runtime.run, daemon=True)
+                x.start()
             elif ctrl['pluginType'] == shared.TYPE_FUNC:
                 logging.info("running function {}".format(ctrl['symbolName']))
                 runtime = FunctionRuntime(ctrl, s)
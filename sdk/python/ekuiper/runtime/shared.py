@@ -18,5 +18,10 @@ CMD_STOP = "stop"
 TYPE_SOURCE = "source"
 TYPE_SINK = "sink"
 TYPE_FUNC = "func"
+TYPE_LOOKUP = "lookup"
+
+STATE_GET = "get"
+STATE_PUT = "put"
+STATE_DELETE = "delete"
 
 REPLY_OK = "ok"
\ No newline at end of file
@@ -16,7 +16,7 @@ import logging
 import traceback
 
 from . import reg
-from .connection import SinkChannel, SinkAckChannel
+from .connection import SinkChannel, SinkAckChannel, StateChannel
 from .symbol import SymbolRuntime, parse_context
 from ..sink import Sink
 
@@ -29,12 +29,15 @@ class SinkRuntime(SymbolRuntime):
         if 'config' in ctrl:
             config = ctrl['config']
         s.configure(config)
+        state_ch = StateChannel(ctrl['meta'])
+        ctx.set_state_channel(state_ch)
         ch = SinkChannel(ctrl['meta'])
         ackCh = SinkAckChannel(ctrl['meta'])
         self.s = s
         self.ctx = ctx
         self.ch = ch
         self.ackCh = ackCh
+        self.state_ch = state_ch
         ctx.set_ack_emitter(ackCh)
         self.running = False
         self.key = f"{ctrl['meta']['ruleId']}_{ctrl['meta']['opId']}" \
@@ -66,6 +69,7 @@ class SinkRuntime(SymbolRuntime):
             self.s.close(self.ctx)
             self.ch.close()
             self.ackCh.close()
+            self.state_ch.close()
             reg.delete(self.key)
         except Exception:
             logging.error(traceback.format_exc())
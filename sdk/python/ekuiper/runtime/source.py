@@ -16,9 +16,9 @@ import logging
 import traceback
 
 from . import reg
-from .connection import SourceChannel
+from .connection import SourceChannel, StateChannel
 from .symbol import parse_context, SymbolRuntime
-from ..source import Source
+from ..source import Source, Rewindable
 
 
 class SourceRuntime(SymbolRuntime):
@@ -27,11 +27,19 @@ class SourceRuntime(SymbolRuntime):
         ctx = parse_context(ctrl)
         ds = ""
         config = {}
-        if 'datasource' in ctrl:
-            ds = ctrl['datasource']
+        if 'dataSource' in ctrl:
+            ds = ctrl['dataSource']
         if 'config' in ctrl:
             config = ctrl['config']
         s.configure(ds, config)
+        if isinstance(s, Rewindable):
+            if ctrl.get('offset') is not None:
+                s.rewind(ctrl['offset'])
+            ctx.set_offset_func(s.get_offset)
+        elif ctrl.get('offset') is not None:
+            raise ValueError('source {} is not rewindable'.format(ctrl['symbolName']))
+        state_ch = StateChannel(ctrl['meta'])
+        ctx.set_state_channel(state_ch)
         ch = SourceChannel(ctrl['meta'])
         ctx.set_emitter(ch)
         key = f"{ctrl['meta']['ruleId']}_{ctrl['meta']['opId']}" \
@@ -39,6 +47,7 @@ class SourceRuntime(SymbolRuntime):
         self.s = s
         self.ctx = ctx
         self.ch = ch
+        self.state_ch = state_ch
         self.running = False
         self.key = key
 
@@ -64,6 +73,7 @@ class SourceRuntime(SymbolRuntime):
         try:
             self.s.close(self.ctx)
             self.ch.close()
+            self.state_ch.close()
             reg.delete(self.key)
         except Exception:
             logging.error(traceback.format_exc())
@@ -34,3 +34,41 @@ class Source(object):
     def close(self, ctx: Context):
         """stop running and clean up"""
         pass
+
+
+class Rewindable(object):
+    """optional feature of the source to take part in the checkpoint of the rule"""
+
+    @abstractmethod
+    def get_offset(self):
+        """return the offset of the tuple just emitted, it is saved with the rule checkpoint"""
+        pass
+
+    @abstractmethod
+    def rewind(self, offset):
+        """rewind to the offset saved in the last checkpoint, called before open"""
+        pass
+
+
+class LookupSource(object):
+    """abstract class for eKuiper lookup source plugin"""
+
+    @abstractmethod
+    def configure(self, datasource: str, conf: dict):
+        """configure with the string datasource and conf map and raise error if any"""
+        pass
+
+    @abstractmethod
+    def open(self, ctx: Context):
+        """open the connection before lookup"""
+        pass
+
+    @abstractmethod
+    def lookup(self, ctx: Context, fields: list, keys: list, values: list) -> list:
+        """query with the keys and values, return the list of dict results"""
+        pass
+
+    @abstractmethod
+    def close(self, ctx: Context):
+        """close the connection and clean up"""
+        pass